@@ -3,6 +3,9 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 )
 
 var ErrResolverNotRegistered = errors.New("dag resolver not registered")
@@ -16,6 +19,7 @@ func (e *CycleError) Error() string {
 }
 
 type Engine struct {
+	mu        sync.RWMutex
 	resolvers map[NodeKind]Resolver
 }
 
@@ -26,16 +30,33 @@ func NewEngine() *Engine {
 }
 
 func (e *Engine) Register(kind NodeKind, resolver Resolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.resolvers[kind] = resolver
 }
 
+func (e *Engine) resolver(kind NodeKind) (Resolver, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	resolver, ok := e.resolvers[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResolverNotRegistered, kind)
+	}
+	return resolver, nil
+}
+
+// NewContext returns a resolve context that may be shared by many goroutines
+// and kept alive across revalidations; use Invalidate or Refresh to evict
+// stale values instead of creating a fresh context.
 func (e *Engine) NewContext() *ResolveContext {
 	return &ResolveContext{
-		engine:    e,
-		cache:     map[NodeKey]Value{},
-		deps:      map[NodeKey][]NodeKey{},
-		revDeps:   map[NodeKey][]NodeKey{},
-		resolving: map[NodeKey]bool{},
+		graph: &resolveGraph{
+			engine:   e,
+			cache:    map[NodeKey]Value{},
+			deps:     map[NodeKey][]NodeKey{},
+			revDeps:  map[NodeKey][]NodeKey{},
+			inflight: map[NodeKey]*resolveCall{},
+		},
 	}
 }
 
@@ -47,68 +68,284 @@ func (e *Engine) Resolve(ctx *ResolveContext, key NodeKey) (Value, error) {
 }
 
 type ResolveContext struct {
-	engine    *Engine
-	cache     map[NodeKey]Value
-	deps      map[NodeKey][]NodeKey
-	revDeps   map[NodeKey][]NodeKey
-	resolving map[NodeKey]bool
+	graph *resolveGraph
+	frame *resolveFrame
+}
+
+type resolveGraph struct {
+	engine   *Engine
+	mu       sync.Mutex
+	epoch    uint64
+	cache    map[NodeKey]Value
+	deps     map[NodeKey][]NodeKey
+	revDeps  map[NodeKey][]NodeKey
+	inflight map[NodeKey]*resolveCall
+}
+
+// resolveCall is a single in-flight resolution shared by every goroutine
+// asking for the same key. blockedOn points at the call this one is currently
+// waiting for, which lets Resolve detect cycles that span goroutines.
+type resolveCall struct {
+	key       NodeKey
+	epoch     uint64
+	done      chan struct{}
+	value     Value
+	err       error
+	blockedOn *resolveCall
+}
+
+type resolveFrame struct {
+	call   *resolveCall
+	parent *resolveFrame
 }
 
 func (ctx *ResolveContext) Resolve(key NodeKey) (Value, error) {
-	if value, ok := ctx.cache[key]; ok {
+	graph := ctx.graph
+	graph.mu.Lock()
+	if value, ok := graph.cache[key]; ok {
+		graph.mu.Unlock()
 		return value, nil
 	}
-	if ctx.resolving[key] {
+	if ctx.resolvingKey(key) {
+		graph.mu.Unlock()
 		return nil, &CycleError{Key: key}
 	}
 
-	resolver, ok := ctx.engine.resolvers[key.Kind]
-	if !ok {
-		return nil, fmt.Errorf("%w: %s", ErrResolverNotRegistered, key.Kind)
+	var current *resolveCall
+	if ctx.frame != nil {
+		current = ctx.frame.call
 	}
 
-	ctx.resolving[key] = true
-	defer delete(ctx.resolving, key)
+	if call, ok := graph.inflight[key]; ok && call.epoch == graph.epoch {
+		if current != nil && call.waitsOn(current) {
+			graph.mu.Unlock()
+			return nil, &CycleError{Key: key}
+		}
+		if current != nil {
+			current.blockedOn = call
+		}
+		graph.mu.Unlock()
+
+		<-call.done
 
-	result, err := resolver.Resolve(ctx, key)
+		if current != nil {
+			graph.mu.Lock()
+			current.blockedOn = nil
+			graph.mu.Unlock()
+		}
+		return call.value, call.err
+	}
+
+	resolver, err := graph.engine.resolver(key.Kind)
 	if err != nil {
+		graph.mu.Unlock()
 		return nil, err
 	}
 
-	ctx.replaceDeps(key, result.Deps)
+	call := &resolveCall{
+		key:   key,
+		epoch: graph.epoch,
+		done:  make(chan struct{}),
+	}
+	graph.inflight[key] = call
+	if current != nil {
+		current.blockedOn = call
+	}
+	graph.mu.Unlock()
+
+	ctx.run(call, resolver)
+
+	if current != nil {
+		graph.mu.Lock()
+		current.blockedOn = nil
+		graph.mu.Unlock()
+	}
+	return call.value, call.err
+}
+
+func (ctx *ResolveContext) run(call *resolveCall, resolver Resolver) {
+	graph := ctx.graph
+	child := &ResolveContext{
+		graph: graph,
+		frame: &resolveFrame{call: call, parent: ctx.frame},
+	}
+
+	finished := false
+	defer func() {
+		if !finished {
+			call.value, call.err = nil, fmt.Errorf("dag resolver panicked while resolving %s", call.key.String())
+		}
+		graph.mu.Lock()
+		if graph.inflight[call.key] == call {
+			delete(graph.inflight, call.key)
+		}
+		if call.err == nil && call.epoch == graph.epoch {
+			graph.cache[call.key] = call.value
+		}
+		graph.mu.Unlock()
+		close(call.done)
+	}()
+
+	result, err := resolver.Resolve(child, call.key)
+	if err != nil {
+		call.err = err
+		finished = true
+		return
+	}
+
+	graph.mu.Lock()
+	graph.replaceDeps(call.key, result.Deps)
+	graph.mu.Unlock()
+
 	for _, dep := range result.Deps {
-		if _, err := ctx.Resolve(dep); err != nil {
-			return nil, err
+		if _, err := child.Resolve(dep); err != nil {
+			call.err = err
+			finished = true
+			return
+		}
+	}
+
+	call.value = result.Value
+	finished = true
+}
+
+func (ctx *ResolveContext) resolvingKey(key NodeKey) bool {
+	for frame := ctx.frame; frame != nil; frame = frame.parent {
+		if frame.call.key == key {
+			return true
 		}
 	}
+	return false
+}
 
-	ctx.cache[key] = result.Value
-	return result.Value, nil
+func (c *resolveCall) waitsOn(target *resolveCall) bool {
+	seen := map[*resolveCall]struct{}{}
+	for current := c; current != nil; current = current.blockedOn {
+		if current == target {
+			return true
+		}
+		if _, ok := seen[current]; ok {
+			return false
+		}
+		seen[current] = struct{}{}
+	}
+	return false
 }
 
-func (ctx *ResolveContext) replaceDeps(key NodeKey, deps []NodeKey) {
-	if previous, ok := ctx.deps[key]; ok {
+func (g *resolveGraph) replaceDeps(key NodeKey, deps []NodeKey) {
+	if previous, ok := g.deps[key]; ok {
 		for _, dep := range previous {
-			ctx.revDeps[dep] = filterKeys(ctx.revDeps[dep], key)
+			g.revDeps[dep] = filterKeys(g.revDeps[dep], key)
 		}
 	}
 
 	next := append([]NodeKey(nil), deps...)
-	ctx.deps[key] = next
+	g.deps[key] = next
 	for _, dep := range next {
-		ctx.revDeps[dep] = appendUniqueKey(ctx.revDeps[dep], key)
+		g.revDeps[dep] = appendUniqueKey(g.revDeps[dep], key)
+	}
+}
+
+// Invalidate evicts the changed keys and everything that transitively depends
+// on them. Dependency edges are kept so AffectedFrom and ExplainPath still
+// describe the previous graph until the evicted keys are resolved again.
+func (ctx *ResolveContext) Invalidate(changed []NodeKey) []NodeKey {
+	graph := ctx.graph
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	affected := graph.affectedFrom(changed)
+	for _, key := range changed {
+		delete(graph.cache, key)
+	}
+	for _, key := range affected {
+		delete(graph.cache, key)
+	}
+	graph.epoch++
+	return affected
+}
+
+// Refresh re-runs the resolvers of the given cached keys in order and keeps
+// their dependents cached when the new value is deeply equal to the old one.
+// It returns the keys whose value changed.
+func (ctx *ResolveContext) Refresh(keys []NodeKey) ([]NodeKey, error) {
+	graph := ctx.graph
+	root := &ResolveContext{graph: graph}
+	changed := make([]NodeKey, 0)
+
+	for _, key := range keys {
+		graph.mu.Lock()
+		previous, ok := graph.cache[key]
+		graph.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		resolver, err := graph.engine.resolver(key.Kind)
+		if err != nil {
+			return changed, err
+		}
+		result, err := resolver.Resolve(root, key)
+		if err != nil {
+			return changed, err
+		}
+
+		graph.mu.Lock()
+		graph.replaceDeps(key, result.Deps)
+		if !reflect.DeepEqual(previous, result.Value) {
+			for _, affected := range graph.affectedFrom([]NodeKey{key}) {
+				delete(graph.cache, affected)
+			}
+			graph.cache[key] = result.Value
+			graph.epoch++
+			changed = append(changed, key)
+		}
+		graph.mu.Unlock()
+
+		for _, dep := range result.Deps {
+			if _, err := root.Resolve(dep); err != nil {
+				return changed, err
+			}
+		}
 	}
+
+	return changed, nil
+}
+
+func (ctx *ResolveContext) CachedKeys() []NodeKey {
+	graph := ctx.graph
+	graph.mu.Lock()
+	keys := make([]NodeKey, 0, len(graph.cache))
+	for key := range graph.cache {
+		keys = append(keys, key)
+	}
+	graph.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
 }
 
 func (ctx *ResolveContext) Dependencies(key NodeKey) []NodeKey {
-	return append([]NodeKey(nil), ctx.deps[key]...)
+	ctx.graph.mu.Lock()
+	defer ctx.graph.mu.Unlock()
+	return append([]NodeKey(nil), ctx.graph.deps[key]...)
 }
 
 func (ctx *ResolveContext) ReverseDependencies(key NodeKey) []NodeKey {
-	return append([]NodeKey(nil), ctx.revDeps[key]...)
+	ctx.graph.mu.Lock()
+	defer ctx.graph.mu.Unlock()
+	return append([]NodeKey(nil), ctx.graph.revDeps[key]...)
 }
 
 func (ctx *ResolveContext) AffectedFrom(changed []NodeKey) []NodeKey {
+	ctx.graph.mu.Lock()
+	defer ctx.graph.mu.Unlock()
+	return ctx.graph.affectedFrom(changed)
+}
+
+func (g *resolveGraph) affectedFrom(changed []NodeKey) []NodeKey {
 	queue := append([]NodeKey(nil), changed...)
 	seen := map[NodeKey]struct{}{}
 	affected := make([]NodeKey, 0, len(changed))
@@ -122,7 +359,7 @@ func (ctx *ResolveContext) AffectedFrom(changed []NodeKey) []NodeKey {
 		}
 		seen[current] = struct{}{}
 
-		for _, next := range ctx.revDeps[current] {
+		for _, next := range g.revDeps[current] {
 			queue = append(queue, next)
 			affected = appendUniqueKey(affected, next)
 		}
@@ -136,6 +373,9 @@ func (ctx *ResolveContext) ExplainPath(from, to NodeKey) []NodeKey {
 		return []NodeKey{from}
 	}
 
+	ctx.graph.mu.Lock()
+	defer ctx.graph.mu.Unlock()
+
 	type pathNode struct {
 		key  NodeKey
 		prev int
@@ -146,7 +386,7 @@ func (ctx *ResolveContext) ExplainPath(from, to NodeKey) []NodeKey {
 
 	for i := 0; i < len(queue); i++ {
 		current := queue[i]
-		for _, next := range ctx.revDeps[current.key] {
+		for _, next := range ctx.graph.revDeps[current.key] {
 			if _, ok := seen[next]; ok {
 				continue
 			}
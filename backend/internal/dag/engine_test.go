@@ -2,7 +2,10 @@ package dag
 
 import (
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type staticResolver struct {
@@ -141,6 +144,159 @@ func TestExplainPathReturnsNilWhenUnreachable(t *testing.T) {
 	}
 }
 
+func TestInvalidateEvictsOnlyAffectedClosure(t *testing.T) {
+	t.Parallel()
+
+	engine := NewEngine()
+	calls := map[NodeKey]int{}
+	var mu sync.Mutex
+	graph := map[NodeKey][]NodeKey{
+		{Kind: "node", ID: "a"}:       nil,
+		{Kind: "node", ID: "b"}:       nil,
+		{Kind: "node", ID: "route-a"}: {{Kind: "node", ID: "a"}},
+		{Kind: "node", ID: "route-b"}: {{Kind: "node", ID: "b"}},
+	}
+	engine.Register("node", ResolverFunc(func(_ *ResolveContext, key NodeKey) (ResolveResult, error) {
+		mu.Lock()
+		calls[key]++
+		mu.Unlock()
+		return ResolveResult{Value: key.ID, Deps: graph[key]}, nil
+	}))
+
+	ctx := engine.NewContext()
+	routeA := NodeKey{Kind: "node", ID: "route-a"}
+	routeB := NodeKey{Kind: "node", ID: "route-b"}
+	for _, key := range []NodeKey{routeA, routeB} {
+		if _, err := ctx.Resolve(key); err != nil {
+			t.Fatalf("Resolve(%s): %v", key.ID, err)
+		}
+	}
+
+	affected := ctx.Invalidate([]NodeKey{{Kind: "node", ID: "a"}})
+	if len(affected) != 1 || affected[0] != routeA {
+		t.Fatalf("Invalidate(a) = %#v, want [route-a]", affected)
+	}
+	if path := ctx.ExplainPath(NodeKey{Kind: "node", ID: "a"}, routeA); len(path) != 2 {
+		t.Fatalf("ExplainPath(a, route-a) after Invalidate = %#v", path)
+	}
+
+	for _, key := range []NodeKey{routeA, routeB} {
+		if _, err := ctx.Resolve(key); err != nil {
+			t.Fatalf("Resolve(%s): %v", key.ID, err)
+		}
+	}
+	if calls[routeA] != 2 || calls[NodeKey{Kind: "node", ID: "a"}] != 2 {
+		t.Fatalf("route-a closure should be resolved again: %#v", calls)
+	}
+	if calls[routeB] != 1 || calls[NodeKey{Kind: "node", ID: "b"}] != 1 {
+		t.Fatalf("route-b closure should stay cached: %#v", calls)
+	}
+}
+
+func TestConcurrentResolveSharesInflightWork(t *testing.T) {
+	t.Parallel()
+
+	engine := NewEngine()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	engine.Register("slow", ResolverFunc(func(_ *ResolveContext, key NodeKey) (ResolveResult, error) {
+		calls.Add(1)
+		<-release
+		return ResolveResult{Value: key.ID}, nil
+	}))
+	engine.Register("route", ResolverFunc(func(ctx *ResolveContext, key NodeKey) (ResolveResult, error) {
+		dep := NodeKey{Kind: "slow", ID: "shared"}
+		if _, err := ctx.Resolve(dep); err != nil {
+			return ResolveResult{}, err
+		}
+		return ResolveResult{Value: key.ID, Deps: []NodeKey{dep}}, nil
+	}))
+
+	ctx := engine.NewContext()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := ctx.Resolve(NodeKey{Kind: "route", ID: string(rune('a' + i))})
+			errs <- err
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Resolve(route): %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("shared dependency resolved %d times, want 1", got)
+	}
+	if reverse := ctx.ReverseDependencies(NodeKey{Kind: "slow", ID: "shared"}); len(reverse) != 8 {
+		t.Fatalf("ReverseDependencies(shared) length = %d, want 8", len(reverse))
+	}
+}
+
+func TestRefreshEvictsDependentsOnlyWhenValueChanges(t *testing.T) {
+	t.Parallel()
+
+	engine := NewEngine()
+	source := NodeKey{Kind: "source", ID: "a"}
+	route := NodeKey{Kind: "route", ID: "/posts/a/"}
+	current := "v1"
+	routeCalls := 0
+	engine.Register(source.Kind, ResolverFunc(func(_ *ResolveContext, _ NodeKey) (ResolveResult, error) {
+		return ResolveResult{Value: current}, nil
+	}))
+	engine.Register(route.Kind, ResolverFunc(func(ctx *ResolveContext, _ NodeKey) (ResolveResult, error) {
+		routeCalls++
+		value, err := ctx.Resolve(source)
+		if err != nil {
+			return ResolveResult{}, err
+		}
+		return ResolveResult{Value: "route:" + value.(string), Deps: []NodeKey{source}}, nil
+	}))
+
+	ctx := engine.NewContext()
+	if _, err := ctx.Resolve(route); err != nil {
+		t.Fatalf("Resolve(route): %v", err)
+	}
+
+	changed, err := ctx.Refresh([]NodeKey{source})
+	if err != nil {
+		t.Fatalf("Refresh unchanged: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("Refresh unchanged = %#v, want none", changed)
+	}
+	if _, err := ctx.Resolve(route); err != nil {
+		t.Fatalf("Resolve(route): %v", err)
+	}
+	if routeCalls != 1 {
+		t.Fatalf("route resolved %d times after unchanged refresh, want 1", routeCalls)
+	}
+
+	current = "v2"
+	changed, err = ctx.Refresh([]NodeKey{source})
+	if err != nil {
+		t.Fatalf("Refresh changed: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != source {
+		t.Fatalf("Refresh changed = %#v, want [source]", changed)
+	}
+	value, err := ctx.Resolve(route)
+	if err != nil {
+		t.Fatalf("Resolve(route): %v", err)
+	}
+	if value != "route:v2" || routeCalls != 2 {
+		t.Fatalf("Resolve(route) = %#v after %d calls", value, routeCalls)
+	}
+}
+
 type ResolverFunc func(ctx *ResolveContext, key NodeKey) (ResolveResult, error)
 
 func (f ResolverFunc) Resolve(ctx *ResolveContext, key NodeKey) (ResolveResult, error) {
@@ -0,0 +1,50 @@
+// Package mediaref finds references to media library files in stored
+// content. The CMS writes two forms: PocketBase file URLs
+// (/api/files/media/<id>/<file>) and public upload paths (/uploads/<name>).
+package mediaref
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CollectionID is the id PocketBase derives for the "media" collection.
+const CollectionID = "pbc_2708086759"
+
+var FileURLPattern = regexp.MustCompile("(?i)(?:https?://[^\"'\\s)]+)?/api/files/([a-zA-Z0-9_-]+)/([a-zA-Z0-9_-]+)/([^\"'\\s)]+)")
+
+var UploadPathPattern = regexp.MustCompile(`/uploads/[^"'\s)<>?#]+`)
+
+// Ref is one media reference: either a record id or an upload path.
+type Ref struct {
+	MediaID string
+	Path    string
+}
+
+func IsMediaCollection(collection string) bool {
+	return collection == "media" || collection == CollectionID
+}
+
+// Find returns the distinct media references in text, in order of first
+// appearance.
+func Find(text string) []Ref {
+	refs := []Ref{}
+	seen := map[Ref]struct{}{}
+	add := func(ref Ref) {
+		if _, ok := seen[ref]; ok {
+			return
+		}
+		seen[ref] = struct{}{}
+		refs = append(refs, ref)
+	}
+	for _, match := range FileURLPattern.FindAllStringSubmatch(text, -1) {
+		if len(match) < 4 || !IsMediaCollection(match[1]) {
+			continue
+		}
+		add(Ref{MediaID: match[2]})
+	}
+	for _, match := range UploadPathPattern.FindAllString(text, -1) {
+		add(Ref{Path: strings.TrimRight(match, ".,;:")})
+	}
+	return refs
+}
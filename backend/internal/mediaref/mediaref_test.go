@@ -0,0 +1,26 @@
+package mediaref
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	body := `<p><img src="http://127.0.0.1:8090/api/files/media/abc123/photo.jpg">` +
+		`<img src="/api/files/pbc_2708086759/def456/x.png"> <a href="/api/files/posts/zzz/a.png">post file</a>` +
+		`<img src='/uploads/hash.webp'> see /uploads/hash.webp, and /uploads/other.png.</p>` +
+		`<img src="/api/files/media/abc123/photo.jpg?thumb=100x100">`
+
+	want := []Ref{
+		{MediaID: "abc123"},
+		{MediaID: "def456"},
+		{Path: "/uploads/hash.webp"},
+		{Path: "/uploads/other.png"},
+	}
+	if got := Find(body); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Find = %#v, want %#v", got, want)
+	}
+	if got := Find("no media here"); len(got) != 0 {
+		t.Fatalf("Find on plain text = %#v", got)
+	}
+}
@@ -13,11 +13,18 @@ func Run() {
 
 	app := pocketbase.New()
 	registerTranslationFeatures(app)
+	registerTranslationReviewFeatures(app)
 	registerSlugGenerationAPI(app)
 	registerBackupImportCommand(app)
 	registerMediaChecksumBackfillCommand(app)
+	registerMediaDimensionsBackfillCommand(app)
+	registerMediaGCCommand(app)
+	registerMediaReferenceTracking(app)
 	registerMediaOptimizationHooks(app)
+	registerMediaDedupeHooks(app)
 	registerStaticRegenHooks(app)
+	registerSnapshotGenerationAPI(app)
+	registerPublishScheduler(app)
 
 	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
 		if err := e.Next(); err != nil {
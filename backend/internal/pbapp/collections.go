@@ -10,6 +10,11 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 )
 
+const (
+	legacyPublicVisibilityRule = `@request.auth.id != "" || (published = true && published_at <= @now)`
+	publicVisibilityRule       = `@request.auth.id != "" || (published = true && published_at <= @now && (unpublish_at = "" || unpublish_at > @now))`
+)
+
 func ensureCollections(app core.App) error {
 	cmsUsers, err := ensureCollection(app, core.CollectionTypeAuth, "cms_users", func(c *core.Collection) error {
 		setRuleIfNil(&c.ListRule, `@request.auth.id != ""`)
@@ -64,7 +69,7 @@ func ensureCollections(app core.App) error {
 		return err
 	}
 
-	_, err = ensureCollection(app, core.CollectionTypeBase, "media", func(c *core.Collection) error {
+	mediaCollection, err := ensureCollection(app, core.CollectionTypeBase, "media", func(c *core.Collection) error {
 		setRuleIfNil(&c.ListRule, `@request.auth.id != "" || public = true`)
 		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" || public = true`)
 		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
@@ -86,6 +91,12 @@ func ensureCollections(app core.App) error {
 			Name: "checksum",
 			Max:  128,
 		})
+		// Hash of the bytes as uploaded, before optimization; checksum is the
+		// hash of the stored file.
+		addFieldIfMissing(c, &core.TextField{
+			Name: "source_checksum",
+			Max:  128,
+		})
 		addFieldIfMissing(c, &core.AutodateField{
 			Name:     "uploaded_at",
 			OnCreate: true,
@@ -101,7 +112,12 @@ func ensureCollections(app core.App) error {
 		addFieldIfMissing(c, &core.BoolField{
 			Name: "public",
 		})
+		// Intrinsic size of the stored file, used for srcset and width/height
+		// attributes on the public site.
+		addFieldIfMissing(c, &core.NumberField{Name: "width", OnlyInt: true})
+		addFieldIfMissing(c, &core.NumberField{Name: "height", OnlyInt: true})
 		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_media_checksum` ON `media` (`checksum`) WHERE `checksum` != ''")
+		addIndexIfMissing(c, "CREATE INDEX `idx_media_source_checksum` ON `media` (`source_checksum`)")
 
 		return nil
 	})
@@ -113,9 +129,51 @@ func ensureCollections(app core.App) error {
 		return err
 	}
 
-	_, err = ensureCollection(app, core.CollectionTypeBase, "pages", func(c *core.Collection) error {
-		setRuleIfNil(&c.ListRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
-		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
+	_, err = ensureCollection(app, core.CollectionTypeBase, "authors", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `id != ""`)
+		setRuleIfNil(&c.ViewRule, `id != ""`)
+		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || (@request.auth.role = "editor" && user = @request.auth.id))`)
+		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || (@request.auth.role = "editor" && user = @request.auth.id))`)
+		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+
+		addFieldIfMissing(c, &core.RelationField{
+			Name:         "user",
+			CollectionId: cmsUsers.Id,
+			MaxSelect:    1,
+			MinSelect:    1,
+			Required:     true,
+		})
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "slug",
+			Required: true,
+			Max:      120,
+			Pattern:  `^[a-z0-9]+(?:-[a-z0-9]+)*$`,
+		})
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "display_name",
+			Required: true,
+			Max:      120,
+		})
+		addFieldIfMissing(c, &core.TextField{Name: "bio"})
+		addFieldIfMissing(c, &core.RelationField{
+			Name:         "avatar",
+			CollectionId: mediaCollection.Id,
+			MaxSelect:    1,
+			MinSelect:    0,
+		})
+		addFieldIfMissing(c, &core.URLField{Name: "url"})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_authors_user` ON `authors` (user)")
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_authors_slug` ON `authors` (slug)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pagesCollection, err := ensureCollection(app, core.CollectionTypeBase, "pages", func(c *core.Collection) error {
+		upgradeRuleIfDefault(&c.ListRule, legacyPublicVisibilityRule, publicVisibilityRule)
+		upgradeRuleIfDefault(&c.ViewRule, legacyPublicVisibilityRule, publicVisibilityRule)
 		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
@@ -149,6 +207,9 @@ func ensureCollections(app core.App) error {
 		addFieldIfMissing(c, &core.DateField{
 			Name: "published_at",
 		})
+		addFieldIfMissing(c, &core.DateField{
+			Name: "unpublish_at",
+		})
 		addFieldIfMissing(c, &core.BoolField{
 			Name: "published",
 		})
@@ -163,8 +224,8 @@ func ensureCollections(app core.App) error {
 	}
 
 	postsCollection, err := ensureCollection(app, core.CollectionTypeBase, "posts", func(c *core.Collection) error {
-		setRuleIfNil(&c.ListRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
-		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
+		upgradeRuleIfDefault(&c.ListRule, legacyPublicVisibilityRule, publicVisibilityRule)
+		upgradeRuleIfDefault(&c.ViewRule, legacyPublicVisibilityRule, publicVisibilityRule)
 		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
@@ -191,6 +252,9 @@ func ensureCollections(app core.App) error {
 		addFieldIfMissing(c, &core.DateField{
 			Name: "published_at",
 		})
+		addFieldIfMissing(c, &core.DateField{
+			Name: "unpublish_at",
+		})
 		addFieldIfMissing(c, &core.BoolField{
 			Name: "published",
 		})
@@ -222,8 +286,8 @@ func ensureCollections(app core.App) error {
 	}
 
 	_, err = ensureCollection(app, core.CollectionTypeBase, "post_translations", func(c *core.Collection) error {
-		setRuleIfNil(&c.ListRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
-		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" || (published = true && published_at <= @now)`)
+		upgradeRuleIfDefault(&c.ListRule, legacyPublicVisibilityRule, publicVisibilityRule)
+		upgradeRuleIfDefault(&c.ViewRule, legacyPublicVisibilityRule, publicVisibilityRule)
 		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
@@ -262,8 +326,15 @@ func ensureCollections(app core.App) error {
 			MinSelect:    0,
 		})
 		addFieldIfMissing(c, &core.DateField{Name: "published_at"})
+		addFieldIfMissing(c, &core.DateField{Name: "unpublish_at"})
 		addFieldIfMissing(c, &core.BoolField{Name: "published"})
 		addFieldIfMissing(c, &core.BoolField{Name: "translation_done"})
+		addFieldIfMissing(c, &core.JSONField{Name: "translation_segments"})
+		addFieldIfMissing(c, &core.SelectField{
+			Name:      "translation_status",
+			MaxSelect: 1,
+			Values:    translationStatuses,
+		})
 		addFieldIfMissing(c, &core.FileField{Name: "featured_image", MaxSelect: 1})
 		addFieldIfMissing(c, &core.FileField{Name: "attachments", MaxSelect: 10})
 
@@ -275,6 +346,152 @@ func ensureCollections(app core.App) error {
 		return err
 	}
 
+	_, err = ensureCollection(app, core.CollectionTypeBase, "page_translations", func(c *core.Collection) error {
+		upgradeRuleIfDefault(&c.ListRule, legacyPublicVisibilityRule, publicVisibilityRule)
+		upgradeRuleIfDefault(&c.ViewRule, legacyPublicVisibilityRule, publicVisibilityRule)
+		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+
+		addFieldIfMissing(c, &core.RelationField{
+			Name:          "source_page",
+			CollectionId:  pagesCollection.Id,
+			MaxSelect:     1,
+			MinSelect:     1,
+			CascadeDelete: true,
+		})
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "locale",
+			Required: true,
+			Max:      20,
+		})
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "title",
+			Required: true,
+		})
+		addFieldIfMissing(c, &core.EditorField{
+			Name:        "body",
+			Required:    true,
+			ConvertURLs: false,
+		})
+		addFieldIfMissing(c, &core.TextField{Name: "menuTitle"})
+		addFieldIfMissing(c, &core.DateField{Name: "published_at"})
+		addFieldIfMissing(c, &core.DateField{Name: "unpublish_at"})
+		addFieldIfMissing(c, &core.BoolField{Name: "published"})
+		addFieldIfMissing(c, &core.BoolField{Name: "translation_done"})
+		addFieldIfMissing(c, &core.JSONField{Name: "translation_segments"})
+		addFieldIfMissing(c, &core.SelectField{
+			Name:      "translation_status",
+			MaxSelect: 1,
+			Values:    translationStatuses,
+		})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_page_translations_source_locale` ON `page_translations` (source_page, locale)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureCollection(app, core.CollectionTypeBase, "series", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `id != ""`)
+		setRuleIfNil(&c.ViewRule, `id != ""`)
+		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "title",
+			Required: true,
+		})
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "slug",
+			Required: true,
+			Pattern:  `^[a-z0-9]+(?:-[a-z0-9]+)*$`,
+		})
+		addFieldIfMissing(c, &core.TextField{Name: "description"})
+		// posts keeps the reading order; translated parts are found through
+		// their source post.
+		addFieldIfMissing(c, &core.RelationField{
+			Name:         "posts",
+			CollectionId: postsCollection.Id,
+			MaxSelect:    200,
+			MinSelect:    0,
+		})
+		addFieldIfMissing(c, &core.JSONField{Name: "title_translations"})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_series_slug` ON `series` (slug)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureCollection(app, core.CollectionTypeBase, "themes", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `id != ""`)
+		setRuleIfNil(&c.ViewRule, `id != ""`)
+		setRuleIfNil(&c.CreateRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+
+		// name is what the Theme setting and ?theme= refer to.
+		addFieldIfMissing(c, &core.TextField{
+			Name:     "name",
+			Required: true,
+			Max:      60,
+			Pattern:  `^[a-z0-9][a-z0-9_-]*$`,
+		})
+		addFieldIfMissing(c, &core.TextField{Name: "description"})
+		addFieldIfMissing(c, &core.FileField{
+			Name:      "package",
+			Required:  true,
+			MaxSelect: 1,
+			MaxSize:   10 << 20,
+			MimeTypes: []string{"application/zip", "application/x-zip-compressed"},
+		})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_themes_name` ON `themes` (name)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureCollection(app, core.CollectionTypeBase, "media_refs", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+
+		// One row per (media, source record, field); maintained by the save
+		// hooks in media_refs.go.
+		addFieldIfMissing(c, &core.RelationField{
+			Name:          "media",
+			CollectionId:  mediaCollection.Id,
+			Required:      true,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		addFieldIfMissing(c, &core.TextField{Name: "source_collection", Required: true, Max: 80})
+		addFieldIfMissing(c, &core.TextField{Name: "source_id", Required: true, Max: 40})
+		addFieldIfMissing(c, &core.TextField{Name: "field", Max: 80})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_media_refs_unique` ON `media_refs` (media, source_collection, source_id, field)")
+		addIndexIfMissing(c, "CREATE INDEX `idx_media_refs_source` ON `media_refs` (source_collection, source_id)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
 	_, err = ensureCollection(app, core.CollectionTypeBase, "translation_jobs", func(c *core.Collection) error {
 		setRuleIfNil(&c.ListRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
 		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
@@ -303,6 +520,72 @@ func ensureCollections(app core.App) error {
 		return err
 	}
 
+	_, err = ensureCollection(app, core.CollectionTypeBase, "translation_memory", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		// Writes stay superuser-only: memory entries are copied into published
+		// translations, and the translation hooks save them directly.
+
+		addFieldIfMissing(c, &core.TextField{Name: "source_hash", Required: true, Max: 64})
+		addFieldIfMissing(c, &core.TextField{Name: "source_locale", Max: 20})
+		addFieldIfMissing(c, &core.TextField{Name: "target_locale", Required: true, Max: 20})
+		addFieldIfMissing(c, &core.TextField{Name: "source_text"})
+		addFieldIfMissing(c, &core.TextField{Name: "translated_text"})
+		addFieldIfMissing(c, &core.TextField{Name: "provider", Max: 40})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		addIndexIfMissing(c, "CREATE UNIQUE INDEX `idx_translation_memory_hash_locales` ON `translation_memory` (source_hash, source_locale, target_locale)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureCollection(app, core.CollectionTypeBase, "revalidation_outbox", func(c *core.Collection) error {
+		setRuleIfNil(&c.ListRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.ViewRule, `@request.auth.id != "" && (@request.auth.role = "admin" || @request.auth.role = "editor")`)
+		setRuleIfNil(&c.UpdateRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
+
+		addFieldIfMissing(c, &core.TextField{Name: "collection", Required: true, Max: 80})
+		addFieldIfMissing(c, &core.TextField{Name: "action", Max: 20})
+		addFieldIfMissing(c, &core.TextField{Name: "record_id", Max: 40})
+		addFieldIfMissing(c, &core.TextField{Name: "dedupe_key", Max: 160})
+		addFieldIfMissing(c, &core.JSONField{Name: "current"})
+		addFieldIfMissing(c, &core.JSONField{Name: "original"})
+		addFieldIfMissing(c, &core.TextField{Name: "status", Max: 40})
+		addFieldIfMissing(c, &core.NumberField{Name: "attempts"})
+		addFieldIfMissing(c, &core.NumberField{Name: "last_status"})
+		addFieldIfMissing(c, &core.TextField{Name: "last_error"})
+		addFieldIfMissing(c, &core.DateField{Name: "next_attempt_at"})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+		addFieldIfMissing(c, &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		addIndexIfMissing(c, "CREATE INDEX `idx_revalidation_outbox_status_next` ON `revalidation_outbox` (status, next_attempt_at)")
+		addIndexIfMissing(c, "CREATE INDEX `idx_revalidation_outbox_dedupe` ON `revalidation_outbox` (dedupe_key, status)")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
 	if err := migrateLegacyPostTranslations(app, postsCollection); err != nil {
 		return err
 	}
@@ -326,6 +609,8 @@ func ensureCollections(app core.App) error {
 		addFieldIfMissing(c, &core.TextField{Name: "comments_script_tag"})
 		addFieldIfMissing(c, &core.BoolField{Name: "enable_code_highlight"})
 		addFieldIfMissing(c, &core.TextField{Name: "highlight_theme"})
+		addFieldIfMissing(c, &core.TextField{Name: "media_encoder", Max: 20})
+		addFieldIfMissing(c, &core.BoolField{Name: "media_keep_metadata"})
 		addFieldIfMissing(c, &core.NumberField{Name: "archive_page_size"})
 		addFieldIfMissing(c, &core.NumberField{Name: "excerpt_length"})
 		addFieldIfMissing(c, &core.NumberField{Name: "home_page_size"})
@@ -349,7 +634,12 @@ func ensureCollections(app core.App) error {
 		addFieldIfMissing(c, &core.TextField{Name: "translation_source_locale"})
 		addFieldIfMissing(c, &core.TextField{Name: "translation_locales"})
 		addFieldIfMissing(c, &core.TextField{Name: "translation_model"})
+		addFieldIfMissing(c, &core.TextField{Name: "translation_provider", Max: 40})
+		addFieldIfMissing(c, &core.TextField{Name: "translation_endpoint"})
+		addFieldIfMissing(c, &core.JSONField{Name: "translation_locale_providers"})
 		addFieldIfMissing(c, &core.NumberField{Name: "translation_requests_per_minute"})
+		addFieldIfMissing(c, &core.BoolField{Name: "translation_approved_only"})
+		addFieldIfMissing(c, &core.JSONField{Name: "ui_strings"})
 		existingGeminiKey := c.Fields.GetByName("gemini_api_key")
 		if existingGeminiKey == nil {
 			c.Fields.Add(&core.TextField{Name: "gemini_api_key", Hidden: true})
@@ -375,6 +665,8 @@ func ensureCollections(app core.App) error {
 		setRuleIfNil(&c.DeleteRule, `@request.auth.id != "" && @request.auth.role = "admin"`)
 
 		addFieldIfMissing(c, &core.TextField{Name: "gemini_api_key"})
+		addFieldIfMissing(c, &core.TextField{Name: "openai_api_key"})
+		addFieldIfMissing(c, &core.TextField{Name: "deepl_api_key"})
 		return nil
 	})
 	if err != nil {
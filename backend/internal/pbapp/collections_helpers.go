@@ -67,3 +67,11 @@ func setRuleIfNil(ptr **string, rule string) {
 	value := rule
 	*ptr = &value
 }
+
+func upgradeRuleIfDefault(ptr **string, previous, rule string) {
+	if *ptr != nil && **ptr != previous {
+		return
+	}
+	value := rule
+	*ptr = &value
+}
@@ -0,0 +1,160 @@
+package pbapp
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// Raw (non-field) record keys used to pass dedupe state between the create
+// request hook and the validate hook.
+const (
+	mediaAllowDuplicateKey = "@allowDuplicate"
+	mediaDuplicateOfKey    = "@duplicateOf"
+)
+
+var errDuplicateMedia = validation.Errors{
+	"file": validation.NewError("validation_media_duplicate", "A media item with the same content already exists."),
+}
+
+var errMediaPathTaken = validation.Errors{
+	"path": validation.NewError("validation_media_path_taken", "Another media item already uses this path."),
+}
+
+func registerMediaDedupeHooks(app *pocketbase.PocketBase) {
+	// A duplicate upload answers with the existing record instead of an
+	// error, so clients can use the result the same way as a fresh upload.
+	// ?allow_duplicate=true stores a second copy on purpose.
+	app.OnRecordCreateRequest("media").BindFunc(func(e *core.RecordRequestEvent) error {
+		if e.Request.URL.Query().Get("allow_duplicate") == "true" {
+			e.Record.SetRaw(mediaAllowDuplicateKey, true)
+		}
+
+		err := e.Next()
+		existing, ok := e.Record.GetRaw(mediaDuplicateOfKey).(*core.Record)
+		if err == nil || !ok || existing == nil {
+			return err
+		}
+		if err := apis.EnrichRecord(e.RequestEvent, existing); err != nil {
+			return err
+		}
+		existing.WithCustomData(true)
+		existing.Set("duplicate", true)
+		return e.JSON(http.StatusOK, existing)
+	})
+}
+
+// dedupeUnsavedMedia runs after optimization. sourceChecksum is the hash of
+// the bytes as uploaded; the stored checksum is the hash of what is kept.
+// Either matching an existing record makes the upload a duplicate.
+func dedupeUnsavedMedia(app core.App, record *core.Record, sourceChecksum string) error {
+	files := record.GetUnsavedFiles("file")
+	if len(files) == 0 || files[0] == nil {
+		return nil
+	}
+	storedChecksum, err := unsavedFileChecksum(files[0])
+	if err != nil {
+		return err
+	}
+	record.Set("source_checksum", sourceChecksum)
+
+	if allow, _ := record.GetRaw(mediaAllowDuplicateKey).(bool); allow {
+		// The unique checksum index keeps one canonical record per content;
+		// intentional copies go without, like backfilled duplicates. Their
+		// path carries the record id so it never lands on another record's.
+		record.Set("checksum", "")
+		if record.Id == "" {
+			record.SetRaw(core.FieldNameId, core.GenerateDefaultRandomId())
+		}
+		if strings.TrimSpace(record.GetString("path")) == "" {
+			record.Set("path", buildDuplicateUploadPath(files[0].Name, storedChecksum, record.Id))
+		}
+	} else {
+		existing, err := findMediaByChecksums(app, record.Id, storedChecksum, sourceChecksum)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			record.SetRaw(mediaDuplicateOfKey, existing)
+			return errDuplicateMedia
+		}
+		record.Set("checksum", storedChecksum)
+	}
+
+	if strings.TrimSpace(record.GetString("path")) == "" {
+		record.Set("path", buildUploadPath(files[0].Name, record.GetString("checksum")))
+	}
+	taken, err := mediaPathTaken(app, record.Id, record.GetString("path"))
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errMediaPathTaken
+	}
+	return nil
+}
+
+func mediaPathTaken(app core.App, excludeID, path string) (bool, error) {
+	if strings.TrimSpace(path) == "" {
+		return false, nil
+	}
+	var count int
+	err := app.RecordQuery("media").
+		Select("count(*)").
+		AndWhere(dbx.HashExp{"path": path}).
+		AndWhere(dbx.Not(dbx.HashExp{"id": excludeID})).
+		Row(&count)
+	return count > 0, err
+}
+
+// buildDuplicateUploadPath is buildUploadPath for an intentional copy: the
+// content path of the canonical record plus the copy's record id.
+func buildDuplicateUploadPath(filename, checksum, recordID string) string {
+	return buildUploadPath(filename, strings.TrimSpace(checksum)+"-"+recordID)
+}
+
+func unsavedFileChecksum(file *filesystem.File) (string, error) {
+	reader, err := file.Reader.Open()
+	if err != nil {
+		return "", fmt.Errorf("open uploaded media: %w", err)
+	}
+	defer reader.Close()
+	return hashReaderSHA256Hex(reader)
+}
+
+func findMediaByChecksums(app core.App, excludeID string, checksums ...string) (*core.Record, error) {
+	values := make([]any, 0, len(checksums))
+	for _, checksum := range checksums {
+		if checksum = strings.TrimSpace(strings.ToLower(checksum)); checksum != "" {
+			values = append(values, checksum)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	query := app.RecordQuery("media").
+		AndWhere(dbx.Or(dbx.In("checksum", values...), dbx.In("source_checksum", values...))).
+		OrderBy("uploaded_at asc", "id asc").
+		Limit(1)
+	if excludeID != "" {
+		query = query.AndWhere(dbx.Not(dbx.HashExp{"id": excludeID}))
+	}
+	existing := &core.Record{}
+	if err := query.One(existing); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
@@ -0,0 +1,133 @@
+package pbapp
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/spf13/cobra"
+	_ "golang.org/x/image/webp"
+)
+
+// setUnsavedMediaDimensions records the intrinsic size of a new upload. It
+// runs after WebP conversion so the size matches the stored file.
+func setUnsavedMediaDimensions(record *core.Record) {
+	for _, file := range record.GetUnsavedFiles("file") {
+		if file == nil {
+			continue
+		}
+		width, height, err := uploadedImageDimensions(file)
+		if err != nil {
+			slog.Warn("media dimensions unavailable", "name", file.OriginalName, "error", err)
+			continue
+		}
+		record.Set("width", width)
+		record.Set("height", height)
+	}
+}
+
+func uploadedImageDimensions(file *filesystem.File) (int, int, error) {
+	reader, err := file.Reader.Open()
+	if err != nil {
+		return 0, 0, fmt.Errorf("open uploaded media: %w", err)
+	}
+	defer reader.Close()
+	return imageDimensions(reader)
+}
+
+func imageDimensions(reader io.Reader) (int, int, error) {
+	config, _, err := image.DecodeConfig(reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+func registerMediaDimensionsBackfillCommand(app *pocketbase.PocketBase) {
+	cmd := &cobra.Command{
+		Use:   "backfill-media-dimensions",
+		Short: "Store width and height for media records that do not have them yet",
+		RunE: func(command *cobra.Command, _ []string) error {
+			if err := app.Bootstrap(); err != nil {
+				return err
+			}
+
+			stats, err := backfillMediaDimensions(app)
+			_, _ = fmt.Fprintf(
+				command.OutOrStdout(),
+				"Backfill result: scanned=%d updated=%d skipped=%d failed=%d\n",
+				stats.Scanned,
+				stats.Updated,
+				stats.Skipped,
+				stats.Failed,
+			)
+			return err
+		},
+	}
+
+	app.RootCmd.AddCommand(cmd)
+}
+
+func backfillMediaDimensions(app core.App) (backfillStats, error) {
+	stats := backfillStats{}
+
+	mediaCollection, err := app.FindCollectionByNameOrId("media")
+	if err != nil {
+		return stats, err
+	}
+	records := make([]*core.Record, 0)
+	if err := app.RecordQuery(mediaCollection).
+		AndWhere(dbx.NewExp("width = 0 OR height = 0 OR width IS NULL OR height IS NULL")).
+		OrderBy("uploaded_at asc", "id asc").
+		All(&records); err != nil {
+		return stats, err
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return stats, err
+	}
+	defer fsys.Close()
+
+	for _, record := range records {
+		stats.Scanned++
+
+		filename := strings.TrimSpace(record.GetString("file"))
+		if filename == "" {
+			stats.Skipped++
+			continue
+		}
+		reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + filename)
+		if err != nil {
+			stats.Failed++
+			continue
+		}
+		width, height, sizeErr := imageDimensions(reader)
+		closeErr := reader.Close()
+		if sizeErr != nil || closeErr != nil {
+			stats.Skipped++
+			continue
+		}
+
+		record.Set("width", width)
+		record.Set("height", height)
+		if err := app.Save(record); err != nil {
+			stats.Failed++
+			continue
+		}
+		stats.Updated++
+	}
+
+	if stats.Failed > 0 {
+		return stats, fmt.Errorf("backfill completed with %d failed records", stats.Failed)
+	}
+	return stats, nil
+}
@@ -0,0 +1,39 @@
+package pbapp
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestUploadedImageDimensions(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	file, err := filesystem.NewFileFromBytes(buf.Bytes(), "photo.png")
+	if err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+
+	width, height, err := uploadedImageDimensions(file)
+	if err != nil {
+		t.Fatalf("uploadedImageDimensions: %v", err)
+	}
+	if width != 64 || height != 48 {
+		t.Fatalf("dimensions = %dx%d, want 64x48", width, height)
+	}
+
+	text, err := filesystem.NewFileFromBytes([]byte("not an image"), "note.txt")
+	if err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+	if _, _, err := uploadedImageDimensions(text); err == nil {
+		t.Fatalf("non-image upload should fail")
+	}
+}
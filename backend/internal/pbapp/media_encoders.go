@@ -0,0 +1,237 @@
+package pbapp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"alleycat-backend/internal/webpenc"
+
+	_ "golang.org/x/image/tiff"
+)
+
+const (
+	mediaEncoderAuto  = "auto"
+	mediaEncoderGo    = "go"
+	mediaEncoderCWebP = "cwebp"
+	mediaEncoderNone  = "none"
+
+	mediaPassthroughJPEGQuality = 90
+	// maxDecodedMediaPixels keeps a hostile upload from allocating gigabytes
+	// while decoding; larger images skip in-process processing.
+	maxDecodedMediaPixels = 80_000_000
+)
+
+// errMediaEncoderSkipped tells the chain to try the next encoder.
+var errMediaEncoderSkipped = errors.New("media encoder skipped")
+
+// MediaEncoder turns one prepared upload into the bytes that get stored.
+// Encoders return errMediaEncoderSkipped for uploads they do not handle.
+type MediaEncoder interface {
+	Encode(upload mediaUpload) (mediaEncoded, error)
+}
+
+type mediaUpload struct {
+	Name   string
+	Data   []byte
+	Format string
+	// Image holds the decoded pixels, already upright unless metadata is
+	// kept. It is nil when the upload could not be decoded.
+	Image        image.Image
+	Exif         []byte
+	Orientation  int
+	KeepMetadata bool
+}
+
+type mediaEncoded struct {
+	Name string
+	Data []byte
+}
+
+type mediaOptimizationSettings struct {
+	Encoder      string
+	KeepMetadata bool
+}
+
+// goWebPEncoder writes lossless WebP in-process. Lossy sources are left to
+// the next encoder because a lossless copy of them only grows.
+type goWebPEncoder struct{}
+
+type cwebpEncoder struct{}
+
+// passthroughEncoder keeps the original format and only strips metadata.
+type passthroughEncoder struct{}
+
+func mediaEncoderChain(settings mediaOptimizationSettings) []MediaEncoder {
+	switch settings.Encoder {
+	case mediaEncoderGo:
+		return []MediaEncoder{goWebPEncoder{}, passthroughEncoder{}}
+	case mediaEncoderCWebP:
+		return []MediaEncoder{cwebpEncoder{}, passthroughEncoder{}}
+	case mediaEncoderNone:
+		return []MediaEncoder{passthroughEncoder{}}
+	default:
+		return []MediaEncoder{goWebPEncoder{}, cwebpEncoder{}, passthroughEncoder{}}
+	}
+}
+
+func normalizeMediaEncoder(value string) string {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case mediaEncoderGo, mediaEncoderCWebP, mediaEncoderNone:
+		return value
+	default:
+		return mediaEncoderAuto
+	}
+}
+
+func encodeMediaUpload(upload mediaUpload, chain []MediaEncoder) (mediaEncoded, error) {
+	var lastErr error
+	for _, encoder := range chain {
+		encoded, err := encoder.Encode(upload)
+		if err == nil {
+			return encoded, nil
+		}
+		if !errors.Is(err, errMediaEncoderSkipped) {
+			slog.Warn("media encoder failed", "encoder", fmt.Sprintf("%T", encoder), "name", upload.Name, "error", err)
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		lastErr = errMediaEncoderSkipped
+	}
+	return mediaEncoded{}, lastErr
+}
+
+func prepareMediaUpload(data []byte, name string, settings mediaOptimizationSettings) mediaUpload {
+	upload := mediaUpload{
+		Name:         name,
+		Data:         data,
+		Format:       mediaUploadFormat(data, name),
+		KeepMetadata: settings.KeepMetadata,
+	}
+	upload.Exif = uploadExif(data, upload.Format)
+	upload.Orientation = exifOrientation(upload.Exif)
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxDecodedMediaPixels {
+		return upload
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return upload
+	}
+	if !upload.KeepMetadata {
+		img = applyExifOrientation(img, upload.Orientation)
+	}
+	upload.Image = img
+	return upload
+}
+
+func mediaUploadFormat(data []byte, name string) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/webp":
+		return "webp"
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".png":
+		return "png"
+	case ".webp":
+		return "webp"
+	case ".tif", ".tiff":
+		return "tiff"
+	}
+	return ""
+}
+
+// needsOrientationFix reports whether the stored pixels must be rewritten
+// for the image to display upright once EXIF is gone.
+func (u mediaUpload) needsOrientationFix() bool {
+	return !u.KeepMetadata && u.Orientation > 1
+}
+
+func (goWebPEncoder) Encode(upload mediaUpload) (mediaEncoded, error) {
+	if upload.Image == nil || upload.Format == "jpeg" || (upload.Format == "webp" && isLossyWebP(upload.Data)) {
+		return mediaEncoded{}, errMediaEncoderSkipped
+	}
+	options := &webpenc.Options{}
+	if upload.KeepMetadata {
+		options.Exif = upload.Exif
+	}
+	var out bytes.Buffer
+	if err := webpenc.Encode(&out, upload.Image, options); err != nil {
+		return mediaEncoded{}, fmt.Errorf("encode media to webp: %w", err)
+	}
+	if out.Len() >= len(upload.Data) && !upload.needsOrientationFix() {
+		return mediaEncoded{}, errMediaEncoderSkipped
+	}
+	return mediaEncoded{Name: webpUploadName(upload.Name), Data: out.Bytes()}, nil
+}
+
+func (cwebpEncoder) Encode(upload mediaUpload) (mediaEncoded, error) {
+	if _, err := exec.LookPath("cwebp"); err != nil {
+		return mediaEncoded{}, errMediaEncoderSkipped
+	}
+	input, inputName := upload.Data, upload.Name
+	if upload.needsOrientationFix() && upload.Image != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, upload.Image); err != nil {
+			return mediaEncoded{}, fmt.Errorf("encode upright media: %w", err)
+		}
+		input, inputName = buf.Bytes(), "upright.png"
+	}
+	output, err := convertImageBytesWithCWebP(input, inputName, upload.KeepMetadata)
+	if err != nil {
+		return mediaEncoded{}, err
+	}
+	return mediaEncoded{Name: webpUploadName(upload.Name), Data: output}, nil
+}
+
+func (passthroughEncoder) Encode(upload mediaUpload) (mediaEncoded, error) {
+	if upload.KeepMetadata {
+		return mediaEncoded{Name: upload.Name, Data: upload.Data}, nil
+	}
+
+	if upload.Image != nil && (upload.needsOrientationFix() || upload.Format == "tiff") {
+		var buf bytes.Buffer
+		name := upload.Name
+		var err error
+		switch upload.Format {
+		case "jpeg":
+			err = jpeg.Encode(&buf, upload.Image, &jpeg.Options{Quality: mediaPassthroughJPEGQuality})
+		case "webp":
+			err = webpenc.Encode(&buf, upload.Image, nil)
+		default:
+			err = png.Encode(&buf, upload.Image)
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + ".png"
+		}
+		if err != nil {
+			return mediaEncoded{}, fmt.Errorf("rewrite upright media: %w", err)
+		}
+		return mediaEncoded{Name: name, Data: buf.Bytes()}, nil
+	}
+
+	data := upload.Data
+	switch upload.Format {
+	case "jpeg":
+		data = stripJPEGMetadata(data)
+	case "png":
+		data = stripPNGMetadata(data)
+	case "webp":
+		data = stripWebPMetadata(data)
+	}
+	return mediaEncoded{Name: upload.Name, Data: data}, nil
+}
@@ -0,0 +1,147 @@
+package pbapp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testExif builds a little-endian TIFF header with a single orientation entry.
+func testExif(orientation uint16) []byte {
+	buf := make([]byte, 8+2+12+4)
+	copy(buf, "II*\x00")
+	binary.LittleEndian.PutUint32(buf[4:], 8)
+	binary.LittleEndian.PutUint16(buf[8:], 1)
+	binary.LittleEndian.PutUint16(buf[10:], exifOrientationTag)
+	binary.LittleEndian.PutUint16(buf[12:], 3)
+	binary.LittleEndian.PutUint32(buf[14:], 1)
+	binary.LittleEndian.PutUint16(buf[18:], orientation)
+	return buf
+}
+
+func testJPEGWithExif(t *testing.T, img image.Image, exif []byte) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	payload := append([]byte("Exif\x00\x00"), exif...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	out := append([]byte{}, encoded.Bytes()[:2]...)
+	out = append(out, segment...)
+	out = append(out, payload...)
+	return append(out, encoded.Bytes()[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	t.Parallel()
+
+	if got := exifOrientation(testExif(6)); got != 6 {
+		t.Fatalf("exifOrientation = %d, want 6", got)
+	}
+	if got := exifOrientation(testExif(42)); got != 1 {
+		t.Fatalf("invalid orientation should be ignored, got %d", got)
+	}
+	if got := exifOrientation([]byte("junk")); got != 1 {
+		t.Fatalf("malformed exif should be ignored, got %d", got)
+	}
+}
+
+func TestApplyExifOrientation(t *testing.T) {
+	t.Parallel()
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 0, blue)
+
+	// Orientation 6 rotates 90 degrees clockwise: the left pixel ends on top.
+	got := applyExifOrientation(src, 6)
+	if b := got.Bounds(); b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("bounds = %v, want 1x2", b)
+	}
+	if got.At(0, 0) != red || got.At(0, 1) != blue {
+		t.Fatalf("unexpected pixels after rotation: %v %v", got.At(0, 0), got.At(0, 1))
+	}
+
+	mirrored := applyExifOrientation(src, 2)
+	if mirrored.At(0, 0) != blue || mirrored.At(1, 0) != red {
+		t.Fatalf("unexpected pixels after mirroring")
+	}
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	t.Parallel()
+
+	data := testJPEGWithExif(t, image.NewGray(image.Rect(0, 0, 4, 4)), testExif(1))
+	if uploadExif(data, "jpeg") == nil {
+		t.Fatalf("test jpeg should carry exif")
+	}
+	stripped := stripJPEGMetadata(data)
+	if uploadExif(stripped, "jpeg") != nil {
+		t.Fatalf("exif should be removed")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Fatalf("stripped jpeg should decode: %v", err)
+	}
+}
+
+func TestMediaEncoderChain(t *testing.T) {
+	t.Parallel()
+
+	flat := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for i := range flat.Pix {
+		flat.Pix[i] = 0x80
+	}
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, flat); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	settings := mediaOptimizationSettings{Encoder: mediaEncoderGo}
+	encoded, err := encodeMediaUpload(prepareMediaUpload(pngData.Bytes(), "flat.png", settings), mediaEncoderChain(settings))
+	if err != nil {
+		t.Fatalf("go encoder chain: %v", err)
+	}
+	if encoded.Name != "flat.webp" {
+		t.Fatalf("name = %q, want flat.webp", encoded.Name)
+	}
+	if _, err := webp.Decode(bytes.NewReader(encoded.Data)); err != nil {
+		t.Fatalf("go encoder output should decode: %v", err)
+	}
+
+	// JPEG sources skip the lossless encoder; passthrough rotates them and
+	// drops EXIF.
+	photo := testJPEGWithExif(t, image.NewGray(image.Rect(0, 0, 8, 4)), testExif(6))
+	settings = mediaOptimizationSettings{Encoder: mediaEncoderGo}
+	encoded, err = encodeMediaUpload(prepareMediaUpload(photo, "photo.jpg", settings), mediaEncoderChain(settings))
+	if err != nil {
+		t.Fatalf("passthrough chain: %v", err)
+	}
+	if encoded.Name != "photo.jpg" || uploadExif(encoded.Data, "jpeg") != nil {
+		t.Fatalf("passthrough should keep the name and strip exif")
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(encoded.Data))
+	if err != nil || config.Width != 4 || config.Height != 8 {
+		t.Fatalf("passthrough should store upright pixels, got %dx%d (%v)", config.Width, config.Height, err)
+	}
+
+	settings = mediaOptimizationSettings{Encoder: mediaEncoderNone, KeepMetadata: true}
+	encoded, err = encodeMediaUpload(prepareMediaUpload(photo, "photo.jpg", settings), mediaEncoderChain(settings))
+	if err != nil || !bytes.Equal(encoded.Data, photo) {
+		t.Fatalf("keeping metadata should leave the upload untouched (%v)", err)
+	}
+
+	if _, err := encodeMediaUpload(mediaUpload{}, nil); !errors.Is(err, errMediaEncoderSkipped) {
+		t.Fatalf("empty chain should report a skip, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package pbapp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+type mediaGCStats struct {
+	Scanned    int
+	Orphans    int
+	Duplicates int
+	Kept       int
+	Deleted    int
+	Failed     int
+}
+
+type mediaGCCandidate struct {
+	Record      *core.Record
+	Reason      string
+	CanonicalID string
+	Referenced  bool
+}
+
+func registerMediaGCCommand(app *pocketbase.PocketBase) {
+	var remove bool
+	var minAge time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "media-gc",
+		Short: "List (or delete) media that nothing references, and checksum duplicates",
+		RunE: func(command *cobra.Command, _ []string) error {
+			if err := app.Bootstrap(); err != nil {
+				return err
+			}
+
+			stats, err := runMediaGC(app, command.OutOrStdout(), remove, minAge)
+			_, _ = fmt.Fprintf(
+				command.OutOrStdout(),
+				"Media GC result: scanned=%d orphans=%d duplicates=%d kept=%d deleted=%d failed=%d\n",
+				stats.Scanned,
+				stats.Orphans,
+				stats.Duplicates,
+				stats.Kept,
+				stats.Deleted,
+				stats.Failed,
+			)
+			return err
+		},
+	}
+
+	cmd.Long = "Rebuild the media reference index, then report media records that no post, page,\n" +
+		"translation, series, author or setting uses, plus duplicates of the same file.\n" +
+		"Nothing is deleted unless --delete is given. Referenced duplicates are always kept."
+	cmd.Flags().BoolVar(&remove, "delete", false, "delete the listed media records")
+	cmd.Flags().DurationVar(&minAge, "min-age", 24*time.Hour, "skip media uploaded more recently than this")
+
+	app.RootCmd.AddCommand(cmd)
+}
+
+func runMediaGC(app core.App, out io.Writer, remove bool, minAge time.Duration) (mediaGCStats, error) {
+	stats := mediaGCStats{}
+
+	if _, err := rebuildMediaRefs(app); err != nil {
+		return stats, err
+	}
+
+	mediaCollection, err := app.FindCollectionByNameOrId("media")
+	if err != nil {
+		return stats, err
+	}
+	records := make([]*core.Record, 0)
+	if err := app.RecordQuery(mediaCollection).
+		OrderBy("uploaded_at asc", "id asc").
+		All(&records); err != nil {
+		return stats, err
+	}
+
+	referenced := map[string]bool{}
+	refs, err := app.FindAllRecords("media_refs")
+	if err != nil {
+		return stats, err
+	}
+	for _, ref := range refs {
+		referenced[ref.GetString("media")] = true
+	}
+
+	stats.Scanned = len(records)
+	cutoff := time.Now().Add(-minAge)
+	for _, candidate := range mediaGCCandidates(records, referenced) {
+		if uploaded := candidate.Record.GetDateTime("uploaded_at"); !uploaded.IsZero() && uploaded.Time().After(cutoff) {
+			continue
+		}
+
+		path := candidate.Record.GetString("path")
+		if candidate.Reason == "duplicate" {
+			stats.Duplicates++
+			if candidate.Referenced {
+				stats.Kept++
+				_, _ = fmt.Fprintf(out, "duplicate %s of %s %s (kept: referenced)\n", candidate.Record.Id, candidate.CanonicalID, path)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "duplicate %s of %s %s\n", candidate.Record.Id, candidate.CanonicalID, path)
+		} else {
+			stats.Orphans++
+			_, _ = fmt.Fprintf(out, "orphan %s %s\n", candidate.Record.Id, path)
+		}
+
+		if !remove {
+			continue
+		}
+		if err := app.Delete(candidate.Record); err != nil {
+			stats.Failed++
+			continue
+		}
+		stats.Deleted++
+	}
+
+	if stats.Failed > 0 {
+		return stats, fmt.Errorf("media gc completed with %d failed deletions", stats.Failed)
+	}
+	return stats, nil
+}
+
+// mediaGCCandidates groups records by checksum (or, for duplicates the
+// checksum backfill already folded, by shared path). The earliest record of a
+// group is canonical; the rest are duplicates. Unreferenced canonical records
+// are orphans. records must be sorted by upload time.
+func mediaGCCandidates(records []*core.Record, referenced map[string]bool) []mediaGCCandidate {
+	canonicalByKey := map[string]string{}
+	candidates := []mediaGCCandidate{}
+	for _, record := range records {
+		keys := []string{}
+		if checksum := strings.TrimSpace(record.GetString("checksum")); checksum != "" {
+			keys = append(keys, "checksum:"+checksum)
+		}
+		if path := strings.TrimSpace(record.GetString("path")); path != "" {
+			keys = append(keys, "path:"+path)
+		}
+
+		canonicalID := ""
+		for _, key := range keys {
+			if id, ok := canonicalByKey[key]; ok {
+				canonicalID = id
+				break
+			}
+		}
+		if canonicalID != "" {
+			candidates = append(candidates, mediaGCCandidate{
+				Record:      record,
+				Reason:      "duplicate",
+				CanonicalID: canonicalID,
+				Referenced:  referenced[record.Id],
+			})
+			continue
+		}
+
+		for _, key := range keys {
+			canonicalByKey[key] = record.Id
+		}
+		if !referenced[record.Id] {
+			candidates = append(candidates, mediaGCCandidate{Record: record, Reason: "orphan"})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,260 @@
+package pbapp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const exifOrientationTag = 0x0112
+
+// uploadExif returns the TIFF-structured EXIF payload embedded in a JPEG,
+// PNG or WebP file.
+func uploadExif(data []byte, format string) []byte {
+	switch format {
+	case "jpeg":
+		for _, segment := range jpegSegments(data) {
+			if segment.marker == 0xe1 && bytes.HasPrefix(segment.payload, []byte("Exif\x00\x00")) {
+				return segment.payload[6:]
+			}
+		}
+	case "png":
+		for _, c := range pngChunks(data) {
+			if c.kind == "eXIf" {
+				return c.payload
+			}
+		}
+	case "webp":
+		for _, c := range webpChunks(data) {
+			if c.kind == "EXIF" {
+				return bytes.TrimPrefix(c.payload, []byte("Exif\x00\x00"))
+			}
+		}
+	}
+	return nil
+}
+
+// exifOrientation reads tag 0x0112 from IFD0. Missing or invalid values
+// mean the pixels are already upright.
+func exifOrientation(exif []byte) int {
+	if len(exif) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(exif[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(exif[4:8]))
+	if offset < 8 || offset+2 > len(exif) {
+		return 1
+	}
+	count := int(order.Uint16(exif[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(exif) {
+			break
+		}
+		if order.Uint16(exif[entry:]) != exifOrientationTag {
+			continue
+		}
+		value := int(order.Uint16(exif[entry+8:]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+	return 1
+}
+
+// applyExifOrientation returns the upright version of img for an EXIF
+// orientation value.
+func applyExifOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
+
+type jpegSegment struct {
+	marker  byte
+	start   int
+	end     int
+	payload []byte
+}
+
+// jpegSegments lists the marker segments before the image data. It returns
+// nil when the stream is malformed.
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+	segments := []jpegSegment{}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return nil
+		}
+		marker := data[i+1]
+		if marker == 0xff {
+			i++
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 {
+			segments = append(segments, jpegSegment{marker: marker, start: i, end: len(data)})
+			return segments
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return nil
+		}
+		segments = append(segments, jpegSegment{marker: marker, start: i, end: i + 2 + size, payload: data[i+4 : i+2+size]})
+		i += 2 + size
+	}
+	return nil
+}
+
+// stripJPEGMetadata drops EXIF/XMP (APP1), IPTC (APP13) and comments while
+// keeping the ICC profile and the compressed data byte for byte.
+func stripJPEGMetadata(data []byte) []byte {
+	segments := jpegSegments(data)
+	if len(segments) == 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	for _, segment := range segments {
+		switch segment.marker {
+		case 0xe1, 0xed, 0xfe:
+			continue
+		}
+		out = append(out, data[segment.start:segment.end]...)
+	}
+	return out
+}
+
+type mediaChunk struct {
+	kind    string
+	start   int
+	end     int
+	payload []byte
+}
+
+func pngChunks(data []byte) []mediaChunk {
+	if len(data) < 8 || string(data[1:4]) != "PNG" {
+		return nil
+	}
+	chunks := []mediaChunk{}
+	for i := 8; i+12 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + size
+		if size < 0 || end > len(data) {
+			return nil
+		}
+		chunks = append(chunks, mediaChunk{kind: string(data[i+4 : i+8]), start: i, end: end, payload: data[i+8 : i+8+size]})
+		i = end
+	}
+	return chunks
+}
+
+func stripPNGMetadata(data []byte) []byte {
+	chunks := pngChunks(data)
+	if len(chunks) == 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	for _, c := range chunks {
+		switch c.kind {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+			continue
+		}
+		out = append(out, data[c.start:c.end]...)
+	}
+	return out
+}
+
+func webpChunks(data []byte) []mediaChunk {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil
+	}
+	chunks := []mediaChunk{}
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1
+		if size < 0 || i+8+size > len(data) {
+			return nil
+		}
+		chunks = append(chunks, mediaChunk{kind: string(data[i : i+4]), start: i, end: min(end, len(data)), payload: data[i+8 : i+8+size]})
+		i = end
+	}
+	return chunks
+}
+
+func isLossyWebP(data []byte) bool {
+	for _, c := range webpChunks(data) {
+		if c.kind == "VP8 " {
+			return true
+		}
+	}
+	return false
+}
+
+func stripWebPMetadata(data []byte) []byte {
+	chunks := webpChunks(data)
+	if len(chunks) == 0 {
+		return data
+	}
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	for _, c := range chunks {
+		switch c.kind {
+		case "EXIF", "XMP ":
+			continue
+		}
+		start := len(out)
+		out = append(out, data[c.start:c.end]...)
+		if c.kind == "VP8X" && len(out) > start+8 {
+			// Clear the EXIF and XMP flags.
+			out[start+8] &^= 0x08 | 0x04
+		}
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
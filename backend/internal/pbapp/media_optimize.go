@@ -20,19 +20,42 @@ const mediaWebPQuality = 82
 
 func registerMediaOptimizationHooks(app *pocketbase.PocketBase) {
 	app.OnRecordValidate("media").BindFunc(func(e *core.RecordEvent) error {
-		if err := convertUnsavedMediaFilesToWebP(e.Record); err != nil {
+		files := e.Record.GetUnsavedFiles("file")
+		if len(files) == 0 || files[0] == nil {
+			return e.Next()
+		}
+		sourceChecksum, err := unsavedFileChecksum(files[0])
+		if err != nil {
+			return err
+		}
+		if err := optimizeUnsavedMediaFiles(e.Record, loadMediaOptimizationSettings(e.App)); err != nil {
+			return err
+		}
+		setUnsavedMediaDimensions(e.Record)
+		if err := dedupeUnsavedMedia(e.App, e.Record, sourceChecksum); err != nil {
 			return err
 		}
 		return e.Next()
 	})
 }
 
-func convertUnsavedMediaFilesToWebP(record *core.Record) error {
+func loadMediaOptimizationSettings(app core.App) mediaOptimizationSettings {
+	record, err := app.FindFirstRecordByFilter("settings", "id != ''")
+	if err != nil || record == nil {
+		return mediaOptimizationSettings{Encoder: mediaEncoderAuto}
+	}
+	return mediaOptimizationSettings{
+		Encoder:      normalizeMediaEncoder(record.GetString("media_encoder")),
+		KeepMetadata: record.GetBool("media_keep_metadata"),
+	}
+}
+
+func optimizeUnsavedMediaFiles(record *core.Record, settings mediaOptimizationSettings) error {
 	for _, file := range record.GetUnsavedFiles("file") {
 		if file == nil {
 			continue
 		}
-		optimized, err := convertUploadedImageToWebP(file)
+		optimized, err := optimizeUploadedImage(file, settings)
 		if err != nil {
 			slog.Warn("media image optimization failed", "name", file.OriginalName, "size", file.Size, "error", err)
 			return err
@@ -45,7 +68,9 @@ func convertUnsavedMediaFilesToWebP(record *core.Record) error {
 	return nil
 }
 
-func convertUploadedImageToWebP(file *filesystem.File) (*filesystem.File, error) {
+// optimizeUploadedImage runs an upload through the encoder chain. It
+// returns nil when the stored file should stay as uploaded.
+func optimizeUploadedImage(file *filesystem.File, settings mediaOptimizationSettings) (*filesystem.File, error) {
 	reader, err := file.Reader.Open()
 	if err != nil {
 		return nil, fmt.Errorf("open uploaded media: %w", err)
@@ -60,19 +85,23 @@ func convertUploadedImageToWebP(file *filesystem.File) (*filesystem.File, error)
 		return nil, nil
 	}
 
-	output, err := convertImageBytesWithCWebP(input, file.OriginalName)
+	upload := prepareMediaUpload(input, file.OriginalName, settings)
+	encoded, err := encodeMediaUpload(upload, mediaEncoderChain(settings))
 	if err != nil {
 		return nil, err
 	}
+	if encoded.Name == file.OriginalName && bytes.Equal(encoded.Data, input) {
+		return nil, nil
+	}
 
-	converted, err := filesystem.NewFileFromBytes(output, webpUploadName(file.OriginalName))
+	optimized, err := filesystem.NewFileFromBytes(encoded.Data, encoded.Name)
 	if err != nil {
 		return nil, fmt.Errorf("create optimized media file: %w", err)
 	}
-	return converted, nil
+	return optimized, nil
 }
 
-func convertImageBytesWithCWebP(input []byte, originalName string) ([]byte, error) {
+func convertImageBytesWithCWebP(input []byte, originalName string, keepMetadata bool) ([]byte, error) {
 	if _, err := exec.LookPath("cwebp"); err != nil {
 		return nil, fmt.Errorf("cwebp command not found: %w", err)
 	}
@@ -89,7 +118,11 @@ func convertImageBytesWithCWebP(input []byte, originalName string) ([]byte, erro
 		return nil, fmt.Errorf("write media temp input: %w", err)
 	}
 
-	cmd := exec.Command("cwebp", "-quiet", "-q", fmt.Sprintf("%d", mediaWebPQuality), "-metadata", "none", inputPath, "-o", outputPath)
+	metadata := "none"
+	if keepMetadata {
+		metadata = "all"
+	}
+	cmd := exec.Command("cwebp", "-quiet", "-q", fmt.Sprintf("%d", mediaWebPQuality), "-metadata", metadata, inputPath, "-o", outputPath)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return nil, fmt.Errorf("convert media to webp: %w: %s", err, strings.TrimSpace(string(out)))
 	}
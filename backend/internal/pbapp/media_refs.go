@@ -0,0 +1,311 @@
+package pbapp
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"alleycat-backend/internal/mediaref"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// mediaReferenceFields lists the text fields scanned for media URLs.
+var mediaReferenceFields = map[string][]string{
+	"posts":             {"body", "content", "excerpt"},
+	"pages":             {"body", "content"},
+	"post_translations": {"body", "excerpt"},
+	"page_translations": {"body"},
+	"series":            {"description"},
+	"authors":           {"bio"},
+	"settings":          {"home_top_image", "footer_html"},
+}
+
+// mediaReferenceRelations lists relation fields that point at media directly.
+var mediaReferenceRelations = map[string][]string{
+	"authors": {"avatar"},
+}
+
+type mediaRefKey struct {
+	MediaID string
+	Field   string
+}
+
+type mediaUsage struct {
+	Collection string `json:"collection"`
+	ID         string `json:"id"`
+	Field      string `json:"field"`
+	Title      string `json:"title"`
+	Slug       string `json:"slug,omitempty"`
+}
+
+type mediaUsageResponse struct {
+	ID    string       `json:"id"`
+	Path  string       `json:"path"`
+	Usage []mediaUsage `json:"usage"`
+}
+
+func mediaReferenceCollections() []string {
+	names := make([]string, 0, len(mediaReferenceFields))
+	for name := range mediaReferenceFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func registerMediaReferenceTracking(app *pocketbase.PocketBase) {
+	collections := mediaReferenceCollections()
+
+	syncHook := func(e *core.RecordEvent) error {
+		if err := syncMediaRefs(e.App, e.Record); err != nil {
+			slog.Warn("media reference sync failed", "collection", e.Record.Collection().Name, "id", e.Record.Id, "error", err)
+		}
+		return e.Next()
+	}
+	app.OnRecordAfterCreateSuccess(collections...).BindFunc(syncHook)
+	app.OnRecordAfterUpdateSuccess(collections...).BindFunc(syncHook)
+	app.OnRecordAfterDeleteSuccess(collections...).BindFunc(func(e *core.RecordEvent) error {
+		if err := deleteMediaRefsForSource(e.App, e.Record.Collection().Name, e.Record.Id); err != nil {
+			slog.Warn("media reference cleanup failed", "collection", e.Record.Collection().Name, "id", e.Record.Id, "error", err)
+		}
+		return e.Next()
+	})
+
+	// Deleting media that content still points at breaks pages, so the API
+	// refuses unless the caller passes ?force=true.
+	app.OnRecordDeleteRequest("media").BindFunc(func(e *core.RecordRequestEvent) error {
+		if e.Request.URL.Query().Get("force") == "true" {
+			return e.Next()
+		}
+		usage, err := findMediaUsage(e.App, e.Record.Id)
+		if err != nil {
+			return err
+		}
+		if len(usage) > 0 {
+			return apis.NewApiError(
+				http.StatusConflict,
+				fmt.Sprintf("This media is still used by %d record(s). Remove the references first or delete with ?force=true.", len(usage)),
+				nil,
+			)
+		}
+		return e.Next()
+	})
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if count, err := se.App.CountRecords("media_refs"); err == nil && count == 0 {
+			if _, err := rebuildMediaRefs(se.App); err != nil {
+				slog.Warn("media reference index build failed", "error", err)
+			}
+		}
+
+		se.Router.GET("/api/media/{id}/usage", func(e *core.RequestEvent) error {
+			if err := requireEditorOrAdminAuth(e); err != nil {
+				return err
+			}
+
+			media, err := e.App.FindRecordById("media", e.Request.PathValue("id"))
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return apis.NewNotFoundError("Media not found.", nil)
+				}
+				return err
+			}
+			usage, err := findMediaUsage(e.App, media.Id)
+			if err != nil {
+				return err
+			}
+			return e.JSON(http.StatusOK, mediaUsageResponse{ID: media.Id, Path: media.GetString("path"), Usage: usage})
+		}).Bind(apis.RequireAuth())
+
+		return se.Next()
+	})
+}
+
+// extractMediaRefs returns the media references held by a source record,
+// keyed by media id and field. Upload paths resolve through resolvePath.
+func extractMediaRefs(record *core.Record, resolvePath func(path string) string) []mediaRefKey {
+	collection := record.Collection().Name
+	keys := []mediaRefKey{}
+	seen := map[mediaRefKey]struct{}{}
+	add := func(key mediaRefKey) {
+		if key.MediaID == "" {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	for _, field := range mediaReferenceFields[collection] {
+		for _, ref := range mediaref.Find(record.GetString(field)) {
+			id := ref.MediaID
+			if id == "" {
+				id = resolvePath(ref.Path)
+			}
+			add(mediaRefKey{MediaID: id, Field: field})
+		}
+	}
+	for _, field := range mediaReferenceRelations[collection] {
+		for _, id := range record.GetStringSlice(field) {
+			add(mediaRefKey{MediaID: strings.TrimSpace(id), Field: field})
+		}
+	}
+	return keys
+}
+
+// mediaPathResolver maps upload paths to the earliest media record using
+// them; duplicates left by the checksum backfill share a path.
+func mediaPathResolver(app core.App) func(path string) string {
+	cache := map[string]string{}
+	return func(path string) string {
+		if id, ok := cache[path]; ok {
+			return id
+		}
+		id := ""
+		record := &core.Record{}
+		err := app.RecordQuery("media").
+			AndWhere(dbx.HashExp{"path": path}).
+			OrderBy("uploaded_at asc", "id asc").
+			Limit(1).
+			One(record)
+		if err == nil {
+			id = record.Id
+		}
+		cache[path] = id
+		return id
+	}
+}
+
+func syncMediaRefs(app core.App, record *core.Record) error {
+	return syncMediaRefsWithResolver(app, record, mediaPathResolver(app))
+}
+
+func syncMediaRefsWithResolver(app core.App, record *core.Record, resolvePath func(path string) string) error {
+	collection := record.Collection().Name
+	wanted := map[mediaRefKey]struct{}{}
+	for _, key := range extractMediaRefs(record, resolvePath) {
+		if _, err := app.FindRecordById("media", key.MediaID); err != nil {
+			continue
+		}
+		wanted[key] = struct{}{}
+	}
+
+	existing, err := app.FindAllRecords("media_refs", dbx.HashExp{"source_collection": collection, "source_id": record.Id})
+	if err != nil {
+		return err
+	}
+	for _, ref := range existing {
+		key := mediaRefKey{MediaID: ref.GetString("media"), Field: ref.GetString("field")}
+		if _, ok := wanted[key]; ok {
+			delete(wanted, key)
+			continue
+		}
+		if err := app.Delete(ref); err != nil {
+			return err
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	refsCollection, err := app.FindCollectionByNameOrId("media_refs")
+	if err != nil {
+		return err
+	}
+	for key := range wanted {
+		ref := core.NewRecord(refsCollection)
+		ref.Set("media", key.MediaID)
+		ref.Set("source_collection", collection)
+		ref.Set("source_id", record.Id)
+		ref.Set("field", key.Field)
+		if err := app.Save(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteMediaRefsForSource(app core.App, collection, id string) error {
+	refs, err := app.FindAllRecords("media_refs", dbx.HashExp{"source_collection": collection, "source_id": id})
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if err := app.Delete(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildMediaRefs rescans every source record and returns how many were
+// indexed.
+func rebuildMediaRefs(app core.App) (int, error) {
+	resolvePath := mediaPathResolver(app)
+	scanned := 0
+	for _, collection := range mediaReferenceCollections() {
+		if _, err := app.FindCollectionByNameOrId(collection); err != nil {
+			continue
+		}
+		records, err := app.FindAllRecords(collection)
+		if err != nil {
+			return scanned, err
+		}
+		for _, record := range records {
+			if err := syncMediaRefsWithResolver(app, record, resolvePath); err != nil {
+				return scanned, err
+			}
+			scanned++
+		}
+	}
+	return scanned, nil
+}
+
+func findMediaUsage(app core.App, mediaID string) ([]mediaUsage, error) {
+	refs, err := app.FindAllRecords("media_refs", dbx.HashExp{"media": mediaID})
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]mediaUsage, 0, len(refs))
+	for _, ref := range refs {
+		item := mediaUsage{
+			Collection: ref.GetString("source_collection"),
+			ID:         ref.GetString("source_id"),
+			Field:      ref.GetString("field"),
+		}
+		if source, err := app.FindRecordById(item.Collection, item.ID); err == nil {
+			item.Title = mediaUsageTitle(source)
+			item.Slug = source.GetString("slug")
+		}
+		usage = append(usage, item)
+	}
+	slices.SortFunc(usage, func(a, b mediaUsage) int {
+		if c := strings.Compare(a.Collection, b.Collection); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return usage, nil
+}
+
+func mediaUsageTitle(source *core.Record) string {
+	switch source.Collection().Name {
+	case "settings":
+		return "Site settings"
+	case "authors":
+		return source.GetString("display_name")
+	case "post_translations", "page_translations":
+		return strings.TrimSpace(source.GetString("title") + " (" + source.GetString("locale") + ")")
+	}
+	return source.GetString("title")
+}
@@ -0,0 +1,75 @@
+package pbapp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestExtractMediaRefs(t *testing.T) {
+	t.Parallel()
+
+	posts := core.NewBaseCollection("posts")
+	posts.Fields.Add(&core.TextField{Name: "body"}, &core.TextField{Name: "excerpt"})
+	post := core.NewRecord(posts)
+	post.Set("body", `<img src="/api/files/media/m1/a.jpg"><img src="/uploads/b.webp"><img src="/uploads/missing.png"><img src="/api/files/media/m1/a.jpg">`)
+	post.Set("excerpt", `<img src="/uploads/b.webp">`)
+
+	resolve := func(path string) string {
+		if path == "/uploads/b.webp" {
+			return "m2"
+		}
+		return ""
+	}
+	want := []mediaRefKey{
+		{MediaID: "m1", Field: "body"},
+		{MediaID: "m2", Field: "body"},
+		{MediaID: "m2", Field: "excerpt"},
+	}
+	if got := extractMediaRefs(post, resolve); !reflect.DeepEqual(got, want) {
+		t.Fatalf("extractMediaRefs = %#v, want %#v", got, want)
+	}
+
+	authors := core.NewBaseCollection("authors")
+	authors.Fields.Add(&core.TextField{Name: "bio"}, &core.RelationField{Name: "avatar", MaxSelect: 1})
+	author := core.NewRecord(authors)
+	author.Set("avatar", "m3")
+	if got := extractMediaRefs(author, resolve); !reflect.DeepEqual(got, []mediaRefKey{{MediaID: "m3", Field: "avatar"}}) {
+		t.Fatalf("author refs = %#v", got)
+	}
+}
+
+func TestMediaGCCandidates(t *testing.T) {
+	t.Parallel()
+
+	media := core.NewBaseCollection("media")
+	media.Fields.Add(&core.TextField{Name: "checksum"}, &core.TextField{Name: "path"})
+	newMedia := func(id, checksum, path string) *core.Record {
+		record := core.NewRecord(media)
+		record.Id = id
+		record.Set("checksum", checksum)
+		record.Set("path", path)
+		return record
+	}
+	records := []*core.Record{
+		newMedia("a", "sum1", "/uploads/sum1.png"),
+		newMedia("b", "", "/uploads/sum1.png"),
+		newMedia("c", "sum1", "/uploads/other.png"),
+		newMedia("d", "sum2", "/uploads/sum2.png"),
+		newMedia("e", "sum3", "/uploads/sum3.png"),
+	}
+	referenced := map[string]bool{"a": true, "c": true, "e": true}
+
+	got := []string{}
+	for _, candidate := range mediaGCCandidates(records, referenced) {
+		got = append(got, candidate.Reason+":"+candidate.Record.Id+":"+candidate.CanonicalID)
+		if candidate.Record.Id == "c" && !candidate.Referenced {
+			t.Fatalf("referenced duplicate should be flagged")
+		}
+	}
+	want := []string{"duplicate:b:a", "duplicate:c:a", "orphan:d:"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+}
@@ -68,4 +68,11 @@ func TestBuildUploadPath(t *testing.T) {
 			t.Fatalf("buildUploadPath returned %q", got)
 		}
 	})
+	t.Run("duplicate path carries the record id", func(t *testing.T) {
+		t.Parallel()
+		got := buildDuplicateUploadPath("Photo.JPG", "abc123", "rec1")
+		if got != "/uploads/abc123-rec1.jpg" || got == buildUploadPath("Photo.JPG", "abc123") {
+			t.Fatalf("buildDuplicateUploadPath returned %q", got)
+		}
+	})
 }
@@ -0,0 +1,296 @@
+package pbapp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type publishBoundaryKind string
+
+const (
+	publishBoundaryPublish   publishBoundaryKind = "publish"
+	publishBoundaryUnpublish publishBoundaryKind = "unpublish"
+)
+
+const (
+	publishScheduleCatchUpWindow = 24 * time.Hour
+	// publishScheduleStateFile in the data dir records the last boundary the
+	// scheduler handled, so a restart only catches up on later ones.
+	publishScheduleStateFile = "publish_schedule.json"
+)
+
+var publishScheduleCollections = []string{"posts", "post_translations", "pages", "page_translations"}
+
+type publishBoundary struct {
+	At         time.Time
+	Collection string
+	RecordID   string
+	Kind       publishBoundaryKind
+}
+
+type publishScheduler struct {
+	mu       sync.Mutex
+	app      core.App
+	upcoming []publishBoundary
+	timer    *time.Timer
+	handled  time.Time
+}
+
+type publishScheduleState struct {
+	HandledAt time.Time `json:"handled_at"`
+}
+
+var publishSchedule = &publishScheduler{}
+
+func registerPublishScheduler(app *pocketbase.PocketBase) {
+	for _, collection := range publishScheduleCollections {
+		collection := collection
+		app.OnRecordAfterCreateSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
+			publishSchedule.track(collection, e.Record)
+			return e.Next()
+		})
+		app.OnRecordAfterUpdateSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
+			publishSchedule.track(collection, e.Record)
+			return e.Next()
+		})
+		app.OnRecordAfterDeleteSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
+			publishSchedule.forget(collection, e.Record.Id)
+			return e.Next()
+		})
+	}
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if err := publishSchedule.start(se.App); err != nil {
+			slog.Error("publish schedule load failed", "error", err)
+		}
+		return se.Next()
+	})
+}
+
+func (s *publishScheduler) start(app core.App) error {
+	handled, err := loadPublishScheduleHandledAt(app.DataDir())
+	if err != nil {
+		slog.Warn("publish schedule state unreadable; catching up on the last day only", "error", err)
+	}
+	s.mu.Lock()
+	s.app = app
+	if handled.After(s.handled) {
+		s.handled = handled
+	}
+	since := publishScheduleCatchUpSince(time.Now().UTC(), s.handled)
+	s.mu.Unlock()
+
+	boundaries := make([]publishBoundary, 0)
+	for _, collection := range publishScheduleCollections {
+		records, err := app.FindRecordsByFilter(
+			collection,
+			"published = true && (published_at > {:since} || unpublish_at > {:since})",
+			"",
+			0,
+			0,
+			dbx.Params{"since": since.Format(types.DefaultDateLayout)},
+		)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			boundaries = append(boundaries, recordPublishBoundaries(collection, record, since)...)
+		}
+	}
+
+	s.mu.Lock()
+	s.upcoming = mergePublishBoundaries(s.upcoming, boundaries)
+	s.rearmLocked()
+	s.mu.Unlock()
+	slog.Info("publish schedule loaded", "boundaries", len(boundaries))
+	return nil
+}
+
+func (s *publishScheduler) track(collection string, record *core.Record) {
+	if record == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upcoming = removePublishBoundaries(s.upcoming, collection, record.Id)
+	s.upcoming = mergePublishBoundaries(s.upcoming, recordPublishBoundaries(collection, record, time.Now().UTC()))
+	s.rearmLocked()
+}
+
+func (s *publishScheduler) forget(collection, recordID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upcoming = removePublishBoundaries(s.upcoming, collection, recordID)
+	s.rearmLocked()
+}
+
+func (s *publishScheduler) rearmLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.app == nil || len(s.upcoming) == 0 {
+		return
+	}
+	delay := time.Until(s.upcoming[0].At)
+	if delay < 0 {
+		delay = 0
+	}
+	s.timer = time.AfterFunc(delay, s.fire)
+}
+
+func (s *publishScheduler) fire() {
+	s.mu.Lock()
+	now := time.Now().UTC()
+	due := make([]publishBoundary, 0)
+	for len(s.upcoming) > 0 && !s.upcoming[0].At.After(now) {
+		due = append(due, s.upcoming[0])
+		s.upcoming = s.upcoming[1:]
+	}
+	app := s.app
+	s.rearmLocked()
+	s.mu.Unlock()
+
+	for _, boundary := range due {
+		emitPublishBoundary(app, boundary)
+	}
+	if len(due) > 0 {
+		s.markHandled(app, due[len(due)-1].At)
+	}
+}
+
+// markHandled records at as the latest handled boundary, in memory and in the
+// data dir.
+func (s *publishScheduler) markHandled(app core.App, at time.Time) {
+	s.mu.Lock()
+	if !at.After(s.handled) {
+		s.mu.Unlock()
+		return
+	}
+	s.handled = at
+	s.mu.Unlock()
+	if err := savePublishScheduleHandledAt(app.DataDir(), at); err != nil {
+		slog.Warn("publish schedule state save failed", "error", err)
+	}
+}
+
+// publishScheduleCatchUpSince returns the time after which boundaries still
+// need handling on start: the last handled boundary, however long ago, or the
+// catch-up window when nothing has been handled yet.
+func publishScheduleCatchUpSince(now, handled time.Time) time.Time {
+	if !handled.IsZero() {
+		return handled.UTC()
+	}
+	return now.Add(-publishScheduleCatchUpWindow)
+}
+
+func loadPublishScheduleHandledAt(dataDir string) (time.Time, error) {
+	raw, err := os.ReadFile(filepath.Join(dataDir, publishScheduleStateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	var state publishScheduleState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return time.Time{}, err
+	}
+	return state.HandledAt, nil
+}
+
+func savePublishScheduleHandledAt(dataDir string, at time.Time) error {
+	raw, err := json.Marshal(publishScheduleState{HandledAt: at.UTC()})
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(dataDir, publishScheduleStateFile)
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+func emitPublishBoundary(app core.App, boundary publishBoundary) {
+	record, err := app.FindRecordById(boundary.Collection, boundary.RecordID)
+	if err != nil {
+		slog.Warn("publish boundary record reload failed", "collection", boundary.Collection, "record_id", boundary.RecordID, "error", err)
+		return
+	}
+
+	current := recordPublishBoundaries(boundary.Collection, record, boundary.At.Add(-time.Second))
+	stillScheduled := false
+	for _, item := range current {
+		if item.Kind == boundary.Kind && item.At.Equal(boundary.At) {
+			stillScheduled = true
+			break
+		}
+	}
+	if !stillScheduled {
+		slog.Info("publish boundary skipped because the schedule changed", "collection", boundary.Collection, "record_id", boundary.RecordID, "kind", boundary.Kind)
+		return
+	}
+
+	slog.Info("publish boundary reached", "collection", boundary.Collection, "record_id", boundary.RecordID, "kind", boundary.Kind, "at", boundary.At)
+	if boundary.Kind == publishBoundaryUnpublish {
+		triggerStaticRegen(app, boundary.Collection, string(boundary.Kind), nil, record)
+		return
+	}
+	triggerStaticRegen(app, boundary.Collection, string(boundary.Kind), record, nil)
+}
+
+func recordPublishBoundaries(collection string, record *core.Record, after time.Time) []publishBoundary {
+	if record == nil || !record.GetBool("published") {
+		return nil
+	}
+	return publishBoundariesFor(
+		collection,
+		record.Id,
+		record.GetDateTime("published_at").Time(),
+		record.GetDateTime("unpublish_at").Time(),
+		after,
+	)
+}
+
+func publishBoundariesFor(collection, recordID string, publishedAt, unpublishAt, after time.Time) []publishBoundary {
+	out := make([]publishBoundary, 0, 2)
+	if !publishedAt.IsZero() && publishedAt.After(after) {
+		out = append(out, publishBoundary{At: publishedAt.UTC(), Collection: collection, RecordID: recordID, Kind: publishBoundaryPublish})
+	}
+	if !unpublishAt.IsZero() && unpublishAt.After(after) && (publishedAt.IsZero() || unpublishAt.After(publishedAt)) {
+		out = append(out, publishBoundary{At: unpublishAt.UTC(), Collection: collection, RecordID: recordID, Kind: publishBoundaryUnpublish})
+	}
+	return out
+}
+
+func mergePublishBoundaries(existing, added []publishBoundary) []publishBoundary {
+	out := append(append([]publishBoundary(nil), existing...), added...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].At.Before(out[j].At)
+	})
+	return out
+}
+
+func removePublishBoundaries(items []publishBoundary, collection, recordID string) []publishBoundary {
+	out := items[:0]
+	for _, item := range items {
+		if item.Collection == collection && item.RecordID == recordID {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package pbapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishBoundariesFor(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	publishAt := now.Add(2 * time.Hour)
+	unpublishAt := now.Add(48 * time.Hour)
+
+	got := publishBoundariesFor("posts", "post-1", publishAt, unpublishAt, now)
+	if len(got) != 2 {
+		t.Fatalf("publishBoundariesFor length = %d, want 2 (%#v)", len(got), got)
+	}
+	if got[0].Kind != publishBoundaryPublish || !got[0].At.Equal(publishAt) {
+		t.Fatalf("publish boundary = %#v", got[0])
+	}
+	if got[1].Kind != publishBoundaryUnpublish || !got[1].At.Equal(unpublishAt) {
+		t.Fatalf("unpublish boundary = %#v", got[1])
+	}
+
+	if got := publishBoundariesFor("posts", "post-1", now.Add(-time.Hour), time.Time{}, now); len(got) != 0 {
+		t.Fatalf("past publish boundary should be ignored: %#v", got)
+	}
+	if got := publishBoundariesFor("pages", "page-1", publishAt, now.Add(time.Hour), now); len(got) != 1 || got[0].Kind != publishBoundaryPublish {
+		t.Fatalf("unpublish before publish should be ignored: %#v", got)
+	}
+}
+
+func TestMergeAndRemovePublishBoundaries(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := mergePublishBoundaries(nil, []publishBoundary{
+		{At: base.Add(3 * time.Hour), Collection: "posts", RecordID: "a", Kind: publishBoundaryUnpublish},
+		{At: base.Add(time.Hour), Collection: "posts", RecordID: "a", Kind: publishBoundaryPublish},
+	})
+	items = mergePublishBoundaries(items, []publishBoundary{
+		{At: base.Add(2 * time.Hour), Collection: "pages", RecordID: "b", Kind: publishBoundaryPublish},
+	})
+	if len(items) != 3 || items[0].RecordID != "a" || items[1].RecordID != "b" || items[2].Kind != publishBoundaryUnpublish {
+		t.Fatalf("mergePublishBoundaries order = %#v", items)
+	}
+
+	items = removePublishBoundaries(items, "posts", "a")
+	if len(items) != 1 || items[0].RecordID != "b" {
+		t.Fatalf("removePublishBoundaries = %#v", items)
+	}
+}
+
+func TestPublishScheduleCatchUpStartsAfterHandledBoundary(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if handled, err := loadPublishScheduleHandledAt(dir); err != nil || !handled.IsZero() {
+		t.Fatalf("loadPublishScheduleHandledAt(empty) = %v, %v", handled, err)
+	}
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := publishScheduleCatchUpSince(now, time.Time{}); !got.Equal(now.Add(-publishScheduleCatchUpWindow)) {
+		t.Fatalf("catch-up without state = %v, want the window start", got)
+	}
+
+	handledAt := now.Add(-time.Hour)
+	if err := savePublishScheduleHandledAt(dir, handledAt); err != nil {
+		t.Fatalf("savePublishScheduleHandledAt: %v", err)
+	}
+	handled, err := loadPublishScheduleHandledAt(dir)
+	if err != nil || !handled.Equal(handledAt) {
+		t.Fatalf("loadPublishScheduleHandledAt = %v, %v; want %v", handled, err, handledAt)
+	}
+	since := publishScheduleCatchUpSince(now, handled)
+	if !since.Equal(handledAt) {
+		t.Fatalf("catch-up since = %v, want %v", since, handledAt)
+	}
+	if got := publishBoundariesFor("posts", "post-1", handledAt, time.Time{}, since); len(got) != 0 {
+		t.Fatalf("handled boundary should not fire again: %#v", got)
+	}
+	if got := publishBoundariesFor("posts", "post-2", handledAt.Add(time.Minute), time.Time{}, since); len(got) != 1 {
+		t.Fatalf("boundary after the handled one should be caught up: %#v", got)
+	}
+	// After an outage longer than the window, catch-up still starts at the
+	// last handled boundary.
+	longAgo := now.Add(-3 * publishScheduleCatchUpWindow)
+	since = publishScheduleCatchUpSince(now, longAgo)
+	if !since.Equal(longAgo) {
+		t.Fatalf("catch-up since = %v, want %v", since, longAgo)
+	}
+	if got := publishBoundariesFor("posts", "post-3", now.Add(-2*publishScheduleCatchUpWindow), time.Time{}, since); len(got) != 1 {
+		t.Fatalf("boundary missed during the outage should be caught up: %#v", got)
+	}
+}
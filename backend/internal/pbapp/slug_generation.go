@@ -61,11 +61,12 @@ func registerSlugGenerationAPI(app *pocketbase.PocketBase) {
 			if err != nil {
 				return err
 			}
-			if strings.TrimSpace(settings.APIKey) == "" {
+			apiKey := settings.APIKeys[translationProviderGemini]
+			if apiKey == "" {
 				return apis.NewBadRequestError("Gemini API key is not configured.", nil)
 			}
 
-			slug, err := generateEnglishSlugWithGemini(title, settings.Model, settings.APIKey, settings.RequestsPM)
+			slug, err := generateEnglishSlugWithGemini(title, settings.geminiModel(), apiKey, settings.RequestsPM)
 			if err != nil {
 				return err
 			}
@@ -108,7 +109,7 @@ func generateEnglishSlugWithGemini(title, model, apiKey string, requestsPerMinut
 	}
 
 	var payload slugGenerationResponse
-	if err := unmarshalGeminiJSON(text, &payload); err != nil {
+	if err := unmarshalTranslationJSON(text, &payload); err != nil {
 		return "", err
 	}
 
@@ -0,0 +1,107 @@
+package pbapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// registerSnapshotGenerationAPI exposes the site server's snapshot
+// generations to admins. The site server is reached on the same host as
+// SSR_REGEN_URL, authorized with STATIC_REGEN_TOKEN.
+func registerSnapshotGenerationAPI(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/api/snapshots", func(e *core.RequestEvent) error {
+			if err := requireAdminAuth(e); err != nil {
+				return err
+			}
+			return proxySnapshotRequest(e, http.MethodGet, "/__internal/snapshots", nil)
+		}).Bind(apis.RequireAuth())
+
+		se.Router.POST("/api/snapshots/{id}/rollback", func(e *core.RequestEvent) error {
+			if err := requireAdminAuth(e); err != nil {
+				return err
+			}
+			body, err := json.Marshal(map[string]string{"id": e.Request.PathValue("id")})
+			if err != nil {
+				return err
+			}
+			return proxySnapshotRequest(e, http.MethodPost, "/__internal/snapshots/rollback", body)
+		}).Bind(apis.RequireAuth())
+
+		return se.Next()
+	})
+}
+
+func requireAdminAuth(e *core.RequestEvent) error {
+	if e.Auth == nil {
+		return apis.NewUnauthorizedError("Authentication required.", nil)
+	}
+	if strings.TrimSpace(e.Auth.GetString("role")) != "admin" {
+		return apis.NewForbiddenError("Admin role required.", nil)
+	}
+	return nil
+}
+
+// siteInternalURL swaps the path of the revalidation target for another
+// internal site server endpoint.
+func siteInternalURL(target, path string) (string, error) {
+	if target == "" {
+		return "", fmt.Errorf("SSR_REGEN_URL is not configured")
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid SSR_REGEN_URL %q", target)
+	}
+	parsed.Path = path
+	parsed.RawPath = ""
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String(), nil
+}
+
+func proxySnapshotRequest(e *core.RequestEvent, method, path string, body []byte) error {
+	target, err := siteInternalURL(regenTarget(), path)
+	if err != nil {
+		return apis.NewApiError(http.StatusServiceUnavailable, err.Error(), nil)
+	}
+	req, err := http.NewRequestWithContext(e.Request.Context(), method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := strings.TrimSpace(os.Getenv("STATIC_REGEN_TOKEN")); token != "" {
+		req.Header.Set("X-Regen-Token", token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return apis.NewApiError(http.StatusBadGateway, "Site server unreachable.", nil)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return apis.NewApiError(http.StatusBadGateway, "Site server response failed.", nil)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return apis.NewNotFoundError("Snapshot generation not found.", nil)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return apis.NewApiError(http.StatusBadGateway, fmt.Sprintf("Site server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(payload))), nil)
+	}
+	return e.Blob(http.StatusOK, "application/json", payload)
+}
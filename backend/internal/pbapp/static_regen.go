@@ -2,15 +2,24 @@ package pbapp
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 type regenRequest struct {
@@ -20,31 +29,86 @@ type regenRequest struct {
 	Original   json.RawMessage `json:"original"`
 }
 
+type regenOutboxStatus string
+
+const (
+	regenOutboxPending    regenOutboxStatus = "pending"
+	regenOutboxDelivering regenOutboxStatus = "delivering"
+	regenOutboxDead       regenOutboxStatus = "dead"
+)
+
+const (
+	regenOutboxCollection   = "revalidation_outbox"
+	regenOutboxMaxAttempts  = 12
+	regenOutboxBaseBackoff  = 2 * time.Second
+	regenOutboxMaxBackoff   = 15 * time.Minute
+	regenOutboxPollInterval = 5 * time.Second
+	regenOutboxBatchSize    = 50
+)
+
+// regenDeliveryTimeout has to cover a full snapshot rebuild: the site server
+// answers only after the revalidation has been applied.
+var regenDeliveryTimeout = 15 * time.Minute
+
+// errRegenNoResponse marks a request the site server received but did not
+// answer in time. The revalidation is most likely still running, so it is
+// retried without counting towards the dead letter.
+var errRegenNoResponse = errors.New("site server sent no response")
+
+var regenOutboxWake = make(chan struct{}, 1)
+
+var regenOutboxWorker = struct {
+	once sync.Once
+}{}
+
 func registerStaticRegenHooks(app *pocketbase.PocketBase) {
 	bindRegenHooks(app, "posts")
 	bindRegenHooks(app, "pages")
 	bindRegenHooks(app, "post_translations")
+	bindRegenHooks(app, "page_translations")
 	bindRegenHooks(app, "settings")
+	bindRegenHooks(app, "authors")
+	bindRegenHooks(app, "series")
+	bindRegenHooks(app, "themes")
+
+	app.OnRecordUpdate(regenOutboxCollection).BindFunc(func(e *core.RecordEvent) error {
+		if e.Record.GetString("status") == string(regenOutboxPending) && e.Record.Original().GetString("status") == string(regenOutboxDead) {
+			e.Record.Set("attempts", 0)
+			e.Record.Set("next_attempt_at", types.NowDateTime())
+			e.Record.Set("last_error", "")
+		}
+		return e.Next()
+	})
+	app.OnRecordAfterUpdateSuccess(regenOutboxCollection).BindFunc(func(e *core.RecordEvent) error {
+		if e.Record.GetString("status") == string(regenOutboxPending) {
+			wakeRegenOutbox()
+		}
+		return e.Next()
+	})
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		startRegenOutboxWorker(se.App)
+		return se.Next()
+	})
 }
 
 func bindRegenHooks(app *pocketbase.PocketBase, collection string) {
 	app.OnRecordAfterCreateSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
-		triggerStaticRegen(collection, "create", e.Record, nil)
+		triggerStaticRegen(e.App, collection, "create", e.Record, nil)
 		return e.Next()
 	})
 	app.OnRecordAfterUpdateSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
-		triggerStaticRegen(collection, "update", e.Record, e.Record.Original())
+		triggerStaticRegen(e.App, collection, "update", e.Record, e.Record.Original())
 		return e.Next()
 	})
 	app.OnRecordAfterDeleteSuccess(collection).BindFunc(func(e *core.RecordEvent) error {
-		triggerStaticRegen(collection, "delete", nil, e.Record.Original())
+		triggerStaticRegen(e.App, collection, "delete", nil, e.Record.Original())
 		return e.Next()
 	})
 }
 
-func triggerStaticRegen(collection, action string, current, original *core.Record) {
-	target := strings.TrimSpace(os.Getenv("SSR_REGEN_URL"))
-	if target == "" {
+func triggerStaticRegen(app core.App, collection, action string, current, original *core.Record) {
+	if regenTarget() == "" {
 		slog.Debug("static regen skipped because SSR_REGEN_URL is empty", "collection", collection, "action", action)
 		return
 	}
@@ -55,40 +119,265 @@ func triggerStaticRegen(collection, action string, current, original *core.Recor
 		Current:    marshalRecordJSON(current),
 		Original:   marshalRecordJSON(original),
 	}
+	recordID := ""
+	for _, record := range []*core.Record{current, original} {
+		if record != nil {
+			recordID = record.Id
+			break
+		}
+	}
 
-	go func() {
-		body, err := json.Marshal(payload)
-		if err != nil {
-			slog.Error("static regen payload marshal failed", "collection", collection, "action", action, "error", err)
-			return
+	if err := enqueueRegenRequest(app, recordID, payload); err != nil {
+		slog.Error("static regen enqueue failed", "collection", collection, "action", action, "record_id", recordID, "error", err)
+		return
+	}
+	wakeRegenOutbox()
+}
+
+func regenTarget() string {
+	return strings.TrimSpace(os.Getenv("SSR_REGEN_URL"))
+}
+
+func enqueueRegenRequest(app core.App, recordID string, payload regenRequest) error {
+	collection, err := app.FindCollectionByNameOrId(regenOutboxCollection)
+	if err != nil {
+		return err
+	}
+
+	dedupeKey := payload.Collection + "|" + recordID
+	entry, err := app.FindFirstRecordByFilter(
+		collection,
+		"dedupe_key = {:key} && status = {:status}",
+		dbx.Params{"key": dedupeKey, "status": string(regenOutboxPending)},
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) || entry == nil || recordID == "" {
+		entry = core.NewRecord(collection)
+		entry.Set("collection", payload.Collection)
+		entry.Set("record_id", recordID)
+		entry.Set("dedupe_key", dedupeKey)
+	} else {
+		payload = mergeRegenRequests(regenRequestFromRecord(entry), payload)
+		slog.Info("static regen coalesced", "collection", payload.Collection, "action", payload.Action, "record_id", recordID, "outbox_id", entry.Id)
+	}
+
+	entry.Set("action", payload.Action)
+	entry.Set("current", payload.Current)
+	entry.Set("original", payload.Original)
+	entry.Set("status", string(regenOutboxPending))
+	entry.Set("attempts", 0)
+	entry.Set("next_attempt_at", types.NowDateTime())
+	entry.Set("last_error", "")
+	entry.Set("last_status", 0)
+	return app.Save(entry)
+}
+
+// mergeRegenRequests folds a newer change into a still pending one. The
+// original side is kept from the first request because that is the state the
+// site snapshot last saw.
+func mergeRegenRequests(pending, next regenRequest) regenRequest {
+	merged := regenRequest{
+		Collection: next.Collection,
+		Action:     next.Action,
+		Current:    next.Current,
+		Original:   pending.Original,
+	}
+	if pending.Action == "create" && next.Action == "update" {
+		merged.Action = "create"
+	}
+	return merged
+}
+
+func regenRequestFromRecord(record *core.Record) regenRequest {
+	return regenRequest{
+		Collection: record.GetString("collection"),
+		Action:     record.GetString("action"),
+		Current:    rawRecordJSONField(record, "current"),
+		Original:   rawRecordJSONField(record, "original"),
+	}
+}
+
+func rawRecordJSONField(record *core.Record, field string) json.RawMessage {
+	raw, ok := record.Get(field).(types.JSONRaw)
+	if !ok || len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.RawMessage(raw)
+}
+
+func regenOutboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return regenOutboxBaseBackoff
+	}
+	delay := regenOutboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= regenOutboxMaxBackoff {
+			return regenOutboxMaxBackoff
+		}
+	}
+	return delay
+}
+
+func wakeRegenOutbox() {
+	select {
+	case regenOutboxWake <- struct{}{}:
+	default:
+	}
+}
+
+func startRegenOutboxWorker(app core.App) {
+	regenOutboxWorker.once.Do(func() {
+		if err := resetStaleRegenDeliveries(app); err != nil {
+			slog.Error("static regen outbox reset failed", "error", err)
+		}
+		go func() {
+			ticker := time.NewTicker(regenOutboxPollInterval)
+			defer ticker.Stop()
+			for {
+				if err := drainRegenOutbox(app); err != nil {
+					slog.Error("static regen outbox drain failed", "error", err)
+				}
+				select {
+				case <-ticker.C:
+				case <-regenOutboxWake:
+				}
+			}
+		}()
+	})
+}
+
+func resetStaleRegenDeliveries(app core.App) error {
+	records, err := app.FindRecordsByFilter(
+		regenOutboxCollection,
+		"status = {:status}",
+		"",
+		0,
+		0,
+		dbx.Params{"status": string(regenOutboxDelivering)},
+	)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		record.Set("status", string(regenOutboxPending))
+		if err := app.Save(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func drainRegenOutbox(app core.App) error {
+	target := regenTarget()
+	if target == "" {
+		return nil
+	}
+
+	records, err := app.FindRecordsByFilter(
+		regenOutboxCollection,
+		"status = {:status} && next_attempt_at <= @now",
+		"created,id",
+		regenOutboxBatchSize,
+		0,
+		dbx.Params{"status": string(regenOutboxPending)},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		record.Set("status", string(regenOutboxDelivering))
+		if err := app.Save(record); err != nil {
+			return err
 		}
 
-		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
-		if err != nil {
-			slog.Error("static regen request init failed", "collection", collection, "action", action, "target", target, "error", err)
-			return
+		payload := regenRequestFromRecord(record)
+		status, deliverErr := deliverRegenRequest(target, payload)
+		if deliverErr == nil {
+			slog.Info("static regen notify completed", "collection", payload.Collection, "action", payload.Action, "status", status, "target", target)
+			if err := app.Delete(record); err != nil {
+				return err
+			}
+			continue
 		}
-		req.Header.Set("Content-Type", "application/json")
-		if token := strings.TrimSpace(os.Getenv("STATIC_REGEN_TOKEN")); token != "" {
-			req.Header.Set("X-Regen-Token", token)
+
+		if errors.Is(deliverErr, errRegenNoResponse) {
+			record.Set("status", string(regenOutboxPending))
+			record.Set("last_status", 0)
+			record.Set("last_error", deliverErr.Error())
+			record.Set("next_attempt_at", types.NowDateTime().Add(regenOutboxBackoff(record.GetInt("attempts")+1)))
+			slog.Warn("static regen notify unanswered", "collection", payload.Collection, "action", payload.Action, "target", target, "error", deliverErr)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+			// The site server is busy with this revalidation.
+			return nil
 		}
 
-		client := &http.Client{Timeout: 20 * time.Second}
-		slog.Info("static regen notify start", "collection", collection, "action", action, "target", target)
-		resp, err := client.Do(req)
-		if err != nil {
-			slog.Error("static regen notify failed", "collection", collection, "action", action, "target", target, "error", err)
-			return
+		attempts := record.GetInt("attempts") + 1
+		record.Set("attempts", attempts)
+		record.Set("last_status", status)
+		record.Set("last_error", deliverErr.Error())
+		if attempts >= regenOutboxMaxAttempts {
+			record.Set("status", string(regenOutboxDead))
+			slog.Error("static regen moved to dead letter", "collection", payload.Collection, "action", payload.Action, "attempts", attempts, "error", deliverErr)
+		} else {
+			record.Set("status", string(regenOutboxPending))
+			record.Set("next_attempt_at", types.NowDateTime().Add(regenOutboxBackoff(attempts)))
+			slog.Warn("static regen notify failed", "collection", payload.Collection, "action", payload.Action, "attempts", attempts, "status", status, "target", target, "error", deliverErr)
 		}
-		defer func() {
-			_ = resp.Body.Close()
-		}()
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			slog.Warn("static regen notify returned non-success status", "collection", collection, "action", action, "status", resp.StatusCode, "target", target)
-			return
+		if err := app.Save(record); err != nil {
+			return err
+		}
+		if status == 0 {
+			// The site server is unreachable; later entries would fail the same way.
+			return nil
+		}
+	}
+	return nil
+}
+
+func deliverRegenRequest(target string, payload regenRequest) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := strings.TrimSpace(os.Getenv("STATIC_REGEN_TOKEN")); token != "" {
+		req.Header.Set("X-Regen-Token", token)
+	}
+
+	var sent atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			sent.Store(info.Err == nil)
+		},
+	}))
+	client := &http.Client{Timeout: regenDeliveryTimeout}
+	slog.Info("static regen notify start", "collection", payload.Collection, "action", payload.Action, "target", target)
+	resp, err := client.Do(req)
+	if err != nil {
+		if sent.Load() {
+			return 0, fmt.Errorf("%w: %v", errRegenNoResponse, err)
 		}
-		slog.Info("static regen notify completed", "collection", collection, "action", action, "status", resp.StatusCode, "target", target)
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
 	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("site server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return resp.StatusCode, nil
 }
 
 func marshalRecordJSON(record *core.Record) json.RawMessage {
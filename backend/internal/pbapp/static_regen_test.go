@@ -0,0 +1,115 @@
+package pbapp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMergeRegenRequestsKeepsFirstOriginal(t *testing.T) {
+	t.Parallel()
+
+	pending := regenRequest{
+		Collection: "posts",
+		Action:     "update",
+		Current:    json.RawMessage(`{"slug":"second"}`),
+		Original:   json.RawMessage(`{"slug":"first"}`),
+	}
+	next := regenRequest{
+		Collection: "posts",
+		Action:     "update",
+		Current:    json.RawMessage(`{"slug":"third"}`),
+		Original:   json.RawMessage(`{"slug":"second"}`),
+	}
+
+	merged := mergeRegenRequests(pending, next)
+	if string(merged.Original) != `{"slug":"first"}` {
+		t.Fatalf("merged original = %s", merged.Original)
+	}
+	if string(merged.Current) != `{"slug":"third"}` {
+		t.Fatalf("merged current = %s", merged.Current)
+	}
+	if merged.Action != "update" {
+		t.Fatalf("merged action = %q", merged.Action)
+	}
+}
+
+func TestMergeRegenRequestsKeepsCreateAction(t *testing.T) {
+	t.Parallel()
+
+	merged := mergeRegenRequests(
+		regenRequest{Collection: "pages", Action: "create", Current: json.RawMessage(`{"url":"/about"}`)},
+		regenRequest{Collection: "pages", Action: "update", Current: json.RawMessage(`{"url":"/about-us"}`), Original: json.RawMessage(`{"url":"/about"}`)},
+	)
+	if merged.Action != "create" {
+		t.Fatalf("merged action = %q, want create", merged.Action)
+	}
+	if merged.Original != nil {
+		t.Fatalf("merged original = %s, want nil", merged.Original)
+	}
+
+	deleted := mergeRegenRequests(
+		regenRequest{Collection: "pages", Action: "create", Current: json.RawMessage(`{"url":"/about"}`)},
+		regenRequest{Collection: "pages", Action: "delete", Original: json.RawMessage(`{"url":"/about"}`)},
+	)
+	if deleted.Action != "delete" || deleted.Current != nil {
+		t.Fatalf("merged delete = %#v", deleted)
+	}
+}
+
+func TestRegenOutboxBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]time.Duration{
+		0:  regenOutboxBaseBackoff,
+		1:  regenOutboxBaseBackoff,
+		2:  2 * regenOutboxBaseBackoff,
+		4:  8 * regenOutboxBaseBackoff,
+		30: regenOutboxMaxBackoff,
+	}
+	for attempts, want := range cases {
+		if got := regenOutboxBackoff(attempts); got != want {
+			t.Fatalf("regenOutboxBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestSiteInternalURL(t *testing.T) {
+	t.Parallel()
+
+	got, err := siteInternalURL("http://site:3000/__internal/revalidate?x=1", "/__internal/snapshots")
+	if err != nil {
+		t.Fatalf("siteInternalURL: %v", err)
+	}
+	if got != "http://site:3000/__internal/snapshots" {
+		t.Fatalf("siteInternalURL = %q", got)
+	}
+	if _, err := siteInternalURL("", "/__internal/snapshots"); err == nil {
+		t.Fatal("expected an error without SSR_REGEN_URL")
+	}
+}
+
+func TestDeliverRegenRequestSeparatesTimeoutsFromConnectionFailures(t *testing.T) {
+	previous := regenDeliveryTimeout
+	regenDeliveryTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { regenDeliveryTimeout = previous })
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	if _, err := deliverRegenRequest(slow.URL, regenRequest{Collection: "posts", Action: "update"}); !errors.Is(err, errRegenNoResponse) {
+		t.Fatalf("slow site error = %v, want errRegenNoResponse", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := deliverRegenRequest(closed.URL, regenRequest{Collection: "posts", Action: "update"}); err == nil || errors.Is(err, errRegenNoResponse) {
+		t.Fatalf("unreachable site error = %v, want a connection failure", err)
+	}
+}
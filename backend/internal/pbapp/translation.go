@@ -28,12 +28,13 @@ const (
 )
 
 type translationSettings struct {
-	Enabled      bool
-	SourceLocale string
-	Locales      []string
-	Model        string
-	APIKey       string
-	RequestsPM   int
+	Enabled         bool
+	SourceLocale    string
+	Locales         []string
+	Provider        translationProviderConfig
+	LocaleProviders map[string]translationProviderConfig
+	APIKeys         map[string]string
+	RequestsPM      int
 }
 
 type geminiResponse struct {
@@ -47,16 +48,9 @@ type geminiResponse struct {
 }
 
 type translatedPayload struct {
-	TranslatedTitle string `json:"translated_title"`
-	TranslatedBody  string `json:"translated_body"`
-}
-
-type translatedTitlePayload struct {
-	TranslatedTitle string `json:"translated_title"`
-}
-
-type translatedBodyPayload struct {
-	TranslatedBody string `json:"translated_body"`
+	TranslatedTitle    string   `json:"translated_title"`
+	TranslatedBody     string   `json:"translated_body"`
+	TranslatedSegments []string `json:"translated_segments"`
 }
 
 type translationJobStatus string
@@ -68,9 +62,10 @@ const (
 	translationJobFailed    translationJobStatus = "failed"
 )
 
-type geminiError struct {
-	Status int
-	Body   string
+type translationProviderError struct {
+	Provider string
+	Status   int
+	Body     string
 }
 
 type batchTranslationJob struct {
@@ -81,15 +76,31 @@ type batchTranslationJob struct {
 	force  bool
 }
 
-type geminiRateLimiter struct {
+type translationRateLimiter struct {
 	mu          sync.Mutex
 	nextAllowed time.Time
 }
 
-var sharedGeminiRateLimiter = &geminiRateLimiter{}
+var translationRateLimiters = struct {
+	mu    sync.Mutex
+	items map[string]*translationRateLimiter
+}{
+	items: map[string]*translationRateLimiter{},
+}
+
+func (e *translationProviderError) Error() string {
+	return fmt.Sprintf("%s request failed: status=%d", e.Provider, e.Status)
+}
 
-func (e *geminiError) Error() string {
-	return fmt.Sprintf("gemini request failed: status=%d", e.Status)
+func translationRateLimiterFor(provider string) *translationRateLimiter {
+	translationRateLimiters.mu.Lock()
+	defer translationRateLimiters.mu.Unlock()
+	limiter := translationRateLimiters.items[provider]
+	if limiter == nil {
+		limiter = &translationRateLimiter{}
+		translationRateLimiters.items[provider] = limiter
+	}
+	return limiter
 }
 
 func registerTranslationFeatures(app *pocketbase.PocketBase) {
@@ -103,12 +114,20 @@ func registerTranslationFeatures(app *pocketbase.PocketBase) {
 		triggerPostTranslation(e.App, e.Record)
 		return e.Next()
 	})
+	app.OnRecordAfterCreateSuccess("pages").BindFunc(func(e *core.RecordEvent) error {
+		triggerPageTranslation(e.App, e.Record)
+		return e.Next()
+	})
+	app.OnRecordAfterUpdateSuccess("pages").BindFunc(func(e *core.RecordEvent) error {
+		triggerPageTranslation(e.App, e.Record)
+		return e.Next()
+	})
 }
 
 func registerTranslateCommand(app *pocketbase.PocketBase) {
 	cmd := &cobra.Command{
 		Use:   "translate-posts",
-		Short: "Translate existing source posts using Gemini",
+		Short: "Translate existing source posts using the configured translation provider",
 		RunE: func(command *cobra.Command, args []string) error {
 			if err := app.Bootstrap(); err != nil {
 				return err
@@ -117,6 +136,16 @@ func registerTranslateCommand(app *pocketbase.PocketBase) {
 		},
 	}
 	app.RootCmd.AddCommand(cmd)
+	app.RootCmd.AddCommand(&cobra.Command{
+		Use:   "translate-pages",
+		Short: "Translate existing source pages using the configured translation provider",
+		RunE: func(command *cobra.Command, args []string) error {
+			if err := app.Bootstrap(); err != nil {
+				return err
+			}
+			return translateAllSourcePages(app)
+		},
+	})
 }
 
 func triggerPostTranslation(app core.App, source *core.Record) {
@@ -131,7 +160,7 @@ func triggerPostTranslation(app core.App, source *core.Record) {
 			_ = failTranslationJob(app, sourceID, err)
 			return
 		}
-		if !settings.Enabled || strings.TrimSpace(settings.APIKey) == "" || len(settings.Locales) == 0 {
+		if !settings.Enabled || len(settings.Locales) == 0 || !settings.hasUsableTranslator() {
 			return
 		}
 		if err := upsertTranslationJobState(app, sourceID, func(job *core.Record) {
@@ -166,12 +195,14 @@ func translateAllSourcePosts(app core.App) error {
 	if !settings.Enabled {
 		return errors.New("post translation is disabled in settings")
 	}
-	if strings.TrimSpace(settings.APIKey) == "" {
-		return errors.New("gemini_api_key is empty in settings")
-	}
 	if len(settings.Locales) == 0 {
 		return errors.New("translation_locales is empty in settings")
 	}
+	for _, locale := range settings.Locales {
+		if _, err := settings.translatorFor(locale); err != nil {
+			return fmt.Errorf("translation provider for %s: %w", locale, err)
+		}
+	}
 
 	records, err := app.FindRecordsByFilter(
 		"posts",
@@ -237,7 +268,7 @@ func translateSourcePost(app core.App, source *core.Record, settings translation
 				firstErr = err
 			}
 			log.Printf("translation locale failed source=%s locale=%s err=%v", source.Id, locale, err)
-			if isGeminiRateLimitError(err) {
+			if isTranslationRateLimitError(err) {
 				_ = completeTranslationJob(app, source.Id, completed, failed, lastError)
 				return err
 			}
@@ -331,46 +362,95 @@ func upsertTranslatedPost(
 		return nil
 	}
 
-	translatedTitle, translatedBody, err := translateWithGemini(
-		title,
-		body,
-		settings.SourceLocale,
-		targetLocale,
-		settings.Model,
-		settings.APIKey,
-		settings.RequestsPM,
-	)
+	plan, err := planTranslatedContent(app, translated, settings, targetLocale, title, body)
 	if err != nil {
 		return err
 	}
+	if len(plan.Stale) > 0 {
+		log.Printf("translation kept human edits source=%s locale=%s stale_segments=%d", source.Id, targetLocale, len(plan.Stale))
+	}
 
-	if errors.Is(err, sql.ErrNoRows) || translated == nil {
+	if translated == nil {
 		translated = core.NewRecord(translationsCollection)
 		translated.Set("source_post", source.Id)
 		translated.Set("locale", targetLocale)
 	}
 
+	translatedBody := composeTranslationBody(plan.Segments)
+	excerpt := strings.TrimSpace(translated.GetString("excerpt"))
+	if excerpt == "" || excerpt == buildExcerpt(translated.GetString("body"), 160) {
+		excerpt = buildExcerpt(translatedBody, 160)
+	}
+
 	translated.Set("slug", source.GetString("slug"))
 	translated.Set("tags", source.GetString("tags"))
 	translated.Set("category", source.GetString("category"))
 	translated.Set("author", source.GetString("author"))
 	translated.Set("published", source.GetBool("published"))
 	translated.Set("published_at", source.GetString("published_at"))
-	translated.Set("title", translatedTitle)
+	translated.Set("unpublish_at", source.GetString("unpublish_at"))
+	translated.Set("title", plan.Title.Text)
 	translated.Set("body", translatedBody)
-	translated.Set("excerpt", buildExcerpt(translatedBody, 160))
+	translated.Set("excerpt", excerpt)
+	state := translationSegmentState{Title: plan.Title, Segments: plan.Segments}
+	translated.Set("translation_segments", state)
+	translated.Set("translation_status", nextTranslationStatus(translated.GetString("translation_status"), state, plan))
 	translated.Set("translation_done", true)
 
 	return app.Save(translated)
 }
 
+// planTranslatedContent translates the title and the changed body segments of
+// a source record into targetLocale, keeping human edits found on the
+// existing translation record (nil when there is none yet).
+func planTranslatedContent(
+	app core.App,
+	translated *core.Record,
+	settings translationSettings,
+	targetLocale string,
+	title string,
+	body string,
+) (translationPlan, error) {
+	translator, err := settings.translatorFor(targetLocale)
+	if err != nil {
+		return translationPlan{}, err
+	}
+
+	var previous *translationSegmentState
+	if translated != nil {
+		if state := loadTranslationSegmentState(translated); state != nil {
+			reconciled := reconcileHumanEdits(*state, translated.GetString("title"), translated.GetString("body"))
+			previous = &reconciled
+		}
+	}
+
+	sourceSegments := splitSourceSegments(body)
+	plan := planTranslationUpdate(previous, title, sourceSegments)
+	if plan.TitlePending {
+		result, err := translator.Translate(translationRequest{
+			SourceLocale: settings.SourceLocale,
+			TargetLocale: targetLocale,
+			Title:        title,
+		})
+		if err != nil {
+			return translationPlan{}, err
+		}
+		plan.Title.Text = result.Title
+	}
+	provider := settings.providerFor(targetLocale).Provider
+	if err := fillPendingSegments(app, translator, &plan, sourceSegments, provider, settings.SourceLocale, targetLocale); err != nil {
+		return translationPlan{}, err
+	}
+	return plan, nil
+}
+
 func loadTranslationSettings(app core.App) (translationSettings, error) {
 	record, err := app.FindFirstRecordByFilter("settings", "id != ''")
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return translationSettings{}, err
 	}
 	if errors.Is(err, sql.ErrNoRows) || record == nil {
-		apiKey, keyErr := loadGeminiAPIKey(app, nil)
+		apiKeys, keyErr := loadTranslationAPIKeys(app, nil)
 		if keyErr != nil {
 			return translationSettings{}, keyErr
 		}
@@ -378,8 +458,8 @@ func loadTranslationSettings(app core.App) (translationSettings, error) {
 			Enabled:      false,
 			SourceLocale: "ja",
 			Locales:      []string{"en"},
-			Model:        defaultTranslationModel,
-			APIKey:       apiKey,
+			Provider:     translationProviderConfig{Provider: translationProviderGemini, Model: defaultTranslationModel},
+			APIKeys:      apiKeys,
 			RequestsPM:   defaultTranslationRPM,
 		}, nil
 	}
@@ -404,15 +484,17 @@ func loadTranslationSettings(app core.App) (translationSettings, error) {
 		filtered = append(filtered, locale)
 	}
 
+	provider := normalizeTranslationProvider(record.GetString("translation_provider"))
 	model := strings.TrimSpace(record.GetString("translation_model"))
-	if model == "" {
-		model = defaultTranslationModel
+	if provider != translationProviderGemini && model == defaultTranslationModel {
+		// The settings form seeds the Gemini default; it means nothing to other providers.
+		model = ""
 	}
 	requestsPM := int(record.GetFloat("translation_requests_per_minute"))
 	if requestsPM <= 0 {
 		requestsPM = defaultTranslationRPM
 	}
-	apiKey, keyErr := loadGeminiAPIKey(app, record)
+	apiKeys, keyErr := loadTranslationAPIKeys(app, record)
 	if keyErr != nil {
 		return translationSettings{}, keyErr
 	}
@@ -420,12 +502,36 @@ func loadTranslationSettings(app core.App) (translationSettings, error) {
 		Enabled:      record.GetBool("enable_post_translation"),
 		SourceLocale: sourceLocale,
 		Locales:      filtered,
-		Model:        model,
-		APIKey:       apiKey,
-		RequestsPM:   requestsPM,
+		Provider: translationProviderConfig{
+			Provider: provider,
+			Model:    model,
+			Endpoint: strings.TrimSpace(record.GetString("translation_endpoint")),
+		},
+		LocaleProviders: parseLocaleProviderOverrides(record),
+		APIKeys:         apiKeys,
+		RequestsPM:      requestsPM,
 	}, nil
 }
 
+func loadTranslationAPIKeys(app core.App, settingsRecord *core.Record) (map[string]string, error) {
+	keys := map[string]string{}
+	geminiKey, err := loadGeminiAPIKey(app, settingsRecord)
+	if err != nil {
+		return nil, err
+	}
+	keys[translationProviderGemini] = geminiKey
+
+	secret, err := app.FindFirstRecordByFilter("app_secrets", "id != ''")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil && secret != nil {
+		keys[translationProviderOpenAI] = strings.TrimSpace(secret.GetString("openai_api_key"))
+		keys[translationProviderDeepL] = strings.TrimSpace(secret.GetString("deepl_api_key"))
+	}
+	return keys, nil
+}
+
 func loadGeminiAPIKey(app core.App, settingsRecord *core.Record) (string, error) {
 	secret, err := app.FindFirstRecordByFilter("app_secrets", "id != ''")
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -499,149 +605,59 @@ func buildExcerpt(input string, maxLen int) string {
 	return strings.TrimSpace(string(runes[:maxLen]))
 }
 
-func translateWithGemini(
-	title string,
-	body string,
-	sourceLocale string,
-	targetLocale string,
-	model string,
-	apiKey string,
-	requestsPerMinute int,
-) (string, string, error) {
-	if len([]rune(body)) <= maxTranslationBodyRunes {
-		return translateTitleAndBodyWithGemini(title, body, sourceLocale, targetLocale, model, apiKey, requestsPerMinute)
-	}
-
-	translatedTitle, err := translateTitleWithGemini(title, sourceLocale, targetLocale, model, apiKey, requestsPerMinute)
-	if err != nil {
-		return "", "", err
-	}
-
-	chunks := splitTranslationBody(body, maxTranslationBodyRunes)
-	if len(chunks) == 0 {
-		return "", "", errors.New("translation body split produced no chunks")
+func (t llmTranslator) Translate(req translationRequest) (translationResult, error) {
+	input := map[string]any{
+		"source_locale": req.SourceLocale,
+		"target_locale": req.TargetLocale,
 	}
-
-	translatedChunks := make([]string, 0, len(chunks))
-	for i, chunk := range chunks {
-		translatedChunk, err := translateBodyChunkWithGemini(chunk, sourceLocale, targetLocale, model, apiKey, requestsPerMinute, i+1, len(chunks))
-		if err != nil {
-			return "", "", err
-		}
-		translatedChunks = append(translatedChunks, translatedChunk)
+	keys := make([]string, 0, 3)
+	if req.Title != "" {
+		input["title"] = req.Title
+		keys = append(keys, "translated_title")
 	}
-
-	return translatedTitle, strings.Join(translatedChunks, ""), nil
-}
-
-func translateTitleAndBodyWithGemini(
-	title string,
-	body string,
-	sourceLocale string,
-	targetLocale string,
-	model string,
-	apiKey string,
-	requestsPerMinute int,
-) (string, string, error) {
-	input := map[string]string{
-		"source_locale": sourceLocale,
-		"target_locale": targetLocale,
-		"title":         title,
-		"body":          body,
+	if req.Body != "" {
+		input["body"] = req.Body
+		keys = append(keys, "translated_body")
 	}
-	inputJSON, _ := json.Marshal(input)
-
-	prompt := "You are a translation engine for blog content. " +
-		"Translate title and HTML body faithfully from source_locale to target_locale. " +
-		"Preserve HTML tags, links, and code blocks in body. " +
-		"Return only JSON with keys translated_title and translated_body.\n" +
-		string(inputJSON)
-
-	text, err := requestGeminiJSON(prompt, model, apiKey, requestsPerMinute, geminiResponseSchema("translated_title", "translated_body"))
-	if err != nil {
-		return "", "", err
+	if len(req.Segments) > 0 {
+		input["segments"] = req.Segments
+		keys = append(keys, "translated_segments")
 	}
-	result, err := parseGeminiTranslationText(text)
-	if err != nil {
-		return "", "", err
+	if len(keys) == 0 {
+		return translationResult{}, nil
 	}
-	return result.TranslatedTitle, result.TranslatedBody, nil
-}
-
-func translateTitleWithGemini(
-	title string,
-	sourceLocale string,
-	targetLocale string,
-	model string,
-	apiKey string,
-	requestsPerMinute int,
-) (string, error) {
-	input := map[string]string{
-		"source_locale": sourceLocale,
-		"target_locale": targetLocale,
-		"title":         title,
+	if req.ChunkCount > 1 {
+		input["chunk_index"] = req.ChunkIndex
+		input["chunk_count"] = req.ChunkCount
 	}
 	inputJSON, _ := json.Marshal(input)
+
 	prompt := "You are a translation engine for blog content. " +
-		"Translate title faithfully from source_locale to target_locale. " +
-		"Return only JSON with key translated_title.\n" +
+		"Translate every provided field faithfully from source_locale to target_locale. " +
+		"body and segments contain HTML; preserve HTML tags, links, entities, and code blocks. " +
+		"segments is an ordered list of fragments from one document: translate each one separately, keep the same order and count, and do not merge, split, or add introductions. " +
+		"Return only JSON with keys " + strings.Join(keys, ", ") + ".\n" +
 		string(inputJSON)
 
-	text, err := requestGeminiJSON(prompt, model, apiKey, requestsPerMinute, geminiResponseSchema("translated_title"))
+	text, err := t.complete(prompt, translationResponseSchema(keys))
 	if err != nil {
-		return "", err
-	}
-
-	var payload translatedTitlePayload
-	if err := unmarshalGeminiJSON(text, &payload); err != nil {
-		return "", err
-	}
-	payload.TranslatedTitle = strings.TrimSpace(payload.TranslatedTitle)
-	if payload.TranslatedTitle == "" {
-		return "", errors.New("gemini translation returned empty title")
+		return translationResult{}, err
 	}
-	return payload.TranslatedTitle, nil
-}
-
-func translateBodyChunkWithGemini(
-	body string,
-	sourceLocale string,
-	targetLocale string,
-	model string,
-	apiKey string,
-	requestsPerMinute int,
-	chunkIndex int,
-	chunkCount int,
-) (string, error) {
-	input := map[string]any{
-		"source_locale": sourceLocale,
-		"target_locale": targetLocale,
-		"chunk_index":   chunkIndex,
-		"chunk_count":   chunkCount,
-		"body":          body,
-	}
-	inputJSON, _ := json.Marshal(input)
-	prompt := "You are a translation engine for blog content. " +
-		"Translate the HTML body fragment faithfully from source_locale to target_locale. " +
-		"Preserve HTML tags, links, entities, and code blocks in body. " +
-		"The fragment is one chunk of a longer document, so keep boundaries natural and do not add introductions or conclusions. " +
-		"Return only JSON with key translated_body.\n" +
-		string(inputJSON)
-
-	text, err := requestGeminiJSON(prompt, model, apiKey, requestsPerMinute, geminiResponseSchema("translated_body"))
+	payload, err := parseTranslationPayloadText(text)
 	if err != nil {
-		return "", err
+		return translationResult{}, err
 	}
-
-	var payload translatedBodyPayload
-	if err := unmarshalGeminiJSON(text, &payload); err != nil {
-		return "", err
+	if (req.Title != "" && payload.TranslatedTitle == "") || (req.Body != "" && payload.TranslatedBody == "") {
+		return translationResult{}, errors.New("translation returned empty title/body")
 	}
-	payload.TranslatedBody = strings.TrimSpace(payload.TranslatedBody)
-	if payload.TranslatedBody == "" {
-		return "", errors.New("gemini translation returned empty body")
+	if len(payload.TranslatedSegments) != len(req.Segments) {
+		return translationResult{}, fmt.Errorf("translation returned %d segments for %d", len(payload.TranslatedSegments), len(req.Segments))
 	}
-	return payload.TranslatedBody, nil
+	return translationResult{
+		Title:    payload.TranslatedTitle,
+		Body:     payload.TranslatedBody,
+		Segments: payload.TranslatedSegments,
+	}, nil
 }
 
 func requestGeminiJSON(prompt, model, apiKey string, requestsPerMinute int, responseSchema map[string]any) (string, error) {
@@ -667,16 +683,33 @@ func requestGeminiJSON(prompt, model, apiKey string, requestsPerMinute int, resp
 		model,
 		apiKey,
 	)
+	return doTranslationRequest(translationProviderGemini, url, nil, bodyBytes, requestsPerMinute, parseGeminiResponseText)
+}
+
+// doTranslationRequest posts body to a provider endpoint with pacing and
+// retries. A response that fails parse is retried like a transport error.
+func doTranslationRequest(
+	provider string,
+	url string,
+	headers map[string]string,
+	body []byte,
+	requestsPerMinute int,
+	parse func([]byte) (string, error),
+) (string, error) {
+	limiter := translationRateLimiterFor(provider)
 
 	var lastErr error
 	for attempt := 1; attempt <= maxTranslateRetries; attempt++ {
-		sharedGeminiRateLimiter.Wait(requestsPerMinute)
+		limiter.Wait(requestsPerMinute)
 
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 		if err != nil {
 			return "", err
 		}
 		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
 
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
@@ -687,12 +720,13 @@ func requestGeminiJSON(prompt, model, apiKey string, requestsPerMinute int, resp
 			if readErr != nil {
 				lastErr = readErr
 			} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-				lastErr = &geminiError{
-					Status: resp.StatusCode,
-					Body:   string(respBody),
+				lastErr = &translationProviderError{
+					Provider: provider,
+					Status:   resp.StatusCode,
+					Body:     string(respBody),
 				}
 			} else {
-				text, parseErr := parseGeminiResponseText(respBody)
+				text, parseErr := parse(respBody)
 				if parseErr == nil {
 					return text, nil
 				}
@@ -707,7 +741,7 @@ func requestGeminiJSON(prompt, model, apiKey string, requestsPerMinute int, resp
 	return "", lastErr
 }
 
-func (l *geminiRateLimiter) Wait(requestsPerMinute int) {
+func (l *translationRateLimiter) Wait(requestsPerMinute int) {
 	if requestsPerMinute <= 0 {
 		return
 	}
@@ -743,19 +777,40 @@ func parseGeminiResponseText(responseBody []byte) (string, error) {
 	return extractFirstJSONObject(text)
 }
 
-func parseGeminiTranslationText(text string) (translatedPayload, error) {
+func parseTranslationPayloadText(text string) (translatedPayload, error) {
 	var payload translatedPayload
-	if err := unmarshalGeminiJSON(text, &payload); err != nil {
+	if err := unmarshalTranslationJSON(text, &payload); err != nil {
 		return translatedPayload{}, err
 	}
 	payload.TranslatedTitle = strings.TrimSpace(payload.TranslatedTitle)
 	payload.TranslatedBody = strings.TrimSpace(payload.TranslatedBody)
-	if payload.TranslatedTitle == "" || payload.TranslatedBody == "" {
-		return translatedPayload{}, errors.New("gemini translation returned empty title/body")
+	for i, segment := range payload.TranslatedSegments {
+		payload.TranslatedSegments[i] = strings.TrimSpace(segment)
+	}
+	if payload.TranslatedTitle == "" && payload.TranslatedBody == "" && len(payload.TranslatedSegments) == 0 {
+		return translatedPayload{}, errors.New("translation returned empty title/body")
 	}
 	return payload, nil
 }
 
+// translationResponseSchema describes the requested translation keys;
+// translated_segments is the only array-valued key.
+func translationResponseSchema(keys []string) map[string]any {
+	properties := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if key == "translated_segments" {
+			properties[key] = map[string]any{"type": "ARRAY", "items": map[string]any{"type": "STRING"}}
+			continue
+		}
+		properties[key] = map[string]any{"type": "STRING"}
+	}
+	return map[string]any{
+		"type":       "OBJECT",
+		"properties": properties,
+		"required":   keys,
+	}
+}
+
 func geminiResponseSchema(fields ...string) map[string]any {
 	properties := make(map[string]any, len(fields))
 	for _, field := range fields {
@@ -768,7 +823,7 @@ func geminiResponseSchema(fields ...string) map[string]any {
 	}
 }
 
-func unmarshalGeminiJSON(text string, target any) error {
+func unmarshalTranslationJSON(text string, target any) error {
 	if err := json.Unmarshal([]byte(text), target); err == nil {
 		return nil
 	} else {
@@ -777,7 +832,7 @@ func unmarshalGeminiJSON(text string, target any) error {
 			if repairErr := json.Unmarshal([]byte(repaired), target); repairErr == nil {
 				return nil
 			} else {
-				return fmt.Errorf("invalid Gemini JSON: %w (after escape repair: %v)", err, repairErr)
+				return fmt.Errorf("invalid translation JSON: %w (after escape repair: %v)", err, repairErr)
 			}
 		}
 		return err
@@ -846,7 +901,7 @@ func extractFirstJSONObject(text string) (string, error) {
 	text = strings.TrimSpace(text)
 	start := strings.IndexByte(text, '{')
 	if start == -1 {
-		return "", errors.New("gemini response did not contain JSON object")
+		return "", errors.New("translation response did not contain JSON object")
 	}
 
 	depth := 0
@@ -883,7 +938,7 @@ func extractFirstJSONObject(text string) (string, error) {
 			}
 		}
 	}
-	return "", errors.New("gemini response contained unterminated JSON object")
+	return "", errors.New("translation response contained unterminated JSON object")
 }
 
 func splitTranslationBody(body string, maxRunes int) []string {
@@ -991,10 +1046,10 @@ func splitOversizedTranslationSegment(segment string, maxRunes int) []string {
 	return chunks
 }
 
-func isGeminiRateLimitError(err error) bool {
-	var ge *geminiError
-	if errors.As(err, &ge) {
-		return ge.Status == http.StatusTooManyRequests
+func isTranslationRateLimitError(err error) bool {
+	var pe *translationProviderError
+	if errors.As(err, &pe) {
+		return pe.Status == http.StatusTooManyRequests
 	}
 	return false
 }
@@ -1077,8 +1132,8 @@ func runTranslationJobs(
 					if err != nil {
 						failedCount++
 						log.Printf("translation locale failed source=%s locale=%s err=%v", job.source.Id, job.locale, err)
-						if stopOnRateLimit && fatalErr == nil && isGeminiRateLimitError(err) {
-							fatalErr = fmt.Errorf("translate-posts stopped due to Gemini rate limit after retry exhaustion: %w", err)
+						if stopOnRateLimit && fatalErr == nil && isTranslationRateLimitError(err) {
+							fatalErr = fmt.Errorf("translate-posts stopped due to provider rate limit after retry exhaustion: %w", err)
 							cancel()
 						}
 					} else {
@@ -0,0 +1,457 @@
+package pbapp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const translationMemoryCollection = "translation_memory"
+
+// translationSegmentState is stored on post_translations.translation_segments.
+// It records what the pipeline last wrote for each source segment so later
+// runs can tell machine output from human edits.
+type translationSegmentState struct {
+	Title    translationSegment   `json:"title"`
+	Segments []translationSegment `json:"segments"`
+}
+
+type translationSegment struct {
+	Hash  string `json:"hash"`
+	Text  string `json:"text"`
+	Human bool   `json:"human,omitempty"`
+	Stale bool   `json:"stale,omitempty"`
+}
+
+type sourceSegment struct {
+	Hash string
+	Raw  string
+}
+
+type translationPlan struct {
+	Title        translationSegment
+	Segments     []translationSegment
+	Pending      []int
+	TitlePending bool
+	Stale        []string
+}
+
+func hashTranslationSegment(text string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitSourceSegments(body string) []sourceSegment {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	parts := splitTranslationSegments(body)
+	out := make([]sourceSegment, 0, len(parts))
+	for _, part := range parts {
+		out = append(out, sourceSegment{Hash: hashTranslationSegment(part), Raw: part})
+	}
+	return out
+}
+
+func composeTranslationBody(segments []translationSegment) string {
+	var body strings.Builder
+	for _, segment := range segments {
+		body.WriteString(segment.Text)
+	}
+	return strings.TrimSpace(body.String())
+}
+
+func loadTranslationSegmentState(record *core.Record) *translationSegmentState {
+	if record == nil {
+		return nil
+	}
+	raw, ok := record.Get("translation_segments").(types.JSONRaw)
+	if !ok || len(raw) == 0 || string(raw) == "null" {
+		// Translations written before segment tracking have no baseline.
+		// One the translator finished is its output and is re-translated
+		// like any machine segment; anything else was written by hand.
+		human := !record.GetBool("translation_done")
+		return &translationSegmentState{
+			Title:    translationSegment{Text: record.GetString("title"), Human: human},
+			Segments: []translationSegment{{Text: record.GetString("body"), Human: human}},
+		}
+	}
+	var state translationSegmentState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		log.Printf("translation segments ignored for translation=%s: %v", record.Id, err)
+		return nil
+	}
+	return &state
+}
+
+// reconcileHumanEdits compares the stored machine output with what the record
+// holds now and marks every segment whose text no longer appears as edited.
+// Edited text between two untouched segments is attributed to the first
+// edited segment of that gap.
+func reconcileHumanEdits(state translationSegmentState, title, body string) translationSegmentState {
+	if strings.TrimSpace(title) != strings.TrimSpace(state.Title.Text) {
+		state.Title.Text = title
+		state.Title.Human = true
+		state.Title.Stale = false
+	}
+	segments := append([]translationSegment(nil), state.Segments...)
+	state.Segments = segments
+	if strings.TrimSpace(body) == composeTranslationBody(segments) {
+		return state
+	}
+
+	body = strings.TrimSpace(body)
+	cursor := 0
+	pending := make([]int, 0)
+	lastMatched := -1
+	assignGap := func(gap string) {
+		if len(pending) > 0 {
+			for n, idx := range pending {
+				segments[idx].Human = true
+				segments[idx].Stale = false
+				segments[idx].Text = ""
+				if n == 0 {
+					// The previous segment already carries the separating whitespace.
+					segments[idx].Text = strings.TrimLeft(gap, " \t\r\n")
+				}
+			}
+			pending = pending[:0]
+			return
+		}
+		if strings.TrimSpace(gap) == "" {
+			return
+		}
+		if lastMatched >= 0 {
+			segments[lastMatched].Text += gap
+			segments[lastMatched].Human = true
+			segments[lastMatched].Stale = false
+		}
+	}
+
+	for i := range segments {
+		text := segments[i].Text
+		if strings.TrimSpace(text) == "" {
+			if segments[i].Human {
+				pending = append(pending, i)
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(text)
+		pos := strings.Index(body[cursor:], trimmed)
+		if pos < 0 {
+			pending = append(pending, i)
+			continue
+		}
+		gap := body[cursor : cursor+pos]
+		if lastMatched < 0 && len(pending) == 0 && strings.TrimSpace(gap) != "" {
+			segments[i].Text = gap + text
+			segments[i].Human = true
+			segments[i].Stale = false
+		} else {
+			assignGap(gap)
+		}
+		cursor += pos + len(trimmed)
+		lastMatched = i
+	}
+	assignGap(body[cursor:])
+	return state
+}
+
+// planTranslationUpdate lines the previous segments up with the current
+// source by hash. Unchanged segments keep their text; changed segments are
+// queued for translation unless a human edited the text they replace, in which
+// case the edit is kept and the segment is reported as stale.
+func planTranslationUpdate(previous *translationSegmentState, sourceTitle string, source []sourceSegment) translationPlan {
+	plan := translationPlan{
+		Title:    translationSegment{Hash: hashTranslationSegment(sourceTitle)},
+		Segments: make([]translationSegment, len(source)),
+	}
+
+	var old []translationSegment
+	if previous != nil {
+		old = previous.Segments
+		switch {
+		case previous.Title.Hash == plan.Title.Hash:
+			plan.Title = previous.Title
+		case previous.Title.Human:
+			plan.Title.Text = previous.Title.Text
+			plan.Title.Human = true
+			plan.Title.Stale = true
+			plan.Stale = append(plan.Stale, plan.Title.Hash)
+		default:
+			plan.TitlePending = true
+		}
+	} else {
+		plan.TitlePending = true
+	}
+
+	oldHashes := make([]string, len(old))
+	for i, segment := range old {
+		oldHashes[i] = segment.Hash
+	}
+	newHashes := make([]string, len(source))
+	for i, segment := range source {
+		newHashes[i] = segment.Hash
+	}
+
+	fillGap := func(oldFrom, oldTo, newFrom, newTo int) {
+		// Same-sized gaps are edits in place, so each segment keeps its own
+		// human text; otherwise human text moves to the first new segment.
+		if oldTo-oldFrom == newTo-newFrom {
+			for k := 0; k < newTo-newFrom; k++ {
+				j := newFrom + k
+				plan.Segments[j] = translationSegment{Hash: source[j].Hash}
+				if !old[oldFrom+k].Human {
+					plan.Pending = append(plan.Pending, j)
+					continue
+				}
+				plan.Segments[j].Text = old[oldFrom+k].Text
+				plan.Segments[j].Human = true
+				plan.Segments[j].Stale = true
+				plan.Stale = append(plan.Stale, source[j].Hash)
+			}
+			return
+		}
+
+		human := strings.Builder{}
+		edited := false
+		for i := oldFrom; i < oldTo; i++ {
+			if old[i].Human {
+				edited = true
+				human.WriteString(old[i].Text)
+			}
+		}
+		for j := newFrom; j < newTo; j++ {
+			plan.Segments[j] = translationSegment{Hash: source[j].Hash}
+			if !edited {
+				plan.Pending = append(plan.Pending, j)
+				continue
+			}
+			plan.Segments[j].Human = true
+			plan.Segments[j].Stale = true
+			if j == newFrom {
+				plan.Segments[j].Text = human.String()
+			}
+			plan.Stale = append(plan.Stale, source[j].Hash)
+		}
+	}
+
+	oldCursor, newCursor := 0, 0
+	for _, match := range alignSegmentHashes(oldHashes, newHashes) {
+		fillGap(oldCursor, match[0], newCursor, match[1])
+		plan.Segments[match[1]] = old[match[0]]
+		oldCursor, newCursor = match[0]+1, match[1]+1
+	}
+	fillGap(oldCursor, len(old), newCursor, len(source))
+	return plan
+}
+
+// alignSegmentHashes returns the longest common subsequence of two hash lists
+// as (old index, new index) pairs.
+func alignSegmentHashes(old, next []string) [][2]int {
+	lengths := make([][]int, len(old)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(next)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(next) - 1; j >= 0; j-- {
+			if old[i] != "" && old[i] == next[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	pairs := make([][2]int, 0, lengths[0][0])
+	i, j := 0, 0
+	for i < len(old) && j < len(next) {
+		switch {
+		case old[i] != "" && old[i] == next[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// translateSegments sends segments in batches that fit one provider call and
+// returns the translations in order.
+func translateSegments(translator Translator, segments []string, sourceLocale, targetLocale string) ([]string, error) {
+	batches := make([][]string, 0)
+	current := make([]string, 0)
+	size := 0
+	for _, segment := range segments {
+		runes := len([]rune(segment))
+		if len(current) > 0 && size+runes > maxTranslationBodyRunes {
+			batches = append(batches, current)
+			current = make([]string, 0)
+			size = 0
+		}
+		current = append(current, segment)
+		size += runes
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	out := make([]string, 0, len(segments))
+	for i, batch := range batches {
+		result, err := translator.Translate(translationRequest{
+			SourceLocale: sourceLocale,
+			TargetLocale: targetLocale,
+			Segments:     batch,
+			ChunkIndex:   i + 1,
+			ChunkCount:   len(batches),
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, result.Segments...)
+	}
+	return out, nil
+}
+
+// fillPendingSegments resolves queued segments from translation memory first
+// and sends only the misses to the provider.
+func fillPendingSegments(
+	app core.App,
+	translator Translator,
+	plan *translationPlan,
+	source []sourceSegment,
+	provider string,
+	sourceLocale string,
+	targetLocale string,
+) error {
+	if len(plan.Pending) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, 0, len(plan.Pending))
+	for _, idx := range plan.Pending {
+		hashes = append(hashes, source[idx].Hash)
+	}
+	memory := lookupTranslationMemory(app, hashes, sourceLocale, targetLocale)
+
+	misses := make([]int, 0)
+	missTexts := make([]string, 0)
+	seen := map[string]struct{}{}
+	for _, idx := range plan.Pending {
+		hash := source[idx].Hash
+		if _, ok := memory[hash]; ok {
+			continue
+		}
+		text := strings.TrimSpace(source[idx].Raw)
+		if !hasTranslatableText(text) {
+			memory[hash] = text
+			continue
+		}
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		misses = append(misses, idx)
+		missTexts = append(missTexts, text)
+	}
+
+	if len(misses) > 0 {
+		translated, err := translateSegments(translator, missTexts, sourceLocale, targetLocale)
+		if err != nil {
+			return err
+		}
+		for n, idx := range misses {
+			memory[source[idx].Hash] = translated[n]
+			storeTranslationMemory(app, source[idx].Hash, missTexts[n], translated[n], provider, sourceLocale, targetLocale)
+		}
+	}
+
+	for _, idx := range plan.Pending {
+		plan.Segments[idx].Text = rewrapSegmentWhitespace(source[idx].Raw, memory[source[idx].Hash])
+	}
+	plan.Pending = nil
+	return nil
+}
+
+func hasTranslatableText(text string) bool {
+	plain := buildExcerpt(text, len(text)+1)
+	for _, r := range plain {
+		if r > ' ' && !strings.ContainsRune("-_=*#|.,;:!?()[]{}<>/\\'\"`~", r) {
+			return true
+		}
+	}
+	return false
+}
+
+func rewrapSegmentWhitespace(raw, translated string) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return raw
+	}
+	start := strings.Index(raw, trimmed)
+	return raw[:start] + translated + raw[start+len(trimmed):]
+}
+
+func lookupTranslationMemory(app core.App, hashes []string, sourceLocale, targetLocale string) map[string]string {
+	out := map[string]string{}
+	if len(hashes) == 0 {
+		return out
+	}
+	values := make([]any, 0, len(hashes))
+	for _, hash := range hashes {
+		values = append(values, hash)
+	}
+	records, err := app.FindAllRecords(
+		translationMemoryCollection,
+		dbx.HashExp{
+			"source_locale": sourceLocale,
+			"target_locale": targetLocale,
+			"source_hash":   values,
+		},
+	)
+	if err != nil {
+		log.Printf("translation memory lookup failed %s->%s: %v", sourceLocale, targetLocale, err)
+		return out
+	}
+	for _, record := range records {
+		out[record.GetString("source_hash")] = record.GetString("translated_text")
+	}
+	return out
+}
+
+func storeTranslationMemory(app core.App, hash, sourceText, translatedText, provider, sourceLocale, targetLocale string) {
+	collection, err := app.FindCollectionByNameOrId(translationMemoryCollection)
+	if err != nil {
+		log.Printf("translation memory store failed: %v", err)
+		return
+	}
+	record, err := app.FindFirstRecordByFilter(
+		collection,
+		"source_hash = {:hash} && source_locale = {:source} && target_locale = {:target}",
+		dbx.Params{"hash": hash, "source": sourceLocale, "target": targetLocale},
+	)
+	if err != nil || record == nil {
+		record = core.NewRecord(collection)
+		record.Set("source_hash", hash)
+		record.Set("source_locale", sourceLocale)
+		record.Set("target_locale", targetLocale)
+	}
+	record.Set("source_text", sourceText)
+	record.Set("translated_text", translatedText)
+	record.Set("provider", provider)
+	if err := app.Save(record); err != nil {
+		log.Printf("translation memory store failed hash=%s %s->%s: %v", hash, sourceLocale, targetLocale, err)
+	}
+}
@@ -0,0 +1,118 @@
+package pbapp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func machineState(t *testing.T, title, body string) translationSegmentState {
+	t.Helper()
+
+	segments := splitSourceSegments(body)
+	plan := planTranslationUpdate(nil, title, segments)
+	plan.Title.Text = "[en] " + title
+	for _, idx := range plan.Pending {
+		plan.Segments[idx].Text = rewrapSegmentWhitespace(segments[idx].Raw, "[en] "+strings.TrimSpace(segments[idx].Raw))
+	}
+	return translationSegmentState{Title: plan.Title, Segments: plan.Segments}
+}
+
+func TestPlanTranslationUpdateQueuesOnlyChangedSegments(t *testing.T) {
+	t.Parallel()
+
+	previous := machineState(t, "Title", "<p>one</p><p>two</p><p>three</p>")
+	source := splitSourceSegments("<p>one</p><p>two fixed</p><p>three</p>")
+
+	plan := planTranslationUpdate(&previous, "Title", source)
+	if plan.TitlePending {
+		t.Fatal("title should be reused")
+	}
+	if !reflect.DeepEqual(plan.Pending, []int{1}) {
+		t.Fatalf("pending = %v, want [1]", plan.Pending)
+	}
+	if plan.Segments[0].Text != previous.Segments[0].Text || plan.Segments[2].Text != previous.Segments[2].Text {
+		t.Fatalf("unchanged segments were not reused: %#v", plan.Segments)
+	}
+	if len(plan.Stale) != 0 {
+		t.Fatalf("stale = %v, want none", plan.Stale)
+	}
+}
+
+func TestReconcileHumanEditsKeepsEditedSegmentAndMarksStale(t *testing.T) {
+	t.Parallel()
+
+	previous := machineState(t, "Title", "<p>one</p><p>two</p><p>three</p>")
+	edited := "[en] <p>one</p>\n<p>hand-tuned two</p>\n[en] <p>three</p>"
+	reconciled := reconcileHumanEdits(previous, previous.Title.Text, edited)
+	if !reconciled.Segments[1].Human || reconciled.Segments[0].Human || reconciled.Segments[2].Human {
+		t.Fatalf("human flags = %v %v %v, want only the middle segment", reconciled.Segments[0].Human, reconciled.Segments[1].Human, reconciled.Segments[2].Human)
+	}
+	if got := composeTranslationBody(reconciled.Segments); got != edited {
+		t.Fatalf("reconciled body = %q, want %q", got, edited)
+	}
+
+	source := splitSourceSegments("<p>one</p><p>two, revised</p><p>three!</p>")
+	plan := planTranslationUpdate(&reconciled, "Title", source)
+	if !reflect.DeepEqual(plan.Pending, []int{2}) {
+		t.Fatalf("pending = %v, want only the untouched changed segment", plan.Pending)
+	}
+	if plan.Segments[1].Text != reconciled.Segments[1].Text || !plan.Segments[1].Human {
+		t.Fatalf("human edit was not kept: %#v", plan.Segments[1])
+	}
+	if !reflect.DeepEqual(plan.Stale, []string{source[1].Hash}) {
+		t.Fatalf("stale = %v, want the edited segment hash", plan.Stale)
+	}
+}
+
+func TestPlanTranslationUpdateTreatsUntrackedTranslationsAsHuman(t *testing.T) {
+	t.Parallel()
+
+	previous := translationSegmentState{
+		Title:    translationSegment{Text: "Manual", Human: true},
+		Segments: []translationSegment{{Text: "<p>manual body</p>", Human: true}},
+	}
+	plan := planTranslationUpdate(&previous, "Title", splitSourceSegments("<p>one</p><p>two</p>"))
+	if plan.TitlePending || len(plan.Pending) != 0 {
+		t.Fatalf("plan = %#v, want nothing sent to the provider", plan)
+	}
+	if got := composeTranslationBody(plan.Segments); got != "<p>manual body</p>" {
+		t.Fatalf("body = %q, want manual translation kept", got)
+	}
+	if len(plan.Stale) != 3 {
+		t.Fatalf("stale = %v, want title and both segments", plan.Stale)
+	}
+}
+
+func TestLoadTranslationSegmentStateSeedsLegacyRecords(t *testing.T) {
+	t.Parallel()
+
+	translations := core.NewBaseCollection("post_translations")
+	translations.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.TextField{Name: "body"},
+		&core.BoolField{Name: "translation_done"},
+		&core.JSONField{Name: "translation_segments"},
+	)
+	record := core.NewRecord(translations)
+	record.Set("title", "[en] Title")
+	record.Set("body", "<p>[en] one</p><p>[en] two</p>")
+	record.Set("translation_done", true)
+
+	legacy := loadTranslationSegmentState(record)
+	if legacy == nil || legacy.Title.Human || legacy.Segments[0].Human {
+		t.Fatalf("finished legacy translation = %#v, want machine output", legacy)
+	}
+	reconciled := reconcileHumanEdits(*legacy, record.GetString("title"), record.GetString("body"))
+	plan := planTranslationUpdate(&reconciled, "Title", splitSourceSegments("<p>one</p><p>two</p>"))
+	if !plan.TitlePending || len(plan.Pending) != 2 || len(plan.Stale) != 0 {
+		t.Fatalf("plan = %#v, want a full re-translation without stale segments", plan)
+	}
+
+	record.Set("translation_done", false)
+	if manual := loadTranslationSegmentState(record); manual == nil || !manual.Title.Human || !manual.Segments[0].Human {
+		t.Fatalf("unfinished legacy translation = %#v, want a human edit", manual)
+	}
+}
@@ -0,0 +1,188 @@
+package pbapp
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func triggerPageTranslation(app core.App, source *core.Record) {
+	if source == nil {
+		return
+	}
+	sourceID := source.Id
+	go func() {
+		settings, err := loadTranslationSettings(app)
+		if err != nil {
+			log.Printf("translation settings load failed: %v", err)
+			return
+		}
+		if !settings.Enabled || len(settings.Locales) == 0 || !settings.hasUsableTranslator() {
+			return
+		}
+		fresh, err := app.FindRecordById("pages", sourceID)
+		if err != nil {
+			log.Printf("translation source reload failed for source page=%s: %v", sourceID, err)
+			return
+		}
+		if err := translateSourcePage(app, fresh, settings); err != nil {
+			log.Printf("translation failed for source page=%s: %v", sourceID, err)
+		}
+	}()
+}
+
+func translateAllSourcePages(app core.App) error {
+	settings, err := loadTranslationSettings(app)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return errors.New("post translation is disabled in settings")
+	}
+	if len(settings.Locales) == 0 {
+		return errors.New("translation_locales is empty in settings")
+	}
+	for _, locale := range settings.Locales {
+		if _, err := settings.translatorFor(locale); err != nil {
+			return fmt.Errorf("translation provider for %s: %w", locale, err)
+		}
+	}
+
+	records, err := app.FindRecordsByFilter("pages", "id != ''", "menuOrder", 0, 0)
+	if err != nil {
+		return err
+	}
+
+	success := 0
+	failed := 0
+	for _, source := range records {
+		for _, locale := range settings.Locales {
+			if err := upsertTranslatedPage(app, source, locale, settings, false); err != nil {
+				failed++
+				log.Printf("translation locale failed source page=%s locale=%s err=%v", source.Id, locale, err)
+				if isTranslationRateLimitError(err) {
+					log.Printf("translate-pages finished: success=%d failed=%d", success, failed)
+					return fmt.Errorf("translate-pages stopped due to provider rate limit after retry exhaustion: %w", err)
+				}
+				continue
+			}
+			success++
+		}
+	}
+	log.Printf("translate-pages finished: success=%d failed=%d", success, failed)
+	if failed > 0 {
+		return fmt.Errorf("translation failed for %d jobs", failed)
+	}
+	return nil
+}
+
+func translateSourcePage(app core.App, source *core.Record, settings translationSettings) error {
+	var firstErr error
+	for _, locale := range settings.Locales {
+		if err := upsertTranslatedPage(app, source, locale, settings, true); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			log.Printf("translation locale failed source page=%s locale=%s err=%v", source.Id, locale, err)
+			if isTranslationRateLimitError(err) {
+				return err
+			}
+		}
+	}
+	return firstErr
+}
+
+// upsertTranslatedPage writes the page_translations record of a source page
+// in targetLocale. The translation is served under /<locale> plus the source
+// page's URL, so only the title, menu title and body are translated.
+func upsertTranslatedPage(app core.App, source *core.Record, targetLocale string, settings translationSettings, force bool) error {
+	title := strings.TrimSpace(source.GetString("title"))
+	body := strings.TrimSpace(source.GetString("body"))
+	if body == "" {
+		body = strings.TrimSpace(source.GetString("content"))
+	}
+	if title == "" || body == "" {
+		return nil
+	}
+
+	translationsCollection, err := app.FindCollectionByNameOrId("page_translations")
+	if err != nil {
+		return err
+	}
+
+	translated, err := app.FindFirstRecordByFilter(
+		translationsCollection,
+		"source_page = {:source} && locale = {:locale}",
+		dbx.Params{"source": source.Id, "locale": targetLocale},
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if translated != nil && translated.GetBool("translation_done") && !force {
+		return nil
+	}
+
+	plan, err := planTranslatedContent(app, translated, settings, targetLocale, title, body)
+	if err != nil {
+		return err
+	}
+	if len(plan.Stale) > 0 {
+		log.Printf("translation kept human edits source page=%s locale=%s stale_segments=%d", source.Id, targetLocale, len(plan.Stale))
+	}
+	menuTitle, err := translatePageMenuTitle(app, settings, targetLocale, strings.TrimSpace(source.GetString("menuTitle")))
+	if err != nil {
+		return err
+	}
+
+	if translated == nil {
+		translated = core.NewRecord(translationsCollection)
+		translated.Set("source_page", source.Id)
+		translated.Set("locale", targetLocale)
+	}
+
+	translated.Set("published", source.GetBool("published"))
+	translated.Set("published_at", source.GetString("published_at"))
+	translated.Set("unpublish_at", source.GetString("unpublish_at"))
+	translated.Set("title", plan.Title.Text)
+	translated.Set("menuTitle", menuTitle)
+	translated.Set("body", composeTranslationBody(plan.Segments))
+	state := translationSegmentState{Title: plan.Title, Segments: plan.Segments}
+	translated.Set("translation_segments", state)
+	translated.Set("translation_status", nextTranslationStatus(translated.GetString("translation_status"), state, plan))
+	translated.Set("translation_done", true)
+
+	return app.Save(translated)
+}
+
+// translatePageMenuTitle goes through translation memory, so the short menu
+// label is only sent to the provider when it changes. An empty label stays
+// empty and menus fall back to the page title.
+func translatePageMenuTitle(app core.App, settings translationSettings, targetLocale, menuTitle string) (string, error) {
+	if menuTitle == "" {
+		return "", nil
+	}
+	hash := hashTranslationSegment(menuTitle)
+	if cached, ok := lookupTranslationMemory(app, []string{hash}, settings.SourceLocale, targetLocale)[hash]; ok {
+		return cached, nil
+	}
+
+	translator, err := settings.translatorFor(targetLocale)
+	if err != nil {
+		return "", err
+	}
+	result, err := translator.Translate(translationRequest{
+		SourceLocale: settings.SourceLocale,
+		TargetLocale: targetLocale,
+		Title:        menuTitle,
+	})
+	if err != nil {
+		return "", err
+	}
+	storeTranslationMemory(app, hash, menuTitle, result.Title, settings.providerFor(targetLocale).Provider, settings.SourceLocale, targetLocale)
+	return result.Title, nil
+}
@@ -0,0 +1,239 @@
+package pbapp
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	translationStatusMachine  = "machine"
+	translationStatusInReview = "in_review"
+	translationStatusApproved = "approved"
+	translationStatusStale    = "stale"
+)
+
+var translationStatuses = []string{
+	translationStatusMachine,
+	translationStatusInReview,
+	translationStatusApproved,
+	translationStatusStale,
+}
+
+type translationSegmentDiff struct {
+	Index       int    `json:"index"`
+	Hash        string `json:"hash,omitempty"`
+	Status      string `json:"status"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	Human       bool   `json:"human"`
+}
+
+type translationDiffResponse struct {
+	ID       string                   `json:"id"`
+	Locale   string                   `json:"locale"`
+	Status   string                   `json:"status"`
+	Title    translationSegmentDiff   `json:"title"`
+	Segments []translationSegmentDiff `json:"segments"`
+}
+
+func registerTranslationReviewFeatures(app *pocketbase.PocketBase) {
+	app.OnRecordUpdate("post_translations", "page_translations").BindFunc(func(e *core.RecordEvent) error {
+		syncTranslationReviewState(e.Record)
+		return e.Next()
+	})
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/api/translations/{id}/diff", func(e *core.RequestEvent) error {
+			if err := requireEditorOrAdminAuth(e); err != nil {
+				return err
+			}
+
+			translated, err := e.App.FindRecordById("post_translations", e.Request.PathValue("id"))
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return apis.NewNotFoundError("Translation not found.", nil)
+				}
+				return err
+			}
+			source, err := e.App.FindRecordById("posts", translated.GetString("source_post"))
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return apis.NewNotFoundError("Source post not found.", nil)
+				}
+				return err
+			}
+
+			return e.JSON(http.StatusOK, buildTranslationDiff(source, translated))
+		}).Bind(apis.RequireAuth())
+
+		return se.Next()
+	})
+}
+
+func normalizeTranslationStatus(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, status := range translationStatuses {
+		if value == status {
+			return status
+		}
+	}
+	return translationStatusMachine
+}
+
+// nextTranslationStatus is the status after a pipeline run. Kept human edits
+// whose source moved on win over everything; fresh machine output needs a new
+// review; a run that changed nothing keeps the reviewer's verdict.
+func nextTranslationStatus(current string, state translationSegmentState, plan translationPlan) string {
+	switch {
+	case state.hasStale():
+		return translationStatusStale
+	case plan.TitlePending || len(plan.Pending) > 0:
+		return translationStatusMachine
+	default:
+		return normalizeTranslationStatus(current)
+	}
+}
+
+func (s translationSegmentState) hasStale() bool {
+	if s.Title.Stale {
+		return true
+	}
+	for _, segment := range s.Segments {
+		if segment.Stale {
+			return true
+		}
+	}
+	return false
+}
+
+// syncTranslationReviewState runs on every translation save so edits made in
+// the CMS are folded into the segment state: edited stale segments count as
+// reviewed, and approving clears whatever is still flagged.
+func syncTranslationReviewState(record *core.Record) {
+	if raw, ok := record.Get("translation_segments").(types.JSONRaw); !ok || len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+	state := loadTranslationSegmentState(record)
+	if state == nil {
+		return
+	}
+
+	reconciled := reconcileHumanEdits(*state, record.GetString("title"), record.GetString("body"))
+	status := normalizeTranslationStatus(record.GetString("translation_status"))
+	if status == translationStatusApproved {
+		reconciled.Title.Stale = false
+		for i := range reconciled.Segments {
+			reconciled.Segments[i].Stale = false
+		}
+	}
+	if status == translationStatusStale && !reconciled.hasStale() {
+		status = translationStatusInReview
+	}
+
+	record.Set("translation_segments", reconciled)
+	record.Set("translation_status", status)
+}
+
+func buildTranslationDiff(source, translated *core.Record) translationDiffResponse {
+	sourceTitle := strings.TrimSpace(source.GetString("title"))
+	sourceBody := strings.TrimSpace(source.GetString("body"))
+	if sourceBody == "" {
+		sourceBody = strings.TrimSpace(source.GetString("content"))
+	}
+
+	state := translationSegmentState{}
+	if loaded := loadTranslationSegmentState(translated); loaded != nil {
+		state = reconcileHumanEdits(*loaded, translated.GetString("title"), translated.GetString("body"))
+	}
+
+	return translationDiffResponse{
+		ID:       translated.Id,
+		Locale:   translated.GetString("locale"),
+		Status:   normalizeTranslationStatus(translated.GetString("translation_status")),
+		Title:    diffTranslationTitle(sourceTitle, state.Title),
+		Segments: diffTranslationSegments(splitSourceSegments(sourceBody), state.Segments),
+	}
+}
+
+func diffTranslationTitle(sourceTitle string, title translationSegment) translationSegmentDiff {
+	diff := translationSegmentDiff{
+		Hash:        hashTranslationSegment(sourceTitle),
+		Status:      segmentDiffStatus(title),
+		Source:      sourceTitle,
+		Translation: strings.TrimSpace(title.Text),
+		Human:       title.Human,
+	}
+	if title.Hash != diff.Hash && !title.Stale {
+		diff.Status = "changed"
+	}
+	return diff
+}
+
+// diffTranslationSegments pairs each source segment with the translation it
+// currently has. Source segments without a translation are "changed";
+// translated text whose source is gone is "removed".
+func diffTranslationSegments(source []sourceSegment, translated []translationSegment) []translationSegmentDiff {
+	oldHashes := make([]string, len(translated))
+	for i, segment := range translated {
+		oldHashes[i] = segment.Hash
+	}
+	newHashes := make([]string, len(source))
+	for i, segment := range source {
+		newHashes[i] = segment.Hash
+	}
+
+	out := make([]translationSegmentDiff, 0, len(source))
+	appendGap := func(oldFrom, oldTo, newFrom, newTo int) {
+		for i := oldFrom; i < oldTo; i++ {
+			if strings.TrimSpace(translated[i].Text) == "" {
+				continue
+			}
+			out = append(out, translationSegmentDiff{
+				Index:       -1,
+				Hash:        translated[i].Hash,
+				Status:      "removed",
+				Translation: strings.TrimSpace(translated[i].Text),
+				Human:       translated[i].Human,
+			})
+		}
+		for j := newFrom; j < newTo; j++ {
+			out = append(out, translationSegmentDiff{
+				Index:  j,
+				Hash:   source[j].Hash,
+				Status: "changed",
+				Source: strings.TrimSpace(source[j].Raw),
+			})
+		}
+	}
+
+	oldCursor, newCursor := 0, 0
+	for _, match := range alignSegmentHashes(oldHashes, newHashes) {
+		appendGap(oldCursor, match[0], newCursor, match[1])
+		segment := translated[match[0]]
+		out = append(out, translationSegmentDiff{
+			Index:       match[1],
+			Hash:        segment.Hash,
+			Status:      segmentDiffStatus(segment),
+			Source:      strings.TrimSpace(source[match[1]].Raw),
+			Translation: strings.TrimSpace(segment.Text),
+			Human:       segment.Human,
+		})
+		oldCursor, newCursor = match[0]+1, match[1]+1
+	}
+	appendGap(oldCursor, len(translated), newCursor, len(source))
+	return out
+}
+
+func segmentDiffStatus(segment translationSegment) string {
+	if segment.Stale {
+		return translationStatusStale
+	}
+	return "current"
+}
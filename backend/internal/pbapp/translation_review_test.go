@@ -0,0 +1,61 @@
+package pbapp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextTranslationStatus(t *testing.T) {
+	t.Parallel()
+
+	previous := machineState(t, "Title", "<p>one</p><p>two</p>")
+	unchanged := planTranslationUpdate(&previous, "Title", splitSourceSegments("<p>one</p><p>two</p>"))
+	changed := planTranslationUpdate(&previous, "Title", splitSourceSegments("<p>one</p><p>two!</p>"))
+
+	edited := previous
+	edited.Segments = append([]translationSegment(nil), previous.Segments...)
+	edited.Segments[1].Human = true
+	kept := planTranslationUpdate(&edited, "Title", splitSourceSegments("<p>one</p><p>two!</p>"))
+
+	tests := []struct {
+		name    string
+		current string
+		plan    translationPlan
+		want    string
+	}{
+		{name: "unchanged keeps approval", current: "approved", plan: unchanged, want: translationStatusApproved},
+		{name: "unknown becomes machine", current: "", plan: unchanged, want: translationStatusMachine},
+		{name: "new machine text needs review", current: "approved", plan: changed, want: translationStatusMachine},
+		{name: "kept human edit is stale", current: "approved", plan: kept, want: translationStatusStale},
+	}
+	for _, tt := range tests {
+		state := translationSegmentState{Title: tt.plan.Title, Segments: tt.plan.Segments}
+		if got := nextTranslationStatus(tt.current, state, tt.plan); got != tt.want {
+			t.Fatalf("%s: status = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffTranslationSegments(t *testing.T) {
+	t.Parallel()
+
+	previous := machineState(t, "Title", "<p>one</p><p>two</p><p>three</p>")
+	previous.Segments[2].Stale = true
+	source := splitSourceSegments("<p>one</p><p>two, revised</p><p>three</p><p>four</p>")
+
+	diff := diffTranslationSegments(source, previous.Segments)
+	statuses := make([]string, 0, len(diff))
+	for _, item := range diff {
+		statuses = append(statuses, item.Status)
+	}
+	want := []string{"current", "removed", "changed", "stale", "changed"}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	if diff[1].Translation != "[en] <p>two</p>" || diff[1].Index != -1 {
+		t.Fatalf("removed entry = %#v", diff[1])
+	}
+	if diff[2].Source != "<p>two, revised</p>" || diff[2].Translation != "" {
+		t.Fatalf("changed entry = %#v", diff[2])
+	}
+}
@@ -131,13 +131,13 @@ func TestExtractFirstJSONObjectKeepsEscapedQuotes(t *testing.T) {
 	}
 }
 
-func TestParseGeminiTranslationTextRepairsInvalidStringEscapes(t *testing.T) {
+func TestParseTranslationPayloadTextRepairsInvalidStringEscapes(t *testing.T) {
 	t.Parallel()
 
 	input := `{"translated_title":"title","translated_body":"Use \uÐ... and C:\Users\docs"}`
-	got, err := parseGeminiTranslationText(input)
+	got, err := parseTranslationPayloadText(input)
 	if err != nil {
-		t.Fatalf("parseGeminiTranslationText returned error: %v", err)
+		t.Fatalf("parseTranslationPayloadText returned error: %v", err)
 	}
 	wantBody := `Use \uÐ... and C:\Users\docs`
 	if got.TranslatedBody != wantBody {
@@ -145,13 +145,13 @@ func TestParseGeminiTranslationTextRepairsInvalidStringEscapes(t *testing.T) {
 	}
 }
 
-func TestParseGeminiTranslationTextKeepsValidEscapes(t *testing.T) {
+func TestParseTranslationPayloadTextKeepsValidEscapes(t *testing.T) {
 	t.Parallel()
 
 	input := `{"translated_title":"line\n\\path","translated_body":"こんにちは\\u4f60"}`
-	got, err := parseGeminiTranslationText(input)
+	got, err := parseTranslationPayloadText(input)
 	if err != nil {
-		t.Fatalf("parseGeminiTranslationText returned error: %v", err)
+		t.Fatalf("parseTranslationPayloadText returned error: %v", err)
 	}
 	if got.TranslatedTitle != "line\n\\path" {
 		t.Fatalf("translated title = %q, want valid JSON escapes to be decoded", got.TranslatedTitle)
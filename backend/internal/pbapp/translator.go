@@ -0,0 +1,356 @@
+package pbapp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	translationProviderGemini = "gemini"
+	translationProviderOpenAI = "openai"
+	translationProviderDeepL  = "deepl"
+
+	defaultOpenAITranslationModel    = "gpt-4o-mini"
+	defaultOpenAITranslationEndpoint = "https://api.openai.com/v1"
+	defaultDeepLTranslationEndpoint  = "https://api-free.deepl.com/v2"
+)
+
+// Translator turns one request into translated text. Requests carry any of a
+// title, a body, or an ordered batch of body segments; translateSegments keeps
+// each batch small enough for a single call.
+type Translator interface {
+	Translate(req translationRequest) (translationResult, error)
+}
+
+type translationRequest struct {
+	SourceLocale string
+	TargetLocale string
+	Title        string
+	Body         string
+	Segments     []string
+	ChunkIndex   int
+	ChunkCount   int
+}
+
+type translationResult struct {
+	Title    string
+	Body     string
+	Segments []string
+}
+
+type translationProviderConfig struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// llmTranslator drives chat-style models that answer prompts with JSON.
+type llmTranslator struct {
+	complete func(prompt string, schema map[string]any) (string, error)
+}
+
+type deeplTranslator struct {
+	endpoint          string
+	apiKey            string
+	requestsPerMinute int
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+func normalizeTranslationProvider(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "gemini":
+		return translationProviderGemini
+	case "openai", "openai-compatible", "ollama", "llama.cpp":
+		return translationProviderOpenAI
+	case "deepl":
+		return translationProviderDeepL
+	default:
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+func (s translationSettings) providerFor(locale string) translationProviderConfig {
+	config := s.Provider
+	config.Provider = normalizeTranslationProvider(config.Provider)
+	if override, ok := s.LocaleProviders[normalizeLocale(locale)]; ok {
+		if provider := normalizeTranslationProvider(override.Provider); strings.TrimSpace(override.Provider) != "" && provider != config.Provider {
+			config = translationProviderConfig{Provider: provider}
+		}
+		if model := strings.TrimSpace(override.Model); model != "" {
+			config.Model = model
+		}
+		if endpoint := strings.TrimSpace(override.Endpoint); endpoint != "" {
+			config.Endpoint = endpoint
+		}
+	}
+
+	switch config.Provider {
+	case translationProviderGemini:
+		if config.Model == "" {
+			config.Model = defaultTranslationModel
+		}
+	case translationProviderOpenAI:
+		if config.Model == "" {
+			config.Model = defaultOpenAITranslationModel
+		}
+		if config.Endpoint == "" {
+			config.Endpoint = defaultOpenAITranslationEndpoint
+		}
+	case translationProviderDeepL:
+		if config.Endpoint == "" {
+			config.Endpoint = defaultDeepLTranslationEndpoint
+		}
+	}
+	return config
+}
+
+func (s translationSettings) translatorFor(locale string) (Translator, error) {
+	config := s.providerFor(locale)
+	return newTranslator(config, s.APIKeys[config.Provider], s.RequestsPM)
+}
+
+func (s translationSettings) hasUsableTranslator() bool {
+	for _, locale := range s.Locales {
+		if _, err := s.translatorFor(locale); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// geminiModel is the model used by Gemini-only helpers such as slug
+// generation, whichever provider handles translations.
+func (s translationSettings) geminiModel() string {
+	if normalizeTranslationProvider(s.Provider.Provider) == translationProviderGemini && strings.TrimSpace(s.Provider.Model) != "" {
+		return strings.TrimSpace(s.Provider.Model)
+	}
+	return defaultTranslationModel
+}
+
+func newTranslator(config translationProviderConfig, apiKey string, requestsPerMinute int) (Translator, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	switch config.Provider {
+	case translationProviderGemini:
+		if apiKey == "" {
+			return nil, errors.New("gemini api key is not configured")
+		}
+		return llmTranslator{
+			complete: func(prompt string, schema map[string]any) (string, error) {
+				return requestGeminiJSON(prompt, config.Model, apiKey, requestsPerMinute, schema)
+			},
+		}, nil
+	case translationProviderOpenAI:
+		// Local llama.cpp and Ollama servers speak the same API without a key.
+		return llmTranslator{
+			complete: func(prompt string, _ map[string]any) (string, error) {
+				return requestOpenAIJSON(prompt, config.Endpoint, config.Model, apiKey, requestsPerMinute)
+			},
+		}, nil
+	case translationProviderDeepL:
+		if apiKey == "" {
+			return nil, errors.New("deepl api key is not configured")
+		}
+		return deeplTranslator{
+			endpoint:          config.Endpoint,
+			apiKey:            apiKey,
+			requestsPerMinute: requestsPerMinute,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q", config.Provider)
+	}
+}
+
+func parseLocaleProviderOverrides(record *core.Record) map[string]translationProviderConfig {
+	raw, ok := record.Get("translation_locale_providers").(types.JSONRaw)
+	if !ok {
+		return nil
+	}
+	overrides, err := decodeLocaleProviderOverrides(raw)
+	if err != nil {
+		log.Printf("translation_locale_providers ignored: %v", err)
+		return nil
+	}
+	return overrides
+}
+
+// decodeLocaleProviderOverrides accepts {"zh-cn": "deepl"} as shorthand for
+// {"zh-cn": {"provider": "deepl"}}.
+func decodeLocaleProviderOverrides(raw []byte) (map[string]translationProviderConfig, error) {
+	if len(strings.TrimSpace(string(raw))) == 0 || strings.TrimSpace(string(raw)) == "null" {
+		return nil, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]translationProviderConfig, len(entries))
+	for locale, value := range entries {
+		locale = normalizeLocale(locale)
+		if locale == "" {
+			continue
+		}
+		var provider string
+		if err := json.Unmarshal(value, &provider); err == nil {
+			overrides[locale] = translationProviderConfig{Provider: provider}
+			continue
+		}
+		var config translationProviderConfig
+		if err := json.Unmarshal(value, &config); err != nil {
+			return nil, fmt.Errorf("locale %s: %w", locale, err)
+		}
+		overrides[locale] = config
+	}
+	return overrides, nil
+}
+
+func requestOpenAIJSON(prompt, endpoint, model, apiKey string, requestsPerMinute int) (string, error) {
+	payload := map[string]any{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature":     0.2,
+		"response_format": map[string]string{"type": "json_object"},
+	}
+	bodyBytes, _ := json.Marshal(payload)
+
+	headers := map[string]string{}
+	if apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+	url := strings.TrimRight(endpoint, "/") + "/chat/completions"
+	return doTranslationRequest(translationProviderOpenAI, url, headers, bodyBytes, requestsPerMinute, parseOpenAIResponseText)
+}
+
+func parseOpenAIResponseText(responseBody []byte) (string, error) {
+	var res openAIChatResponse
+	if err := json.Unmarshal(responseBody, &res); err != nil {
+		return "", err
+	}
+	if len(res.Choices) == 0 {
+		return "", errors.New("empty openai choices")
+	}
+	text := strings.TrimSpace(res.Choices[0].Message.Content)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	return extractFirstJSONObject(strings.TrimSpace(text))
+}
+
+func (t deeplTranslator) Translate(req translationRequest) (translationResult, error) {
+	texts := make([]string, 0, 2+len(req.Segments))
+	if req.Title != "" {
+		texts = append(texts, req.Title)
+	}
+	if req.Body != "" {
+		texts = append(texts, req.Body)
+	}
+	texts = append(texts, req.Segments...)
+	if len(texts) == 0 {
+		return translationResult{}, nil
+	}
+
+	payload := map[string]any{
+		"text":         texts,
+		"target_lang":  deeplLanguageCode(req.TargetLocale, true),
+		"tag_handling": "html",
+	}
+	if source := deeplLanguageCode(req.SourceLocale, false); source != "" {
+		payload["source_lang"] = source
+	}
+	bodyBytes, _ := json.Marshal(payload)
+
+	var translated []string
+	_, err := doTranslationRequest(
+		translationProviderDeepL,
+		strings.TrimRight(t.endpoint, "/")+"/translate",
+		map[string]string{"Authorization": "DeepL-Auth-Key " + t.apiKey},
+		bodyBytes,
+		t.requestsPerMinute,
+		func(responseBody []byte) (string, error) {
+			var res deeplResponse
+			if err := json.Unmarshal(responseBody, &res); err != nil {
+				return "", err
+			}
+			if len(res.Translations) != len(texts) {
+				return "", fmt.Errorf("deepl returned %d translations for %d texts", len(res.Translations), len(texts))
+			}
+			translated = translated[:0]
+			for _, item := range res.Translations {
+				translated = append(translated, strings.TrimSpace(item.Text))
+			}
+			return "", nil
+		},
+	)
+	if err != nil {
+		return translationResult{}, err
+	}
+
+	result := translationResult{}
+	if req.Title != "" {
+		result.Title = translated[0]
+		translated = translated[1:]
+	}
+	if req.Body != "" {
+		result.Body = translated[0]
+		translated = translated[1:]
+	}
+	if len(req.Segments) > 0 {
+		result.Segments = translated
+	}
+	if (req.Title != "" && result.Title == "") || (req.Body != "" && result.Body == "") {
+		return translationResult{}, errors.New("translation returned empty title/body")
+	}
+	return result, nil
+}
+
+// deeplLanguageCode maps site locales to DeepL codes. Source languages take
+// no region, and a few targets require one.
+func deeplLanguageCode(locale string, target bool) string {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return ""
+	}
+	base, region, _ := strings.Cut(locale, "-")
+	if !target {
+		return strings.ToUpper(base)
+	}
+	switch base {
+	case "zh":
+		if region == "tw" || region == "hk" || region == "hant" {
+			return "ZH-HANT"
+		}
+		return "ZH-HANS"
+	case "en":
+		if region == "gb" || region == "uk" {
+			return "EN-GB"
+		}
+		return "EN-US"
+	case "pt":
+		if region == "pt" {
+			return "PT-PT"
+		}
+		return "PT-BR"
+	}
+	return strings.ToUpper(base)
+}
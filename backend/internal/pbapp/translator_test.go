@@ -0,0 +1,162 @@
+package pbapp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeTranslator tags text with the target locale so tests can exercise the
+// pipeline without a provider.
+type fakeTranslator struct{}
+
+func (fakeTranslator) Translate(req translationRequest) (translationResult, error) {
+	prefix := "[" + normalizeLocale(req.TargetLocale) + "] "
+	result := translationResult{}
+	if req.Title != "" {
+		result.Title = prefix + req.Title
+	}
+	if req.Body != "" {
+		result.Body = prefix + req.Body
+	}
+	for _, segment := range req.Segments {
+		result.Segments = append(result.Segments, prefix+segment)
+	}
+	return result, nil
+}
+
+func TestTranslationSettingsProviderForLocale(t *testing.T) {
+	t.Parallel()
+
+	overrides, err := decodeLocaleProviderOverrides([]byte(`{"zh_CN":"deepl","ko":{"provider":"ollama","model":"qwen2.5","endpoint":"http://localhost:11434/v1"},"fr":{"model":"gemini-2.0-flash"}}`))
+	if err != nil {
+		t.Fatalf("decodeLocaleProviderOverrides returned error: %v", err)
+	}
+	settings := translationSettings{
+		Provider:        translationProviderConfig{Provider: "gemini", Model: "gemini-1.5-pro"},
+		LocaleProviders: overrides,
+	}
+
+	cases := map[string]translationProviderConfig{
+		"en":    {Provider: translationProviderGemini, Model: "gemini-1.5-pro"},
+		"zh-cn": {Provider: translationProviderDeepL, Endpoint: defaultDeepLTranslationEndpoint},
+		"ko":    {Provider: translationProviderOpenAI, Model: "qwen2.5", Endpoint: "http://localhost:11434/v1"},
+		"fr":    {Provider: translationProviderGemini, Model: "gemini-2.0-flash"},
+	}
+	for locale, want := range cases {
+		if got := settings.providerFor(locale); got != want {
+			t.Fatalf("providerFor(%q) = %#v, want %#v", locale, got, want)
+		}
+	}
+}
+
+func TestTranslateSegmentsBatchesByRuneBudget(t *testing.T) {
+	t.Parallel()
+
+	segment := "<p>" + strings.Repeat("a", maxTranslationBodyRunes/3) + "</p>"
+	segments := []string{segment, segment, segment, segment}
+	got, err := translateSegments(fakeTranslator{}, segments, "ja", "en")
+	if err != nil {
+		t.Fatalf("translateSegments returned error: %v", err)
+	}
+	if len(got) != len(segments) {
+		t.Fatalf("translateSegments returned %d segments, want %d", len(got), len(segments))
+	}
+	for i, text := range got {
+		if text != "[en] "+segments[i] {
+			t.Fatalf("segment %d = %q", i, text)
+		}
+	}
+}
+
+func TestOpenAITranslatorParsesChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none for keyless servers", got)
+		}
+		var req struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "llama3" {
+			t.Errorf("model = %q", req.Model)
+		}
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"translated_title\":\"Hello\",\"translated_body\":\"<p>World</p>\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	translator, err := newTranslator(translationProviderConfig{Provider: translationProviderOpenAI, Model: "llama3", Endpoint: server.URL + "/v1"}, "", 0)
+	if err != nil {
+		t.Fatalf("newTranslator returned error: %v", err)
+	}
+	got, err := translator.Translate(translationRequest{SourceLocale: "ja", TargetLocale: "en", Title: "こんにちは", Body: "<p>世界</p>"})
+	if err != nil {
+		t.Fatalf("Translate returned error: %v", err)
+	}
+	if got.Title != "Hello" || got.Body != "<p>World</p>" {
+		t.Fatalf("Translate = %#v", got)
+	}
+}
+
+func TestDeepLTranslatorSendsHTMLTexts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req struct {
+			Text        []string `json:"text"`
+			SourceLang  string   `json:"source_lang"`
+			TargetLang  string   `json:"target_lang"`
+			TagHandling string   `json:"tag_handling"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Text) != 2 || req.SourceLang != "JA" || req.TargetLang != "ZH-HANT" || req.TagHandling != "html" {
+			t.Errorf("request = %#v", req)
+		}
+		_, _ = io.WriteString(w, `{"translations":[{"text":"標題"},{"text":"<p>內文</p>"}]}`)
+	}))
+	defer server.Close()
+
+	translator, err := newTranslator(translationProviderConfig{Provider: translationProviderDeepL, Endpoint: server.URL}, "secret", 0)
+	if err != nil {
+		t.Fatalf("newTranslator returned error: %v", err)
+	}
+	got, err := translator.Translate(translationRequest{SourceLocale: "ja", TargetLocale: "zh-tw", Title: "タイトル", Body: "<p>本文</p>"})
+	if err != nil {
+		t.Fatalf("Translate returned error: %v", err)
+	}
+	if got.Title != "標題" || got.Body != "<p>內文</p>" {
+		t.Fatalf("Translate = %#v", got)
+	}
+}
+
+func TestNewTranslatorRequiresKeysForHostedProviders(t *testing.T) {
+	t.Parallel()
+
+	for _, provider := range []string{translationProviderGemini, translationProviderDeepL} {
+		if _, err := newTranslator(translationProviderConfig{Provider: provider}, "", 0); err == nil {
+			t.Fatalf("newTranslator(%s) without key returned nil error", provider)
+		}
+	}
+	if _, err := newTranslator(translationProviderConfig{Provider: "unknown"}, "key", 0); err == nil {
+		t.Fatal("newTranslator(unknown) returned nil error")
+	}
+}
+
+func TestNewTranslatorRejectsFakeProvider(t *testing.T) {
+	t.Parallel()
+
+	if _, err := newTranslator(translationProviderConfig{Provider: normalizeTranslationProvider("fake")}, "", 0); err == nil {
+		t.Fatal("newTranslator accepted the fake provider, which would publish placeholder text")
+	}
+}
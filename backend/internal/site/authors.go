@@ -0,0 +1,145 @@
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func authorArchivePath(slug string) string {
+	return "/authors/" + url.PathEscape(strings.TrimSpace(slug)) + "/"
+}
+
+// extractAuthorFeedRoute matches /authors/<slug>/feed.xml and
+// /authors/<slug>/feed.json.
+func extractAuthorFeedRoute(path string) (slug string, format string, ok bool) {
+	rest, found := strings.CutPrefix(path, "/authors/")
+	if !found {
+		return "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	switch parts[1] {
+	case "feed.xml":
+		return decodePathSegment(parts[0]), "xml", true
+	case "feed.json":
+		return decodePathSegment(parts[0]), "json", true
+	default:
+		return "", "", false
+	}
+}
+
+func authorAvatarURL(author *AuthorRecord) string {
+	if author == nil || strings.TrimSpace(author.Avatar) == "" {
+		return ""
+	}
+	media, ok := getMediaByIDs([]string{author.Avatar})[strings.TrimSpace(author.Avatar)]
+	if !ok {
+		return ""
+	}
+	return mediaPublicURL(media)
+}
+
+func renderAuthorByline(author *AuthorRecord) string {
+	if author == nil || strings.TrimSpace(author.Slug) == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<p class="post-author">By <a href="%s" rel="author">%s</a></p>`,
+		escapeHTML(authorArchivePath(author.Slug)),
+		escapeHTML(defaultString(strings.TrimSpace(author.DisplayName), author.Slug)),
+	)
+}
+
+func renderAuthorProfile(author *AuthorRecord) string {
+	if author == nil {
+		return ""
+	}
+	parts := make([]string, 0, 3)
+	if avatar := authorAvatarURL(author); avatar != "" {
+		parts = append(parts, fmt.Sprintf(`<img src="%s" alt="%s" class="author-avatar" width="96" height="96" loading="lazy" />`,
+			escapeHTML(avatar), escapeHTML(defaultString(strings.TrimSpace(author.DisplayName), author.Slug))))
+	}
+	if bio := strings.TrimSpace(author.Bio); bio != "" {
+		parts = append(parts, fmt.Sprintf(`<p class="author-bio">%s</p>`, escapeHTML(bio)))
+	}
+	if link := strings.TrimSpace(author.URL); link != "" {
+		parts = append(parts, fmt.Sprintf(`<p class="author-url"><a href="%s" rel="me">%s</a></p>`, escapeHTML(link), escapeHTML(link)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return `<section class="author-profile">` + strings.Join(parts, "") + `</section>`
+}
+
+func archiveHeadExtras(route archiveRoute, settings SettingsRecord) string {
+	if route.author == nil {
+		return renderArchiveBreadcrumbJSONLD(route, settings)
+	}
+	basePath := strings.TrimSuffix(authorArchivePath(route.author.Slug), "/")
+	title := escapeHTML(route.title + " - " + settings.SiteName)
+	links := make([]string, 0, 2)
+	if settings.EnableFeedXML {
+		links = append(links, fmt.Sprintf(`<link rel="alternate" href="%s/feed.xml" type="application/atom+xml" title="%s" />`, escapeHTML(basePath), title))
+	}
+	if settings.EnableFeedJSON {
+		links = append(links, fmt.Sprintf(`<link rel="alternate" href="%s/feed.json" type="application/json" title="%s" />`, escapeHTML(basePath), title))
+	}
+	return strings.Join(links, "\n    ")
+}
+
+// renderArchiveFeedLinks lists the feeds of an archive. Feeds carry source
+// posts only, so translated archives link none.
+func renderArchiveFeedLinks(route archiveRoute, settings SettingsRecord) string {
+	if route.locale != "" {
+		return ""
+	}
+	if route.author == nil {
+		return renderFeedLinkList(settings)
+	}
+	return renderAuthorProfile(route.author) + renderFeedLinkListFor(settings, authorArchivePath(route.author.Slug))
+}
+
+// authorJSONLDPerson describes the author as a schema.org Person pointing at
+// their archive.
+func authorJSONLDPerson(author *AuthorRecord, settings SettingsRecord) map[string]any {
+	if author == nil {
+		return nil
+	}
+	person := map[string]any{
+		"@type": "Person",
+		"name":  defaultString(strings.TrimSpace(author.DisplayName), author.Slug),
+	}
+	if strings.TrimSpace(author.Slug) != "" {
+		person["url"] = defaultString(buildAbsoluteSiteURL(settings, authorArchivePath(author.Slug)), authorArchivePath(author.Slug))
+	}
+	if link := strings.TrimSpace(author.URL); link != "" {
+		person["sameAs"] = []string{link}
+	}
+	if avatar := authorAvatarURL(author); avatar != "" {
+		person["image"] = defaultString(buildAbsoluteSiteURL(settings, avatar), avatar)
+	}
+	return person
+}
+
+func collectAuthorArchiveRoutes(items ...*PostRecord) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, item := range items {
+		if item == nil || strings.TrimSpace(item.Author) == "" {
+			continue
+		}
+		author := getAuthorByUserID(item.Author)
+		if author == nil || strings.TrimSpace(author.Slug) == "" {
+			continue
+		}
+		route := authorArchivePath(author.Slug)
+		if _, ok := seen[route]; ok {
+			continue
+		}
+		seen[route] = struct{}{}
+		out = append(out, route)
+	}
+	return out
+}
@@ -1,9 +1,97 @@
 package site
 
-import "net/http"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cache-Control policy per route class. Snapshot HTML revalidates on every
+// request by default, which is cheap once clients send validators.
+var (
+	htmlCacheControl    = getEnv("CACHE_CONTROL_HTML", "public, max-age=0, must-revalidate")
+	feedCacheControl    = getEnv("CACHE_CONTROL_FEED", "public, max-age=300")
+	sitemapCacheControl = getEnv("CACHE_CONTROL_SITEMAP", "public, max-age=3600")
+	ogImageCacheControl = getEnv("CACHE_CONTROL_OG_IMAGE", "public, max-age=86400")
+)
 
 func setNoStoreCacheHeaders(w http.ResponseWriter) {
 	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Pragma", "no-cache")
 	w.Header().Set("Expires", "0")
 }
+
+func contentSHA256(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func setValidatorHeaders(w http.ResponseWriter, cacheControl, etag string, modTime time.Time) {
+	if cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+}
+
+// requestNotModified reports whether the client copy is current. As in
+// net/http, If-None-Match wins over If-Modified-Since when both are sent.
+func requestNotModified(r *http.Request, etag string, modTime time.Time) bool {
+	if r == nil || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+func writeNotModified(w http.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// writeCacheableBody sends body with a content-hash ETag, or 304 when the
+// client already has it. Large text bodies are gzipped on the fly.
+func writeCacheableBody(w http.ResponseWriter, r *http.Request, cacheControl, contentType string, body []byte, modTime time.Time) {
+	encoding := dynamicResponseEncoding(r, contentType, len(body))
+	etag := encodedETag(contentETag(body), encoding)
+	if isCompressibleContentType(contentType) {
+		addVaryAcceptEncoding(w)
+	}
+	setValidatorHeaders(w, cacheControl, etag, modTime)
+	if requestNotModified(r, etag, modTime) {
+		writeNotModified(w)
+		return
+	}
+	writeEncodedBody(w, http.StatusOK, contentType, encoding, body)
+}
@@ -4,9 +4,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
-func TestFeedAndSitemapResponsesDisableCaching(t *testing.T) {
+func TestFeedAndSitemapResponsesAreCacheable(t *testing.T) {
 	t.Parallel()
 
 	settings := SettingsRecord{
@@ -17,34 +18,39 @@ func TestFeedAndSitemapResponsesDisableCaching(t *testing.T) {
 	}
 
 	tests := []struct {
-		name string
-		path string
-		run  func(*httptest.ResponseRecorder, *http.Request)
+		name         string
+		path         string
+		cacheControl string
+		run          func(*httptest.ResponseRecorder, *http.Request)
 	}{
 		{
-			name: "feed json",
-			path: "/feed.json",
+			name:         "feed json",
+			path:         "/feed.json",
+			cacheControl: feedCacheControl,
 			run: func(rec *httptest.ResponseRecorder, req *http.Request) {
 				writeJSONFeed(rec, req, settings)
 			},
 		},
 		{
-			name: "feed xml",
-			path: "/feed.xml",
+			name:         "feed xml",
+			path:         "/feed.xml",
+			cacheControl: feedCacheControl,
 			run: func(rec *httptest.ResponseRecorder, req *http.Request) {
 				writeRSSFeed(rec, req, settings)
 			},
 		},
 		{
-			name: "sitemap",
-			path: "/sitemap.xml",
+			name:         "sitemap",
+			path:         "/sitemap.xml",
+			cacheControl: sitemapCacheControl,
 			run: func(rec *httptest.ResponseRecorder, req *http.Request) {
 				writeSitemap(rec, req, settings)
 			},
 		},
 		{
-			name: "robots",
-			path: "/robots.txt",
+			name:         "robots",
+			path:         "/robots.txt",
+			cacheControl: sitemapCacheControl,
 			run: func(rec *httptest.ResponseRecorder, req *http.Request) {
 				writeRobotsTXT(rec, req, settings)
 			},
@@ -58,15 +64,49 @@ func TestFeedAndSitemapResponsesDisableCaching(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "https://example.com"+tt.path, nil)
 			rec := httptest.NewRecorder()
 			tt.run(rec, req)
-			if got := rec.Header().Get("Cache-Control"); got != "no-store" {
-				t.Fatalf("Cache-Control = %q, want %q", got, "no-store")
+			if got := rec.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Fatalf("Cache-Control = %q, want %q", got, tt.cacheControl)
 			}
-			if got := rec.Header().Get("Pragma"); got != "no-cache" {
-				t.Fatalf("Pragma = %q, want %q", got, "no-cache")
+			etag := rec.Header().Get("ETag")
+			if rec.Code != http.StatusOK || etag == "" {
+				t.Fatalf("status = %d, ETag = %q", rec.Code, etag)
 			}
-			if got := rec.Header().Get("Expires"); got != "0" {
-				t.Fatalf("Expires = %q, want %q", got, "0")
+
+			req = httptest.NewRequest(http.MethodGet, "https://example.com"+tt.path, nil)
+			req.Header.Set("If-None-Match", etag)
+			rec = httptest.NewRecorder()
+			tt.run(rec, req)
+			if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+				t.Fatalf("conditional status = %d, want %d", rec.Code, http.StatusNotModified)
 			}
 		})
 	}
 }
+
+func TestRequestNotModified(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   bool
+	}{
+		{name: "no validators", want: false},
+		{name: "matching etag", header: map[string]string{"If-None-Match": `"a", "abc"`}, want: true},
+		{name: "weak etag", header: map[string]string{"If-None-Match": `W/"abc"`}, want: true},
+		{name: "other etag", header: map[string]string{"If-None-Match": `"def"`}, want: false},
+		{name: "etag wins over date", header: map[string]string{"If-None-Match": `"def"`, "If-Modified-Since": modTime.Format(http.TimeFormat)}, want: false},
+		{name: "same date", header: map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, want: true},
+		{name: "older date", header: map[string]string{"If-Modified-Since": modTime.Add(-time.Hour).Format(http.TimeFormat)}, want: false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for key, value := range tt.header {
+			req.Header.Set(key, value)
+		}
+		if got := requestNotModified(req, `"abc"`, modTime.Add(500*time.Millisecond)); got != tt.want {
+			t.Fatalf("%s: requestNotModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -24,9 +24,16 @@ func invalidateSitemapCache() {
 	sitemapCache.mu.Unlock()
 }
 
+func invalidateSearchIndexes() {
+	siteSearchIndexes.mu.Lock()
+	siteSearchIndexes.items = map[string]*searchIndex{}
+	siteSearchIndexes.mu.Unlock()
+}
+
 func invalidateDerivedCaches() {
 	invalidateSettingsCache()
 	invalidateTaxonomyCache()
 	invalidateFeedCache()
 	invalidateSitemapCache()
+	invalidateThemeCache()
 }
@@ -0,0 +1,171 @@
+package site
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	// compressMinBytes skips bodies too small to gain from compression.
+	compressMinBytes      = 512
+	snapshotBrotliQuality = 11
+)
+
+// snapshotEncodings lists precompressed sibling formats in order of
+// preference, with the suffix each sibling file uses.
+var snapshotEncodings = []struct {
+	name   string
+	suffix string
+}{
+	{name: "br", suffix: ".br"},
+	{name: "gzip", suffix: ".gz"},
+}
+
+func isCompressibleContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/atom+xml", "application/rss+xml",
+		"application/feed+json", "application/javascript", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// acceptsEncoding reports whether Accept-Encoding allows coding, honoring
+// q=0 and the * wildcard.
+func acceptsEncoding(r *http.Request, coding string) bool {
+	if r == nil {
+		return false
+	}
+	wildcard := false
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != coding && name != "*" {
+			continue
+		}
+		accepted := true
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				accepted = err == nil && q > 0
+			}
+		}
+		if name == coding {
+			return accepted
+		}
+		wildcard = accepted
+	}
+	return wildcard
+}
+
+// encodedETag derives a distinct strong validator per content coding.
+func encodedETag(etag, encoding string) string {
+	if etag == "" || encoding == "" {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+func gzipBytes(body []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func brotliBytes(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := brotli.NewWriterLevel(&buf, snapshotBrotliQuality)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSnapshotSiblings writes .br/.gz copies of a snapshot file and returns
+// the encodings that are now available. Stale siblings from an earlier write
+// are removed when they can't be regenerated.
+func writeSnapshotSiblings(target, contentType string, body []byte) []string {
+	encodings := []string{}
+	compressible := isCompressibleContentType(contentType) && len(body) >= compressMinBytes
+	for _, encoding := range snapshotEncodings {
+		sibling := target + encoding.suffix
+		if !compressible {
+			_ = os.Remove(sibling)
+			continue
+		}
+		var encoded []byte
+		var err error
+		switch encoding.name {
+		case "br":
+			encoded, err = brotliBytes(body)
+		case "gzip":
+			encoded, err = gzipBytes(body, gzip.BestCompression)
+		}
+		if err != nil || len(encoded) >= len(body) {
+			_ = os.Remove(sibling)
+			continue
+		}
+		if err := writeFileAtomic(sibling, encoded); err != nil {
+			_ = os.Remove(sibling)
+			continue
+		}
+		encodings = append(encodings, encoding.name)
+	}
+	return encodings
+}
+
+// dynamicResponseEncoding picks the coding for a response compressed on the
+// fly. Only gzip is used; brotli is reserved for precompressed snapshots.
+func dynamicResponseEncoding(r *http.Request, contentType string, size int) string {
+	if size < compressMinBytes || !isCompressibleContentType(contentType) || !acceptsEncoding(r, "gzip") {
+		return ""
+	}
+	return "gzip"
+}
+
+// writeEncodedBody writes body with the negotiated coding. Headers such as
+// ETag must already account for the coding.
+func writeEncodedBody(w http.ResponseWriter, status int, contentType, encoding string, body []byte) {
+	if encoding == "gzip" {
+		if encoded, err := gzipBytes(body, gzip.DefaultCompression); err == nil {
+			w.Header().Set("Content-Encoding", "gzip")
+			body = encoded
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func addVaryAcceptEncoding(w http.ResponseWriter) {
+	for _, value := range w.Header().Values("Vary") {
+		if strings.Contains(strings.ToLower(value), "accept-encoding") {
+			return
+		}
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+}
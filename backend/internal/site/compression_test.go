@@ -0,0 +1,141 @@
+package site
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func gunzipBody(t *testing.T, body []byte) string {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	return string(out)
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		coding string
+		want   bool
+	}{
+		{header: "gzip, deflate, br", coding: "br", want: true},
+		{header: "gzip;q=0.8", coding: "gzip", want: true},
+		{header: "gzip;q=0, *", coding: "gzip", want: false},
+		{header: "*;q=0.5", coding: "br", want: true},
+		{header: "*;q=0", coding: "gzip", want: false},
+		{header: "", coding: "gzip", want: false},
+		{header: "deflate", coding: "gzip", want: false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.header)
+		if got := acceptsEncoding(req, tt.coding); got != tt.want {
+			t.Fatalf("acceptsEncoding(%q, %q) = %v, want %v", tt.header, tt.coding, got, tt.want)
+		}
+	}
+}
+
+func TestServePrerenderedSnapshotPrecompressed(t *testing.T) {
+	root := t.TempDir()
+	body := `{"items":"` + strings.Repeat("alleycat ", 200) + `"}`
+	if err := writeSnapshotFile(root, "/index-test.json", []byte(body)); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
+	}
+	target, err := snapshotFilePath(root, "/index-test.json")
+	if err != nil {
+		t.Fatalf("snapshotFilePath: %v", err)
+	}
+	if _, err := os.Stat(target + ".gz"); err != nil {
+		t.Fatalf("gzip sibling missing: %v", err)
+	}
+	if _, err := os.Stat(target + ".br"); err != nil {
+		t.Fatalf("brotli sibling missing: %v", err)
+	}
+
+	prevSnapshot := getPrerenderedSnapshotDir()
+	setPrerenderedSnapshotDir(root)
+	t.Cleanup(func() {
+		prerenderedSnapshot.mu.Lock()
+		prerenderedSnapshot.dir = prevSnapshot
+		prerenderedSnapshot.mu.Unlock()
+	})
+
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/index-test.json", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		if !servePrerenderedSnapshot(rec, req, "/index-test.json") {
+			t.Fatal("servePrerenderedSnapshot returned false")
+		}
+		return rec
+	}
+
+	plain := serve("")
+	if plain.Header().Get("Content-Encoding") != "" || plain.Body.String() != body {
+		t.Fatalf("identity response should be the plain file")
+	}
+	gzipped := serve("gzip")
+	if gzipped.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", gzipped.Header().Get("Content-Encoding"))
+	}
+	if got := gunzipBody(t, gzipped.Body.Bytes()); got != body {
+		t.Fatalf("gzip sibling does not match the file")
+	}
+	if gzipped.Header().Get("ETag") == plain.Header().Get("ETag") {
+		t.Fatalf("encoded responses need their own ETag")
+	}
+	if vary := gzipped.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Fatalf("Vary = %q", vary)
+	}
+	if got, want := gzipped.Header().Get("Content-Type"), snapshotContentType("/index-test.json"); got != want {
+		t.Fatalf("Content-Type = %q, want %q", got, want)
+	}
+	brotlied := serve("gzip, br")
+	if got := brotlied.Header().Get("Content-Encoding"); got != "br" {
+		t.Fatalf("Content-Encoding = %q, want br", got)
+	}
+	decoded, err := io.ReadAll(brotli.NewReader(brotlied.Body))
+	if err != nil || string(decoded) != body {
+		t.Fatalf("brotli sibling does not match the file: %v", err)
+	}
+}
+
+func TestWriteCacheableBodyCompressesOnTheFly(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(strings.Repeat("<url>https://example.com/</url>\n", 40))
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	writeCacheableBody(rec, req, sitemapCacheControl, "application/xml; charset=utf-8", body, time.Time{})
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	if got := gunzipBody(t, rec.Body.Bytes()); got != string(body) {
+		t.Fatalf("gzip body does not match")
+	}
+
+	small := httptest.NewRecorder()
+	writeCacheableBody(small, req, sitemapCacheControl, "text/plain; charset=utf-8", []byte("User-agent: *\n"), time.Time{})
+	if small.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("small bodies should not be compressed")
+	}
+}
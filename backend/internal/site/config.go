@@ -4,6 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
+
+	"alleycat-backend/internal/mediaref"
 )
 
 var (
@@ -11,12 +15,14 @@ var (
 	publicDir        = getEnv("PUBLIC_DIR", "/public")
 	defaultPublicDir = getEnv("DEFAULT_PUBLIC_DIR", "/default-public-asset")
 	staticExportDir  = getEnv("STATIC_EXPORT_DIR", "/tmp/alleycat-static")
+	themeCacheDir    = getEnv("THEME_CACHE_DIR", filepath.Join(os.TempDir(), "alleycat-themes"))
+	mediaVariantDir  = getEnv("MEDIA_VARIANT_DIR", filepath.Join(os.TempDir(), "alleycat-media-variants"))
 	activePublicDir  = resolvePublicDir()
 	listenAddr       = getEnv("LISTEN_ADDR", ":8888")
 )
 
 var headingRe = regexp.MustCompile(`(?is)<h([1-6])([^>]*)>(.*?)</h[1-6]>`)
-var mediaFileRe = regexp.MustCompile("(?i)(?:https?://[^\"'\\s)]+)?/api/files/([a-zA-Z0-9_-]+)/([a-zA-Z0-9_-]+)/([^\"'\\s)]+)")
+var mediaFileRe = mediaref.FileURLPattern
 
 func resolvePublicDir() string {
 	if hasPublicAssets(publicDir) {
@@ -50,6 +56,14 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvInt64(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(getEnv(key, "")), 10, 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
 func defaultString(value, fallback string) string {
 	if value == "" {
 		return fallback
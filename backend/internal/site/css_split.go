@@ -93,6 +93,11 @@ func resolveStylesheetPath(stylesPath string) (string, bool) {
 	if err != nil || strings.HasPrefix(rel, "..") {
 		return "", false
 	}
+	if _, err := os.Stat(absPath); err != nil {
+		if themeAsset, ok := resolveThemeAssetPath(clean); ok {
+			return themeAsset, true
+		}
+	}
 	return absPath, true
 }
 
@@ -0,0 +1,27 @@
+package site
+
+import "alleycat-backend/internal/dag"
+
+type authorProfileResolver struct{}
+
+func (authorProfileResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
+	author := getAuthorByUserID(key.ID)
+	if author == nil {
+		return dag.ResolveResult{}, nil
+	}
+	return dag.ResolveResult{
+		Value: author,
+	}, nil
+}
+
+type authorBySlugResolver struct{}
+
+func (authorBySlugResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
+	author := getAuthorBySlug(key.ID)
+	if author == nil {
+		return dag.ResolveResult{}, nil
+	}
+	return dag.ResolveResult{
+		Value: author,
+	}, nil
+}
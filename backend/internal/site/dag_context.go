@@ -0,0 +1,107 @@
+package site
+
+import (
+	"sync"
+
+	"alleycat-backend/internal/dag"
+)
+
+var siteDAG = struct {
+	mu  sync.RWMutex
+	ctx *dag.ResolveContext
+}{}
+
+var siteDAGSourceKinds = []dag.NodeKind{
+	nodeSettings,
+	nodePageByURL,
+	nodePageTranslationByURL,
+	nodePostBySlug,
+	nodeTranslationBySlug,
+	nodeMenuPages,
+	nodePostFamily,
+	nodeAdjacentPosts,
+	nodeRelatedPosts,
+	nodeHomeListing,
+	nodeArchiveListing,
+	nodeAuthorProfile,
+	nodeAuthorBySlug,
+	nodeSeries,
+}
+
+func sharedSiteDAGContext() *dag.ResolveContext {
+	siteDAG.mu.RLock()
+	ctx := siteDAG.ctx
+	siteDAG.mu.RUnlock()
+	if ctx != nil {
+		return ctx
+	}
+
+	siteDAG.mu.Lock()
+	defer siteDAG.mu.Unlock()
+	if siteDAG.ctx == nil {
+		siteDAG.ctx = newSiteDAGEngine().NewContext()
+	}
+	return siteDAG.ctx
+}
+
+func setSharedSiteDAGContext(ctx *dag.ResolveContext) {
+	siteDAG.mu.Lock()
+	siteDAG.ctx = ctx
+	siteDAG.mu.Unlock()
+}
+
+// dagSourceRefresh records the one refresh of the shared DAG's cached
+// sources made for a snapshot build context.
+type dagSourceRefresh struct {
+	once    sync.Once
+	mu      sync.Mutex
+	changed []dag.NodeKey
+	pending []dag.NodeKey
+	err     error
+}
+
+// refreshDAGSources refreshes the shared DAG's cached sources the first time
+// it is called for ctx; later calls reuse that result, so every route of one
+// revalidation resolves against the same warm graph.
+func (ctx *snapshotBuildContext) refreshDAGSources(resolveCtx *dag.ResolveContext) error {
+	ctx.dagRefresh.once.Do(func() {
+		changed, err := refreshSiteDAGSources(resolveCtx)
+		ctx.dagRefresh.mu.Lock()
+		ctx.dagRefresh.changed, ctx.dagRefresh.pending, ctx.dagRefresh.err = changed, changed, err
+		ctx.dagRefresh.mu.Unlock()
+	})
+	ctx.dagRefresh.mu.Lock()
+	defer ctx.dagRefresh.mu.Unlock()
+	return ctx.dagRefresh.err
+}
+
+// takeRefreshedDAGSources returns the sources whose value changed in the
+// refresh and clears them, so their routes are only rewritten once.
+func (ctx *snapshotBuildContext) takeRefreshedDAGSources() []dag.NodeKey {
+	ctx.dagRefresh.mu.Lock()
+	defer ctx.dagRefresh.mu.Unlock()
+	pending := ctx.dagRefresh.pending
+	ctx.dagRefresh.pending = nil
+	return pending
+}
+
+// rewindRefreshedDAGSources hands the refreshed sources out again, for a
+// revalidation that starts over on a new clone.
+func (ctx *snapshotBuildContext) rewindRefreshedDAGSources() {
+	ctx.dagRefresh.mu.Lock()
+	ctx.dagRefresh.pending = ctx.dagRefresh.changed
+	ctx.dagRefresh.mu.Unlock()
+}
+
+func refreshSiteDAGSources(ctx *dag.ResolveContext) ([]dag.NodeKey, error) {
+	cached := ctx.CachedKeys()
+	keys := make([]dag.NodeKey, 0, len(cached))
+	for _, kind := range siteDAGSourceKinds {
+		for _, key := range cached {
+			if key.Kind == kind {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return ctx.Refresh(keys)
+}
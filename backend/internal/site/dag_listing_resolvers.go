@@ -4,10 +4,10 @@ import "alleycat-backend/internal/dag"
 
 type homeListingResolver struct{}
 
-func (homeListingResolver) Resolve(_ *dag.ResolveContext, _ dag.NodeKey) (dag.ResolveResult, error) {
+func (homeListingResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
 	if snapshot := currentSnapshotBuildContext(); snapshot != nil {
 		return dag.ResolveResult{
-			Value: append([]PostRecord(nil), snapshot.publishedPosts...),
+			Value: snapshot.postsForLocale(localeScopeValue(key.Scope)),
 		}, nil
 	}
 	return dag.ResolveResult{
@@ -35,10 +35,10 @@ func (archiveListingResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (d
 
 type homeRenderInputResolver struct{}
 
-func (homeRenderInputResolver) Resolve(ctx *dag.ResolveContext, _ dag.NodeKey) (dag.ResolveResult, error) {
+func (homeRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
 	settingsDep := settingsNodeKey()
-	menuDep := menuPagesNodeKey()
-	listingDep := homeListingNodeKey()
+	menuDep := menuPagesNodeKey(localeScopeValue(key.Scope))
+	listingDep := homeListingNodeKey(localeScopeValue(key.Scope))
 
 	settingsValue, err := ctx.Resolve(settingsDep)
 	if err != nil {
@@ -71,7 +71,7 @@ type archiveRenderInputResolver struct{}
 
 func (archiveRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
 	settingsDep := settingsNodeKey()
-	menuDep := menuPagesNodeKey()
+	menuDep := menuPagesNodeKey(localeScopeValue(key.Scope))
 	listingDep := archiveListingNodeKey(key.ID)
 
 	settingsValue, err := ctx.Resolve(settingsDep)
@@ -90,6 +90,18 @@ func (archiveRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeK
 	settings, _ := settingsValue.(SettingsRecord)
 	menu, _ := menuValue.([]PageRecord)
 	listing, _ := listingValue.(archiveListing)
+	deps := []dag.NodeKey{settingsDep, menuDep, listingDep}
+
+	var author *AuthorRecord
+	if slug := parseArchiveRoute(key.ID).authorSlug; slug != "" {
+		authorDep := authorBySlugNodeKey(slug)
+		authorValue, err := ctx.Resolve(authorDep)
+		if err != nil {
+			return dag.ResolveResult{}, err
+		}
+		author, _ = authorValue.(*AuthorRecord)
+		deps = append(deps, authorDep)
+	}
 
 	return dag.ResolveResult{
 		Value: archiveRenderInputValue{
@@ -97,7 +109,8 @@ func (archiveRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeK
 			Settings: settings,
 			Menu:     menu,
 			Listing:  listing,
+			Author:   author,
 		},
-		Deps: []dag.NodeKey{settingsDep, menuDep, listingDep},
+		Deps: deps,
 	}, nil
 }
@@ -5,21 +5,25 @@ import "alleycat-backend/internal/dag"
 const (
 	nodeScopeBase = "base"
 
-	nodeSettings           dag.NodeKind = "site.settings"
-	nodeMenuPages          dag.NodeKind = "site.menu_pages"
-	nodePageByURL          dag.NodeKind = "site.page_by_url"
-	nodePostBySlug         dag.NodeKind = "site.post_by_slug"
-	nodeTranslationBySlug  dag.NodeKind = "site.translation_by_slug"
-	nodePostFamily         dag.NodeKind = "site.post_family"
-	nodeAdjacentPosts      dag.NodeKind = "site.adjacent_posts"
-	nodeRelatedPosts       dag.NodeKind = "site.related_posts"
-	nodeHomeListing        dag.NodeKind = "site.home_listing"
-	nodeArchiveListing     dag.NodeKind = "site.archive_listing"
-	nodeHomeRenderInput    dag.NodeKind = "site.home_render_input"
-	nodeArchiveRenderInput dag.NodeKind = "site.archive_render_input"
-	nodePageRenderInput    dag.NodeKind = "site.page_render_input"
-	nodePostRenderInput    dag.NodeKind = "site.post_render_input"
-	nodeRoute              dag.NodeKind = "site.route"
+	nodeSettings             dag.NodeKind = "site.settings"
+	nodeMenuPages            dag.NodeKind = "site.menu_pages"
+	nodePageByURL            dag.NodeKind = "site.page_by_url"
+	nodePageTranslationByURL dag.NodeKind = "site.page_translation_by_url"
+	nodePostBySlug           dag.NodeKind = "site.post_by_slug"
+	nodeTranslationBySlug    dag.NodeKind = "site.translation_by_slug"
+	nodePostFamily           dag.NodeKind = "site.post_family"
+	nodeAdjacentPosts        dag.NodeKind = "site.adjacent_posts"
+	nodeRelatedPosts         dag.NodeKind = "site.related_posts"
+	nodeHomeListing          dag.NodeKind = "site.home_listing"
+	nodeArchiveListing       dag.NodeKind = "site.archive_listing"
+	nodeHomeRenderInput      dag.NodeKind = "site.home_render_input"
+	nodeArchiveRenderInput   dag.NodeKind = "site.archive_render_input"
+	nodePageRenderInput      dag.NodeKind = "site.page_render_input"
+	nodePostRenderInput      dag.NodeKind = "site.post_render_input"
+	nodeAuthorProfile        dag.NodeKind = "site.author_profile"
+	nodeAuthorBySlug         dag.NodeKind = "site.author_by_slug"
+	nodeSeries               dag.NodeKind = "site.series"
+	nodeRoute                dag.NodeKind = "site.route"
 )
 
 func baseLocaleScope(locale string) string {
@@ -33,8 +37,8 @@ func settingsNodeKey() dag.NodeKey {
 	return dag.NodeKey{Kind: nodeSettings, ID: "default"}
 }
 
-func menuPagesNodeKey() dag.NodeKey {
-	return dag.NodeKey{Kind: nodeMenuPages, ID: "default"}
+func menuPagesNodeKey(locale string) dag.NodeKey {
+	return dag.NodeKey{Kind: nodeMenuPages, Scope: baseLocaleScope(locale), ID: "default"}
 }
 
 func postBySlugNodeKey(locale, slug string) dag.NodeKey {
@@ -50,10 +54,18 @@ func postBySlugNodeKey(locale, slug string) dag.NodeKey {
 	}
 }
 
-func pageByURLNodeKey(path string) dag.NodeKey {
+// pageByURLNodeKey is keyed by the source page URL; a translated page is
+// served at /<locale> plus that URL.
+func pageByURLNodeKey(locale, path string) dag.NodeKey {
+	scope := baseLocaleScope(locale)
+	kind := nodePageByURL
+	if scope != nodeScopeBase {
+		kind = nodePageTranslationByURL
+	}
 	return dag.NodeKey{
-		Kind: nodePageByURL,
-		ID:   cleanPath(path),
+		Kind:  kind,
+		Scope: scope,
+		ID:    cleanPath(path),
 	}
 }
 
@@ -88,38 +100,66 @@ func postRenderInputNodeKey(locale, slug string) dag.NodeKey {
 	}
 }
 
-func pageRenderInputNodeKey(path string) dag.NodeKey {
+func pageRenderInputNodeKey(locale, path string) dag.NodeKey {
 	return dag.NodeKey{
-		Kind: nodePageRenderInput,
-		ID:   cleanPath(path),
+		Kind:  nodePageRenderInput,
+		Scope: baseLocaleScope(locale),
+		ID:    cleanPath(path),
 	}
 }
 
-func homeRenderInputNodeKey() dag.NodeKey {
+func homeRenderInputNodeKey(locale string) dag.NodeKey {
 	return dag.NodeKey{
-		Kind: nodeHomeRenderInput,
-		ID:   "/",
+		Kind:  nodeHomeRenderInput,
+		Scope: baseLocaleScope(locale),
+		ID:    "/",
 	}
 }
 
-func homeListingNodeKey() dag.NodeKey {
+func homeListingNodeKey(locale string) dag.NodeKey {
 	return dag.NodeKey{
-		Kind: nodeHomeListing,
-		ID:   "/",
+		Kind:  nodeHomeListing,
+		Scope: baseLocaleScope(locale),
+		ID:    "/",
 	}
 }
 
+// archiveRenderInputNodeKey and archiveListingNodeKey are scoped by the
+// locale of a localized archive path such as /en/archive/go.
 func archiveRenderInputNodeKey(path string) dag.NodeKey {
 	return dag.NodeKey{
-		Kind: nodeArchiveRenderInput,
-		ID:   cleanPath(path),
+		Kind:  nodeArchiveRenderInput,
+		Scope: baseLocaleScope(parseArchiveRoute(path).locale),
+		ID:    cleanPath(path),
 	}
 }
 
 func archiveListingNodeKey(path string) dag.NodeKey {
 	return dag.NodeKey{
-		Kind: nodeArchiveListing,
-		ID:   cleanPath(path),
+		Kind:  nodeArchiveListing,
+		Scope: baseLocaleScope(parseArchiveRoute(path).locale),
+		ID:    cleanPath(path),
+	}
+}
+
+func authorProfileNodeKey(userID string) dag.NodeKey {
+	return dag.NodeKey{
+		Kind: nodeAuthorProfile,
+		ID:   userID,
+	}
+}
+
+func authorBySlugNodeKey(slug string) dag.NodeKey {
+	return dag.NodeKey{
+		Kind: nodeAuthorBySlug,
+		ID:   slug,
+	}
+}
+
+func seriesNodeKey(slug string) dag.NodeKey {
+	return dag.NodeKey{
+		Kind: nodeSeries,
+		ID:   slug,
 	}
 }
 
@@ -152,6 +192,7 @@ type postRenderInputValue struct {
 
 type pageRenderInputValue struct {
 	Page     *PageRecord
+	Locale   string
 	Settings SettingsRecord
 	Menu     []PageRecord
 }
@@ -167,6 +208,7 @@ type archiveRenderInputValue struct {
 	Settings SettingsRecord
 	Menu     []PageRecord
 	Listing  archiveListing
+	Author   *AuthorRecord
 }
 
 type routeValue struct {
@@ -9,9 +9,9 @@ import (
 type pageByURLResolver struct{}
 
 func (pageByURLResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
-	page := getPageByURL(key.ID)
+	page := getPageInLocale(key.ID, localeScopeValue(key.Scope))
 	if page == nil && !strings.HasSuffix(key.ID, "/") {
-		page = getPageByURL(key.ID + "/")
+		page = getPageInLocale(key.ID+"/", localeScopeValue(key.Scope))
 	}
 	if page == nil {
 		return dag.ResolveResult{}, nil
@@ -24,9 +24,10 @@ func (pageByURLResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.Re
 type pageRenderInputResolver struct{}
 
 func (pageRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
-	pageDep := pageByURLNodeKey(key.ID)
+	locale := localeScopeValue(key.Scope)
+	pageDep := pageByURLNodeKey(locale, key.ID)
 	settingsDep := settingsNodeKey()
-	menuDep := menuPagesNodeKey()
+	menuDep := menuPagesNodeKey(locale)
 
 	pageValue, err := ctx.Resolve(pageDep)
 	if err != nil {
@@ -48,6 +49,7 @@ func (pageRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey)
 	return dag.ResolveResult{
 		Value: pageRenderInputValue{
 			Page:     page,
+			Locale:   locale,
 			Settings: settings,
 			Menu:     menu,
 		},
@@ -15,6 +15,7 @@ func newSiteDAGEngine() *dag.Engine {
 	engine.Register(nodeSettings, settingsResolver{})
 	engine.Register(nodeMenuPages, menuPagesResolver{})
 	engine.Register(nodePageByURL, pageByURLResolver{})
+	engine.Register(nodePageTranslationByURL, pageByURLResolver{})
 	engine.Register(nodePostBySlug, postBySlugResolver{})
 	engine.Register(nodeTranslationBySlug, translationBySlugResolver{})
 	engine.Register(nodePostFamily, postFamilyResolver{})
@@ -26,6 +27,9 @@ func newSiteDAGEngine() *dag.Engine {
 	engine.Register(nodeArchiveRenderInput, archiveRenderInputResolver{})
 	engine.Register(nodePageRenderInput, pageRenderInputResolver{})
 	engine.Register(nodePostRenderInput, postRenderInputResolver{})
+	engine.Register(nodeAuthorProfile, authorProfileResolver{})
+	engine.Register(nodeAuthorBySlug, authorBySlugResolver{})
+	engine.Register(nodeSeries, seriesResolver{})
 	engine.Register(nodeRoute, routeResolver{})
 	return engine
 }
@@ -45,7 +49,8 @@ func (settingsResolver) Resolve(_ *dag.ResolveContext, _ dag.NodeKey) (dag.Resol
 
 type menuPagesResolver struct{}
 
-func (menuPagesResolver) Resolve(_ *dag.ResolveContext, _ dag.NodeKey) (dag.ResolveResult, error) {
+func (menuPagesResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
+	locale := localeScopeValue(key.Scope)
 	deps := []dag.NodeKey{}
 	if snapshot := currentSnapshotBuildContext(); snapshot != nil {
 		deps = make([]dag.NodeKey, 0, len(snapshot.publishedPages))
@@ -54,11 +59,14 @@ func (menuPagesResolver) Resolve(_ *dag.ResolveContext, _ dag.NodeKey) (dag.Reso
 			if pageURL == "" {
 				continue
 			}
-			deps = append(deps, pageByURLNodeKey(pageURL))
+			deps = append(deps, pageByURLNodeKey("", pageURL))
+			if locale != "" {
+				deps = append(deps, pageByURLNodeKey(locale, pageURL))
+			}
 		}
 	}
 	return dag.ResolveResult{
-		Value: getPagesMenu(),
+		Value: getPagesMenuInLocale(locale),
 		Deps:  deps,
 	}, nil
 }
@@ -169,7 +177,7 @@ func (postRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey)
 	locale := localeScopeValue(key.Scope)
 	postDep := postBySlugNodeKey(locale, key.ID)
 	settingsDep := settingsNodeKey()
-	menuDep := menuPagesNodeKey()
+	menuDep := menuPagesNodeKey(locale)
 	adjacentDep := adjacentPostsNodeKey(locale, key.ID)
 
 	postValue, err := ctx.Resolve(postDep)
@@ -230,6 +238,28 @@ func (postRenderInputResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey)
 		}
 	}
 
+	if authorID := postAuthorID(post, family.Source); authorID != "" {
+		authorDep := authorProfileNodeKey(authorID)
+		if _, err := ctx.Resolve(authorDep); err != nil {
+			return dag.ResolveResult{}, err
+		}
+		deps = append(deps, authorDep)
+	}
+
+	seriesSourceID := ""
+	if translation != nil {
+		seriesSourceID = strings.TrimSpace(translation.SourcePost)
+	} else if post != nil {
+		seriesSourceID = strings.TrimSpace(post.ID)
+	}
+	if series := getSeriesByPostID(seriesSourceID); series != nil && strings.TrimSpace(series.Slug) != "" {
+		seriesDep := seriesNodeKey(strings.TrimSpace(series.Slug))
+		if _, err := ctx.Resolve(seriesDep); err != nil {
+			return dag.ResolveResult{}, err
+		}
+		deps = append(deps, seriesDep)
+	}
+
 	if settings.ShowRelatedPosts {
 		relatedDep := relatedPostsNodeKey(locale, key.ID)
 		relatedValue, err := ctx.Resolve(relatedDep)
@@ -266,7 +296,7 @@ type routeResolver struct{}
 
 func (routeResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
 	if key.ID == "/" {
-		inputDep := homeRenderInputNodeKey()
+		inputDep := homeRenderInputNodeKey("")
 		inputValueRaw, err := ctx.Resolve(inputDep)
 		if err != nil {
 			return dag.ResolveResult{}, err
@@ -281,7 +311,34 @@ func (routeResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.Reso
 		}, nil
 	}
 
-	if strings.HasPrefix(key.ID, "/archive") {
+	// A first segment that only looks like a locale (/faq/) is left to the
+	// page lookup below.
+	localizedListing := false
+	if locale, rest, ok := extractLocalizedListingRoute(key.ID); ok {
+		settingsValue, err := ctx.Resolve(settingsNodeKey())
+		if err != nil {
+			return dag.ResolveResult{}, err
+		}
+		settings, _ := settingsValue.(SettingsRecord)
+		localizedListing = isEnabledTranslationLocale(settings, locale)
+		if localizedListing && rest == "/" {
+			inputDep := homeRenderInputNodeKey(locale)
+			inputValueRaw, err := ctx.Resolve(inputDep)
+			if err != nil {
+				return dag.ResolveResult{}, err
+			}
+			inputValue, _ := inputValueRaw.(homeRenderInputValue)
+			return dag.ResolveResult{
+				Value: routeValue{
+					Path: key.ID,
+					Body: []byte(renderLocalizedHome(locale, inputValue.Settings)),
+				},
+				Deps: []dag.NodeKey{inputDep},
+			}, nil
+		}
+	}
+
+	if localizedListing || strings.HasPrefix(key.ID, "/archive") || strings.HasPrefix(key.ID, "/authors/") {
 		inputDep := archiveRenderInputNodeKey(key.ID)
 		inputValueRaw, err := ctx.Resolve(inputDep)
 		if err != nil {
@@ -297,26 +354,78 @@ func (routeResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.Reso
 		}, nil
 	}
 
-	locale, slug, ok := resolvePostPath(key.ID)
-	if !ok {
-		inputDep := pageRenderInputNodeKey(key.ID)
-		inputValueRaw, err := ctx.Resolve(inputDep)
+	if seriesSlug, ok := extractSeriesSlug(key.ID); ok {
+		seriesDep := seriesNodeKey(seriesSlug)
+		settingsDep := settingsNodeKey()
+		menuDep := menuPagesNodeKey("")
+		seriesValue, err := ctx.Resolve(seriesDep)
 		if err != nil {
 			return dag.ResolveResult{}, err
 		}
-		inputValue, _ := inputValueRaw.(pageRenderInputValue)
+		settingsValue, err := ctx.Resolve(settingsDep)
+		if err != nil {
+			return dag.ResolveResult{}, err
+		}
+		if _, err := ctx.Resolve(menuDep); err != nil {
+			return dag.ResolveResult{}, err
+		}
+		series, _ := seriesValue.(*SeriesRecord)
+		settings, _ := settingsValue.(SettingsRecord)
+		html, _ := renderSeriesIndex(series, settings)
+		return dag.ResolveResult{
+			Value: routeValue{
+				Path: key.ID,
+				Body: []byte(html),
+			},
+			Deps: []dag.NodeKey{seriesDep, settingsDep, menuDep},
+		}, nil
+	}
+
+	locale, slug, ok := resolvePostPath(key.ID)
+	if !ok {
+		// /<locale>/<page-url> is a translated page when the locale is
+		// enabled and the page has a translation; otherwise it may still be
+		// a source page whose URL starts with a locale-like segment.
+		deps := make([]dag.NodeKey, 0, 3)
+		var inputValue pageRenderInputValue
+		if pageLocale, pageURL, ok := extractLocalizedPageRoute(key.ID); ok {
+			settingsDep := settingsNodeKey()
+			settingsValue, err := ctx.Resolve(settingsDep)
+			if err != nil {
+				return dag.ResolveResult{}, err
+			}
+			deps = append(deps, settingsDep)
+			if settings, _ := settingsValue.(SettingsRecord); isEnabledTranslationLocale(settings, pageLocale) {
+				inputDep := pageRenderInputNodeKey(pageLocale, pageURL)
+				inputValueRaw, err := ctx.Resolve(inputDep)
+				if err != nil {
+					return dag.ResolveResult{}, err
+				}
+				inputValue, _ = inputValueRaw.(pageRenderInputValue)
+				deps = append(deps, inputDep)
+			}
+		}
+		if inputValue.Page == nil {
+			inputDep := pageRenderInputNodeKey("", key.ID)
+			inputValueRaw, err := ctx.Resolve(inputDep)
+			if err != nil {
+				return dag.ResolveResult{}, err
+			}
+			inputValue, _ = inputValueRaw.(pageRenderInputValue)
+			deps = append(deps, inputDep)
+		}
 		if inputValue.Page == nil {
 			return dag.ResolveResult{}, fmt.Errorf("%w: %s", errUnsupportedRouteNode, key.ID)
 		}
 
-		html, ok := renderPageFromRecord(inputValue.Page, inputValue.Settings)
+		html, ok := renderPageInLocale(inputValue.Page, inputValue.Locale, inputValue.Settings)
 		if !ok {
 			return dag.ResolveResult{
 				Value: routeValue{
 					Path: key.ID,
 					Body: []byte(renderNotFound(inputValue.Settings)),
 				},
-				Deps: []dag.NodeKey{inputDep},
+				Deps: deps,
 			}, nil
 		}
 
@@ -325,7 +434,7 @@ func (routeResolver) Resolve(ctx *dag.ResolveContext, key dag.NodeKey) (dag.Reso
 				Path: key.ID,
 				Body: []byte(html),
 			},
-			Deps: []dag.NodeKey{inputDep},
+			Deps: deps,
 		}, nil
 	}
 	inputDep := postRenderInputNodeKey(locale, slug)
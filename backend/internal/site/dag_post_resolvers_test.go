@@ -372,7 +372,7 @@ func TestSiteDAGResolvesArchiveRouteWithListingDependency(t *testing.T) {
 			t.Fatalf("route value type = %T", value)
 		}
 		body := string(route.Body)
-		if !strings.Contains(body, "Archive") || !strings.Contains(body, "Hello") {
+		if !strings.Contains(body, "アーカイブ") || !strings.Contains(body, "Hello") {
 			t.Fatalf("archive route body missing expected content: %s", body)
 		}
 		deps := resolveCtx.Dependencies(routeNodeKey("/archive/"))
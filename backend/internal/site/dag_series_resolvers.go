@@ -0,0 +1,15 @@
+package site
+
+import "alleycat-backend/internal/dag"
+
+type seriesResolver struct{}
+
+func (seriesResolver) Resolve(_ *dag.ResolveContext, key dag.NodeKey) (dag.ResolveResult, error) {
+	series := getSeriesBySlug(key.ID)
+	if series == nil {
+		return dag.ResolveResult{}, nil
+	}
+	return dag.ResolveResult{
+		Value: series,
+	}, nil
+}
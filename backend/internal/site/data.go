@@ -3,6 +3,7 @@ package site
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +14,11 @@ import (
 const taxonomyCacheTTL = 60 * time.Second
 const mediaPathCacheTTL = 60 * time.Second
 
+const (
+	translationStatusApproved = "approved"
+	approvedTranslationFilter = `translation_status = "approved"`
+)
+
 type taxonomyCacheEntry struct {
 	expiresAt  time.Time
 	tags       []string
@@ -52,9 +58,25 @@ func getPostTranslations(params map[string]string) (PBList[PostTranslationRecord
 	if ctx := currentSnapshotBuildContext(); ctx != nil {
 		return ctx.queryPostTranslations(params), nil
 	}
+	if getSettings().TranslationApprovedOnly {
+		params = withApprovedTranslationFilter(params)
+	}
 	return fetchList[PostTranslationRecord](fmt.Sprintf("%s/api/collections/post_translations/records", pbURL), params)
 }
 
+func withApprovedTranslationFilter(params map[string]string) map[string]string {
+	out := make(map[string]string, len(params)+1)
+	for key, value := range params {
+		out[key] = value
+	}
+	if filter := strings.TrimSpace(out["filter"]); filter != "" {
+		out["filter"] = "(" + filter + ") && " + approvedTranslationFilter
+	} else {
+		out["filter"] = approvedTranslationFilter
+	}
+	return out
+}
+
 func getPages(params map[string]string) (PBList[PageRecord], error) {
 	if ctx := currentSnapshotBuildContext(); ctx != nil {
 		items := make([]PageRecord, 0, len(ctx.publishedPages))
@@ -68,6 +90,115 @@ func getPages(params map[string]string) (PBList[PageRecord], error) {
 	return fetchList[PageRecord](fmt.Sprintf("%s/api/collections/pages/records", pbURL), params)
 }
 
+func getPageTranslations(params map[string]string) (PBList[PageTranslationRecord], error) {
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		return ctx.queryPageTranslations(params), nil
+	}
+	if getSettings().TranslationApprovedOnly {
+		params = withApprovedTranslationFilter(params)
+	}
+	return fetchList[PageTranslationRecord](fmt.Sprintf("%s/api/collections/page_translations/records", pbURL), params)
+}
+
+func getAuthors(params map[string]string) (PBList[AuthorRecord], error) {
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		return ctx.queryAuthors(params), nil
+	}
+	return fetchList[AuthorRecord](fmt.Sprintf("%s/api/collections/authors/records", pbURL), params)
+}
+
+func getAuthorByUserID(userID string) *AuthorRecord {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil
+	}
+	return firstAuthor(fmt.Sprintf("user = \"%s\"", escapeFilter(userID)))
+}
+
+func getAuthorBySlug(slug string) *AuthorRecord {
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		return nil
+	}
+	return firstAuthor(fmt.Sprintf("slug = \"%s\"", escapeFilter(slug)))
+}
+
+func firstAuthor(filter string) *AuthorRecord {
+	data, err := getAuthors(map[string]string{
+		"page":    "1",
+		"perPage": "1",
+		"filter":  filter,
+	})
+	if err != nil || len(data.Items) == 0 {
+		return nil
+	}
+	item := data.Items[0]
+	return &item
+}
+
+func getSeries(params map[string]string) (PBList[SeriesRecord], error) {
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		return ctx.querySeries(params), nil
+	}
+	return fetchList[SeriesRecord](fmt.Sprintf("%s/api/collections/series/records", pbURL), params)
+}
+
+func getSeriesBySlug(slug string) *SeriesRecord {
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		return nil
+	}
+	return firstSeries(fmt.Sprintf("slug = \"%s\"", escapeFilter(slug)))
+}
+
+// getSeriesByPostID returns the first series, by slug, that lists the post.
+func getSeriesByPostID(postID string) *SeriesRecord {
+	postID = strings.TrimSpace(postID)
+	if postID == "" {
+		return nil
+	}
+	data, err := getSeries(map[string]string{
+		"page":    "1",
+		"perPage": "20",
+		"filter":  fmt.Sprintf("posts ~ \"%s\"", escapeFilter(postID)),
+		"sort":    "slug",
+	})
+	if err != nil {
+		return nil
+	}
+	for _, item := range data.Items {
+		if slices.Contains(item.Posts, postID) {
+			return &item
+		}
+	}
+	return nil
+}
+
+func firstSeries(filter string) *SeriesRecord {
+	data, err := getSeries(map[string]string{
+		"page":    "1",
+		"perPage": "1",
+		"filter":  filter,
+	})
+	if err != nil || len(data.Items) == 0 {
+		return nil
+	}
+	item := data.Items[0]
+	return &item
+}
+
+// postAuthorID is the cms_users id credited for a post. Translations inherit
+// the source post's author when their own field is empty.
+func postAuthorID(post *PostRecord, source *PostRecord) string {
+	if post != nil && strings.TrimSpace(post.Author) != "" {
+		return strings.TrimSpace(post.Author)
+	}
+	if source != nil {
+		return strings.TrimSpace(source.Author)
+	}
+	return ""
+}
+
 func getPagesMenu() []PageRecord {
 	if ctx := currentSnapshotBuildContext(); ctx != nil {
 		return append([]PageRecord(nil), ctx.menu...)
@@ -102,6 +233,107 @@ func getPageByURL(path string) *PageRecord {
 	return &data.Items[0]
 }
 
+// getPagesMenuInLocale is the page menu shown in locale: translated pages link
+// to their /<locale> route, the rest keep the source page and its title.
+func getPagesMenuInLocale(locale string) []PageRecord {
+	menu := getPagesMenu()
+	if normalizeLocale(locale) == "" || len(menu) == 0 {
+		return menu
+	}
+	return localizePagesMenu(menu, listPublishedPageTranslationsByLocale(locale))
+}
+
+func localizePagesMenu(menu []PageRecord, translations []PageTranslationRecord) []PageRecord {
+	bySource := make(map[string]PageTranslationRecord, len(translations))
+	for _, item := range translations {
+		bySource[strings.TrimSpace(item.SourcePage)] = item
+	}
+	out := make([]PageRecord, 0, len(menu))
+	for _, page := range menu {
+		if translation, ok := bySource[strings.TrimSpace(page.ID)]; ok {
+			page = localizePage(page, translation)
+		}
+		out = append(out, page)
+	}
+	return out
+}
+
+// localizePage is a source page as served at /<locale><url> by translation.
+// Empty translated fields fall back to the source page.
+func localizePage(source PageRecord, translation PageTranslationRecord) PageRecord {
+	page := source
+	page.URL = localePathPrefix(translation.Locale) + source.URL
+	page.Title = defaultString(strings.TrimSpace(translation.Title), source.Title)
+	if body := strings.TrimSpace(translation.Body); body != "" {
+		page.Body = translation.Body
+		page.Content = ""
+	}
+	menuTitle := defaultString(strings.TrimSpace(translation.MenuTitle), strings.TrimSpace(translation.Title))
+	page.MenuTitle = defaultString(menuTitle, source.MenuTitle)
+	page.PublishedAt = defaultString(strings.TrimSpace(translation.PublishedAt), source.PublishedAt)
+	return page
+}
+
+func getPageByID(id string) *PageRecord {
+	if strings.TrimSpace(id) == "" {
+		return nil
+	}
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		for _, item := range ctx.publishedPages {
+			if item.ID == id {
+				copy := item
+				return &copy
+			}
+		}
+		return nil
+	}
+	data, err := getPages(map[string]string{
+		"perPage": "1",
+		"filter":  fmt.Sprintf("id = \"%s\" && published = true", escapeFilter(id)),
+	})
+	if err != nil || len(data.Items) == 0 {
+		return nil
+	}
+	return &data.Items[0]
+}
+
+// getLocalizedPageByURL finds the translation in locale of the published
+// source page at path.
+func getLocalizedPageByURL(path string, locale string) *PageRecord {
+	source := getPageByURL(path)
+	if source == nil {
+		return nil
+	}
+	translation := getPageTranslation(source.ID, locale)
+	if translation == nil {
+		return nil
+	}
+	page := localizePage(*source, *translation)
+	return &page
+}
+
+func getPageInLocale(path string, locale string) *PageRecord {
+	if locale == "" {
+		return getPageByURL(path)
+	}
+	return getLocalizedPageByURL(path, locale)
+}
+
+func getPageTranslation(sourcePageID string, locale string) *PageTranslationRecord {
+	locale = normalizeLocale(locale)
+	if strings.TrimSpace(sourcePageID) == "" || locale == "" {
+		return nil
+	}
+	data, err := getPageTranslations(map[string]string{
+		"perPage": "1",
+		"filter":  fmt.Sprintf("source_page = \"%s\" && locale = \"%s\" && published = true", escapeFilter(sourcePageID), escapeFilter(locale)),
+	})
+	if err != nil || len(data.Items) == 0 {
+		return nil
+	}
+	return &data.Items[0]
+}
+
 func getPostBySlugInLocale(slug string, locale string) *PostRecord {
 	if ctx := currentSnapshotBuildContext(); ctx != nil {
 		if normalizeLocale(locale) == "" {
@@ -280,11 +512,18 @@ func filterTranslationsByEnabledLocales(items []PostTranslationRecord, settings
 		if !isEnabledTranslationLocale(settings, item.Locale) {
 			continue
 		}
+		if settings.TranslationApprovedOnly && !isApprovedTranslation(item) {
+			continue
+		}
 		filtered = append(filtered, item)
 	}
 	return filtered
 }
 
+func isApprovedTranslation(item PostTranslationRecord) bool {
+	return strings.EqualFold(strings.TrimSpace(item.TranslationStatus), translationStatusApproved)
+}
+
 func getEnabledTranslationsBySource(sourcePostID string, settings SettingsRecord) []PostTranslationRecord {
 	return filterTranslationsByEnabledLocales(getPostTranslationsBySource(sourcePostID), settings)
 }
@@ -546,6 +785,64 @@ func getMediaByPath(path string) *MediaRecord {
 	return &item
 }
 
+// getMediaByPaths resolves several upload paths at once, sharing the
+// per-path cache with getMediaByPath.
+func getMediaByPaths(paths []string) map[string]MediaRecord {
+	result := make(map[string]MediaRecord, len(paths))
+	now := time.Now()
+	missing := make([]string, 0, len(paths))
+	mediaPathCache.mu.RLock()
+	for _, path := range paths {
+		if _, ok := result[path]; ok || slices.Contains(missing, path) {
+			continue
+		}
+		cached, ok := mediaPathCache.items[path]
+		if !ok || !now.Before(cached.expiresAt) {
+			missing = append(missing, path)
+			continue
+		}
+		if cached.found {
+			result[path] = cached.media
+		}
+	}
+	mediaPathCache.mu.RUnlock()
+
+	const chunkSize = 40
+	for start := 0; start < len(missing); start += chunkSize {
+		chunk := missing[start:min(start+chunkSize, len(missing))]
+		parts := make([]string, 0, len(chunk))
+		for _, path := range chunk {
+			parts = append(parts, fmt.Sprintf("path = \"%s\"", escapeFilter(path)))
+		}
+		data, err := fetchList[MediaRecord](fmt.Sprintf("%s/api/collections/media/records", pbURL), map[string]string{
+			"page":    "1",
+			"perPage": strconv.Itoa(len(chunk)),
+			"filter":  strings.Join(parts, " || "),
+		})
+		if err != nil {
+			continue
+		}
+		found := make(map[string]MediaRecord, len(data.Items))
+		for _, item := range data.Items {
+			found[item.Path] = item
+		}
+		mediaPathCache.mu.Lock()
+		for _, path := range chunk {
+			item, ok := found[path]
+			mediaPathCache.items[path] = mediaPathCacheEntry{
+				expiresAt: now.Add(mediaPathCacheTTL),
+				found:     ok,
+				media:     item,
+			}
+			if ok {
+				result[path] = item
+			}
+		}
+		mediaPathCache.mu.Unlock()
+	}
+	return result
+}
+
 func collectTags() []string {
 	tags, _ := collectTaxonomies()
 	return tags
@@ -601,6 +898,14 @@ func listPublishedPagesStrict() ([]PageRecord, error) {
 	return listPublishedRecords(getPages, "published = true", 200, true, "-published_at", "-date")
 }
 
+func listAuthorsStrict() ([]AuthorRecord, error) {
+	return listPublishedRecords(getAuthors, `slug != ""`, 200, true, "slug")
+}
+
+func listSeriesStrict() ([]SeriesRecord, error) {
+	return listPublishedRecords(getSeries, `slug != ""`, 200, true, "slug")
+}
+
 func listPublishedTranslationsByLocale(locale string) []PostTranslationRecord {
 	items, _ := listPublishedTranslationsByLocaleStrict(locale)
 	return items
@@ -616,6 +921,21 @@ func listPublishedTranslationsByLocaleStrict(locale string) ([]PostTranslationRe
 	)
 }
 
+func listPublishedPageTranslationsByLocale(locale string) []PageTranslationRecord {
+	items, _ := listPublishedPageTranslationsByLocaleStrict(locale)
+	return items
+}
+
+func listPublishedPageTranslationsByLocaleStrict(locale string) ([]PageTranslationRecord, error) {
+	return listPublishedRecords(
+		getPageTranslations,
+		fmt.Sprintf("published = true && locale = \"%s\"", escapeFilter(locale)),
+		200,
+		true,
+		"-published_at",
+	)
+}
+
 func listPublishedRecords[T any](fetch pagedListFetcher[T], filter string, perPage int, strict bool, sorts ...string) ([]T, error) {
 	if perPage <= 0 {
 		perPage = 200
@@ -697,6 +1017,7 @@ func translationToPost(item PostTranslationRecord) PostRecord {
 		Excerpt:     item.Excerpt,
 		Tags:        item.Tags,
 		Category:    item.Category,
+		Author:      item.Author,
 		Published:   item.Published,
 		PublishedAt: item.PublishedAt,
 		Date:        item.PublishedAt,
@@ -723,7 +1044,19 @@ func (ctx *snapshotBuildContext) queryPosts(params map[string]string) PBList[Pos
 	} else if tag := extractFilterValue(filter, `tags ~ "`); tag != "" {
 		items = append([]PostRecord(nil), ctx.postsByTag[tag]...)
 	}
-	if query := extractSearchQuery(filter); query != "" {
+	if query := extractSearchQuery(filter); query != "" && ctx.searchIndex != nil {
+		allowed := make(map[string]struct{}, len(items))
+		for _, item := range items {
+			allowed[searchDocumentID(item)] = struct{}{}
+		}
+		filtered := make([]PostRecord, 0, len(items))
+		for _, hit := range ctx.searchIndex.search(query) {
+			if _, ok := allowed[searchDocumentID(hit.Post)]; ok {
+				filtered = append(filtered, hit.Post)
+			}
+		}
+		items = filtered
+	} else if query != "" {
 		filtered := make([]PostRecord, 0, len(items))
 		for _, item := range items {
 			if snapshotPostMatchesQuery(item, query) {
@@ -741,6 +1074,15 @@ func (ctx *snapshotBuildContext) queryPosts(params map[string]string) PBList[Pos
 		}
 		items = filtered
 	}
+	if author := extractFilterValue(filter, `author = "`); author != "" {
+		filtered := make([]PostRecord, 0, len(items))
+		for _, item := range items {
+			if strings.TrimSpace(item.Author) == author {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
 	if slug := extractFilterValue(filter, `slug = "`); slug != "" {
 		filtered := make([]PostRecord, 0, len(items))
 		for _, item := range items {
@@ -787,6 +1129,102 @@ func (ctx *snapshotBuildContext) queryPostTranslations(params map[string]string)
 	return paginateSnapshotTranslations(filtered, params["page"], params["perPage"])
 }
 
+func (ctx *snapshotBuildContext) queryPageTranslations(params map[string]string) PBList[PageTranslationRecord] {
+	filter := strings.TrimSpace(params["filter"])
+	locale := normalizeLocale(extractFilterValue(filter, `locale = "`))
+	sourceID := extractFilterValue(filter, `source_page = "`)
+
+	items := make([]PageTranslationRecord, 0)
+	for _, item := range ctx.pageTranslationByKey {
+		if locale != "" && normalizeLocale(item.Locale) != locale {
+			continue
+		}
+		if sourceID != "" && strings.TrimSpace(item.SourcePage) != sourceID {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Locale != items[j].Locale {
+			return items[i].Locale < items[j].Locale
+		}
+		return items[i].SourcePage < items[j].SourcePage
+	})
+
+	page, perPage := snapshotPageParams(params["page"], params["perPage"])
+	start, end := snapshotPageBounds(page, perPage, len(items))
+	out := PBList[PageTranslationRecord]{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: len(items),
+		TotalPages: snapshotTotalPages(len(items), perPage),
+	}
+	if start < end {
+		out.Items = append([]PageTranslationRecord(nil), items[start:end]...)
+	}
+	return out
+}
+
+func (ctx *snapshotBuildContext) queryAuthors(params map[string]string) PBList[AuthorRecord] {
+	filter := strings.TrimSpace(params["filter"])
+	var items []AuthorRecord
+	switch {
+	case extractFilterValue(filter, `user = "`) != "":
+		if item, ok := ctx.authorByUser[extractFilterValue(filter, `user = "`)]; ok {
+			items = []AuthorRecord{item}
+		}
+	case extractFilterValue(filter, `slug = "`) != "":
+		if item, ok := ctx.authorBySlug[extractFilterValue(filter, `slug = "`)]; ok {
+			items = []AuthorRecord{item}
+		}
+	default:
+		items = append([]AuthorRecord(nil), ctx.authors...)
+	}
+
+	page, perPage := snapshotPageParams(params["page"], params["perPage"])
+	start, end := snapshotPageBounds(page, perPage, len(items))
+	out := PBList[AuthorRecord]{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: len(items),
+		TotalPages: snapshotTotalPages(len(items), perPage),
+	}
+	if start < end {
+		out.Items = append([]AuthorRecord(nil), items[start:end]...)
+	}
+	return out
+}
+
+func (ctx *snapshotBuildContext) querySeries(params map[string]string) PBList[SeriesRecord] {
+	filter := strings.TrimSpace(params["filter"])
+	var items []SeriesRecord
+	switch {
+	case extractFilterValue(filter, `slug = "`) != "":
+		if item, ok := ctx.seriesBySlug[extractFilterValue(filter, `slug = "`)]; ok {
+			items = []SeriesRecord{item}
+		}
+	case extractFilterValue(filter, `posts ~ "`) != "":
+		if item, ok := ctx.seriesByPost[extractFilterValue(filter, `posts ~ "`)]; ok {
+			items = []SeriesRecord{item}
+		}
+	default:
+		items = append([]SeriesRecord(nil), ctx.series...)
+	}
+
+	page, perPage := snapshotPageParams(params["page"], params["perPage"])
+	start, end := snapshotPageBounds(page, perPage, len(items))
+	out := PBList[SeriesRecord]{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: len(items),
+		TotalPages: snapshotTotalPages(len(items), perPage),
+	}
+	if start < end {
+		out.Items = append([]SeriesRecord(nil), items[start:end]...)
+	}
+	return out
+}
+
 func paginateSnapshotPosts(items []PostRecord, pageValue, perPageValue string) PBList[PostRecord] {
 	page, perPage := snapshotPageParams(pageValue, perPageValue)
 	totalItems := len(items)
@@ -1,6 +1,7 @@
 package site
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -15,3 +16,72 @@ func TestPostPublishedTimeParsesPocketBaseTimestamp(t *testing.T) {
 		t.Fatalf("postPublishedTime() = %v, want %v", got, want)
 	}
 }
+
+func TestFilterTranslationsByEnabledLocalesApprovedOnly(t *testing.T) {
+	t.Parallel()
+
+	items := []PostTranslationRecord{
+		{ID: "a", Locale: "en", TranslationStatus: "approved"},
+		{ID: "b", Locale: "en", TranslationStatus: "in_review"},
+		{ID: "c", Locale: "fr", TranslationStatus: "approved"},
+		{ID: "d", Locale: "en"},
+	}
+	ids := func(items []PostTranslationRecord) []string {
+		out := []string{}
+		for _, item := range items {
+			out = append(out, item.ID)
+		}
+		return out
+	}
+
+	settings := SettingsRecord{TranslationLocales: "en"}
+	if got := ids(filterTranslationsByEnabledLocales(items, settings)); !reflect.DeepEqual(got, []string{"a", "b", "d"}) {
+		t.Fatalf("filtered = %v, want every enabled locale", got)
+	}
+	settings.TranslationApprovedOnly = true
+	if got := ids(filterTranslationsByEnabledLocales(items, settings)); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("filtered = %v, want approved only", got)
+	}
+}
+
+func TestWithApprovedTranslationFilter(t *testing.T) {
+	t.Parallel()
+
+	params := map[string]string{"filter": `published = true || slug = "x"`}
+	got := withApprovedTranslationFilter(params)
+	if want := `(published = true || slug = "x") && translation_status = "approved"`; got["filter"] != want {
+		t.Fatalf("filter = %q, want %q", got["filter"], want)
+	}
+	if params["filter"] != `published = true || slug = "x"` {
+		t.Fatal("input params were modified")
+	}
+}
+
+func TestLocalizePagesMenuFallsBackToSourcePage(t *testing.T) {
+	t.Parallel()
+
+	menu := []PageRecord{
+		{ID: "about", Title: "概要", URL: "/about/", MenuTitle: "私たち"},
+		{ID: "contact", Title: "お問い合わせ", URL: "/contact/"},
+		{ID: "faq", Title: "よくある質問", URL: "/faq/", MenuTitle: "FAQ"},
+	}
+	translations := []PageTranslationRecord{
+		{SourcePage: "about", Locale: "en", Title: "About", MenuTitle: "About us"},
+		{SourcePage: "faq", Locale: "en", Title: "Questions"},
+	}
+
+	links := newNavView(localizePagesMenu(menu, translations), SettingsRecord{}).Links
+	want := []navLink{
+		{URL: "/en/about/", Label: "About us"},
+		{URL: "/contact/", Label: "お問い合わせ"},
+		{URL: "/en/faq/", Label: "Questions"},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("links = %#v", links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Fatalf("links[%d] = %#v, want %#v", i, links[i], want[i])
+		}
+	}
+}
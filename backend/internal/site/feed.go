@@ -24,47 +24,87 @@ var feedItemsCache = struct {
 	items: map[string]feedCacheEntry{},
 }
 
+// feedScope selects which posts a feed carries and where it lives. The
+// site-wide feed has an empty basePath.
+type feedScope struct {
+	title    string
+	basePath string
+	filter   string
+}
+
+func siteFeedScope(settings SettingsRecord) feedScope {
+	return feedScope{title: settings.SiteName, filter: "published = true"}
+}
+
+func authorFeedScope(settings SettingsRecord, author AuthorRecord) feedScope {
+	route := parseArchiveRoute(authorArchivePath(author.Slug)).withAuthor(author)
+	return feedScope{
+		title:    route.title + " - " + settings.SiteName,
+		basePath: route.basePath,
+		filter:   route.filter,
+	}
+}
+
 func writeJSONFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecord) {
-	items := fetchFeedItems(settings)
+	writeScopedJSONFeed(w, r, settings, siteFeedScope(settings))
+}
+
+func writeRSSFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecord) {
+	writeScopedRSSFeed(w, r, settings, siteFeedScope(settings))
+}
+
+func writeScopedJSONFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecord, scope feedScope) {
+	items := fetchFeedItems(settings, scope)
 	baseURL := normalizeSiteBaseURL(settings.SiteURL)
 	feed := map[string]any{
 		"version": "https://jsonfeed.org/version/1",
-		"title":   settings.SiteName,
+		"title":   scope.title,
 		"home_page_url": func() string {
 			if baseURL == "" {
 				return ""
 			}
-			return baseURL + "/"
+			return baseURL + scope.basePath + "/"
 		}(),
 		"feed_url": func() string {
 			if baseURL == "" {
 				return ""
 			}
-			return baseURL + "/feed.json"
+			return baseURL + scope.basePath + "/feed.json"
 		}(),
 		"items": items,
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	setNoStoreCacheHeaders(w)
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(feed)
+	body, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeCacheableBody(w, r, feedCacheControl, "application/json; charset=utf-8", append(body, '\n'), latestFeedItemTime(items))
 }
 
-func writeRSSFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecord) {
-	items := fetchFeedItems(settings)
+func writeScopedRSSFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecord, scope feedScope) {
+	items := fetchFeedItems(settings, scope)
 	baseURL := normalizeSiteBaseURL(settings.SiteURL)
-	updated := time.Now().UTC().Format(time.RFC3339)
+	// <updated> follows the newest entry so the body, and its ETag, only
+	// change when the feed does.
+	modTime := latestFeedItemTime(items)
+	updated := modTime.Format(time.RFC3339)
+	if modTime.IsZero() {
+		updated = time.Now().UTC().Format(time.RFC3339)
+	}
 	builder := strings.Builder{}
 	builder.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
 	builder.WriteString("<feed xmlns=\"http://www.w3.org/2005/Atom\">\n")
-	builder.WriteString(fmt.Sprintf("  <title>%s</title>\n", escapeHTML(settings.SiteName)))
+	builder.WriteString(fmt.Sprintf("  <title>%s</title>\n", escapeHTML(scope.title)))
 	if baseURL != "" {
-		builder.WriteString(fmt.Sprintf("  <link href=\"%s/\"/>\n", baseURL))
-		builder.WriteString(fmt.Sprintf("  <link href=\"%s/feed.xml\" rel=\"self\"/>\n", baseURL))
+		builder.WriteString(fmt.Sprintf("  <link href=\"%s%s/\"/>\n", baseURL, escapeHTML(scope.basePath)))
+		builder.WriteString(fmt.Sprintf("  <link href=\"%s%s/feed.xml\" rel=\"self\"/>\n", baseURL, escapeHTML(scope.basePath)))
 	}
 	builder.WriteString(fmt.Sprintf("  <updated>%s</updated>\n", updated))
-	builder.WriteString(fmt.Sprintf("  <id>%s</id>\n", escapeHTML(defaultString(baseURL, settings.SiteName))))
+	feedID := settings.SiteName + scope.basePath
+	if baseURL != "" {
+		feedID = baseURL + scope.basePath
+	}
+	builder.WriteString(fmt.Sprintf("  <id>%s</id>\n", escapeHTML(feedID)))
 	for _, item := range items {
 		builder.WriteString("  <entry>\n")
 		builder.WriteString(fmt.Sprintf("    <title>%s</title>\n", escapeHTML(item.Title)))
@@ -80,9 +120,7 @@ func writeRSSFeed(w http.ResponseWriter, r *http.Request, settings SettingsRecor
 	}
 	builder.WriteString("</feed>")
 
-	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
-	setNoStoreCacheHeaders(w)
-	_, _ = w.Write([]byte(builder.String()))
+	writeCacheableBody(w, r, feedCacheControl, "application/atom+xml; charset=utf-8", []byte(builder.String()), modTime)
 }
 
 type feedItem struct {
@@ -93,19 +131,20 @@ type feedItem struct {
 	Summary string `json:"summary"`
 }
 
-func fetchFeedItems(settings SettingsRecord) []feedItem {
+func fetchFeedItems(settings SettingsRecord, scope feedScope) []feedItem {
 	key := fmt.Sprintf(
-		"limit=%d|excerpt=%d|site=%s",
+		"limit=%d|excerpt=%d|site=%s|filter=%s",
 		settings.FeedItemsLimit,
 		settings.ExcerptLength,
 		normalizeSiteBaseURL(settings.SiteURL),
+		scope.filter,
 	)
 	now := time.Now()
 	feedItemsCache.mu.RLock()
 	cached, ok := feedItemsCache.items[key]
 	feedItemsCache.mu.RUnlock()
 	if ok && now.Before(cached.expiresAt) {
-		return append([]feedItem(nil), cached.items...)
+		return append(make([]feedItem, 0, len(cached.items)), cached.items...)
 	}
 
 	limit := settings.FeedItemsLimit
@@ -115,14 +154,14 @@ func fetchFeedItems(settings SettingsRecord) []feedItem {
 	posts, err := getPosts(map[string]string{
 		"page":    "1",
 		"perPage": fmt.Sprintf("%d", limit),
-		"filter":  "published = true",
+		"filter":  scope.filter,
 		"sort":    "-published_at",
 	})
 	if err != nil {
 		posts, _ = getPosts(map[string]string{
 			"page":    "1",
 			"perPage": fmt.Sprintf("%d", limit),
-			"filter":  "published = true",
+			"filter":  scope.filter,
 			"sort":    "-date",
 		})
 	}
@@ -187,6 +226,16 @@ func feedItemTime(item feedItem) time.Time {
 	return time.Time{}
 }
 
+func latestFeedItemTime(items []feedItem) time.Time {
+	latest := time.Time{}
+	for _, item := range items {
+		if published := feedItemTime(item); published.After(latest) {
+			latest = published
+		}
+	}
+	return latest
+}
+
 func sortFeedItems(items []feedItem) {
 	sort.SliceStable(items, func(i, j int) bool {
 		a := feedItemTime(items[i])
@@ -0,0 +1,590 @@
+package site
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Code blocks are highlighted at render time with highlight.js class names so
+// bodies that were highlighted in the CMS editor and bodies highlighted here
+// share the same theme CSS.
+
+var codeBlockRe = regexp.MustCompile(`(?is)<pre([^>]*)>\s*<code([^>]*)>(.*?)</code>\s*</pre>`)
+var codeLanguageRe = regexp.MustCompile(`(?i)\blang(?:uage)?-([a-z0-9_+#-]+)`)
+var classAttrRe = regexp.MustCompile(`(?i)\bclass\s*=\s*"([^"]*)"`)
+
+type highlightLanguage struct {
+	keywords        map[string]struct{}
+	literals        map[string]struct{}
+	builtins        map[string]struct{}
+	lineComments    []string
+	blockComment    [2]string
+	quotes          string
+	multilineQuotes string
+	tripleQuotes    bool
+	caseInsensitive bool
+	hashMeta        bool
+	atMeta          bool
+	dollarVariables bool
+	bangMacros      bool
+	keyAttrs        bool
+	cssProperties   bool
+	dashedWords     bool
+	markup          bool
+}
+
+func wordSet(words string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, word := range strings.Fields(words) {
+		out[word] = struct{}{}
+	}
+	return out
+}
+
+var (
+	cKeywords  = "auto break case char const continue default do double else enum extern float for goto if inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while"
+	jsKeywords = "as async await break case catch class const continue debugger default delete do else export extends finally for from function if import in instanceof let new of return static super switch this throw try typeof var void while with yield"
+)
+
+var highlightLanguages = map[string]*highlightLanguage{
+	"go": {
+		keywords:        wordSet("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var"),
+		literals:        wordSet("true false nil iota"),
+		builtins:        wordSet("append cap clear close complex copy delete imag len make max min new panic print println real recover any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr"),
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+	},
+	"javascript": {
+		keywords:        wordSet(jsKeywords),
+		literals:        wordSet("true false null undefined NaN Infinity"),
+		builtins:        wordSet("console window document globalThis Array Boolean Date Error JSON Map Math Number Object Promise RegExp Set String Symbol"),
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+	},
+	"typescript": {
+		keywords:        wordSet(jsKeywords + " abstract declare enum implements interface keyof namespace private protected public readonly satisfies type"),
+		literals:        wordSet("true false null undefined NaN Infinity"),
+		builtins:        wordSet("console window document globalThis Array Boolean Date Error JSON Map Math Number Object Promise RegExp Set String Symbol any boolean never number string unknown void Record Partial Readonly"),
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+	},
+	"python": {
+		keywords:     wordSet("and as assert async await break case class continue def del elif else except finally for from global if import in is lambda match nonlocal not or pass raise return try while with yield"),
+		literals:     wordSet("True False None"),
+		builtins:     wordSet("self cls print len range dict list set tuple str int float bool bytes open super isinstance enumerate zip map filter sorted reversed sum min max any all"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		tripleQuotes: true,
+		atMeta:       true,
+	},
+	"bash": {
+		keywords:        wordSet("if then else elif fi for while until do done case esac in function return local export select"),
+		literals:        wordSet("true false"),
+		builtins:        wordSet("alias cd echo eval exec exit printf pwd read set shift source test trap unset"),
+		lineComments:    []string{"#"},
+		quotes:          "\"'",
+		multilineQuotes: "\"'",
+		dollarVariables: true,
+		dashedWords:     true,
+	},
+	"rust": {
+		keywords:     wordSet("as async await break const continue crate dyn else enum extern fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while"),
+		literals:     wordSet("true false None Some Ok Err"),
+		builtins:     wordSet("bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize Box Option Result String Vec"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+		bangMacros:   true,
+	},
+	"c": {
+		keywords:     wordSet(cKeywords),
+		literals:     wordSet("true false NULL"),
+		builtins:     wordSet("printf scanf malloc free size_t FILE"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		hashMeta:     true,
+	},
+	"cpp": {
+		keywords:     wordSet(cKeywords + " bool catch class constexpr delete explicit friend mutable namespace new noexcept operator override private protected public template this throw try typename using virtual"),
+		literals:     wordSet("true false NULL nullptr"),
+		builtins:     wordSet("std string vector map cout cin endl size_t"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		hashMeta:     true,
+	},
+	"java": {
+		keywords:     wordSet("abstract assert boolean break byte case catch char class const continue default do double else enum extends final finally float for if implements import instanceof int interface long native new package private protected public record return short static super switch synchronized this throw throws transient try var void volatile while"),
+		literals:     wordSet("true false null"),
+		builtins:     wordSet("String System Object Integer List Map Optional"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		atMeta:       true,
+	},
+	"csharp": {
+		keywords:     wordSet("abstract as async await base bool break byte case catch char class const continue decimal default delegate do double else enum event explicit extern finally fixed float for foreach if implicit in int interface internal is lock long namespace new object operator out override params private protected public readonly ref return sealed short sizeof static string struct switch this throw try typeof uint ulong unsafe using var virtual void volatile while"),
+		literals:     wordSet("true false null"),
+		builtins:     wordSet("Console String Task List Dictionary"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		hashMeta:     true,
+	},
+	"sql": {
+		keywords:        wordSet("add all alter and as asc begin by case commit create default delete desc distinct drop else end exists foreign from group having if in index inner insert into is join key left like limit not null offset on or order outer primary references returning right rollback select set table then union unique update values when where with"),
+		literals:        wordSet("true false null"),
+		builtins:        wordSet("avg coalesce count max min now sum"),
+		lineComments:    []string{"--"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'",
+		caseInsensitive: true,
+	},
+	"json": {
+		literals: wordSet("true false null"),
+		quotes:   "\"",
+		keyAttrs: true,
+	},
+	"yaml": {
+		literals:     wordSet("true false null yes no on off"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keyAttrs:     true,
+		dashedWords:  true,
+	},
+	"css": {
+		keywords:      wordSet("important"),
+		lineComments:  []string{"//"},
+		blockComment:  [2]string{"/*", "*/"},
+		quotes:        "\"'",
+		atMeta:        true,
+		cssProperties: true,
+		dashedWords:   true,
+	},
+	"xml": {
+		markup: true,
+	},
+}
+
+var highlightLanguageAliases = map[string]string{
+	"golang":     "go",
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"mjs":        "javascript",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"py":         "python",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"console":    "bash",
+	"rs":         "rust",
+	"h":          "c",
+	"c++":        "cpp",
+	"hpp":        "cpp",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"kotlin":     "java",
+	"postgresql": "sql",
+	"mysql":      "sql",
+	"sqlite":     "sql",
+	"yml":        "yaml",
+	"scss":       "css",
+	"less":       "css",
+	"html":       "xml",
+	"svg":        "xml",
+	"vue":        "xml",
+}
+
+func lookupHighlightLanguage(name string) *highlightLanguage {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := highlightLanguageAliases[name]; ok {
+		name = alias
+	}
+	return highlightLanguages[name]
+}
+
+// highlightCodeBlocks rewrites <pre><code class="language-x"> blocks into
+// highlighted markup. Blocks that already carry markup, such as bodies the
+// editor highlighted on save, are only tagged with the hljs class.
+func highlightCodeBlocks(body string) string {
+	if !strings.Contains(body, "<pre") {
+		return body
+	}
+	return codeBlockRe.ReplaceAllStringFunc(body, func(match string) string {
+		parts := codeBlockRe.FindStringSubmatch(match)
+		if len(parts) < 4 {
+			return match
+		}
+		preAttrs, codeAttrs, content := parts[1], parts[2], parts[3]
+		if strings.Contains(content, "<") {
+			return "<pre" + preAttrs + "><code" + withClass(codeAttrs, "hljs") + ">" + content + "</code></pre>"
+		}
+		languageName := ""
+		if found := codeLanguageRe.FindStringSubmatch(codeAttrs); len(found) > 1 {
+			languageName = found[1]
+		} else if found := codeLanguageRe.FindStringSubmatch(preAttrs); len(found) > 1 {
+			languageName = found[1]
+		}
+		language := lookupHighlightLanguage(languageName)
+		if language == nil {
+			return "<pre" + preAttrs + "><code" + withClass(codeAttrs, "hljs") + ">" + content + "</code></pre>"
+		}
+		return "<pre" + preAttrs + "><code" + withClass(codeAttrs, "hljs") + ">" + highlightSource(html.UnescapeString(content), language) + "</code></pre>"
+	})
+}
+
+func withClass(attrs string, class string) string {
+	if found := classAttrRe.FindStringSubmatchIndex(attrs); found != nil {
+		existing := attrs[found[2]:found[3]]
+		for _, item := range strings.Fields(existing) {
+			if item == class {
+				return attrs
+			}
+		}
+		return attrs[:found[3]] + " " + class + attrs[found[3]:]
+	}
+	return attrs + ` class="` + class + `"`
+}
+
+func highlightSource(source string, language *highlightLanguage) string {
+	if language.markup {
+		return highlightMarkup(source)
+	}
+	h := &codeHighlighter{src: source, lang: language}
+	h.run()
+	return h.out.String()
+}
+
+type codeHighlighter struct {
+	src        string
+	lang       *highlightLanguage
+	out        strings.Builder
+	braceDepth int
+}
+
+func (h *codeHighlighter) emit(class, text string) {
+	if text == "" {
+		return
+	}
+	if class == "" {
+		h.out.WriteString(escapeHTML(text))
+		return
+	}
+	h.out.WriteString(`<span class="`)
+	h.out.WriteString(class)
+	h.out.WriteString(`">`)
+	h.out.WriteString(escapeHTML(text))
+	h.out.WriteString(`</span>`)
+}
+
+func (h *codeHighlighter) run() {
+	src := h.src
+	lang := h.lang
+	i := 0
+	for i < len(src) {
+		rest := src[i:]
+		if end, ok := h.matchComment(rest); ok {
+			h.emit("hljs-comment", rest[:end])
+			i += end
+			continue
+		}
+		ch := src[i]
+		switch {
+		case lang.hashMeta && ch == '#' && atLineStart(src, i):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			h.emit("hljs-meta", rest[:end])
+			i += end
+		case strings.IndexByte(lang.quotes, ch) >= 0:
+			end := h.scanString(rest)
+			class := "hljs-string"
+			if lang.keyAttrs && nextNonSpace(src, i+end) == ':' {
+				class = "hljs-attr"
+			}
+			h.emit(class, rest[:end])
+			i += end
+		case lang.dollarVariables && ch == '$' && i+1 < len(src) && (isWordStart(src[i+1]) || src[i+1] == '{'):
+			end := 1
+			if src[i+1] == '{' {
+				if closing := strings.IndexByte(rest, '}'); closing > 0 {
+					end = closing + 1
+				}
+			} else {
+				for end < len(rest) && isWordChar(rest[end]) {
+					end++
+				}
+			}
+			h.emit("hljs-variable", rest[:end])
+			i += end
+		case lang.atMeta && ch == '@' && i+1 < len(src) && isWordStart(src[i+1]):
+			end := 1
+			for end < len(rest) && (isWordChar(rest[end]) || rest[end] == '.' || (lang.dashedWords && rest[end] == '-')) {
+				end++
+			}
+			class := "hljs-meta"
+			if lang.cssProperties {
+				class = "hljs-keyword"
+			}
+			h.emit(class, rest[:end])
+			i += end
+		case isDigit(ch) && (i == 0 || !isWordChar(src[i-1])):
+			end := 1
+			for end < len(rest) && (isWordChar(rest[end]) || (rest[end] == '.' && end+1 < len(rest) && isDigit(rest[end+1]))) {
+				end++
+			}
+			h.emit("hljs-number", rest[:end])
+			i += end
+		case lang.cssProperties && ch == '#' && i+1 < len(src) && isHexDigit(src[i+1]) && h.braceDepth > 0:
+			end := 1
+			for end < len(rest) && isHexDigit(rest[end]) {
+				end++
+			}
+			h.emit("hljs-number", rest[:end])
+			i += end
+		case isWordStart(ch):
+			end := 1
+			for end < len(rest) && (isWordChar(rest[end]) || (lang.dashedWords && rest[end] == '-' && end+1 < len(rest) && isWordChar(rest[end+1]))) {
+				end++
+			}
+			h.emit(h.classifyWord(rest[:end], src, i+end), rest[:end])
+			i += end
+		default:
+			if ch == '{' {
+				h.braceDepth++
+			} else if ch == '}' && h.braceDepth > 0 {
+				h.braceDepth--
+			}
+			end := 1
+			for end < len(rest) && !h.startsToken(rest, end) {
+				if rest[end] == '{' {
+					h.braceDepth++
+				} else if rest[end] == '}' && h.braceDepth > 0 {
+					h.braceDepth--
+				}
+				end++
+			}
+			h.emit("", rest[:end])
+			i += end
+		}
+	}
+}
+
+// startsToken reports whether a plain run must stop at rest[pos] because a
+// highlighted token may begin there.
+func (h *codeHighlighter) startsToken(rest string, pos int) bool {
+	ch := rest[pos]
+	if isWordChar(ch) || strings.IndexByte(h.lang.quotes, ch) >= 0 {
+		return true
+	}
+	switch ch {
+	case '#', '@', '$', '-', '/':
+		return true
+	}
+	return false
+}
+
+func (h *codeHighlighter) matchComment(rest string) (int, bool) {
+	for _, prefix := range h.lang.lineComments {
+		if !strings.HasPrefix(rest, prefix) {
+			continue
+		}
+		if prefix == "#" && h.lang.dollarVariables && len(rest) > 1 && rest[1] == '{' {
+			continue
+		}
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+		return end, true
+	}
+	if open := h.lang.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+		end := strings.Index(rest[len(open):], h.lang.blockComment[1])
+		if end < 0 {
+			return len(rest), true
+		}
+		return len(open) + end + len(h.lang.blockComment[1]), true
+	}
+	return 0, false
+}
+
+func (h *codeHighlighter) scanString(rest string) int {
+	quote := rest[0]
+	if h.lang.tripleQuotes && len(rest) >= 3 && rest[1] == quote && rest[2] == quote {
+		delimiter := rest[:3]
+		if end := strings.Index(rest[3:], delimiter); end >= 0 {
+			return 3 + end + 3
+		}
+		return len(rest)
+	}
+	multiline := strings.IndexByte(h.lang.multilineQuotes, quote) >= 0
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			if quote != '`' || h.lang.dollarVariables {
+				i++
+			}
+		case '\n':
+			if !multiline {
+				return i
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(rest)
+}
+
+func (h *codeHighlighter) classifyWord(word string, src string, end int) string {
+	lang := h.lang
+	key := word
+	if lang.caseInsensitive {
+		key = strings.ToLower(word)
+	}
+	next := nextNonSpace(src, end)
+	if lang.keyAttrs && next == ':' && startsLineKey(src, end-len(word)) {
+		return "hljs-attr"
+	}
+	if lang.cssProperties {
+		if h.braceDepth > 0 && next == ':' {
+			return "hljs-attribute"
+		}
+		if _, ok := lang.keywords[key]; ok {
+			return "hljs-keyword"
+		}
+		return ""
+	}
+	if _, ok := lang.keywords[key]; ok {
+		return "hljs-keyword"
+	}
+	if _, ok := lang.literals[key]; ok {
+		return "hljs-literal"
+	}
+	if lang.bangMacros && end < len(src) && src[end] == '!' {
+		return "hljs-built_in"
+	}
+	if _, ok := lang.builtins[key]; ok {
+		return "hljs-built_in"
+	}
+	if next == '(' {
+		return "hljs-title function_"
+	}
+	return ""
+}
+
+var markupTagRe = regexp.MustCompile(`(?s)^<(/?)([A-Za-z][\w:.-]*)((?:\s+[^\s=/>]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+))?)*)\s*(/?)>`)
+var markupAttrRe = regexp.MustCompile(`(?s)(\s+)([^\s=/>]+)(?:(\s*=\s*)("[^"]*"|'[^']*'|[^\s>]+))?`)
+
+func highlightMarkup(source string) string {
+	out := strings.Builder{}
+	i := 0
+	for i < len(source) {
+		rest := source[i:]
+		if strings.HasPrefix(rest, "<!--") {
+			end := strings.Index(rest, "-->")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 3
+			}
+			out.WriteString(`<span class="hljs-comment">` + escapeHTML(rest[:end]) + `</span>`)
+			i += end
+			continue
+		}
+		if strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?") {
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			out.WriteString(`<span class="hljs-meta">` + escapeHTML(rest[:end+1]) + `</span>`)
+			i += end + 1
+			continue
+		}
+		if tag := markupTagRe.FindStringSubmatch(rest); tag != nil {
+			out.WriteString(`<span class="hljs-tag">&lt;` + tag[1] + `<span class="hljs-name">` + escapeHTML(tag[2]) + `</span>`)
+			for _, attr := range markupAttrRe.FindAllStringSubmatch(tag[3], -1) {
+				out.WriteString(attr[1] + `<span class="hljs-attr">` + escapeHTML(attr[2]) + `</span>`)
+				if attr[4] != "" {
+					out.WriteString(escapeHTML(attr[3]) + `<span class="hljs-string">` + escapeHTML(attr[4]) + `</span>`)
+				}
+			}
+			out.WriteString(tag[4] + `&gt;</span>`)
+			i += len(tag[0])
+			continue
+		}
+		end := strings.IndexByte(rest[1:], '<')
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end++
+		}
+		out.WriteString(escapeHTML(rest[:end]))
+		i += end
+	}
+	return out.String()
+}
+
+func atLineStart(src string, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch src[j] {
+		case ' ', '\t':
+			continue
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// startsLineKey reports whether only indentation or a list marker precedes
+// src[i] on its line, as with YAML mapping keys.
+func startsLineKey(src string, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch src[j] {
+		case ' ', '\t', '-':
+			continue
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func nextNonSpace(src string, i int) byte {
+	for ; i < len(src); i++ {
+		if src[i] != ' ' && src[i] != '\t' {
+			return src[i]
+		}
+	}
+	return 0
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+func isWordStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch >= 0x80
+}
+
+func isWordChar(ch byte) bool {
+	return isWordStart(ch) || isDigit(ch)
+}
@@ -0,0 +1,89 @@
+package site
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		mustContain []string
+		mustNot     []string
+	}{
+		{
+			name: "go",
+			body: `<pre><code class="language-go">// greet
+func main() {
+	fmt.Println(&quot;hi &lt;3&quot;, 42, nil)
+}</code></pre>`,
+			mustContain: []string{
+				`<code class="language-go hljs">`,
+				`<span class="hljs-comment">// greet</span>`,
+				`<span class="hljs-keyword">func</span> <span class="hljs-title function_">main</span>()`,
+				`<span class="hljs-string">&#34;hi &lt;3&#34;</span>`,
+				`<span class="hljs-number">42</span>`,
+				`<span class="hljs-literal">nil</span>`,
+			},
+		},
+		{
+			name: "json keys",
+			body: `<pre><code class="language-json">{&quot;draft&quot;: false}</code></pre>`,
+			mustContain: []string{
+				`<span class="hljs-attr">&#34;draft&#34;</span>`,
+				`<span class="hljs-literal">false</span>`,
+			},
+		},
+		{
+			name: "markup",
+			body: `<pre><code class="language-html">&lt;a href=&quot;/x&quot;&gt;x&lt;/a&gt;</code></pre>`,
+			mustContain: []string{
+				`<span class="hljs-tag">&lt;<span class="hljs-name">a</span> <span class="hljs-attr">href</span>=<span class="hljs-string">&#34;/x&#34;</span>&gt;</span>`,
+			},
+		},
+		{
+			name:        "already highlighted",
+			body:        `<pre><code class="language-go"><span class="hljs-keyword">func</span></code></pre>`,
+			mustContain: []string{`<code class="language-go hljs"><span class="hljs-keyword">func</span></code>`},
+		},
+		{
+			name:        "unknown language",
+			body:        `<pre><code class="language-brainfuck">++&gt;</code></pre>`,
+			mustContain: []string{`<code class="language-brainfuck hljs">++&gt;</code>`},
+			mustNot:     []string{`<span`},
+		},
+		{
+			name:        "inline code untouched",
+			body:        `<p><code>func</code></p>`,
+			mustContain: []string{`<p><code>func</code></p>`},
+			mustNot:     []string{`hljs`},
+		},
+	}
+
+	for _, tt := range tests {
+		got := highlightCodeBlocks(tt.body)
+		for _, want := range tt.mustContain {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%s: missing %q in %s", tt.name, want, got)
+			}
+		}
+		for _, unwanted := range tt.mustNot {
+			if strings.Contains(got, unwanted) {
+				t.Fatalf("%s: unexpected %q in %s", tt.name, unwanted, got)
+			}
+		}
+	}
+}
+
+func TestHighlightSourceSQLIsCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	got := highlightSource("SELECT id FROM posts -- all", lookupHighlightLanguage("postgresql"))
+	want := `<span class="hljs-keyword">SELECT</span> id <span class="hljs-keyword">FROM</span> posts <span class="hljs-comment">-- all</span>`
+	if got != want {
+		t.Fatalf("highlightSource() = %s, want %s", got, want)
+	}
+}
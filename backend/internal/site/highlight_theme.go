@@ -0,0 +1,139 @@
+package site
+
+import (
+	"fmt"
+	"strings"
+)
+
+type highlightPalette struct {
+	background string
+	foreground string
+	comment    string
+	keyword    string
+	str        string
+	number     string
+	literal    string
+	title      string
+	builtIn    string
+	attr       string
+	name       string
+	meta       string
+	variable   string
+}
+
+// highlightPalettes mirror the highlight.js themes the site used to load from
+// cdnjs, so existing HighlightTheme settings keep their look.
+var highlightPalettes = map[string]highlightPalette{
+	"github-dark": {
+		background: "#0d1117", foreground: "#c9d1d9", comment: "#8b949e", keyword: "#ff7b72", str: "#a5d6ff",
+		number: "#79c0ff", literal: "#79c0ff", title: "#d2a8ff", builtIn: "#ffa657", attr: "#79c0ff",
+		name: "#7ee787", meta: "#79c0ff", variable: "#ffa657",
+	},
+	"github": {
+		background: "#ffffff", foreground: "#24292e", comment: "#6a737d", keyword: "#d73a49", str: "#032f62",
+		number: "#005cc5", literal: "#005cc5", title: "#6f42c1", builtIn: "#e36209", attr: "#005cc5",
+		name: "#22863a", meta: "#005cc5", variable: "#e36209",
+	},
+	"atom-one-dark": {
+		background: "#282c34", foreground: "#abb2bf", comment: "#5c6370", keyword: "#c678dd", str: "#98c379",
+		number: "#d19a66", literal: "#56b6c2", title: "#61aeee", builtIn: "#e6c07b", attr: "#d19a66",
+		name: "#e06c75", meta: "#61aeee", variable: "#e06c75",
+	},
+	"atom-one-light": {
+		background: "#fafafa", foreground: "#383a42", comment: "#a0a1a7", keyword: "#a626a4", str: "#50a14f",
+		number: "#986801", literal: "#0184bb", title: "#4078f2", builtIn: "#c18401", attr: "#986801",
+		name: "#e45649", meta: "#4078f2", variable: "#986801",
+	},
+	"tokyo-night-dark": {
+		background: "#1a1b26", foreground: "#9aa5ce", comment: "#565f89", keyword: "#bb9af7", str: "#9ece6a",
+		number: "#ff9e64", literal: "#ff9e64", title: "#7aa2f7", builtIn: "#e0af68", attr: "#73daca",
+		name: "#f7768e", meta: "#2ac3de", variable: "#f7768e",
+	},
+	"tokyo-night-light": {
+		background: "#d5d6db", foreground: "#565a6e", comment: "#9699a3", keyword: "#5a4a78", str: "#485e30",
+		number: "#965027", literal: "#965027", title: "#34548a", builtIn: "#8f5e15", attr: "#33635c",
+		name: "#8c4351", meta: "#0f4b6e", variable: "#8c4351",
+	},
+	"solarized-dark": {
+		background: "#002b36", foreground: "#839496", comment: "#586e75", keyword: "#859900", str: "#2aa198",
+		number: "#2aa198", literal: "#2aa198", title: "#268bd2", builtIn: "#dc322f", attr: "#b58900",
+		name: "#268bd2", meta: "#cb4b16", variable: "#b58900",
+	},
+	"solarized-light": {
+		background: "#fdf6e3", foreground: "#657b83", comment: "#93a1a1", keyword: "#859900", str: "#2aa198",
+		number: "#2aa198", literal: "#2aa198", title: "#268bd2", builtIn: "#dc322f", attr: "#b58900",
+		name: "#268bd2", meta: "#cb4b16", variable: "#b58900",
+	},
+	"monokai": {
+		background: "#272822", foreground: "#dddddd", comment: "#75715e", keyword: "#f92672", str: "#e6db74",
+		number: "#ae81ff", literal: "#ae81ff", title: "#a6e22e", builtIn: "#e6db74", attr: "#a6e22e",
+		name: "#f92672", meta: "#75715e", variable: "#fd971f",
+	},
+	"dracula": {
+		background: "#282a36", foreground: "#f8f8f2", comment: "#6272a4", keyword: "#ff79c6", str: "#f1fa8c",
+		number: "#bd93f9", literal: "#bd93f9", title: "#50fa7b", builtIn: "#8be9fd", attr: "#50fa7b",
+		name: "#ff79c6", meta: "#ff79c6", variable: "#ffb86c",
+	},
+	"vs": {
+		background: "#ffffff", foreground: "#000000", comment: "#008000", keyword: "#0000ff", str: "#a31515",
+		number: "#000000", literal: "#0000ff", title: "#000000", builtIn: "#2b91af", attr: "#ff0000",
+		name: "#a31515", meta: "#2b91af", variable: "#000000",
+	},
+}
+
+// highlightThemes returns the dark and light palette names for the configured
+// HighlightTheme.
+func highlightThemes(settings SettingsRecord) (string, string) {
+	switch strings.ToLower(strings.TrimSpace(settings.HighlightTheme)) {
+	case "github":
+		return "github-dark", "github"
+	case "atom-one-dark", "atom-one-light":
+		return "atom-one-dark", "atom-one-light"
+	case "tokyo-night-dark", "tokyo-night-light":
+		return "tokyo-night-dark", "tokyo-night-light"
+	case "solarized-dark", "solarized-light":
+		return "solarized-dark", "solarized-light"
+	case "monokai":
+		return "monokai", "github"
+	case "dracula":
+		return "dracula", "github"
+	case "vs":
+		return "github-dark", "vs"
+	default:
+		return "github-dark", "github"
+	}
+}
+
+func highlightPaletteVars(palette highlightPalette) string {
+	return fmt.Sprintf("--hl-bg:%s;--hl-fg:%s;--hl-comment:%s;--hl-keyword:%s;--hl-string:%s;--hl-number:%s;--hl-literal:%s;--hl-title:%s;--hl-built-in:%s;--hl-attr:%s;--hl-name:%s;--hl-meta:%s;--hl-variable:%s;",
+		palette.background, palette.foreground, palette.comment, palette.keyword, palette.str, palette.number, palette.literal,
+		palette.title, palette.builtIn, palette.attr, palette.name, palette.meta, palette.variable)
+}
+
+// highlightThemeCSS renders the palette for both color schemes. The dark
+// palette is the default, prefers-color-scheme picks light without script, and
+// the navbar toggle's data-theme attribute overrides both.
+func highlightThemeCSS(settings SettingsRecord) string {
+	darkName, lightName := highlightThemes(settings)
+	dark := highlightPaletteVars(highlightPalettes[darkName])
+	light := highlightPaletteVars(highlightPalettes[lightName])
+	return `<style id="hljs-theme" data-theme-dark="` + darkName + `" data-theme-light="` + lightName + `">
+    :root{` + dark + `}
+    @media (prefers-color-scheme: light){:root{` + light + `}}
+    :root[data-theme="dark"]{` + dark + `}
+    :root[data-theme="light"]{` + light + `}
+    pre code.hljs{display:block;overflow-x:auto;padding:1em}
+    .hljs{color:var(--hl-fg);background:var(--hl-bg)}
+    .hljs-comment,.hljs-quote{color:var(--hl-comment);font-style:italic}
+    .hljs-keyword,.hljs-selector-tag,.hljs-type{color:var(--hl-keyword)}
+    .hljs-string,.hljs-regexp{color:var(--hl-string)}
+    .hljs-number{color:var(--hl-number)}
+    .hljs-literal{color:var(--hl-literal)}
+    .hljs-title,.hljs-section{color:var(--hl-title)}
+    .hljs-built_in{color:var(--hl-built-in)}
+    .hljs-attr,.hljs-attribute,.hljs-property{color:var(--hl-attr)}
+    .hljs-name,.hljs-tag{color:var(--hl-name)}
+    .hljs-meta{color:var(--hl-meta)}
+    .hljs-variable,.hljs-template-variable,.hljs-params{color:var(--hl-variable)}
+    </style>`
+}
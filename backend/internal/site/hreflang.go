@@ -0,0 +1,131 @@
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// languageAlternate is one member of a post's translation cluster: the source
+// post or one of its published translations.
+type languageAlternate struct {
+	locale string
+	path   string
+}
+
+// postLanguageAlternates lists the source post first, then its translations
+// in enabled locales, one per locale. The visible language links, the head
+// hreflang tags and the sitemaps all use this list so they can't disagree.
+func postLanguageAlternates(sourceLocale string, sourcePost *PostRecord, translations []PostTranslationRecord, settings SettingsRecord) []languageAlternate {
+	items := make([]languageAlternate, 0, len(translations)+1)
+	seen := map[string]struct{}{}
+
+	if sourcePost != nil && strings.TrimSpace(sourcePost.Slug) != "" && sourceLocale != "" {
+		items = append(items, languageAlternate{
+			locale: sourceLocale,
+			path:   "/posts/" + url.PathEscape(sourcePost.Slug) + "/",
+		})
+		seen[sourceLocale] = struct{}{}
+	}
+
+	for _, t := range translations {
+		locale := normalizeLocale(t.Locale)
+		if locale == "" {
+			continue
+		}
+		if _, ok := seen[locale]; ok {
+			continue
+		}
+		if settings.TranslationApprovedOnly && !isApprovedTranslation(t) {
+			continue
+		}
+		slug := strings.TrimSpace(t.Slug)
+		if slug == "" {
+			continue
+		}
+		items = append(items, languageAlternate{
+			locale: locale,
+			path:   "/" + url.PathEscape(locale) + "/posts/" + url.PathEscape(slug) + "/",
+		})
+		seen[locale] = struct{}{}
+	}
+	return items
+}
+
+// hreflangEntries adds x-default, pointing at the source post, to a cluster.
+// A post without translations has no cluster.
+func hreflangEntries(sourceLocale string, alternates []languageAlternate) []languageAlternate {
+	if len(alternates) <= 1 {
+		return nil
+	}
+	entries := append([]languageAlternate(nil), alternates...)
+	if alternates[0].locale == sourceLocale {
+		entries = append(entries, languageAlternate{locale: "x-default", path: alternates[0].path})
+	}
+	return entries
+}
+
+func renderHreflangLinks(sourceLocale string, alternates []languageAlternate, settings SettingsRecord) string {
+	entries := hreflangEntries(sourceLocale, alternates)
+	links := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		links = append(links, fmt.Sprintf(`<link rel="alternate" hreflang="%s" href="%s" />`, escapeHTML(entry.locale), escapeHTML(defaultString(buildAbsoluteSiteURL(settings, entry.path), entry.path))))
+	}
+	return strings.Join(links, "\n    ")
+}
+
+// sitemapPostAlternates maps every post and translation URL in the sitemaps
+// to the xhtml:link alternates of its cluster.
+func sitemapPostAlternates(baseURL string, settings SettingsRecord, posts []PostRecord, translations []PostTranslationRecord) map[string][]sitemapAlternate {
+	sourceLocale := siteSourceLocale(settings)
+	bySource := map[string][]PostTranslationRecord{}
+	for _, item := range filterTranslationsByEnabledLocales(translations, settings) {
+		sourceID := strings.TrimSpace(item.SourcePost)
+		bySource[sourceID] = append(bySource[sourceID], item)
+	}
+
+	out := map[string][]sitemapAlternate{}
+	for i := range posts {
+		post := &posts[i]
+		items := bySource[post.ID]
+		if len(items) == 0 {
+			continue
+		}
+		alternates := postLanguageAlternates(sourceLocale, post, items, settings)
+		entries := hreflangEntries(sourceLocale, alternates)
+		if len(entries) == 0 {
+			continue
+		}
+		links := make([]sitemapAlternate, 0, len(entries))
+		for _, entry := range entries {
+			links = append(links, sitemapAlternate{Rel: "alternate", Hreflang: entry.locale, Href: baseURL + entry.path})
+		}
+		for _, alternate := range alternates {
+			out[baseURL+alternate.path] = links
+		}
+	}
+	return out
+}
+
+// listSitemapTranslations returns the published translations in every
+// enabled locale.
+func listSitemapTranslations(settings SettingsRecord) []PostTranslationRecord {
+	locales := parseTranslationLocales(settings.TranslationLocales)
+	results := make([][]PostTranslationRecord, len(locales))
+	var wg sync.WaitGroup
+	for i, locale := range locales {
+		wg.Add(1)
+		go func(i int, locale string) {
+			defer wg.Done()
+			results[i] = listPublishedTranslationsByLocale(locale)
+		}(i, locale)
+	}
+	wg.Wait()
+
+	out := []PostTranslationRecord{}
+	for _, items := range results {
+		out = append(out, items...)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package site
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHreflangLinks(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{SiteURL: "https://example.com", TranslationLocales: "en,zh-cn", TranslationApprovedOnly: true}
+	source := &PostRecord{ID: "p1", Slug: "konnichiwa"}
+	translations := []PostTranslationRecord{
+		{SourcePost: "p1", Locale: "en", Slug: "hello", TranslationStatus: translationStatusApproved},
+		{SourcePost: "p1", Locale: "zh-cn", Slug: "nihao", TranslationStatus: "draft"},
+	}
+
+	got := renderHreflangLinks("ja", postLanguageAlternates("ja", source, translations, settings), settings)
+	for _, token := range []string{
+		`<link rel="alternate" hreflang="ja" href="https://example.com/posts/konnichiwa" />`,
+		`<link rel="alternate" hreflang="en" href="https://example.com/en/posts/hello" />`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/posts/konnichiwa" />`,
+	} {
+		if !strings.Contains(got, token) {
+			t.Fatalf("hreflang links missing %q: %s", token, got)
+		}
+	}
+	if strings.Contains(got, "zh-cn") {
+		t.Fatalf("unapproved translation should be left out: %s", got)
+	}
+
+	if got := renderHreflangLinks("ja", postLanguageAlternates("ja", source, nil, settings), settings); got != "" {
+		t.Fatalf("a post without translations has no cluster: %s", got)
+	}
+}
+
+func TestSitemapPostAlternates(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{TranslationSourceLocale: "ja", TranslationLocales: "en"}
+	posts := []PostRecord{{ID: "p1", Slug: "konnichiwa"}, {ID: "p2", Slug: "alone"}}
+	translations := []PostTranslationRecord{
+		{SourcePost: "p1", Locale: "en", Slug: "hello"},
+		{SourcePost: "p2", Locale: "fr", Slug: "seul"},
+	}
+
+	alternates := sitemapPostAlternates("https://example.com", settings, posts, translations)
+	source := alternates["https://example.com/posts/konnichiwa/"]
+	translated := alternates["https://example.com/en/posts/hello/"]
+	if len(source) != 3 || len(translated) != 3 {
+		t.Fatalf("cluster sizes = %d, %d", len(source), len(translated))
+	}
+	if _, ok := alternates["https://example.com/posts/alone/"]; ok {
+		t.Fatalf("posts whose translations are all in disabled locales have no cluster")
+	}
+
+	body, err := buildSitemapXML([]sitemapURL{{Loc: "https://example.com/posts/konnichiwa/", Alternates: source}})
+	if err != nil {
+		t.Fatalf("buildSitemapXML: %v", err)
+	}
+	for _, token := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		`<xhtml:link rel="alternate" hreflang="en" href="https://example.com/en/posts/hello/"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/posts/konnichiwa/"></xhtml:link>`,
+	} {
+		if !strings.Contains(string(body), token) {
+			t.Fatalf("sitemap missing %q: %s", token, body)
+		}
+	}
+
+	plain, _ := buildSitemapXML([]sitemapURL{{Loc: "https://example.com/"}})
+	if strings.Contains(string(plain), "xhtml") {
+		t.Fatalf("sitemaps without alternates should not declare xhtml: %s", plain)
+	}
+}
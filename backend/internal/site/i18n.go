@@ -0,0 +1,501 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// uiText is the message catalog of one locale. Theme templates read it as
+// {{.Text.key}}; Go code uses get and format so a missing key falls back to
+// English instead of rendering empty.
+type uiText map[string]string
+
+// defaultUIText holds the shipped catalogs. date_format is a Go time layout.
+// Settings can override any key per locale through ui_strings.
+var defaultUIText = map[string]uiText{
+	"en": {
+		"archive":            "Archive",
+		"archive_tag":        "tag: %s",
+		"archive_category":   "category: %s",
+		"tags":               "tags:",
+		"categories":         "categories:",
+		"previous":           "Previous",
+		"next":               "Next",
+		"older_post":         "← Older post",
+		"newer_post":         "Newer post →",
+		"previous_part":      "← Previous part",
+		"next_part":          "Next part →",
+		"series_part":        "part %d of %d",
+		"related_posts":      "Related Posts",
+		"table_of_contents":  "Table of contents",
+		"language":           "language:",
+		"search_placeholder": "Search posts...",
+		"search_label":       "Search posts",
+		"search_submit":      "Search",
+		"search_clear":       "Clear",
+		"search_no_results":  "No posts found.",
+		"read_time":          "%d min",
+		"read_more":          "Read →",
+		"more_posts_before":  "More posts can be found in ",
+		"more_posts_link":    "the archive",
+		"more_posts_after":   ".",
+		"not_found_title":    "Not Found",
+		"not_found_body":     "Page not found.",
+		"date_format":        "Jan 2, 2006",
+	},
+	"ja": {
+		"archive":            "アーカイブ",
+		"archive_tag":        "タグ: %s",
+		"archive_category":   "カテゴリー: %s",
+		"tags":               "タグ:",
+		"categories":         "カテゴリー:",
+		"previous":           "前へ",
+		"next":               "次へ",
+		"older_post":         "← 前の記事",
+		"newer_post":         "次の記事 →",
+		"previous_part":      "← 前のパート",
+		"next_part":          "次のパート →",
+		"series_part":        "全%[2]d回中%[1]d回目",
+		"related_posts":      "関連記事",
+		"table_of_contents":  "目次",
+		"language":           "言語:",
+		"search_placeholder": "記事を検索...",
+		"search_label":       "記事を検索",
+		"search_submit":      "検索",
+		"search_clear":       "クリア",
+		"search_no_results":  "記事が見つかりませんでした。",
+		"read_time":          "%d分",
+		"read_more":          "続きを読む →",
+		"more_posts_before":  "過去の記事は",
+		"more_posts_link":    "アーカイブ",
+		"more_posts_after":   "にあります。",
+		"not_found_title":    "ページが見つかりません",
+		"not_found_body":     "お探しのページは見つかりませんでした。",
+		"date_format":        "2006年1月2日",
+	},
+	"zh": {
+		"archive":            "归档",
+		"archive_tag":        "标签: %s",
+		"archive_category":   "分类: %s",
+		"tags":               "标签:",
+		"categories":         "分类:",
+		"previous":           "上一页",
+		"next":               "下一页",
+		"older_post":         "← 上一篇",
+		"newer_post":         "下一篇 →",
+		"previous_part":      "← 上一部分",
+		"next_part":          "下一部分 →",
+		"series_part":        "第%d部分，共%d部分",
+		"related_posts":      "相关文章",
+		"table_of_contents":  "目录",
+		"language":           "语言:",
+		"search_placeholder": "搜索文章...",
+		"search_label":       "搜索文章",
+		"search_submit":      "搜索",
+		"search_clear":       "清除",
+		"search_no_results":  "没有找到文章。",
+		"read_time":          "%d 分钟",
+		"read_more":          "阅读全文 →",
+		"more_posts_before":  "更多文章请查看",
+		"more_posts_link":    "归档",
+		"more_posts_after":   "。",
+		"not_found_title":    "未找到页面",
+		"not_found_body":     "找不到该页面。",
+		"date_format":        "2006年1月2日",
+	},
+	"zh-tw": {
+		"archive":            "封存",
+		"archive_tag":        "標籤: %s",
+		"archive_category":   "分類: %s",
+		"tags":               "標籤:",
+		"categories":         "分類:",
+		"previous":           "上一頁",
+		"next":               "下一頁",
+		"older_post":         "← 上一篇",
+		"newer_post":         "下一篇 →",
+		"previous_part":      "← 上一部分",
+		"next_part":          "下一部分 →",
+		"series_part":        "第%d部分，共%d部分",
+		"related_posts":      "相關文章",
+		"table_of_contents":  "目錄",
+		"language":           "語言:",
+		"search_placeholder": "搜尋文章...",
+		"search_label":       "搜尋文章",
+		"search_submit":      "搜尋",
+		"search_clear":       "清除",
+		"search_no_results":  "找不到文章。",
+		"read_time":          "%d 分鐘",
+		"read_more":          "閱讀全文 →",
+		"more_posts_before":  "更多文章請見",
+		"more_posts_link":    "封存",
+		"more_posts_after":   "。",
+		"not_found_title":    "找不到頁面",
+		"not_found_body":     "找不到該頁面。",
+		"date_format":        "2006年1月2日",
+	},
+	"ko": {
+		"archive":            "보관함",
+		"archive_tag":        "태그: %s",
+		"archive_category":   "카테고리: %s",
+		"tags":               "태그:",
+		"categories":         "카테고리:",
+		"previous":           "이전",
+		"next":               "다음",
+		"older_post":         "← 이전 글",
+		"newer_post":         "다음 글 →",
+		"previous_part":      "← 이전 편",
+		"next_part":          "다음 편 →",
+		"series_part":        "%[2]d편 중 %[1]d편",
+		"related_posts":      "관련 글",
+		"table_of_contents":  "목차",
+		"language":           "언어:",
+		"search_placeholder": "글 검색...",
+		"search_label":       "글 검색",
+		"search_submit":      "검색",
+		"search_clear":       "지우기",
+		"search_no_results":  "글을 찾을 수 없습니다.",
+		"read_time":          "%d분",
+		"read_more":          "더 읽기 →",
+		"more_posts_before":  "더 많은 글은 ",
+		"more_posts_link":    "보관함",
+		"more_posts_after":   "에서 볼 수 있습니다.",
+		"not_found_title":    "페이지를 찾을 수 없습니다",
+		"not_found_body":     "요청한 페이지를 찾을 수 없습니다.",
+		"date_format":        "2006년 1월 2일",
+	},
+	"fr": {
+		"archive":            "Archives",
+		"archive_tag":        "étiquette : %s",
+		"archive_category":   "catégorie : %s",
+		"tags":               "étiquettes :",
+		"categories":         "catégories :",
+		"previous":           "Précédent",
+		"next":               "Suivant",
+		"older_post":         "← Article précédent",
+		"newer_post":         "Article suivant →",
+		"previous_part":      "← Partie précédente",
+		"next_part":          "Partie suivante →",
+		"series_part":        "partie %d sur %d",
+		"related_posts":      "Articles liés",
+		"table_of_contents":  "Sommaire",
+		"language":           "langue :",
+		"search_placeholder": "Rechercher des articles...",
+		"search_label":       "Rechercher des articles",
+		"search_submit":      "Rechercher",
+		"search_clear":       "Effacer",
+		"search_no_results":  "Aucun article trouvé.",
+		"read_time":          "%d min",
+		"read_more":          "Lire →",
+		"more_posts_before":  "D'autres articles sont disponibles dans ",
+		"more_posts_link":    "les archives",
+		"more_posts_after":   ".",
+		"not_found_title":    "Page introuvable",
+		"not_found_body":     "Cette page est introuvable.",
+		"date_format":        "02/01/2006",
+	},
+	"de": {
+		"archive":            "Archiv",
+		"archive_tag":        "Schlagwort: %s",
+		"archive_category":   "Kategorie: %s",
+		"tags":               "Schlagwörter:",
+		"categories":         "Kategorien:",
+		"previous":           "Zurück",
+		"next":               "Weiter",
+		"older_post":         "← Älterer Beitrag",
+		"newer_post":         "Neuerer Beitrag →",
+		"previous_part":      "← Vorheriger Teil",
+		"next_part":          "Nächster Teil →",
+		"series_part":        "Teil %d von %d",
+		"related_posts":      "Ähnliche Beiträge",
+		"table_of_contents":  "Inhaltsverzeichnis",
+		"language":           "Sprache:",
+		"search_placeholder": "Beiträge durchsuchen...",
+		"search_label":       "Beiträge durchsuchen",
+		"search_submit":      "Suchen",
+		"search_clear":       "Zurücksetzen",
+		"search_no_results":  "Keine Beiträge gefunden.",
+		"read_time":          "%d Min.",
+		"read_more":          "Weiterlesen →",
+		"more_posts_before":  "Weitere Beiträge findest du im ",
+		"more_posts_link":    "Archiv",
+		"more_posts_after":   ".",
+		"not_found_title":    "Nicht gefunden",
+		"not_found_body":     "Seite nicht gefunden.",
+		"date_format":        "02.01.2006",
+	},
+	"es": {
+		"archive":            "Archivo",
+		"archive_tag":        "etiqueta: %s",
+		"archive_category":   "categoría: %s",
+		"tags":               "etiquetas:",
+		"categories":         "categorías:",
+		"previous":           "Anterior",
+		"next":               "Siguiente",
+		"older_post":         "← Entrada anterior",
+		"newer_post":         "Entrada siguiente →",
+		"previous_part":      "← Parte anterior",
+		"next_part":          "Parte siguiente →",
+		"series_part":        "parte %d de %d",
+		"related_posts":      "Entradas relacionadas",
+		"table_of_contents":  "Índice",
+		"language":           "idioma:",
+		"search_placeholder": "Buscar entradas...",
+		"search_label":       "Buscar entradas",
+		"search_submit":      "Buscar",
+		"search_clear":       "Borrar",
+		"search_no_results":  "No se encontraron artículos.",
+		"read_time":          "%d min",
+		"read_more":          "Leer →",
+		"more_posts_before":  "Encontrarás más entradas en ",
+		"more_posts_link":    "el archivo",
+		"more_posts_after":   ".",
+		"not_found_title":    "No encontrado",
+		"not_found_body":     "Página no encontrada.",
+		"date_format":        "02/01/2006",
+	},
+	"pt": {
+		"archive":            "Arquivo",
+		"archive_tag":        "tag: %s",
+		"archive_category":   "categoria: %s",
+		"tags":               "tags:",
+		"categories":         "categorias:",
+		"previous":           "Anterior",
+		"next":               "Próxima",
+		"older_post":         "← Post anterior",
+		"newer_post":         "Próximo post →",
+		"previous_part":      "← Parte anterior",
+		"next_part":          "Próxima parte →",
+		"series_part":        "parte %d de %d",
+		"related_posts":      "Posts relacionados",
+		"table_of_contents":  "Sumário",
+		"language":           "idioma:",
+		"search_placeholder": "Buscar posts...",
+		"search_label":       "Buscar posts",
+		"search_submit":      "Buscar",
+		"search_clear":       "Limpar",
+		"search_no_results":  "Nenhum artigo encontrado.",
+		"read_time":          "%d min",
+		"read_more":          "Ler →",
+		"more_posts_before":  "Mais posts podem ser encontrados no ",
+		"more_posts_link":    "arquivo",
+		"more_posts_after":   ".",
+		"not_found_title":    "Não encontrado",
+		"not_found_body":     "Página não encontrada.",
+		"date_format":        "02/01/2006",
+	},
+	"ru": {
+		"archive":            "Архив",
+		"archive_tag":        "тег: %s",
+		"archive_category":   "категория: %s",
+		"tags":               "теги:",
+		"categories":         "категории:",
+		"previous":           "Назад",
+		"next":               "Далее",
+		"older_post":         "← Предыдущая запись",
+		"newer_post":         "Следующая запись →",
+		"previous_part":      "← Предыдущая часть",
+		"next_part":          "Следующая часть →",
+		"series_part":        "часть %d из %d",
+		"related_posts":      "Похожие записи",
+		"table_of_contents":  "Содержание",
+		"language":           "язык:",
+		"search_placeholder": "Поиск по записям...",
+		"search_label":       "Поиск по записям",
+		"search_submit":      "Найти",
+		"search_clear":       "Сбросить",
+		"search_no_results":  "Записи не найдены.",
+		"read_time":          "%d мин",
+		"read_more":          "Читать →",
+		"more_posts_before":  "Другие записи можно найти в ",
+		"more_posts_link":    "архиве",
+		"more_posts_after":   ".",
+		"not_found_title":    "Не найдено",
+		"not_found_body":     "Страница не найдена.",
+		"date_format":        "02.01.2006",
+	},
+	"ar": {
+		"archive":            "الأرشيف",
+		"archive_tag":        "الوسم: %s",
+		"archive_category":   "التصنيف: %s",
+		"tags":               "الوسوم:",
+		"categories":         "التصنيفات:",
+		"previous":           "السابق",
+		"next":               "التالي",
+		"older_post":         "→ المقالة الأقدم",
+		"newer_post":         "المقالة الأحدث ←",
+		"previous_part":      "→ الجزء السابق",
+		"next_part":          "الجزء التالي ←",
+		"series_part":        "الجزء %d من %d",
+		"related_posts":      "مقالات ذات صلة",
+		"table_of_contents":  "المحتويات",
+		"language":           "اللغة:",
+		"search_placeholder": "ابحث في المقالات...",
+		"search_label":       "ابحث في المقالات",
+		"search_submit":      "بحث",
+		"search_clear":       "مسح",
+		"search_no_results":  "لم يتم العثور على مقالات.",
+		"read_time":          "%d دقيقة",
+		"read_more":          "اقرأ المزيد ←",
+		"more_posts_before":  "يمكنك العثور على المزيد من المقالات في ",
+		"more_posts_link":    "الأرشيف",
+		"more_posts_after":   ".",
+		"not_found_title":    "غير موجود",
+		"not_found_body":     "الصفحة غير موجودة.",
+		"date_format":        "2006/01/02",
+	},
+}
+
+// rtlLanguages are the base languages written right to left.
+var rtlLanguages = map[string]struct{}{
+	"ar":  {},
+	"ckb": {},
+	"dv":  {},
+	"fa":  {},
+	"he":  {},
+	"ps":  {},
+	"sd":  {},
+	"ug":  {},
+	"ur":  {},
+	"yi":  {},
+}
+
+// charCountLanguages are written without spaces between words, so their
+// reading time is counted in characters.
+var charCountLanguages = map[string]struct{}{
+	"ja": {},
+	"ko": {},
+	"zh": {},
+}
+
+var uiTextCache = struct {
+	mu    sync.RWMutex
+	items map[string]uiText
+}{
+	items: map[string]uiText{},
+}
+
+func (text uiText) get(key string) string {
+	if value, ok := text[key]; ok {
+		return value
+	}
+	return defaultUIText["en"][key]
+}
+
+func (text uiText) format(key string, args ...any) string {
+	return fmt.Sprintf(text.get(key), args...)
+}
+
+func (text uiText) date(value string) string {
+	return formatDateLayout(value, text.get("date_format"))
+}
+
+func (text uiText) readTime(minutes int) string {
+	return text.format("read_time", minutes)
+}
+
+// siteUILocale is the locale of pages that have no locale in their path.
+func siteUILocale(settings SettingsRecord) string {
+	if locale := normalizeLocale(settings.SiteLanguage); locale != "" {
+		return locale
+	}
+	return defaultString(siteSourceLocale(settings), "en")
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(normalizeLocale(locale), "-")
+	return base
+}
+
+func localeDirection(locale string) string {
+	if _, ok := rtlLanguages[baseLanguage(locale)]; ok {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// uiTextFor resolves the catalog of locale. Later layers win: English, the
+// base language ("pt" for "pt-br"), then the exact locale, each with its
+// settings overrides on top of the shipped strings.
+func uiTextFor(settings SettingsRecord, locale string) uiText {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		locale = siteUILocale(settings)
+	}
+	key := locale + "|" + string(settings.UIStrings)
+	uiTextCache.mu.RLock()
+	cached, ok := uiTextCache.items[key]
+	uiTextCache.mu.RUnlock()
+	if ok {
+		return cached
+	}
+
+	overrides := parseUIStringOverrides(settings.UIStrings)
+	layers := []string{"en"}
+	if base := baseLanguage(locale); base != "en" {
+		layers = append(layers, base)
+	}
+	if locale != layers[len(layers)-1] {
+		layers = append(layers, locale)
+	}
+	text := uiText{}
+	for _, layer := range layers {
+		for k, v := range defaultUIText[layer] {
+			text[k] = v
+		}
+		for k, v := range overrides[layer] {
+			text[k] = v
+		}
+	}
+
+	uiTextCache.mu.Lock()
+	if len(uiTextCache.items) >= utilCacheMaxEntries {
+		uiTextCache.items = map[string]uiText{}
+	}
+	uiTextCache.items[key] = text
+	uiTextCache.mu.Unlock()
+	return text
+}
+
+// parseUIStringOverrides reads {"<locale>": {"<key>": "<text>"}}. Entries
+// that aren't strings are skipped so a typo in settings can't break pages.
+func parseUIStringOverrides(raw json.RawMessage) map[string]map[string]string {
+	var locales map[string]map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &locales) != nil {
+		return nil
+	}
+	out := make(map[string]map[string]string, len(locales))
+	for locale, entries := range locales {
+		locale = normalizeLocale(locale)
+		if locale == "" {
+			continue
+		}
+		for key, value := range entries {
+			var text string
+			if json.Unmarshal(value, &text) != nil {
+				continue
+			}
+			if out[locale] == nil {
+				out[locale] = map[string]string{}
+			}
+			out[locale][strings.TrimSpace(key)] = text
+		}
+	}
+	return out
+}
+
+// readTimeMinutes estimates reading time at about 220 words a minute, or 700
+// characters a minute for languages written without spaces.
+func readTimeMinutes(body, locale string) int {
+	if _, ok := charCountLanguages[baseLanguage(locale)]; ok {
+		return calcReadTime(body)
+	}
+	words := len(strings.Fields(stripHTML(body)))
+	minutes := int(math.Ceil(float64(words) / 220.0))
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
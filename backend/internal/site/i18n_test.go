@@ -0,0 +1,125 @@
+package site
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUITextForLayersOverridesOnDefaults(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{
+		SiteLanguage: "en",
+		UIStrings:    json.RawMessage(`{"pt": {"older_post": "← Anterior", "read_time": 5}, "PT_BR": {"next": "Próximo"}}`),
+	}
+
+	text := uiTextFor(settings, "pt-br")
+	if got := text.get("older_post"); got != "← Anterior" {
+		t.Fatalf("base language override = %q", got)
+	}
+	if got := text.get("next"); got != "Próximo" {
+		t.Fatalf("exact locale override = %q", got)
+	}
+	if got := text.get("related_posts"); got != "Posts relacionados" {
+		t.Fatalf("shipped base language string = %q", got)
+	}
+	if got := text.readTime(3); got != "3 min" {
+		t.Fatalf("non-string override should be skipped, got %q", got)
+	}
+
+	unknown := uiTextFor(settings, "xx")
+	if got := unknown.get("newer_post"); got != "Newer post →" {
+		t.Fatalf("unknown locale should fall back to English, got %q", got)
+	}
+	if got := uiTextFor(settings, "").get("archive"); got != "Archive" {
+		t.Fatalf("empty locale should use the site language, got %q", got)
+	}
+
+	broken := SettingsRecord{UIStrings: json.RawMessage(`{"ja": `)}
+	if got := uiTextFor(broken, "ja").get("table_of_contents"); got != "目次" {
+		t.Fatalf("invalid overrides should be ignored, got %q", got)
+	}
+	if got := uiText(nil).get("search_submit"); got != "Search" {
+		t.Fatalf("nil catalog should read English, got %q", got)
+	}
+}
+
+func TestLocaleDirection(t *testing.T) {
+	t.Parallel()
+
+	for locale, want := range map[string]string{"ar": "rtl", "he-il": "rtl", "fa": "rtl", "ja": "ltr", "en": "ltr", "": "ltr"} {
+		if got := localeDirection(locale); got != want {
+			t.Fatalf("localeDirection(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestLocalizedDateAndReadTime(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{}
+	for locale, want := range map[string]string{
+		"en": "Apr 16, 2026",
+		"ja": "2026年4月16日",
+		"ko": "2026년 4월 16일",
+		"de": "16.04.2026",
+	} {
+		if got := uiTextFor(settings, locale).date("2026-04-16T10:00:00Z"); got != want {
+			t.Fatalf("date in %s = %q, want %q", locale, got, want)
+		}
+	}
+	if got := formatDate("2026-04-16T10:00:00Z"); got != "2026-04-16" {
+		t.Fatalf("formatDate = %q", got)
+	}
+
+	words := strings.Repeat("word ", 500)
+	if got := readTimeMinutes(words, "en"); got != 3 {
+		t.Fatalf("english read time = %d, want 3", got)
+	}
+	if got := readTimeMinutes(strings.Repeat("語", 1500), "ja"); got != 3 {
+		t.Fatalf("japanese read time = %d, want 3", got)
+	}
+	if got := uiTextFor(settings, "ja").readTime(3); got != "3分" {
+		t.Fatalf("japanese read time label = %q", got)
+	}
+}
+
+func TestLocalizedThemeLayout(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.SiteLanguage = "en"
+	settings.TranslationSourceLocale = "en"
+
+	layout := newLocalizedThemeLayout("مرحبا", "ar", nil, settings, "")
+	head := renderThemeTemplate(settings, "head.html", layout.Head)
+	if !strings.Contains(head, `<html lang="ar" dir="rtl">`) {
+		t.Fatalf("arabic head missing lang/dir: %s", head)
+	}
+	nav := renderThemeTemplate(settings, "nav.html", layout.Nav)
+	if !strings.Contains(nav, "الأرشيف") {
+		t.Fatalf("arabic nav missing archive label: %s", nav)
+	}
+
+	site := renderThemeTemplate(settings, "head.html", newThemeLayout("Home", nil, settings, "").Head)
+	if !strings.Contains(site, `<html lang="en" dir="ltr">`) {
+		t.Fatalf("site head missing lang/dir: %s", site)
+	}
+}
+
+func TestRenderSearchFormAndPaginationUseCatalog(t *testing.T) {
+	t.Parallel()
+
+	text := uiTextFor(SettingsRecord{}, "fr")
+	form := renderSearchForm(parseArchiveRoute("/archive/go/"), "go", text)
+	for _, token := range []string{`placeholder="Rechercher des articles..."`, `>Rechercher</button>`, `>Effacer</a>`, `data-search-tag="go"`, `data-search-empty="Aucun article trouvé."`, `<script>`} {
+		if !strings.Contains(form, token) {
+			t.Fatalf("search form missing %q: %s", token, form)
+		}
+	}
+	pagination := renderPagination("/archive", 2, 3, "", text)
+	if !strings.Contains(pagination, "<span>Précédent</span>") || !strings.Contains(pagination, "<span>Suivant</span>") {
+		t.Fatalf("pagination not localized: %s", pagination)
+	}
+}
@@ -49,13 +49,18 @@ func isSourceLocale(settings SettingsRecord, targetLocale string) bool {
 		return false
 	}
 
-	source := normalizeLocale(settings.TranslationSourceLocale)
-	if source == "" {
-		source = normalizeLocale(settings.SiteLanguage)
-	}
+	source := siteSourceLocale(settings)
 	return source != "" && locale == source
 }
 
+// siteSourceLocale is the locale source posts are written in.
+func siteSourceLocale(settings SettingsRecord) string {
+	if source := normalizeLocale(settings.TranslationSourceLocale); source != "" {
+		return source
+	}
+	return normalizeLocale(settings.SiteLanguage)
+}
+
 func parseLocaleSegment(value string) (string, bool) {
 	locale := normalizeLocale(value)
 	if locale == "" || locale != strings.TrimSpace(value) || !localePathPattern.MatchString(locale) {
@@ -83,3 +88,53 @@ func extractLocalizedPostRoute(path string) (string, string, bool) {
 	}
 	return locale, strings.TrimSpace(parts[2]), true
 }
+
+// extractLocalizedListingRoute splits /<locale>/ and /<locale>/archive/...
+// into the locale and the listing path it localizes ("/" for the home page).
+func extractLocalizedListingRoute(path string) (string, string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > 1 && parts[1] != "archive" {
+		return "", "", false
+	}
+	locale, ok := parseLocaleSegment(parts[0])
+	if !ok {
+		return "", "", false
+	}
+	if len(parts) == 1 {
+		return locale, "/", true
+	}
+	return locale, "/" + strings.Join(parts[1:], "/"), true
+}
+
+// localePathPrefix is the path prefix of a translation locale's routes; the
+// source locale has none.
+func localePathPrefix(locale string) string {
+	if normalizeLocale(locale) == "" {
+		return ""
+	}
+	return "/" + normalizeLocale(locale)
+}
+
+// listingPathPrefix is the prefix of the home and archive links shown on a
+// page in locale: enabled translation locales have their own listings.
+func listingPathPrefix(settings SettingsRecord, locale string) string {
+	if isSourceLocale(settings, locale) || !isEnabledTranslationLocale(settings, locale) {
+		return ""
+	}
+	return localePathPrefix(locale)
+}
+
+// extractLocalizedPageRoute splits /<locale>/<page-url> into the locale and
+// the source page URL it translates.
+func extractLocalizedPageRoute(path string) (string, string, bool) {
+	trimmed := strings.TrimPrefix(path, "/")
+	first, rest, found := strings.Cut(trimmed, "/")
+	if !found || strings.Trim(rest, "/") == "" {
+		return "", "", false
+	}
+	locale, ok := parseLocaleSegment(first)
+	if !ok {
+		return "", "", false
+	}
+	return locale, "/" + rest, true
+}
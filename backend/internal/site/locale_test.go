@@ -42,6 +42,64 @@ func TestExtractLocalizedPostRoute(t *testing.T) {
 	}
 }
 
+func TestExtractLocalizedListingRoute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path   string
+		locale string
+		rest   string
+		ok     bool
+	}{
+		{path: "/en/", locale: "en", rest: "/", ok: true},
+		{path: "/en", locale: "en", rest: "/", ok: true},
+		{path: "/en/archive/go/2/", locale: "en", rest: "/archive/go/2", ok: true},
+		{path: "/en/posts/hello/"},
+		{path: "/archive/"},
+		{path: "/about/"},
+	}
+	for _, tt := range tests {
+		locale, rest, ok := extractLocalizedListingRoute(tt.path)
+		if locale != tt.locale || rest != tt.rest || ok != tt.ok {
+			t.Fatalf("extractLocalizedListingRoute(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.path, locale, rest, ok, tt.locale, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestExtractLocalizedPageRoute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path    string
+		locale  string
+		pageURL string
+		ok      bool
+	}{
+		{path: "/en/about/", locale: "en", pageURL: "/about/", ok: true},
+		{path: "/pt-br/company/team", locale: "pt-br", pageURL: "/company/team", ok: true},
+		{path: "/en/"},
+		{path: "/about/"},
+		{path: "/About/team/"},
+	}
+	for _, tt := range tests {
+		locale, pageURL, ok := extractLocalizedPageRoute(tt.path)
+		if locale != tt.locale || pageURL != tt.pageURL || ok != tt.ok {
+			t.Fatalf("extractLocalizedPageRoute(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.path, locale, pageURL, ok, tt.locale, tt.pageURL, tt.ok)
+		}
+	}
+}
+
+func TestListingPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{TranslationSourceLocale: "ja", TranslationLocales: "en"}
+	for locale, want := range map[string]string{"en": "/en", "ja": "", "fr": "", "": ""} {
+		if got := listingPathPrefix(settings, locale); got != want {
+			t.Fatalf("listingPathPrefix(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
 func TestIsSourceLocale(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -34,6 +35,14 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 		handleRevalidate(w, r)
 		return
 	}
+	if path == "/__internal/snapshots" {
+		handleSnapshotGenerations(w, r)
+		return
+	}
+	if path == "/__internal/snapshots/rollback" {
+		handleSnapshotRollback(w, r)
+		return
+	}
 	if path == "/feed.json" || path == "/feed.xml" {
 		settings := requestSettings(r)
 		if !isFeedRouteEnabled(path, settings) {
@@ -48,6 +57,13 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if locale, ok := extractSearchIndexLocale(path); ok && locale != "" {
+		settings := requestSettings(r)
+		if !isEnabledTranslationLocale(settings, locale) {
+			http.NotFound(w, r)
+			return
+		}
+	}
 	if locale, ok := extractLocalizedPostRouteLocale(path); ok {
 		settings := requestSettings(r)
 		if !isEnabledTranslationLocale(settings, locale) {
@@ -62,7 +78,7 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 	if path == "/" {
 		settings := requestSettings(r)
 		html := renderHome(settings)
-		writeHTML(w, html)
+		writeHTML(w, r, html)
 		return
 	}
 
@@ -70,7 +86,7 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 		settings := requestSettings(r)
 		searchQuery := strings.TrimSpace(r.URL.Query().Get("q"))
 		html := renderArchive("/archive/", searchQuery, settings)
-		writeHTML(w, html)
+		writeHTML(w, r, html)
 		return
 	}
 
@@ -108,9 +124,14 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 		writeLocalizedSitemap(w, r, settings, locale)
 		return
 	}
+	if locale, ok := extractSearchIndexLocale(path); ok {
+		settings := requestSettings(r)
+		writeSearchIndexJSON(w, settings, locale)
+		return
+	}
 	if strings.HasPrefix(path, "/og/") {
 		settings := requestSettings(r)
-		if servePostOGImage(w, path, settings) {
+		if servePostOGImage(w, r, path, settings) {
 			return
 		}
 		http.NotFound(w, r)
@@ -121,7 +142,62 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 		settings := requestSettings(r)
 		searchQuery := strings.TrimSpace(r.URL.Query().Get("q"))
 		html := renderArchive(path, searchQuery, settings)
-		writeHTML(w, html)
+		writeHTML(w, r, html)
+		return
+	}
+
+	if locale, rest, ok := extractLocalizedListingRoute(path); ok {
+		settings := requestSettings(r)
+		if isEnabledTranslationLocale(settings, locale) {
+			if rest == "/" {
+				writeHTML(w, r, renderLocalizedHome(locale, settings))
+				return
+			}
+			searchQuery := strings.TrimSpace(r.URL.Query().Get("q"))
+			writeHTML(w, r, renderArchive(path, searchQuery, settings))
+			return
+		}
+	}
+
+	if slug, format, ok := extractAuthorFeedRoute(path); ok {
+		settings := requestSettings(r)
+		if !isFeedRouteEnabled("/feed."+format, settings) {
+			http.NotFound(w, r)
+			return
+		}
+		author := getAuthorBySlug(slug)
+		if author == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if format == "json" {
+			writeScopedJSONFeed(w, r, settings, authorFeedScope(settings, *author))
+		} else {
+			writeScopedRSSFeed(w, r, settings, authorFeedScope(settings, *author))
+		}
+		return
+	}
+
+	if strings.HasPrefix(path, "/authors/") {
+		settings := requestSettings(r)
+		searchQuery := strings.TrimSpace(r.URL.Query().Get("q"))
+		if _, ok := resolveArchiveRoute(path); !ok {
+			writeHTMLStatus(w, renderNotFound(settings), http.StatusNotFound)
+			return
+		}
+		html := renderArchive(path, searchQuery, settings)
+		writeHTML(w, r, html)
+		return
+	}
+
+	if slug, ok := extractSeriesSlug(path); ok {
+		settings := requestSettings(r)
+		html, found := renderSeriesIndex(getSeriesBySlug(slug), settings)
+		if !found {
+			writeHTMLStatus(w, html, http.StatusNotFound)
+			return
+		}
+		writeHTML(w, r, html)
 		return
 	}
 
@@ -150,7 +226,7 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 			writeHTMLStatus(w, html, http.StatusNotFound)
 			return
 		}
-		writeHTML(w, html)
+		writeHTML(w, r, html)
 		return
 	}
 	if isLocalizedPostPath(path) {
@@ -178,10 +254,21 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 			writeHTMLStatus(w, html, http.StatusNotFound)
 			return
 		}
-		writeHTML(w, html)
+		writeHTML(w, r, html)
 		return
 	}
 
+	if locale, pageURL, ok := extractLocalizedPageRoute(path); ok {
+		settings := requestSettings(r)
+		if isEnabledTranslationLocale(settings, locale) {
+			if page := getLocalizedPageByURL(pageURL, locale); page != nil {
+				html, _ := renderPageInLocale(page, locale, settings)
+				writeHTML(w, r, html)
+				return
+			}
+		}
+	}
+
 	var settings SettingsRecord
 	var page *PageRecord
 	var wg sync.WaitGroup
@@ -201,7 +288,7 @@ func routeHandler(w http.ResponseWriter, r *http.Request) {
 		writeHTMLStatus(w, html, http.StatusNotFound)
 		return
 	}
-	writeHTML(w, html)
+	writeHTML(w, r, html)
 }
 
 func isLocalizedPostPath(path string) bool {
@@ -234,7 +321,7 @@ func withPreviewTheme(settings SettingsRecord, r *http.Request) SettingsRecord {
 		return settings
 	}
 	previewTheme := strings.TrimSpace(r.URL.Query().Get("theme"))
-	if previewTheme != "" {
+	if previewTheme != "" && isKnownTheme(previewTheme) {
 		settings.Theme = previewTheme
 	}
 	return settings
@@ -262,8 +349,11 @@ func withRequestSiteURL(settings SettingsRecord, r *http.Request) SettingsRecord
 	return settings
 }
 
-func writeHTML(w http.ResponseWriter, content string) {
-	writeHTMLStatus(w, content, http.StatusOK)
+func writeHTML(w http.ResponseWriter, r *http.Request, content string) {
+	contentType := "text/html; charset=utf-8"
+	encoding := dynamicResponseEncoding(r, contentType, len(content))
+	addVaryAcceptEncoding(w)
+	writeEncodedBody(w, http.StatusOK, contentType, encoding, []byte(content))
 }
 
 func writeHTMLStatus(w http.ResponseWriter, content string, status int) {
@@ -302,7 +392,11 @@ func serveStatic(w http.ResponseWriter, r *http.Request) bool {
 	filePath := filepath.Join(activePublicDir, clean)
 	info, err := os.Stat(filePath)
 	if err != nil || info.IsDir() {
-		return false
+		themeAsset, ok := resolveThemeAssetPath(clean)
+		if !ok {
+			return false
+		}
+		filePath = themeAsset
 	}
 
 	http.ServeFile(w, r, filePath)
@@ -317,6 +411,9 @@ func shouldServePrerenderedSnapshot(clean string) bool {
 	if strings.HasPrefix(clean, "/sitemap-") && strings.HasSuffix(clean, ".xml") {
 		return false
 	}
+	if _, _, ok := extractAuthorFeedRoute(clean); ok {
+		return false
+	}
 	return true
 }
 
@@ -333,18 +430,72 @@ func servePrerenderedSnapshot(w http.ResponseWriter, r *http.Request, clean stri
 	if err != nil || info.IsDir() {
 		return false
 	}
-	body, err := os.ReadFile(target)
-	if err != nil {
+	meta, ok := lookupSnapshotFileMeta(target)
+	if !ok {
 		return false
 	}
 	contentType := snapshotContentType(clean)
-	if strings.HasPrefix(contentType, "text/html") {
-		body = absolutizePrerenderedSnapshotHTML(body, withRequestSiteURL(getSettings(), r))
+	if strings.HasPrefix(contentType, "text/html") && !meta.absolutized {
+		return serveRequestAbsolutizedSnapshot(w, r, target, contentType, info.Size(), meta)
+	}
+
+	// The file (or its precompressed sibling) goes out unchanged, so
+	// ServeContent can hand it to sendfile.
+	encoding := ""
+	file, err := os.Open(target)
+	if err != nil {
+		return false
+	}
+	for _, candidate := range snapshotEncodings {
+		if !slices.Contains(meta.encodings, candidate.name) || !acceptsEncoding(r, candidate.name) {
+			continue
+		}
+		if sibling, err := os.Open(target + candidate.suffix); err == nil {
+			_ = file.Close()
+			file, encoding = sibling, candidate.name
+			break
+		}
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	etag := encodedETag(meta.etag, encoding)
+	if len(meta.encodings) > 0 {
+		addVaryAcceptEncoding(w)
+	}
+	setValidatorHeaders(w, htmlCacheControl, etag, meta.modTime)
+	if requestNotModified(r, etag, meta.modTime) {
+		writeNotModified(w)
+		return true
+	}
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
 	}
-	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Content-Type", contentType)
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(body)
+	http.ServeContent(w, r, "", meta.modTime, file)
+	return true
+}
+
+// serveRequestAbsolutizedSnapshot handles HTML written without a configured
+// site URL: links are absolutized for the requesting host, so the body (and
+// its ETag) is built per request and compressed on the fly.
+func serveRequestAbsolutizedSnapshot(w http.ResponseWriter, r *http.Request, target, contentType string, size int64, meta snapshotFileMeta) bool {
+	settings := withRequestSiteURL(getSettings(), r)
+	encoding := dynamicResponseEncoding(r, contentType, int(size))
+	etag := encodedETag(contentETag([]byte(meta.etag+settings.SiteURL)), encoding)
+	addVaryAcceptEncoding(w)
+	setValidatorHeaders(w, htmlCacheControl, etag, meta.modTime)
+	if requestNotModified(r, etag, meta.modTime) {
+		writeNotModified(w)
+		return true
+	}
+
+	body, err := os.ReadFile(target)
+	if err != nil {
+		return false
+	}
+	writeEncodedBody(w, http.StatusOK, contentType, encoding, absolutizePrerenderedSnapshotHTML(body, settings))
 	return true
 }
 
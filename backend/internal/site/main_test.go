@@ -212,17 +212,10 @@ func TestRouteHandlerLocalizedPostIgnoresStaleSnapshotWhenLocaleDisabled(t *test
 	}
 }
 
-func TestServePrerenderedSnapshotIgnoresConditionalCacheHeaders(t *testing.T) {
+func TestServePrerenderedSnapshotConditionalRequests(t *testing.T) {
 	root := t.TempDir()
-	target, err := snapshotFilePath(root, "/archive/")
-	if err != nil {
-		t.Fatalf("snapshotFilePath: %v", err)
-	}
-	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-		t.Fatalf("MkdirAll: %v", err)
-	}
-	if err := os.WriteFile(target, []byte("fresh snapshot"), 0o644); err != nil {
-		t.Fatalf("WriteFile: %v", err)
+	if err := writeSnapshotFile(root, "/feed-test.json", []byte(`{"v":1}`)); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
 	}
 
 	prevSnapshot := getPrerenderedSnapshotDir()
@@ -233,21 +226,44 @@ func TestServePrerenderedSnapshotIgnoresConditionalCacheHeaders(t *testing.T) {
 		prerenderedSnapshot.mu.Unlock()
 	})
 
-	req := httptest.NewRequest(http.MethodGet, "/archive/", nil)
-	req.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
-	rec := httptest.NewRecorder()
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed-test.json", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		if !servePrerenderedSnapshot(rec, req, "/feed-test.json") {
+			t.Fatal("servePrerenderedSnapshot returned false")
+		}
+		return rec
+	}
 
-	if !servePrerenderedSnapshot(rec, req, "/archive/") {
-		t.Fatal("servePrerenderedSnapshot returned false")
+	rec := serve("", "")
+	etag := rec.Header().Get("ETag")
+	lastModified := rec.Header().Get("Last-Modified")
+	if rec.Code != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("status = %d, ETag = %q, Last-Modified = %q", rec.Code, etag, lastModified)
 	}
-	if rec.Code != http.StatusOK {
-		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	if cache := rec.Header().Get("Cache-Control"); cache != htmlCacheControl {
+		t.Fatalf("Cache-Control = %q, want %q", cache, htmlCacheControl)
+	}
+	if rec := serve("If-None-Match", etag); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("If-None-Match status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if rec := serve("If-Modified-Since", lastModified); rec.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	// A rewrite with new content must not be answered from the old validators.
+	if err := writeSnapshotFile(root, "/feed-test.json", []byte(`{"v":2}`)); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
 	}
-	if body := strings.TrimSpace(rec.Body.String()); body != "fresh snapshot" {
-		t.Fatalf("body = %q, want %q", body, "fresh snapshot")
+	rec = serve("If-None-Match", etag)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"v":2}` {
+		t.Fatalf("stale ETag should get fresh content, got %d %q", rec.Code, rec.Body.String())
 	}
-	if cache := rec.Header().Get("Cache-Control"); cache != "no-store" {
-		t.Fatalf("Cache-Control = %q, want %q", cache, "no-store")
+	if rec := serve("If-Modified-Since", lastModified); rec.Code != http.StatusOK {
+		t.Fatalf("stale If-Modified-Since status = %d, want %d", rec.Code, http.StatusOK)
 	}
 }
 
@@ -318,12 +334,12 @@ func TestServePrerenderedSnapshotAbsolutizesOGMetaURLs(t *testing.T) {
 func TestShouldServePrerenderedSnapshot(t *testing.T) {
 	t.Parallel()
 
-	for _, path := range []string{"/feed.xml", "/feed.json", "/robots.txt", "/sitemap.xml", "/sitemap-ja.xml"} {
+	for _, path := range []string{"/feed.xml", "/feed.json", "/robots.txt", "/sitemap.xml", "/sitemap-ja.xml", "/authors/jane/feed.xml", "/authors/jane/feed.json"} {
 		if shouldServePrerenderedSnapshot(path) {
 			t.Fatalf("shouldServePrerenderedSnapshot(%q) = true, want false", path)
 		}
 	}
-	for _, path := range []string{"/", "/archive/", "/posts/hello/"} {
+	for _, path := range []string{"/", "/archive/", "/posts/hello/", "/authors/jane/"} {
 		if !shouldServePrerenderedSnapshot(path) {
 			t.Fatalf("shouldServePrerenderedSnapshot(%q) = false, want true", path)
 		}
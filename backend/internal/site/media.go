@@ -2,10 +2,11 @@ package site
 
 import (
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"alleycat-backend/internal/mediaref"
 )
 
 func rewriteMediaURLs(body string) string {
@@ -21,7 +22,7 @@ func rewriteMediaURLs(body string) string {
 		}
 		collection := match[1]
 		id := match[2]
-		if collection != "media" && collection != "pbc_2708086759" {
+		if !mediaref.IsMediaCollection(collection) {
 			continue
 		}
 		if _, ok := seenIDs[id]; ok {
@@ -65,31 +66,22 @@ func rewriteMediaURLs(body string) string {
 	})
 }
 
+// mediaPublicURL is the URL a media record is served from on the public site.
+func mediaPublicURL(media MediaRecord) string {
+	path := strings.TrimSpace(media.Path)
+	if path == "" {
+		return fmt.Sprintf("/api/files/media/%s/%s", media.ID, url.PathEscape(media.File))
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "/") {
+		return path
+	}
+	return "/" + path
+}
+
 func serveMediaUpload(w http.ResponseWriter, r *http.Request, clean string) bool {
 	media := getMediaByPath(clean)
 	if media == nil || media.File == "" {
-		return false
-	}
-	fileURL := fmt.Sprintf("%s/api/files/media/%s/%s", pbURL, media.ID, url.PathEscape(media.File))
-	resp, err := httpClient.Get(fileURL)
-	if err != nil {
-		return false
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return false
-	}
-	contentType := resp.Header.Get("Content-Type")
-	if contentType != "" {
-		w.Header().Set("Content-Type", contentType)
-	}
-	cache := resp.Header.Get("Cache-Control")
-	if cache != "" {
-		w.Header().Set("Cache-Control", cache)
+		return serveMediaVariant(w, r, clean)
 	}
-	w.WriteHeader(resp.StatusCode)
-	_, err = io.Copy(w, resp.Body)
-	return err == nil
+	return serveMediaOriginal(w, r, *media)
 }
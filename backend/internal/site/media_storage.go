@@ -0,0 +1,432 @@
+package site
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"alleycat-backend/internal/mediaref"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+const (
+	mediaStoragePocketBase = "pocketbase"
+	mediaStorageFS         = "fs"
+	mediaStorageS3         = "s3"
+)
+
+var errMediaNotFound = errors.New("media file not found")
+
+// mediaObject is an open media original. *blob.Reader from PocketBase's
+// filesystem package satisfies it.
+type mediaObject interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// mediaStore opens media originals by their PocketBase storage key,
+// <collection id>/<record id>/<file name>.
+type mediaStore interface {
+	Open(key string) (mediaObject, error)
+}
+
+type mediaStorageConfig struct {
+	Backend          string
+	Dir              string
+	S3Bucket         string
+	S3Region         string
+	S3Endpoint       string
+	S3AccessKey      string
+	S3Secret         string
+	S3ForcePathStyle bool
+}
+
+func mediaStorageConfigFromEnv() mediaStorageConfig {
+	forcePathStyle, _ := strconv.ParseBool(getEnv("MEDIA_S3_FORCE_PATH_STYLE", "false"))
+	return mediaStorageConfig{
+		Backend:          strings.ToLower(strings.TrimSpace(getEnv("MEDIA_STORAGE", mediaStoragePocketBase))),
+		Dir:              getEnv("MEDIA_STORAGE_DIR", "/pb/pb_data/storage"),
+		S3Bucket:         getEnv("MEDIA_S3_BUCKET", ""),
+		S3Region:         getEnv("MEDIA_S3_REGION", "us-east-1"),
+		S3Endpoint:       getEnv("MEDIA_S3_ENDPOINT", ""),
+		S3AccessKey:      getEnv("MEDIA_S3_ACCESS_KEY", ""),
+		S3Secret:         getEnv("MEDIA_S3_SECRET", ""),
+		S3ForcePathStyle: forcePathStyle,
+	}
+}
+
+// activeMediaStore falls back to proxying through PocketBase when the
+// configured storage cannot be opened, so a bad setting degrades to the
+// slower path instead of breaking every upload.
+var activeMediaStore = sync.OnceValue(func() mediaStore {
+	config := mediaStorageConfigFromEnv()
+	store, err := newMediaStore(config)
+	if err != nil {
+		slog.Warn("media storage unavailable; proxying through PocketBase", "backend", config.Backend, "error", err)
+		return pocketBaseMediaStore{baseURL: pbURL}
+	}
+	return store
+})
+
+var mediaFileCache = newMediaLRU(
+	getEnvInt64("MEDIA_CACHE_BYTES", 64<<20),
+	getEnvInt64("MEDIA_CACHE_MAX_FILE_BYTES", 1<<20),
+)
+
+// mediaHTTPClient has no overall timeout: large originals may take longer
+// to stream than the JSON API calls httpClient is tuned for.
+var mediaHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+}
+
+func newMediaStore(config mediaStorageConfig) (mediaStore, error) {
+	switch config.Backend {
+	case "", mediaStoragePocketBase:
+		return pocketBaseMediaStore{baseURL: pbURL}, nil
+	case mediaStorageFS:
+		// NewLocal creates missing directories; a missing storage dir means
+		// PocketBase's data is not mounted here.
+		info, err := os.Stat(config.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", config.Dir)
+		}
+		fs, err := filesystem.NewLocal(config.Dir)
+		if err != nil {
+			return nil, err
+		}
+		return filesystemMediaStore{fs: fs}, nil
+	case mediaStorageS3:
+		if config.S3Bucket == "" || config.S3Endpoint == "" {
+			return nil, errors.New("MEDIA_S3_BUCKET and MEDIA_S3_ENDPOINT are required")
+		}
+		fs, err := filesystem.NewS3(config.S3Bucket, config.S3Region, config.S3Endpoint, config.S3AccessKey, config.S3Secret, config.S3ForcePathStyle)
+		if err != nil {
+			return nil, err
+		}
+		return filesystemMediaStore{fs: fs}, nil
+	default:
+		return nil, fmt.Errorf("unknown media storage %q", config.Backend)
+	}
+}
+
+func mediaStorageKey(media MediaRecord) string {
+	return mediaref.CollectionID + "/" + media.ID + "/" + media.File
+}
+
+type filesystemMediaStore struct {
+	fs *filesystem.System
+}
+
+func (s filesystemMediaStore) Open(key string) (mediaObject, error) {
+	reader, err := s.fs.GetReader(key)
+	if err != nil {
+		if errors.Is(err, filesystem.ErrNotFound) {
+			return nil, errMediaNotFound
+		}
+		return nil, err
+	}
+	return reader, nil
+}
+
+// pocketBaseMediaStore reads originals through PocketBase's file API. Seeks
+// turn into Range requests, so partial responses don't fetch the whole file.
+type pocketBaseMediaStore struct {
+	baseURL string
+}
+
+func (s pocketBaseMediaStore) Open(key string) (mediaObject, error) {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	object := &httpMediaObject{url: strings.TrimRight(s.baseURL, "/") + "/api/files/" + strings.Join(parts, "/")}
+	if err := object.fetch(0); err != nil {
+		return nil, err
+	}
+	if object.size < 0 {
+		// Without a length the object can't seek; buffer it instead.
+		defer object.Close()
+		data, err := io.ReadAll(io.LimitReader(object.body, maxMediaOriginalBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxMediaOriginalBytes {
+			return nil, errors.New("media original too large")
+		}
+		return newMemoryMediaObject(data, object.modTime), nil
+	}
+	return object, nil
+}
+
+type httpMediaObject struct {
+	url     string
+	size    int64
+	modTime time.Time
+	body    io.ReadCloser
+	// bodyOffset is where body currently reads from; pos is where the
+	// caller wants to read next.
+	bodyOffset int64
+	pos        int64
+}
+
+func (o *httpMediaObject) fetch(offset int64) error {
+	req, err := http.NewRequest(http.MethodGet, o.url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := mediaHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		_ = resp.Body.Close()
+		return errMediaNotFound
+	case offset == 0 && resp.StatusCode == http.StatusOK:
+		o.size = resp.ContentLength
+		o.modTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+	default:
+		_ = resp.Body.Close()
+		return fmt.Errorf("fetch media original: http %d", resp.StatusCode)
+	}
+	o.body = resp.Body
+	o.bodyOffset = offset
+	return nil
+}
+
+func (o *httpMediaObject) Read(p []byte) (int, error) {
+	if o.pos >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil || o.bodyOffset != o.pos {
+		if o.body != nil {
+			_ = o.body.Close()
+			o.body = nil
+		}
+		if err := o.fetch(o.pos); err != nil {
+			return 0, err
+		}
+	}
+	n, err := o.body.Read(p)
+	o.pos += int64(n)
+	o.bodyOffset += int64(n)
+	return n, err
+}
+
+func (o *httpMediaObject) Seek(offset int64, whence int) (int64, error) {
+	next := offset
+	switch whence {
+	case io.SeekCurrent:
+		next += o.pos
+	case io.SeekEnd:
+		next += o.size
+	}
+	if next < 0 {
+		return o.pos, errors.New("seek before start of media object")
+	}
+	o.pos = next
+	return next, nil
+}
+
+func (o *httpMediaObject) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (o *httpMediaObject) Size() int64 {
+	return o.size
+}
+
+func (o *httpMediaObject) ModTime() time.Time {
+	return o.modTime
+}
+
+type memoryMediaObject struct {
+	*bytes.Reader
+	modTime time.Time
+}
+
+func newMemoryMediaObject(data []byte, modTime time.Time) memoryMediaObject {
+	return memoryMediaObject{Reader: bytes.NewReader(data), modTime: modTime}
+}
+
+func (o memoryMediaObject) Close() error {
+	return nil
+}
+
+func (o memoryMediaObject) ModTime() time.Time {
+	return o.modTime
+}
+
+// mediaLRU keeps small, frequently requested originals in memory. Entries are
+// keyed by storage key; replacing an upload changes its file name, so stale
+// entries simply age out.
+type mediaLRU struct {
+	mu           sync.Mutex
+	maxBytes     int64
+	maxItemBytes int64
+	usedBytes    int64
+	order        *list.List
+	items        map[string]*list.Element
+}
+
+type mediaLRUEntry struct {
+	key     string
+	data    []byte
+	modTime time.Time
+}
+
+func newMediaLRU(maxBytes, maxItemBytes int64) *mediaLRU {
+	return &mediaLRU{
+		maxBytes:     maxBytes,
+		maxItemBytes: min(maxItemBytes, maxBytes),
+		order:        list.New(),
+		items:        map[string]*list.Element{},
+	}
+}
+
+func (c *mediaLRU) Fits(size int64) bool {
+	return size >= 0 && size <= c.maxItemBytes
+}
+
+func (c *mediaLRU) Get(key string) (mediaLRUEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return mediaLRUEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	return *element.Value.(*mediaLRUEntry), true
+}
+
+func (c *mediaLRU) Add(key string, data []byte, modTime time.Time) {
+	if !c.Fits(int64(len(data))) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[key]; ok {
+		c.usedBytes -= int64(len(element.Value.(*mediaLRUEntry).data))
+		c.order.Remove(element)
+	}
+	c.items[key] = c.order.PushFront(&mediaLRUEntry{key: key, data: data, modTime: modTime})
+	c.usedBytes += int64(len(data))
+	for c.usedBytes > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*mediaLRUEntry)
+		c.order.Remove(oldest)
+		delete(c.items, entry.key)
+		c.usedBytes -= int64(len(entry.data))
+	}
+}
+
+// setMediaResponseHeaders prepares headers for http.ServeContent, which then
+// answers Range, If-None-Match and If-Modified-Since. Checksum paths
+// (/uploads/<checksum>.ext) never change content, so they are immutable;
+// other paths revalidate against the checksum ETag daily.
+func setMediaResponseHeaders(w http.ResponseWriter, media MediaRecord) {
+	header := w.Header()
+	if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(media.File))); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	checksum := strings.ToLower(strings.TrimSpace(media.Checksum))
+	if checksum == "" {
+		header.Set("Cache-Control", "public, max-age=86400")
+		return
+	}
+	header.Set("ETag", `"`+checksum+`"`)
+	if strings.Contains(strings.ToLower(media.Path), checksum) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+		return
+	}
+	header.Set("Cache-Control", "public, max-age=86400")
+}
+
+func serveMediaOriginal(w http.ResponseWriter, r *http.Request, media MediaRecord) bool {
+	return serveStoredMedia(w, r, activeMediaStore(), mediaFileCache, media)
+}
+
+func serveStoredMedia(w http.ResponseWriter, r *http.Request, store mediaStore, cache *mediaLRU, media MediaRecord) bool {
+	key := mediaStorageKey(media)
+	if entry, ok := cache.Get(key); ok {
+		setMediaResponseHeaders(w, media)
+		http.ServeContent(w, r, media.File, entry.modTime, bytes.NewReader(entry.data))
+		return true
+	}
+
+	object, err := store.Open(key)
+	if err != nil {
+		if !errors.Is(err, errMediaNotFound) {
+			slog.Warn("media original unavailable", "key", key, "error", err)
+		}
+		return false
+	}
+	defer func() {
+		_ = object.Close()
+	}()
+
+	var content io.ReadSeeker = object
+	if cache.Fits(object.Size()) {
+		data, err := io.ReadAll(object)
+		if err != nil {
+			slog.Warn("media original read failed", "key", key, "error", err)
+			return false
+		}
+		cache.Add(key, data, object.ModTime())
+		content = bytes.NewReader(data)
+	}
+	setMediaResponseHeaders(w, media)
+	http.ServeContent(w, r, media.File, object.ModTime(), content)
+	return true
+}
+
+func readMediaOriginal(media MediaRecord) ([]byte, error) {
+	key := mediaStorageKey(media)
+	if entry, ok := mediaFileCache.Get(key); ok {
+		return entry.data, nil
+	}
+	object, err := activeMediaStore().Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = object.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(object, maxMediaOriginalBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxMediaOriginalBytes {
+		return nil, errors.New("media original too large")
+	}
+	return data, nil
+}
@@ -0,0 +1,225 @@
+package site
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMediaModTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func testMediaRecord() (MediaRecord, []byte) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	return MediaRecord{
+		ID:       "media123",
+		File:     "photo_abc123.png",
+		Path:     "/uploads/deadbeef.png",
+		Checksum: "deadbeef",
+	}, content
+}
+
+// fakeObjectServer serves objects under prefix the way S3 and PocketBase's
+// file API do, including Range and Last-Modified.
+func fakeObjectServer(t *testing.T, prefix string, objects map[string][]byte) (*httptest.Server, *[]string) {
+	t.Helper()
+	ranges := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := objects[strings.TrimPrefix(r.URL.Path, prefix)]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "image/png")
+		http.ServeContent(w, r, "", testMediaModTime, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &ranges
+}
+
+func serveTestMedia(store mediaStore, cache *mediaLRU, media MediaRecord, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "https://example.com"+media.Path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	if !serveStoredMedia(rec, req, store, cache, media) {
+		rec.Code = http.StatusNotFound
+	}
+	return rec
+}
+
+func assertMediaResponses(t *testing.T, store mediaStore, cache *mediaLRU, media MediaRecord, content []byte) {
+	t.Helper()
+
+	rec := serveTestMedia(store, cache, media, nil)
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), content) {
+		t.Fatalf("full response = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("ETag"); got != `"deadbeef"` {
+		t.Fatalf("ETag = %q", got)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+		t.Fatalf("Cache-Control = %q", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	rec = serveTestMedia(store, cache, media, http.Header{"Range": {"bytes=10-15"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "abcdef" {
+		t.Fatalf("range response = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 10-15/36" {
+		t.Fatalf("Content-Range = %q", got)
+	}
+
+	rec = serveTestMedia(store, cache, media, http.Header{"If-None-Match": {`"deadbeef"`}})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match status = %d, want 304", rec.Code)
+	}
+
+	rec = serveTestMedia(store, cache, media, http.Header{"If-Modified-Since": {testMediaModTime.Add(time.Hour).Format(http.TimeFormat)}})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since status = %d, want 304", rec.Code)
+	}
+
+	missing := media
+	missing.File = "missing.png"
+	if rec := serveTestMedia(store, cache, missing, nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing file status = %d, want 404", rec.Code)
+	}
+}
+
+func TestFilesystemMediaStore(t *testing.T) {
+	t.Parallel()
+
+	media, content := testMediaRecord()
+	dir := t.TempDir()
+	target := filepath.Join(dir, filepath.FromSlash(mediaStorageKey(media)))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(target, testMediaModTime, testMediaModTime); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := newMediaStore(mediaStorageConfig{Backend: mediaStorageFS, Dir: dir})
+	if err != nil {
+		t.Fatalf("newMediaStore: %v", err)
+	}
+	assertMediaResponses(t, store, newMediaLRU(0, 0), media, content)
+
+	if _, err := newMediaStore(mediaStorageConfig{Backend: mediaStorageFS, Dir: filepath.Join(dir, "missing")}); err == nil {
+		t.Fatalf("a missing storage dir should be rejected")
+	}
+}
+
+func TestS3MediaStore(t *testing.T) {
+	t.Parallel()
+
+	media, content := testMediaRecord()
+	server, ranges := fakeObjectServer(t, "/media-bucket/", map[string][]byte{mediaStorageKey(media): content})
+	store, err := newMediaStore(mediaStorageConfig{
+		Backend:          mediaStorageS3,
+		S3Bucket:         "media-bucket",
+		S3Region:         "us-east-1",
+		S3Endpoint:       server.URL,
+		S3AccessKey:      "minio",
+		S3Secret:         "minio123",
+		S3ForcePathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("newMediaStore: %v", err)
+	}
+	assertMediaResponses(t, store, newMediaLRU(0, 0), media, content)
+
+	sawRange := false
+	for _, value := range *ranges {
+		sawRange = sawRange || value == "bytes=10-"
+	}
+	if !sawRange {
+		t.Fatalf("range responses should read from the object offset, got %q", *ranges)
+	}
+}
+
+func TestPocketBaseMediaStore(t *testing.T) {
+	t.Parallel()
+
+	media, content := testMediaRecord()
+	server, ranges := fakeObjectServer(t, "/api/files/", map[string][]byte{mediaStorageKey(media): content})
+	store := pocketBaseMediaStore{baseURL: server.URL}
+	assertMediaResponses(t, store, newMediaLRU(0, 0), media, content)
+
+	sawRange := false
+	for _, value := range *ranges {
+		sawRange = sawRange || value == "bytes=10-"
+	}
+	if !sawRange {
+		t.Fatalf("range responses should reopen at the offset, got %q", *ranges)
+	}
+
+	object, err := store.Open(mediaStorageKey(media))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+	if _, err := object.Seek(-6, io.SeekEnd); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	tail, err := io.ReadAll(object)
+	if err != nil || string(tail) != "uvwxyz" {
+		t.Fatalf("tail = %q (%v)", tail, err)
+	}
+}
+
+func TestServeStoredMediaUsesCache(t *testing.T) {
+	t.Parallel()
+
+	media, content := testMediaRecord()
+	server, ranges := fakeObjectServer(t, "/api/files/", map[string][]byte{mediaStorageKey(media): content})
+	store := pocketBaseMediaStore{baseURL: server.URL}
+	cache := newMediaLRU(1<<10, 1<<10)
+
+	assertMediaResponses(t, store, cache, media, content)
+	// After the first request every response comes from memory.
+	if len(*ranges) != 1 {
+		t.Fatalf("store requests = %d, want 1", len(*ranges))
+	}
+}
+
+func TestMediaLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	cache := newMediaLRU(10, 4)
+	cache.Add("a", []byte("aaaa"), time.Time{})
+	cache.Add("b", []byte("bbbb"), time.Time{})
+	cache.Add("huge", []byte("hugefile"), time.Time{})
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("files above the item limit should not be cached")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("a should be cached")
+	}
+	cache.Add("c", []byte("cccc"), time.Time{})
+	if _, ok := cache.Get("b"); ok {
+		t.Fatalf("b should be evicted as least recently used")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("a should survive eviction")
+	}
+	if cache.usedBytes != 8 {
+		t.Fatalf("usedBytes = %d, want 8", cache.usedBytes)
+	}
+}
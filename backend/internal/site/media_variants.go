@@ -0,0 +1,376 @@
+package site
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	mediaVariantWebPQuality = 82
+	mediaVariantAVIFQuality = 60
+	maxMediaOriginalBytes   = 50 << 20
+	mediaVariantFailureTTL  = 10 * time.Minute
+	// responsiveImageSizes matches the max-width of <main> in the built-in
+	// theme.
+	responsiveImageSizes = "(max-width: 1100px) 100vw, 1100px"
+)
+
+var mediaVariantWidths = parseMediaVariantWidths(getEnv("MEDIA_VARIANT_WIDTHS", "480,960,1600"))
+
+var mediaVariantPathPattern = regexp.MustCompile(`^(/uploads/.+)-w([0-9]+)\.(webp|avif)$`)
+var imgTagPattern = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+var imgSrcAttrPattern = regexp.MustCompile(`(?is)\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+var imgAttrPatterns = map[string]*regexp.Regexp{
+	"width":   regexp.MustCompile(`(?i)\swidth\s*=`),
+	"height":  regexp.MustCompile(`(?i)\sheight\s*=`),
+	"loading": regexp.MustCompile(`(?i)\sloading\s*=`),
+	"srcset":  regexp.MustCompile(`(?i)\ssrcset\s*=`),
+}
+
+var avifEncoderAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("avifenc")
+	return err == nil
+})
+
+var webpEncoderAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("cwebp")
+	return err == nil
+})
+
+var mediaVariantGroup singleflight.Group
+
+// mediaVariantFailures remembers variants that failed to build, keyed by
+// target file, so a broken original is not decoded again on every request.
+var mediaVariantFailures = struct {
+	mu    sync.Mutex
+	items map[string]time.Time
+}{items: map[string]time.Time{}}
+
+func parseMediaVariantWidths(raw string) []int {
+	widths := []int{}
+	for _, part := range strings.Split(raw, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || width <= 0 || width > 4096 || slices.Contains(widths, width) {
+			continue
+		}
+		widths = append(widths, width)
+	}
+	slices.Sort(widths)
+	return widths
+}
+
+// mediaVariantPath is the public URL of a resized copy of an upload, e.g.
+// /uploads/abc.webp -> /uploads/abc-w960.webp.
+func mediaVariantPath(uploadPath string, width int, format string) string {
+	return strings.TrimSuffix(uploadPath, path.Ext(uploadPath)) + "-w" + strconv.Itoa(width) + "." + format
+}
+
+func parseMediaVariantPath(clean string) (stem string, width int, format string, ok bool) {
+	match := mediaVariantPathPattern.FindStringSubmatch(clean)
+	if len(match) < 4 {
+		return "", 0, "", false
+	}
+	width, err := strconv.Atoi(match[2])
+	if err != nil || width <= 0 {
+		return "", 0, "", false
+	}
+	return match[1], width, match[3], true
+}
+
+// mediaSrcset lists the configured widths below the original plus the
+// original width itself, which for WebP is the stored file.
+func mediaSrcset(src string, media MediaRecord, format string) string {
+	if media.Width <= 0 {
+		return ""
+	}
+	parts := []string{}
+	for _, width := range mediaVariantWidths {
+		if width >= media.Width {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %dw", mediaVariantPath(src, width, format), width))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	full := src
+	if format != "webp" || !strings.EqualFold(path.Ext(src), ".webp") {
+		full = mediaVariantPath(src, media.Width, format)
+	}
+	return strings.Join(append(parts, fmt.Sprintf("%s %dw", full, media.Width)), ", ")
+}
+
+func imgSrc(tag string) string {
+	match := imgSrcAttrPattern.FindStringSubmatch(tag)
+	if len(match) < 3 {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(match[1] + match[2]))
+}
+
+// responsiveImages adds intrinsic size, lazy loading and srcset to <img>
+// tags that point at media uploads.
+func responsiveImages(body string) string {
+	tags := imgTagPattern.FindAllString(body, -1)
+	paths := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if src := imgSrc(tag); strings.HasPrefix(src, "/uploads/") {
+			paths = append(paths, src)
+		}
+	}
+	if len(paths) == 0 {
+		return body
+	}
+	media := getMediaByPaths(paths)
+	return imgTagPattern.ReplaceAllStringFunc(body, func(tag string) string {
+		item, ok := media[imgSrc(tag)]
+		if !ok {
+			return tag
+		}
+		return responsiveImageTag(tag, item)
+	})
+}
+
+func responsiveImageTag(tag string, media MediaRecord) string {
+	if imgAttrPatterns["srcset"].MatchString(tag) {
+		return tag
+	}
+	src := imgSrc(tag)
+	attrs := []string{}
+	if media.Width > 0 && media.Height > 0 && !imgAttrPatterns["width"].MatchString(tag) && !imgAttrPatterns["height"].MatchString(tag) {
+		attrs = append(attrs, fmt.Sprintf(`width="%d" height="%d"`, media.Width, media.Height))
+	}
+	if !imgAttrPatterns["loading"].MatchString(tag) {
+		attrs = append(attrs, `loading="lazy"`)
+	}
+	srcset := mediaSrcset(src, media, "webp")
+	if srcset != "" {
+		attrs = append(attrs, fmt.Sprintf(`srcset="%s" sizes="%s"`, escapeHTML(srcset), responsiveImageSizes))
+	}
+	out := tag
+	if len(attrs) > 0 {
+		closing := ">"
+		trimmed := strings.TrimSuffix(tag, ">")
+		if strings.HasSuffix(trimmed, "/") {
+			closing = " />"
+			trimmed = strings.TrimSuffix(trimmed, "/")
+		}
+		out = strings.TrimRight(trimmed, " \t\n") + " " + strings.Join(attrs, " ") + closing
+	}
+	if srcset == "" || !avifEncoderAvailable() {
+		return out
+	}
+	avif := mediaSrcset(src, media, "avif")
+	return fmt.Sprintf(`<picture><source type="image/avif" srcset="%s" sizes="%s" />%s</picture>`, escapeHTML(avif), responsiveImageSizes, out)
+}
+
+func serveMediaVariant(w http.ResponseWriter, r *http.Request, clean string) bool {
+	stem, width, format, ok := parseMediaVariantPath(clean)
+	if !ok {
+		return false
+	}
+	var media *MediaRecord
+	for _, ext := range []string{".webp", ".jpg", ".jpeg", ".png"} {
+		if media = getMediaByPath(stem + ext); media != nil {
+			break
+		}
+	}
+	if media == nil || media.File == "" {
+		return false
+	}
+	if !slices.Contains(mediaVariantWidths, width) && width != media.Width {
+		return false
+	}
+	if !mediaVariantEncoderAvailable(format) {
+		// AVIF is only offered in <picture> when avifenc exists; WebP srcsets
+		// are always written, so they fall back to the original.
+		if format == "avif" {
+			return false
+		}
+		return serveMediaOriginal(w, r, *media)
+	}
+	target, err := ensureMediaVariant(*media, width, format)
+	if err != nil {
+		slog.Warn("media variant unavailable; serving original", "path", clean, "error", err)
+		return serveMediaOriginal(w, r, *media)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFile(w, r, target)
+	return true
+}
+
+// ensureMediaVariant returns the cached variant file, generating it on first
+// request. Variants live under mediaVariantDir/<media id>/ and are keyed by
+// the stored file name, so replacing the upload invalidates them.
+func ensureMediaVariant(media MediaRecord, width int, format string) (string, error) {
+	stem := strings.TrimSuffix(filepath.Base(media.File), filepath.Ext(media.File))
+	dir := filepath.Join(mediaVariantDir, filepath.Base(media.ID))
+	target := filepath.Join(dir, fmt.Sprintf("%s-w%d.%s", stem, width, format))
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return target, nil
+	}
+	mediaVariantFailures.mu.Lock()
+	failedUntil, failed := mediaVariantFailures.items[target]
+	mediaVariantFailures.mu.Unlock()
+	if failed && time.Now().Before(failedUntil) {
+		return "", errors.New("media variant failed recently")
+	}
+	_, err, _ := mediaVariantGroup.Do(target, func() (any, error) {
+		return nil, buildMediaVariant(media, width, format, dir, stem, target)
+	})
+	mediaVariantFailures.mu.Lock()
+	if err != nil {
+		mediaVariantFailures.items[target] = time.Now().Add(mediaVariantFailureTTL)
+	} else {
+		delete(mediaVariantFailures.items, target)
+	}
+	mediaVariantFailures.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+func mediaVariantEncoderAvailable(format string) bool {
+	switch format {
+	case "webp":
+		return webpEncoderAvailable()
+	case "avif":
+		return avifEncoderAvailable()
+	default:
+		return false
+	}
+}
+
+func buildMediaVariant(media MediaRecord, width int, format, dir, stem, target string) error {
+	original, err := readMediaOriginal(media)
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return fmt.Errorf("decode media original: %w", err)
+	}
+	encoded, err := encodeMediaVariant(resizeImageToWidth(img, width), format)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	pruneStaleMediaVariants(dir, stem)
+	tmp, err := os.CreateTemp(dir, ".variant-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(encoded); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func pruneStaleMediaVariants(dir, stem string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), stem+"-w") && !strings.HasPrefix(entry.Name(), ".") {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
+// resizeImageToWidth scales down to width, keeping the aspect ratio. Images
+// are never upscaled.
+func resizeImageToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if width >= bounds.Dx() {
+		return img
+	}
+	height := max(1, int(math.Round(float64(bounds.Dy())*float64(width)/float64(bounds.Dx()))))
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Src, nil)
+	return dst
+}
+
+func encodeMediaVariant(img image.Image, format string) ([]byte, error) {
+	switch format {
+	case "webp":
+		return runImageEncoder(img, "output.webp", "cwebp", "-quiet", "-q", strconv.Itoa(mediaVariantWebPQuality), "-metadata", "none", "{in}", "-o", "{out}")
+	case "avif":
+		return runImageEncoder(img, "output.avif", "avifenc", "--speed", "6", "-q", strconv.Itoa(mediaVariantAVIFQuality), "{in}", "{out}")
+	default:
+		return nil, fmt.Errorf("unsupported media variant format %q", format)
+	}
+}
+
+func runImageEncoder(img image.Image, outputName, command string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("%s command not found: %w", command, err)
+	}
+	dir, err := os.MkdirTemp("", "alleycat-variant-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	inputPath := filepath.Join(dir, "input.png")
+	outputPath := filepath.Join(dir, outputName)
+	input := bytes.Buffer{}
+	if err := png.Encode(&input, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(inputPath, input.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+	resolved := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "{in}":
+			arg = inputPath
+		case "{out}":
+			arg = outputPath
+		}
+		resolved = append(resolved, arg)
+	}
+	if out, err := exec.Command(command, resolved...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(string(out)))
+	}
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(output) == 0 {
+		return nil, errors.New(command + ": empty output")
+	}
+	return output, nil
+}
@@ -0,0 +1,93 @@
+package site
+
+import (
+	"image"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMediaVariantWidths(t *testing.T) {
+	got := parseMediaVariantWidths("960, 480,abc,-1,960,99999,1600")
+	want := []int{480, 960, 1600}
+	if !slices.Equal(got, want) {
+		t.Fatalf("parseMediaVariantWidths = %v, want %v", got, want)
+	}
+}
+
+func TestMediaVariantPathRoundTrip(t *testing.T) {
+	variant := mediaVariantPath("/uploads/2024/photo.webp", 960, "avif")
+	if variant != "/uploads/2024/photo-w960.avif" {
+		t.Fatalf("mediaVariantPath = %q", variant)
+	}
+	stem, width, format, ok := parseMediaVariantPath(variant)
+	if !ok || stem != "/uploads/2024/photo" || width != 960 || format != "avif" {
+		t.Fatalf("parseMediaVariantPath(%q) = %q, %d, %q, %v", variant, stem, width, format, ok)
+	}
+	if _, _, _, ok := parseMediaVariantPath("/uploads/photo.webp"); ok {
+		t.Fatalf("plain upload path should not parse as a variant")
+	}
+}
+
+func TestResponsiveImageTag(t *testing.T) {
+	prev := mediaVariantWidths
+	mediaVariantWidths = []int{480, 960, 1600}
+	t.Cleanup(func() { mediaVariantWidths = prev })
+
+	media := MediaRecord{Path: "/uploads/photo.webp", Width: 1200, Height: 800}
+	got := responsiveImageTag(`<img src="/uploads/photo.webp" alt="x">`, media)
+	for _, want := range []string{
+		`width="1200" height="800"`,
+		`loading="lazy"`,
+		`srcset="/uploads/photo-w480.webp 480w, /uploads/photo-w960.webp 960w, /uploads/photo.webp 1200w"`,
+		`sizes="` + responsiveImageSizes + `"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("responsiveImageTag missing %q in %s", want, got)
+		}
+	}
+
+	explicit := `<img src="/uploads/photo.webp" width="10" height="10" loading="eager">`
+	got = responsiveImageTag(explicit, media)
+	if strings.Count(got, "width=") != 1 || strings.Contains(got, `loading="lazy"`) {
+		t.Fatalf("existing attributes should be kept: %s", got)
+	}
+
+	small := MediaRecord{Path: "/uploads/icon.webp", Width: 320, Height: 320}
+	got = responsiveImageTag(`<img src="/uploads/icon.webp" />`, small)
+	if strings.Contains(got, "srcset") || !strings.HasSuffix(got, " />") {
+		t.Fatalf("images narrower than every variant should not get a srcset: %s", got)
+	}
+}
+
+func TestResizeImageToWidth(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	got := resizeImageToWidth(src, 400).Bounds()
+	if got.Dx() != 400 || got.Dy() != 200 {
+		t.Fatalf("resized bounds = %v, want 400x200", got)
+	}
+	if resizeImageToWidth(src, 2000).Bounds().Dx() != 1000 {
+		t.Fatalf("resize should not upscale")
+	}
+}
+
+func TestEnsureMediaVariantSkipsRecentFailures(t *testing.T) {
+	t.Parallel()
+
+	media := MediaRecord{ID: "variant-failure-test", File: "broken.png"}
+	target := filepath.Join(mediaVariantDir, media.ID, "broken-w480.webp")
+	mediaVariantFailures.mu.Lock()
+	mediaVariantFailures.items[target] = time.Now().Add(time.Minute)
+	mediaVariantFailures.mu.Unlock()
+	t.Cleanup(func() {
+		mediaVariantFailures.mu.Lock()
+		delete(mediaVariantFailures.items, target)
+		mediaVariantFailures.mu.Unlock()
+	})
+
+	if _, err := ensureMediaVariant(media, 480, "webp"); err == nil || !strings.Contains(err.Error(), "failed recently") {
+		t.Fatalf("ensureMediaVariant error = %v, want the cached failure", err)
+	}
+}
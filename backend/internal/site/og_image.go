@@ -127,7 +127,7 @@ func extractLocaleFromPostPath(path string) string {
 	return ""
 }
 
-func servePostOGImage(w http.ResponseWriter, path string, settings SettingsRecord) bool {
+func servePostOGImage(w http.ResponseWriter, r *http.Request, path string, settings SettingsRecord) bool {
 	locale, slug, ok := extractPostOGImageRequest(path)
 	if !ok {
 		return false
@@ -154,10 +154,7 @@ func servePostOGImage(w http.ResponseWriter, path string, settings SettingsRecor
 	if err != nil {
 		return false
 	}
-	w.Header().Set("Content-Type", "image/png")
-	w.Header().Set("Cache-Control", "public, max-age=3600")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(body)
+	writeCacheableBody(w, r, ogImageCacheControl, "image/png", body, postPublishedTime(*post))
 	return true
 }
 
@@ -126,7 +126,7 @@ func TestServePostOGImageDoesNotRequireFeatureFlag(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	err := withSnapshotBuildContext(ctx, func() error {
-		ok := servePostOGImage(rec, "/og/posts/self-hosted-edgedns-research.png", SettingsRecord{
+		ok := servePostOGImage(rec, nil, "/og/posts/self-hosted-edgedns-research.png", SettingsRecord{
 			SiteName:     "Alleycat",
 			SiteLanguage: "ja",
 		})
@@ -183,7 +183,7 @@ func TestServePostOGImageUsesSourceLocaleForLocalizedSourceRoute(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	err := withSnapshotBuildContext(ctx, func() error {
-		ok := servePostOGImage(rec, "/og/ja/posts/starlinkconoha-vpstcp.png", SettingsRecord{
+		ok := servePostOGImage(rec, nil, "/og/ja/posts/starlinkconoha-vpstcp.png", SettingsRecord{
 			SiteName:                "Alleycat",
 			SiteLanguage:            "ja",
 			TranslationSourceLocale: "ja",
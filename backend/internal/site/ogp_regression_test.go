@@ -83,7 +83,7 @@ func TestSourceLocaleRegressionLocalizedOGImageRouteServesPNG(t *testing.T) {
 	rec := httptest.NewRecorder()
 
 	err := withSnapshotBuildContext(ctx, func() error {
-		ok := servePostOGImage(rec, "/og/ja/posts/starlinkconoha-vpstcp.png", sourceLocaleRegressionSettings())
+		ok := servePostOGImage(rec, nil, "/og/ja/posts/starlinkconoha-vpstcp.png", sourceLocaleRegressionSettings())
 		if !ok {
 			t.Fatalf("servePostOGImage should resolve /og/ja/posts/... for source locale posts")
 		}
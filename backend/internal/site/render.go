@@ -3,8 +3,10 @@ package site
 import (
 	"fmt"
 	"html"
+	"html/template"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -59,137 +61,21 @@ func themeFontStylesheet(themeOverride string) string {
 	}
 }
 
-func highlightStylesheets(settings SettingsRecord) (string, string) {
-	switch strings.ToLower(strings.TrimSpace(settings.HighlightTheme)) {
-	case "github":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css"
-	case "atom-one-dark", "atom-one-light":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/atom-one-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/atom-one-light.min.css"
-	case "tokyo-night-dark", "tokyo-night-light":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/tokyo-night-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/tokyo-night-light.min.css"
-	case "solarized-dark", "solarized-light":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/solarized-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/solarized-light.min.css"
-	case "monokai":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/monokai.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css"
-	case "dracula":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/dracula.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css"
-	case "vs":
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/vs.min.css"
-	default:
-		return "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github-dark.min.css",
-			"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css"
-	}
-}
-
 func renderHead(title string, settings SettingsRecord) string {
 	return renderHeadWithExtras(title, settings, "")
 }
 
 func renderHeadWithExtras(title string, settings SettingsRecord, extraHead string) string {
-	pageTitle := escapeHTML(title) + " - " + escapeHTML(settings.SiteName)
-	styles := themeStylesheet(settings.Theme)
-	themeStyles, splitCriticalStyles := splitThemeStylesheetForHead(styles)
+	return renderThemeTemplate(settings, "head.html", newHeadView(title, siteUILocale(settings), settings, extraHead))
+}
+
+func newHeadView(title, locale string, settings SettingsRecord, extraHead string) headView {
+	themeStyles, splitCriticalStyles := splitThemeStylesheetForHead(themeFor(settings).stylesheet)
 	fontStylesheet := themeFontStylesheet(settings.Theme)
-	commonContentStyles := criticalBaseStyles + splitCriticalStyles + `
-    <style>
-    .body pre,
-    .body code {
-      font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, "Liberation Mono", monospace;
-    }
-    .body :not(pre) > code {
-      white-space: pre-wrap;
-      overflow-wrap: anywhere;
-      word-break: break-word;
-      padding: 0.12rem 0.38rem;
-      border-radius: 6px;
-      background: rgba(127, 127, 127, 0.12);
-      font-size: 0.92em;
-    }
-    .body pre {
-      margin: 1rem 0;
-      border: 1px solid rgba(127, 127, 127, 0.24);
-      border-radius: 10px;
-      background: rgba(127, 127, 127, 0.08);
-      overflow-x: auto;
-    }
-    .body pre code {
-      display: block;
-      padding: 0;
-      background: transparent;
-      white-space: pre;
-      overflow-wrap: normal;
-      word-break: normal;
-      line-height: 1.65;
-    }
-    .post-related {
-      margin-top: 1.5rem;
-      padding: 1rem;
-      border: 1px solid rgba(127, 127, 127, 0.24);
-      border-radius: 10px;
-    }
-    .post-related-list {
-      margin: 0;
-      padding-left: 1.25rem;
-      display: grid;
-      gap: 0.5rem;
-    }
-    .post-related-list li p {
-      margin: 0.2rem 0 0;
-      opacity: 0.75;
-      font-size: 0.9rem;
-    }
-    .post-comments {
-      margin-top: 1.5rem;
-      padding-top: 0.5rem;
-    }
-    .post-toc {
-      margin: 1rem 0 1.2rem;
-      padding: 0.85rem 1rem;
-      border: 1px solid rgba(127, 127, 127, 0.24);
-      border-radius: 10px;
-    }
-    .post-toc h2 {
-      margin: 0 0 0.5rem;
-      font-size: 1rem;
-    }
-    .post-toc ul {
-      margin: 0;
-      padding-left: 1.15rem;
-      display: grid;
-      gap: 0.3rem;
-    }
-    .post-toc li[data-level="2"] {
-      opacity: 0.96;
-    }
-    .post-toc li[data-level="3"] {
-      margin-left: 0.75rem;
-      opacity: 0.9;
-    }
-    .post-toc li[data-level="4"] {
-      margin-left: 1.5rem;
-      opacity: 0.85;
-    }
-    .post-toc li[data-level="5"] {
-      margin-left: 2.25rem;
-      opacity: 0.8;
-    }
-    .post-toc li[data-level="6"] {
-      margin-left: 3rem;
-      opacity: 0.75;
-    }
-    </style>`
 	fontStyles := ""
 	if fontStylesheet != "" {
 		fontStyles = "<link rel=\"preconnect\" href=\"https://fonts.googleapis.com\" />\n    <link rel=\"preconnect\" href=\"https://fonts.gstatic.com\" crossorigin />\n    " + asyncStylesheetTag(fontStylesheet)
 	}
-	metaDesc := escapeHTML(settings.Description)
 	analytics := ""
 	if settings.EnableAnalytics && settings.AnalyticsURL != "" && settings.AnalyticsSiteID != "" {
 		analytics = fmt.Sprintf("<script defer src=\"%s\" data-website-id=\"%s\"></script>", escapeHTML(settings.AnalyticsURL), escapeHTML(settings.AnalyticsSiteID))
@@ -200,36 +86,45 @@ func renderHeadWithExtras(title string, settings SettingsRecord, extraHead strin
 	}
 	codeHighlight := ""
 	if settings.EnableCodeHighlight {
-		highlightDarkCSS, highlightLightCSS := highlightStylesheets(settings)
-		codeHighlight = fmt.Sprintf("<link rel=\"preconnect\" href=\"https://cdnjs.cloudflare.com\" crossorigin />\n    <link rel=\"preload\" href=\"%s\" as=\"style\" />\n    <link rel=\"preload\" href=\"%s\" as=\"style\" />\n    <link id=\"hljs-theme-link\" rel=\"stylesheet\" href=\"%s\" data-theme-dark=\"%s\" data-theme-light=\"%s\" />\n    <script defer src=\"https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/highlight.min.js\"></script>\n    <script>window.addEventListener('DOMContentLoaded',()=>{if(window.hljs){window.hljs.highlightAll();}});</script>", highlightDarkCSS, highlightLightCSS, highlightDarkCSS, highlightDarkCSS, highlightLightCSS)
-	}
-	if strings.TrimSpace(extraHead) != "" {
-		extraHead = strings.TrimSpace(extraHead)
-	}
-	feedAlternates := renderFeedAlternates(settings)
-
-	return fmt.Sprintf(`<!doctype html>
-<html lang="%s">
-  <head>
-    <meta charset="UTF-8" />
-    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
-    <title>%s</title>
-    <meta name="supported-color-schemes" content="light dark" />
-    <meta name="theme-color" content="hsl(220, 20%%, 100%%)" media="(prefers-color-scheme: light)" />
-    <meta name="theme-color" content="hsl(220, 20%%, 10%%)" media="(prefers-color-scheme: dark)" />
-    %s
-    %s
-    %s
-    %s
-    <link rel="icon" type="image/png" sizes="32x32" href="/favicon.png" />
-    <meta name="description" content="%s" />
-    <meta name="robots" content="max-image-preview:large" />
-    %s
-    %s
-    %s
-    %s
-  </head>
-  <body>`, escapeHTML(settings.SiteLanguage), pageTitle, themeStyles, fontStyles, commonContentStyles, feedAlternates, metaDesc, analytics, ads, codeHighlight, extraHead)
+		codeHighlight = highlightThemeCSS(settings)
+	}
+
+	return headView{
+		Lang:           locale,
+		Dir:            localeDirection(locale),
+		Title:          title,
+		SiteName:       settings.SiteName,
+		Description:    settings.Description,
+		ThemeStyles:    template.HTML(themeStyles),
+		FontStyles:     template.HTML(fontStyles),
+		CriticalStyles: template.HTML(criticalBaseStyles + splitCriticalStyles),
+		FeedAlternates: template.HTML(renderFeedAlternates(settings)),
+		Analytics:      template.HTML(analytics),
+		Ads:            template.HTML(ads),
+		CodeHighlight:  template.HTML(codeHighlight),
+		Extra:          template.HTML(strings.TrimSpace(extraHead)),
+	}
+}
+
+func newThemeLayout(title string, menu []PageRecord, settings SettingsRecord, extraHead string) themeLayout {
+	return newLocalizedThemeLayout(title, siteUILocale(settings), menu, settings, extraHead)
+}
+
+// newLocalizedThemeLayout renders the page chrome in locale: the html lang
+// and dir attributes and the UI text the templates read from .Text.
+func newLocalizedThemeLayout(title, locale string, menu []PageRecord, settings SettingsRecord, extraHead string) themeLayout {
+	text := uiTextFor(settings, locale)
+	nav := newNavView(menu, settings)
+	nav.Text = text
+	prefix := listingPathPrefix(settings, locale)
+	nav.HomeURL = prefix + "/"
+	nav.ArchiveURL = prefix + "/archive/"
+	return themeLayout{
+		Head:   newHeadView(title, locale, settings, extraHead),
+		Nav:    nav,
+		Footer: footerView{FooterHTML: template.HTML(strings.TrimSpace(settings.FooterHTML))},
+		Text:   text,
+	}
 }
 
 type postMetaInput struct {
@@ -238,6 +133,13 @@ type postMetaInput struct {
 	Title       string
 	Description string
 	PublishedAt string
+	Author      *AuthorRecord
+	// Image is the featured image path, used for structured data when no OG
+	// image is generated.
+	Image string
+	// SourcePath and SourceLocale identify the source post of a translation.
+	SourcePath   string
+	SourceLocale string
 }
 
 func renderPostMetaTags(input postMetaInput, settings SettingsRecord) string {
@@ -274,6 +176,11 @@ func renderPostMetaTags(input postMetaInput, settings SettingsRecord) string {
 	if publishedAt := strings.TrimSpace(input.PublishedAt); publishedAt != "" {
 		parts = append(parts, fmt.Sprintf(`<meta property="article:published_time" content="%s" />`, escapeHTML(publishedAt)))
 	}
+	if input.Author != nil {
+		name := defaultString(strings.TrimSpace(input.Author.DisplayName), input.Author.Slug)
+		parts = append(parts, fmt.Sprintf(`<meta name="author" content="%s" />`, escapeHTML(name)))
+	}
+	imageURL := ""
 	if settings.EnableOGPImageGeneration {
 		imageLocale := extractLocaleFromPostPath(input.Path)
 		if imageLocale == "" {
@@ -283,7 +190,7 @@ func renderPostMetaTags(input postMetaInput, settings SettingsRecord) string {
 				imageLocale = normalizeLocale(settings.SiteLanguage)
 			}
 		}
-		imageURL := buildAbsoluteSiteURL(settings, postOGImageRoute(imageLocale, extractSlugFromPostPath(input.Path)))
+		imageURL = buildAbsoluteSiteURL(settings, postOGImageRoute(imageLocale, extractSlugFromPostPath(input.Path)))
 		if imageURL == "" {
 			imageURL = postOGImageRoute(imageLocale, extractSlugFromPostPath(input.Path))
 		}
@@ -295,61 +202,27 @@ func renderPostMetaTags(input postMetaInput, settings SettingsRecord) string {
 			fmt.Sprintf(`<meta name="twitter:image:alt" content="%s" />`, escapeHTML(strings.TrimSpace(input.Title))),
 		)
 	}
+	if jsonLD := renderPostJSONLD(input, canonicalURL, imageURL, settings); jsonLD != "" {
+		parts = append(parts, jsonLD)
+	}
 
 	return strings.Join(parts, "\n    ")
 }
 
 func renderNav(menu []PageRecord, settings SettingsRecord) string {
-	links := strings.Builder{}
+	return renderThemeTemplate(settings, "nav.html", newNavView(menu, settings))
+}
+
+func newNavView(menu []PageRecord, settings SettingsRecord) navView {
+	links := make([]navLink, 0, len(menu))
 	for _, page := range menu {
 		label := page.MenuTitle
 		if strings.TrimSpace(label) == "" {
 			label = page.Title
 		}
-		links.WriteString(fmt.Sprintf(`        <li><a href="%s">%s</a></li>`, escapeHTML(page.URL), escapeHTML(label)))
+		links = append(links, navLink{URL: page.URL, Label: label})
 	}
-	return fmt.Sprintf(`<nav class="navbar">
-      <a href="/" class="navbar-home">
-        <strong>%s</strong>
-      </a>
-
-      <ul class="navbar-links">
-        <li><a href="/archive/">Archive</a></li>
-        %s
-	        <li>
-	          <script>
-	            (() => {
-	              const root = document.documentElement;
-	              const prefersDark = window.matchMedia("(prefers-color-scheme: dark)").matches;
-	              let theme = localStorage.getItem("theme") || (prefersDark ? "dark" : "light");
-	              const applyTheme = (nextTheme) => {
-	                root.dataset.theme = nextTheme;
-	                const hljsThemeLink = document.getElementById("hljs-theme-link");
-	                if (hljsThemeLink) {
-	                  const darkHref = hljsThemeLink.getAttribute("data-theme-dark");
-	                  const lightHref = hljsThemeLink.getAttribute("data-theme-light");
-	                  if (nextTheme === "dark" && darkHref) {
-	                    hljsThemeLink.setAttribute("href", darkHref);
-	                  }
-	                  if (nextTheme === "light" && lightHref) {
-	                    hljsThemeLink.setAttribute("href", lightHref);
-	                  }
-	                }
-	              };
-	              applyTheme(theme);
-	              window.changeTheme = () => {
-	                theme = theme === "dark" ? "light" : "dark";
-	                localStorage.setItem("theme", theme);
-                applyTheme(theme);
-              };
-            })();
-          </script>
-          <button class="button" onclick="changeTheme()">
-            <span class="icon">◐</span>
-          </button>
-        </li>
-      </ul>
-    </nav>`, escapeHTML(settings.SiteName), links.String())
+	return navView{SiteName: settings.SiteName, HomeURL: "/", ArchiveURL: "/archive/", Links: links}
 }
 
 func renderFeedAlternates(settings SettingsRecord) string {
@@ -365,12 +238,19 @@ func renderFeedAlternates(settings SettingsRecord) string {
 }
 
 func renderFeedLinkList(settings SettingsRecord) string {
+	return renderFeedLinkListFor(settings, "")
+}
+
+// renderFeedLinkListFor links the feeds served under basePath; "" is the
+// site-wide feed.
+func renderFeedLinkListFor(settings SettingsRecord, basePath string) string {
+	basePath = strings.TrimSuffix(basePath, "/")
 	links := make([]string, 0, 2)
 	if settings.EnableFeedXML {
-		links = append(links, `<a href="/feed.xml">Atom</a>`)
+		links = append(links, fmt.Sprintf(`<a href="%s/feed.xml">Atom</a>`, escapeHTML(basePath)))
 	}
 	if settings.EnableFeedJSON {
-		links = append(links, `<a href="/feed.json">JSON</a>`)
+		links = append(links, fmt.Sprintf(`<a href="%s/feed.json">JSON</a>`, escapeHTML(basePath)))
 	}
 	if len(links) == 0 {
 		return ""
@@ -379,15 +259,10 @@ func renderFeedLinkList(settings SettingsRecord) string {
 }
 
 func renderFooter(settings SettingsRecord) string {
-	if strings.TrimSpace(settings.FooterHTML) == "" {
-		return "\n  </body>\n</html>"
-	}
-	return fmt.Sprintf(`<footer class="footer">%s</footer>
-  </body>
-</html>`, settings.FooterHTML)
+	return renderThemeTemplate(settings, "footer.html", footerView{FooterHTML: template.HTML(strings.TrimSpace(settings.FooterHTML))})
 }
 
-func renderPagination(base string, pageNumber, totalPages int, query string) string {
+func renderPagination(base string, pageNumber, totalPages int, query string, text uiText) string {
 	if totalPages <= 1 {
 		return ""
 	}
@@ -405,12 +280,12 @@ func renderPagination(base string, pageNumber, totalPages int, query string) str
 			link = fmt.Sprintf("%s/%d/", base, prevPage)
 		}
 		link += querySuffix
-		prev = fmt.Sprintf(`<li class="pagination-prev"><a href="%s" rel="prev"><span>Previous</span><strong>%d</strong></a></li>`, link, prevPage)
+		prev = fmt.Sprintf(`<li class="pagination-prev"><a href="%s" rel="prev"><span>%s</span><strong>%d</strong></a></li>`, link, escapeHTML(text.get("previous")), prevPage)
 	}
 	if pageNumber < totalPages {
 		nextPage := pageNumber + 1
 		link := fmt.Sprintf("%s/%d/%s", base, nextPage, querySuffix)
-		next = fmt.Sprintf(`<li class="pagination-next"><a href="%s" rel="next"><span>Next</span><strong>%d</strong></a></li>`, link, nextPage)
+		next = fmt.Sprintf(`<li class="pagination-next"><a href="%s" rel="next"><span>%s</span><strong>%d</strong></a></li>`, link, escapeHTML(text.get("next")), nextPage)
 	}
 	return fmt.Sprintf(`<nav class="page-pagination pagination">
     <ul>
@@ -420,67 +295,89 @@ func renderPagination(base string, pageNumber, totalPages int, query string) str
   </nav>`, prev, next)
 }
 
-func renderTagsNav(tags []string) string {
+func renderTagsNav(tags []string, prefix string, text uiText) string {
 	if len(tags) == 0 {
 		return ""
 	}
 	items := strings.Builder{}
 	for _, tag := range tags {
-		items.WriteString(fmt.Sprintf(`<li><a href="/archive/%s/" class="badge">%s</a></li>`, url.PathEscape(tag), escapeHTML(tag)))
+		items.WriteString(fmt.Sprintf(`<li><a href="%s/archive/%s/" class="badge">%s</a></li>`, prefix, url.PathEscape(tag), escapeHTML(tag)))
 	}
 	return fmt.Sprintf(`<nav class="page-navigation">
-    <h2>tags:</h2>
+    <h2>%s</h2>
     <ul class="page-navigation-tags">
       %s
     </ul>
-  </nav>`, items.String())
+  </nav>`, escapeHTML(text.get("tags")), items.String())
 }
 
-func renderCategoriesNav(categories []string) string {
+func renderCategoriesNav(categories []string, prefix string, text uiText) string {
 	if len(categories) == 0 {
 		return ""
 	}
 	items := strings.Builder{}
 	for _, category := range categories {
-		items.WriteString(fmt.Sprintf(`<li><a href="/archive/category/%s/" class="badge">%s</a></li>`, url.PathEscape(category), escapeHTML(category)))
+		items.WriteString(fmt.Sprintf(`<li><a href="%s/archive/category/%s/" class="badge">%s</a></li>`, prefix, url.PathEscape(category), escapeHTML(category)))
 	}
 	return fmt.Sprintf(`<nav class="page-navigation">
-    <h2>categories:</h2>
+    <h2>%s</h2>
     <ul class="page-navigation-tags">
       %s
     </ul>
-  </nav>`, items.String())
+  </nav>`, escapeHTML(text.get("categories")), items.String())
 }
 
-func renderSearchForm(actionPath, query string) string {
-	safeAction := escapeHTML(actionPath)
+// renderSearchForm renders the archive search box for route. The inline
+// script answers searches from the route's exported search index, so the
+// form also works when the snapshot is served by a static host.
+func renderSearchForm(route archiveRoute, query string, text uiText) string {
+	safeAction := escapeHTML(route.basePath + "/")
 	safeQuery := escapeHTML(strings.TrimSpace(query))
 	clearHTML := ""
 	if safeQuery != "" {
-		clearHTML = fmt.Sprintf(`<a class="search-clear" href="%s">Clear</a>`, safeAction)
+		clearHTML = fmt.Sprintf(`<a class="search-clear" href="%s">%s</a>`, safeAction, escapeHTML(text.get("search_clear")))
+	}
+	tag, category := route.scope()
+	author := ""
+	if route.author != nil {
+		author = strings.TrimSpace(route.author.User)
 	}
 	return fmt.Sprintf(`<div class="search" id="search">
-    <form class="search-form" action="%s" method="get">
-      <input class="search-input" type="search" name="q" value="%s" placeholder="Search posts..." aria-label="Search posts" />
-      <button class="search-submit" type="submit">Search</button>
+    <form class="search-form" action="%s" method="get" data-search-index="%s" data-search-tag="%s" data-search-category="%s" data-search-author="%s" data-search-empty="%s" data-search-more="%s">
+      <input class="search-input" type="search" name="q" value="%s" placeholder="%s" aria-label="%s" />
+      <button class="search-submit" type="submit">%s</button>
       %s
     </form>
-  </div>`, safeAction, safeQuery, clearHTML)
+    <script>%s</script>
+  </div>`, safeAction, escapeHTML(searchIndexRoutePath(route.locale)), escapeHTML(tag), escapeHTML(category), escapeHTML(author), escapeHTML(text.get("search_no_results")), escapeHTML(text.get("read_more")), safeQuery, escapeHTML(text.get("search_placeholder")), escapeHTML(text.get("search_label")), escapeHTML(text.get("search_submit")), clearHTML, searchClientScript)
 }
 
-func renderPostTags(tags []string, show bool) string {
+// renderPostTags links each tag to its archive under prefix, the locale
+// prefix of the listing the post belongs to.
+func renderPostTags(tags []string, prefix string, show bool) string {
 	if !show || len(tags) == 0 {
 		return ""
 	}
 	items := strings.Builder{}
 	for _, tag := range tags {
-		items.WriteString(fmt.Sprintf(`<a class="badge" href="/archive/%s/">%s</a>`, url.PathEscape(tag), escapeHTML(tag)))
+		items.WriteString(fmt.Sprintf(`<a class="badge" href="%s/archive/%s/">%s</a>`, prefix, url.PathEscape(tag), escapeHTML(tag)))
 	}
 	return fmt.Sprintf(`<div class="post-tags">%s</div>`, items.String())
 }
 
-func renderPostList(items []PostRecord, showTags bool, excerptLength int) string {
-	list := strings.Builder{}
+func renderPostList(items []PostRecord, settings SettingsRecord) string {
+	return renderPostListWithSnippets(items, nil, settings)
+}
+
+func renderPostListWithSnippets(items []PostRecord, snippets map[string]string, settings SettingsRecord) string {
+	return renderLocalizedPostList(items, snippets, siteUILocale(settings), "", settings)
+}
+
+// renderLocalizedPostList renders items with locale's UI text, linking posts
+// and tags under prefix.
+func renderLocalizedPostList(items []PostRecord, snippets map[string]string, locale, prefix string, settings SettingsRecord) string {
+	text := uiTextFor(settings, locale)
+	list := make([]postListItem, 0, len(items))
 	for _, post := range items {
 		body := post.Body
 		if body == "" {
@@ -488,46 +385,33 @@ func renderPostList(items []PostRecord, showTags bool, excerptLength int) string
 		}
 		excerpt := post.Excerpt
 		if strings.TrimSpace(excerpt) == "" {
-			length := excerptLength
+			length := settings.ExcerptLength
 			if length <= 0 {
 				length = 160
 			}
 			excerpt = buildExcerpt(body, length)
 		}
-		tags := parseTags(post.Tags)
-		tagsHTML := renderPostTags(tags, showTags)
 		date := post.PublishedAt
 		if date == "" {
 			date = post.Date
 		}
-		postDetails := ""
-		if date != "" || tagsHTML != "" {
-			postDetails = fmt.Sprintf(`<div class="post-details">
-              %s
-              <p>%d min</p>
-              %s
-            </div>`, func() string {
-				if date == "" {
-					return ""
-				}
-				return fmt.Sprintf(`<p><time datetime="%s">%s</time></p>`, escapeHTML(date), formatDate(date))
-			}(), calcReadTime(body), tagsHTML)
+		readTime := readTimeMinutes(body, locale)
+		item := postListItem{
+			URL:           prefix + "/posts/" + post.Slug + "/",
+			Title:         defaultString(post.Title, post.Slug),
+			Date:          date,
+			ReadTime:      readTime,
+			ReadTimeLabel: text.readTime(readTime),
+			Tags:          template.HTML(renderPostTags(parseTags(post.Tags), prefix, settings.ShowTags)),
+			Excerpt:       excerpt,
+			Snippet:       template.HTML(snippets[searchDocumentID(post)]),
 		}
-
-		list.WriteString(fmt.Sprintf(`<article class="post">
-          <header class="post-header">
-            <h2 class="post-title">
-              <a href="/posts/%s/">%s</a>
-            </h2>
-            %s
-          </header>
-          <div class="post-excerpt body">%s</div>
-          <a href="/posts/%s/" class="post-link">Read →</a>
-        </article>`, escapeHTML(post.Slug), escapeHTML(defaultString(post.Title, post.Slug)), postDetails, escapeHTML(excerpt), escapeHTML(post.Slug)))
-	}
-	return fmt.Sprintf(`<section class="postList">
-    %s
-  </section>`, list.String())
+		if date != "" {
+			item.DateLabel = text.date(date)
+		}
+		list = append(list, item)
+	}
+	return renderThemeTemplate(settings, "post_list.html", postListView{Items: list, Text: text})
 }
 
 func renderHome(settings SettingsRecord) string {
@@ -563,34 +447,81 @@ func renderHome(settings SettingsRecord) string {
 	wg.Wait()
 	items := posts.Items
 
-	topImage := strings.TrimSpace(settings.HomeTopImage)
-	imageHTML := ""
-	if topImage != "" {
-		imageHTML = fmt.Sprintf(`<img src="%s" alt="%s" class="top-image" />`, escapeHTML(topImage), escapeHTML(settings.HomeTopImageAlt))
+	return renderHomePage(items, "", menu, settings)
+}
+
+// renderLocalizedHome is the home page of a translation locale, listing its
+// latest translations.
+func renderLocalizedHome(locale string, settings SettingsRecord) string {
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		return renderLocalizedHomeFromSnapshot(ctx, locale, settings)
+	}
+	var menu []PageRecord
+	var translations PBList[PostTranslationRecord]
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		menu = getPagesMenuInLocale(locale)
+	}()
+	go func() {
+		defer wg.Done()
+		translations, _ = getPostTranslations(map[string]string{
+			"page":    "1",
+			"perPage": strconv.Itoa(settings.HomePageSize),
+			"filter":  fmt.Sprintf("published = true && locale = \"%s\"", escapeFilter(locale)),
+			"sort":    "-published_at",
+		})
+	}()
+	wg.Wait()
+	items := make([]PostRecord, 0, len(translations.Items))
+	for _, item := range translations.Items {
+		items = append(items, translationToPost(item))
 	}
 
-	return renderHead("Home", settings) +
-		renderNav(menu, settings) +
-		fmt.Sprintf(`<main class="body-home">
-      <header class="page-header">
-        %s
-        <h1 class="page-title">%s</h1>
-      </header>
-      %s
-      <hr>
-      <p>More posts can be found in <a href="/archive/">the archive</a>.</p>
-    </main>`, imageHTML, escapeHTML(settings.WelcomeText), renderPostList(items, settings.ShowTags, settings.ExcerptLength)) +
-		renderFooter(settings)
+	return renderHomePage(items, locale, menu, settings)
+}
+
+// renderHomePage renders the home page of locale, "" being the source
+// locale. The welcome text is written in the source locale, so translated
+// home pages leave it out.
+func renderHomePage(items []PostRecord, locale string, menu []PageRecord, settings SettingsRecord) string {
+	view := homeView{
+		TopImage:    strings.TrimSpace(settings.HomeTopImage),
+		TopImageAlt: settings.HomeTopImageAlt,
+	}
+	if locale == "" {
+		view.themeLayout = newThemeLayout("Home", menu, settings, renderWebSiteJSONLD(settings))
+		view.WelcomeText = settings.WelcomeText
+		view.Posts = template.HTML(renderPostList(items, settings))
+	} else {
+		view.themeLayout = newLocalizedThemeLayout("Home", locale, menu, settings, "")
+		view.Posts = template.HTML(renderLocalizedPostList(items, nil, locale, localePathPrefix(locale), settings))
+	}
+	return renderThemeTemplate(settings, "home.html", view)
 }
 
 func renderArchive(path, query string, settings SettingsRecord) string {
 	if ctx := currentSnapshotBuildContext(); ctx != nil {
 		return renderArchiveFromSnapshot(ctx, path, query, settings)
 	}
-	route := parseArchiveRoute(path)
+	route, ok := resolveArchiveRoute(path)
+	if !ok {
+		return renderNotFound(settings)
+	}
+	if route.locale != "" {
+		return renderLocalizedArchive(route, query, settings)
+	}
 	showTagsNav := route.isRoot() && settings.ShowArchiveTags && settings.ShowTags && route.pageNumber == 1
 	showCategoriesNav := route.isRoot() && settings.ShowCategories && route.pageNumber == 1
 	searchQuery := strings.TrimSpace(query)
+	if searchQuery != "" {
+		if index := currentSearchIndex(""); index != nil {
+			items, snippets := archiveSearchResults(index, route, searchQuery)
+			posts := paginateSnapshotPosts(items, strconv.Itoa(route.pageNumber), strconv.Itoa(settings.ArchivePageSize))
+			return renderArchiveSearchPage(route, searchQuery, posts, snippets, getPagesMenu(), settings)
+		}
+	}
 	filter := route.filter
 	if searchQuery != "" {
 		searchFilter := escapeFilter(searchQuery)
@@ -625,37 +556,39 @@ func renderArchive(path, query string, settings SettingsRecord) string {
 		}
 	}()
 	wg.Wait()
-	pagination := renderPagination(route.basePath, route.pageNumber, posts.TotalPages, searchQuery)
+	text := uiTextFor(settings, "")
+	pagination := renderPagination(route.basePath, route.pageNumber, posts.TotalPages, searchQuery, text)
 	searchHTML := ""
 	if settings.ShowArchiveSearch {
-		searchAction := route.basePath + "/"
-		searchHTML = renderSearchForm(searchAction, searchQuery)
+		searchHTML = renderSearchForm(route, searchQuery, text)
 	}
 
 	tagsNav := ""
 	if showTagsNav {
-		tagsNav = renderTagsNav(collectTags())
+		tagsNav = renderTagsNav(collectTags(), "", text)
 	}
 	categoriesNav := ""
 	if showCategoriesNav {
-		categoriesNav = renderCategoriesNav(collectCategories())
-	}
-	feedLinks := renderFeedLinkList(settings)
+		categoriesNav = renderCategoriesNav(collectCategories(), "", text)
+	}
+	feedLinks := renderArchiveFeedLinks(route, settings)
+
+	return renderArchivePage(route, archiveView{
+		FeedLinks:     template.HTML(feedLinks),
+		Search:        template.HTML(searchHTML),
+		Posts:         template.HTML(renderPostList(posts.Items, settings)),
+		Pagination:    template.HTML(pagination),
+		TagsNav:       template.HTML(tagsNav),
+		CategoriesNav: template.HTML(categoriesNav),
+	}, menu, settings)
+}
 
-	return renderHead(route.title, settings) +
-		renderNav(menu, settings) +
-		fmt.Sprintf(`<main class="body-tag">
-      <header class="page-header">
-        <h1 class="page-title">%s</h1>
-        %s
-        %s
-      </header>
-      %s
-      %s
-      %s
-      %s
-    </main>`, escapeHTML(route.title), feedLinks, searchHTML, renderPostList(posts.Items, settings.ShowTags, settings.ExcerptLength), pagination, tagsNav, categoriesNav) +
-		renderFooter(settings)
+func renderArchivePage(route archiveRoute, view archiveView, menu []PageRecord, settings SettingsRecord) string {
+	locale := defaultString(route.locale, siteUILocale(settings))
+	title := route.localizedTitle(uiTextFor(settings, locale))
+	view.themeLayout = newLocalizedThemeLayout(title, locale, menu, settings, archiveHeadExtras(route, settings))
+	view.Title = title
+	return renderThemeTemplate(settings, "archive.html", view)
 }
 
 func renderHomeFromSnapshot(ctx *snapshotBuildContext, settings SettingsRecord) string {
@@ -668,27 +601,30 @@ func renderHomeFromSnapshot(ctx *snapshotBuildContext, settings SettingsRecord)
 		items = items[:limit]
 	}
 
-	topImage := strings.TrimSpace(settings.HomeTopImage)
-	imageHTML := ""
-	if topImage != "" {
-		imageHTML = fmt.Sprintf(`<img src="%s" alt="%s" class="top-image" />`, escapeHTML(topImage), escapeHTML(settings.HomeTopImageAlt))
+	return renderHomePage(items, "", ctx.menu, settings)
+}
+
+func renderLocalizedHomeFromSnapshot(ctx *snapshotBuildContext, locale string, settings SettingsRecord) string {
+	limit := settings.HomePageSize
+	if limit <= 0 {
+		limit = 3
+	}
+	items := ctx.postsForLocale(locale)
+	if len(items) > limit {
+		items = items[:limit]
 	}
 
-	return renderHead("Home", settings) +
-		renderNav(ctx.menu, settings) +
-		fmt.Sprintf(`<main class="body-home">
-      <header class="page-header">
-        %s
-        <h1 class="page-title">%s</h1>
-      </header>
-      %s
-      <hr>
-      <p>More posts can be found in <a href="/archive/">the archive</a>.</p>
-    </main>`, imageHTML, escapeHTML(settings.WelcomeText), renderPostList(items, settings.ShowTags, settings.ExcerptLength)) +
-		renderFooter(settings)
+	return renderHomePage(items, locale, getPagesMenuInLocale(locale), settings)
 }
 
 func parseArchiveRoute(path string) archiveRoute {
+	if locale, rest, ok := extractLocalizedListingRoute(path); ok && rest != "/" {
+		route := parseArchiveRoute(rest)
+		route.locale = locale
+		route.filter = ""
+		route.basePath = localePathPrefix(locale) + route.basePath
+		return route
+	}
 	route := archiveRoute{
 		pageNumber: 1,
 		basePath:   "/archive",
@@ -696,6 +632,19 @@ func parseArchiveRoute(path string) archiveRoute {
 		title:      "Archive",
 	}
 	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "authors" && parts[1] != "" {
+		slug := decodePathSegment(parts[1])
+		route.authorSlug = slug
+		route.title = "author: " + slug
+		route.filter = ""
+		route.basePath = strings.TrimSuffix(authorArchivePath(slug), "/")
+		if len(parts) >= 3 {
+			if n, err := strconv.Atoi(parts[2]); err == nil && n > 0 {
+				route.pageNumber = n
+			}
+		}
+		return route
+	}
 	if len(parts) < 1 || parts[0] != "archive" || len(parts) < 2 {
 		return route
 	}
@@ -734,18 +683,134 @@ func (route archiveRoute) listingKey() string {
 }
 
 func (route archiveRoute) isRoot() bool {
-	return route.title == "Archive"
+	return route.unprefixedBasePath() == "/archive"
 }
 
-func renderArchiveFromSnapshot(ctx *snapshotBuildContext, path, query string, settings SettingsRecord) string {
+func (route archiveRoute) unprefixedBasePath() string {
+	return strings.TrimPrefix(route.basePath, localePathPrefix(route.locale))
+}
+
+// resolveArchiveRoute parses path and, for author archives, looks the author
+// up so the route carries a usable filter and title. It reports false when
+// the author does not exist.
+func resolveArchiveRoute(path string) (archiveRoute, bool) {
 	route := parseArchiveRoute(path)
+	if route.authorSlug == "" {
+		return route, true
+	}
+	author := getAuthorBySlug(route.authorSlug)
+	if author == nil {
+		return route, false
+	}
+	return route.withAuthor(*author), true
+}
+
+func (route archiveRoute) withAuthor(author AuthorRecord) archiveRoute {
+	route.author = &author
+	route.title = defaultString(strings.TrimSpace(author.DisplayName), author.Slug)
+	route.filter = fmt.Sprintf("published = true && author = \"%s\"", escapeFilter(strings.TrimSpace(author.User)))
+	return route
+}
+
+// localizedTitle is the archive heading in the catalog's language. Author
+// archives are titled with the author's name and need no translation.
+func (route archiveRoute) localizedTitle(text uiText) string {
+	if route.isRoot() {
+		return text.get("archive")
+	}
+	tag, category := route.scope()
+	if category != "" {
+		return text.format("archive_category", category)
+	}
+	if tag != "" {
+		return text.format("archive_tag", tag)
+	}
+	return route.title
+}
+
+func (route archiveRoute) scope() (tag, category string) {
+	if route.isRoot() || route.authorSlug != "" {
+		return "", ""
+	}
+	basePath := route.unprefixedBasePath()
+	if rest, ok := strings.CutPrefix(basePath, "/archive/category/"); ok {
+		return "", decodePathSegment(rest)
+	}
+	return decodePathSegment(strings.TrimPrefix(basePath, "/archive/")), ""
+}
+
+// matches reports whether post belongs to the route's tag, category or
+// author archive.
+func (route archiveRoute) matches(post PostRecord) bool {
+	tag, category := route.scope()
+	if tag != "" && !slices.Contains(parseTags(post.Tags), tag) {
+		return false
+	}
+	if category != "" && strings.TrimSpace(post.Category) != category {
+		return false
+	}
+	if route.author != nil && strings.TrimSpace(post.Author) != strings.TrimSpace(route.author.User) {
+		return false
+	}
+	return true
+}
+
+func renderArchiveFromSnapshot(ctx *snapshotBuildContext, path, query string, settings SettingsRecord) string {
+	route, ok := resolveArchiveRoute(path)
+	if !ok {
+		return renderNotFound(settings)
+	}
+	listing := ctx.archiveIndex[route.listingKey()]
+	if route.locale != "" {
+		tags, categories := collectTaxonomiesStrict(ctx.postsForLocale(route.locale))
+		return renderArchiveListing(route, listing.posts, currentSearchIndex(route.locale), query, tags, categories, getPagesMenuInLocale(route.locale), settings)
+	}
+	return renderArchiveListing(route, listing.posts, ctx.searchIndex, query, ctx.tags, ctx.categories, ctx.menu, settings)
+}
+
+// renderLocalizedArchive renders a translation locale's archive from its
+// published translations.
+func renderLocalizedArchive(route archiveRoute, query string, settings SettingsRecord) string {
+	var menu []PageRecord
+	var translations []PostTranslationRecord
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		menu = getPagesMenuInLocale(route.locale)
+	}()
+	go func() {
+		defer wg.Done()
+		translations = listPublishedTranslationsByLocale(route.locale)
+	}()
+	wg.Wait()
+
+	posts := make([]PostRecord, 0, len(translations))
+	listing := make([]PostRecord, 0, len(translations))
+	for _, item := range translations {
+		post := translationToPost(item)
+		posts = append(posts, post)
+		if route.matches(post) {
+			listing = append(listing, post)
+		}
+	}
+	tags, categories := collectTaxonomiesStrict(posts)
+	return renderArchiveListing(route, listing, currentSearchIndex(route.locale), query, tags, categories, menu, settings)
+}
+
+// renderArchiveListing renders one page of an archive whose posts are
+// already in memory. Without a search index a query falls back to substring
+// matching.
+func renderArchiveListing(route archiveRoute, listing []PostRecord, index *searchIndex, query string, tags, categories []string, menu []PageRecord, settings SettingsRecord) string {
 	showTagsNav := route.isRoot() && settings.ShowArchiveTags && settings.ShowTags && route.pageNumber == 1
 	showCategoriesNav := route.isRoot() && settings.ShowCategories && route.pageNumber == 1
-	listing := ctx.archiveIndex[route.listingKey()]
 
-	items := append([]PostRecord(nil), listing.posts...)
+	items := append([]PostRecord(nil), listing...)
 	searchQuery := strings.TrimSpace(query)
-	if searchQuery != "" {
+	var snippets map[string]string
+	if searchQuery != "" && index != nil {
+		items, snippets = archiveSearchResults(index, route, searchQuery)
+	} else if searchQuery != "" {
 		filtered := make([]PostRecord, 0, len(items))
 		for _, item := range items {
 			if snapshotPostMatchesQuery(item, searchQuery) {
@@ -755,35 +820,63 @@ func renderArchiveFromSnapshot(ctx *snapshotBuildContext, path, query string, se
 		items = filtered
 	}
 	posts := paginateSnapshotPosts(items, strconv.Itoa(route.pageNumber), strconv.Itoa(settings.ArchivePageSize))
-	pagination := renderPagination(route.basePath, route.pageNumber, posts.TotalPages, searchQuery)
+	locale := defaultString(route.locale, siteUILocale(settings))
+	prefix := localePathPrefix(route.locale)
+	text := uiTextFor(settings, locale)
+	pagination := renderPagination(route.basePath, route.pageNumber, posts.TotalPages, searchQuery, text)
 	searchHTML := ""
 	if settings.ShowArchiveSearch {
-		searchHTML = renderSearchForm(route.basePath+"/", searchQuery)
+		searchHTML = renderSearchForm(route, searchQuery, text)
 	}
 	tagsNav := ""
 	if showTagsNav {
-		tagsNav = renderTagsNav(ctx.tags)
+		tagsNav = renderTagsNav(tags, prefix, text)
 	}
 	categoriesNav := ""
 	if showCategoriesNav {
-		categoriesNav = renderCategoriesNav(ctx.categories)
+		categoriesNav = renderCategoriesNav(categories, prefix, text)
+	}
+	feedLinks := renderArchiveFeedLinks(route, settings)
+
+	return renderArchivePage(route, archiveView{
+		FeedLinks:     template.HTML(feedLinks),
+		Search:        template.HTML(searchHTML),
+		Posts:         template.HTML(renderLocalizedPostList(posts.Items, snippets, locale, prefix, settings)),
+		Pagination:    template.HTML(pagination),
+		TagsNav:       template.HTML(tagsNav),
+		CategoriesNav: template.HTML(categoriesNav),
+	}, menu, settings)
+}
+
+func archiveSearchResults(index *searchIndex, route archiveRoute, query string) ([]PostRecord, map[string]string) {
+	hits := index.search(query)
+	items := make([]PostRecord, 0, len(hits))
+	snippets := make(map[string]string, len(hits))
+	for _, hit := range hits {
+		if !route.matches(hit.Post) {
+			continue
+		}
+		items = append(items, hit.Post)
+		if hit.Snippet != "" {
+			snippets[searchDocumentID(hit.Post)] = hit.Snippet
+		}
 	}
-	feedLinks := renderFeedLinkList(settings)
+	return items, snippets
+}
 
-	return renderHead(route.title, settings) +
-		renderNav(ctx.menu, settings) +
-		fmt.Sprintf(`<main class="body-tag">
-      <header class="page-header">
-        <h1 class="page-title">%s</h1>
-        %s
-        %s
-      </header>
-      %s
-      %s
-      %s
-      %s
-    </main>`, escapeHTML(route.title), feedLinks, searchHTML, renderPostList(posts.Items, settings.ShowTags, settings.ExcerptLength), pagination, tagsNav, categoriesNav) +
-		renderFooter(settings)
+func renderArchiveSearchPage(route archiveRoute, searchQuery string, posts PBList[PostRecord], snippets map[string]string, menu []PageRecord, settings SettingsRecord) string {
+	text := uiTextFor(settings, "")
+	pagination := renderPagination(route.basePath, route.pageNumber, posts.TotalPages, searchQuery, text)
+	searchHTML := ""
+	if settings.ShowArchiveSearch {
+		searchHTML = renderSearchForm(route, searchQuery, text)
+	}
+	return renderArchivePage(route, archiveView{
+		FeedLinks:  template.HTML(renderArchiveFeedLinks(route, settings)),
+		Search:     template.HTML(searchHTML),
+		Posts:      template.HTML(renderPostListWithSnippets(posts.Items, snippets, settings)),
+		Pagination: template.HTML(pagination),
+	}, menu, settings)
 }
 
 func prefetchPostRenderInput(path string) (*postRenderInput, bool) {
@@ -835,8 +928,26 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 	var menu []PageRecord
 	var newer *PostRecord
 	var older *PostRecord
+	var author *AuthorRecord
+	seriesHTML := ""
 	related := []PostRecord{}
 	var wg sync.WaitGroup
+	seriesSourceID := post.ID
+	if locale != "" {
+		seriesSourceID = input.translation.SourcePost
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		seriesHTML = renderPostSeriesBox(seriesSourceID, locale, settings)
+	}()
+	if authorID := postAuthorID(post, sourcePost); authorID != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			author = getAuthorByUserID(authorID)
+		}()
+	}
 	if locale == "" {
 		wg.Add(1)
 		go func(sourceID string) {
@@ -847,7 +958,7 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		menu = getPagesMenu()
+		menu = getPagesMenuInLocale(locale)
 	}()
 	go func() {
 		defer wg.Done()
@@ -866,18 +977,24 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 	if body == "" {
 		body = post.Content
 	}
-	body = rewriteMediaURLs(body)
-	body, tocHTML := buildTOC(body, settings.ShowToc)
+	body = responsiveImages(rewriteMediaURLs(body))
+	if settings.EnableCodeHighlight {
+		body = highlightCodeBlocks(body)
+	}
+	pageLocale := defaultString(currentLocale, siteUILocale(settings))
+	text := uiTextFor(settings, pageLocale)
+	body, tocHTML := buildTOC(body, settings.ShowToc, text)
 	date := post.PublishedAt
 	if date == "" {
 		date = post.Date
 	}
-	categoryHTML := ""
-	if settings.ShowCategories && strings.TrimSpace(post.Category) != "" {
-		categoryHTML = fmt.Sprintf(`<p>%s</p>`, escapeHTML(post.Category))
+	category := ""
+	if settings.ShowCategories {
+		category = strings.TrimSpace(post.Category)
 	}
-	postTags := renderPostTags(parseTags(post.Tags), settings.ShowTags)
-	languageHTML := renderLanguageLinks(sourceLocale, currentLocale, sourcePost, translations)
+	postTags := renderPostTags(parseTags(post.Tags), listingPathPrefix(settings, locale), settings.ShowTags)
+	languageHTML := renderLanguageLinks(sourceLocale, currentLocale, sourcePost, translations, settings, text)
+	hreflangHTML := renderHreflangLinks(sourceLocale, postLanguageAlternates(sourceLocale, sourcePost, translations, settings), settings)
 	postPathPrefix := "/posts/"
 	if locale != "" {
 		postPathPrefix = "/" + locale + "/posts/"
@@ -887,10 +1004,10 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 		olderHTML := ""
 		newerHTML := ""
 		if older != nil {
-			olderHTML = fmt.Sprintf(`<li class="pagination-prev"><a href="%s%s/" rel="prev"><span>← Older post</span><strong>%s</strong></a></li>`, postPathPrefix, url.PathEscape(older.Slug), escapeHTML(defaultString(older.Title, "Post")))
+			olderHTML = fmt.Sprintf(`<li class="pagination-prev"><a href="%s%s/" rel="prev"><span>%s</span><strong>%s</strong></a></li>`, postPathPrefix, url.PathEscape(older.Slug), escapeHTML(text.get("older_post")), escapeHTML(defaultString(older.Title, "Post")))
 		}
 		if newer != nil {
-			newerHTML = fmt.Sprintf(`<li class="pagination-next"><a href="%s%s/" rel="next"><span>Newer post →</span><strong>%s</strong></a></li>`, postPathPrefix, url.PathEscape(newer.Slug), escapeHTML(defaultString(newer.Title, "Post")))
+			newerHTML = fmt.Sprintf(`<li class="pagination-next"><a href="%s%s/" rel="next"><span>%s</span><strong>%s</strong></a></li>`, postPathPrefix, url.PathEscape(newer.Slug), escapeHTML(text.get("newer_post")), escapeHTML(defaultString(newer.Title, "Post")))
 		}
 		navHTML = fmt.Sprintf(`<nav class="page-pagination pagination post-pagination">
       <ul>
@@ -901,7 +1018,7 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 	}
 	relatedHTML := ""
 	if settings.ShowRelatedPosts {
-		relatedHTML = renderRelatedPosts(related, postPathPrefix)
+		relatedHTML = renderRelatedPosts(related, postPathPrefix, text)
 	}
 	commentsHTML := renderCommentsSection(settings)
 	excerpt := strings.TrimSpace(post.Excerpt)
@@ -909,44 +1026,50 @@ func renderPostFromInput(input *postRenderInput, settings SettingsRecord) (strin
 		excerpt = buildExcerpt(body, settings.ExcerptLength)
 	}
 	postPath := postPathPrefix + strings.TrimSpace(post.Slug) + "/"
+	sourcePath := ""
+	if locale != "" && sourcePost != nil && strings.TrimSpace(sourcePost.Slug) != "" {
+		sourcePath = "/posts/" + strings.TrimSpace(sourcePost.Slug) + "/"
+	}
 	headExtras := renderPostMetaTags(postMetaInput{
-		Path:        postPath,
-		Locale:      currentLocale,
-		Title:       defaultString(post.Title, "Post"),
-		Description: excerpt,
-		PublishedAt: date,
+		Path:         postPath,
+		Locale:       currentLocale,
+		Title:        defaultString(post.Title, "Post"),
+		Description:  excerpt,
+		PublishedAt:  date,
+		Author:       author,
+		Image:        postFeaturedImagePath(input.translation, sourcePost),
+		SourcePath:   sourcePath,
+		SourceLocale: sourceLocale,
 	}, settings)
-
-	return renderHeadWithExtras(defaultString(post.Title, "Post"), settings, headExtras) +
-		renderNav(menu, settings) +
-		fmt.Sprintf(`<main class="body-post">
-      <article class="post">
-        <header class="post-header">
-          <h1 class="post-title">%s</h1>
-          <div class="post-details">
-            %s
-            <p>%d min</p>
-            %s
-            %s
-            %s
-          </div>
-        </header>
-        %s
-        <div class="post-body body">%s</div>
-      </article>
-      %s
-      %s
-      %s
-    </main>`, escapeHTML(post.Title), func() string {
-			if date == "" {
-				return ""
-			}
-			return fmt.Sprintf(`<p><time datetime="%s">%s</time></p>`, escapeHTML(date), formatDate(date))
-		}(), calcReadTime(body), categoryHTML, postTags, languageHTML, tocHTML, body, commentsHTML, relatedHTML, navHTML) +
-		renderFooter(settings), true
+	if hreflangHTML != "" {
+		headExtras += "\n    " + hreflangHTML
+	}
+
+	readTime := readTimeMinutes(body, pageLocale)
+	view := postView{
+		themeLayout:   newLocalizedThemeLayout(defaultString(post.Title, "Post"), pageLocale, menu, settings, headExtras),
+		Title:         post.Title,
+		Date:          date,
+		Byline:        template.HTML(renderAuthorByline(author)),
+		ReadTime:      readTime,
+		ReadTimeLabel: text.readTime(readTime),
+		Category:      category,
+		Tags:          template.HTML(postTags),
+		Languages:     template.HTML(languageHTML),
+		Series:        template.HTML(seriesHTML),
+		TOC:           template.HTML(tocHTML),
+		Body:          template.HTML(body),
+		Comments:      template.HTML(commentsHTML),
+		Related:       template.HTML(relatedHTML),
+		Navigation:    template.HTML(navHTML),
+	}
+	if date != "" {
+		view.DateLabel = text.date(date)
+	}
+	return renderThemeTemplate(settings, "post.html", view), true
 }
 
-func buildTOC(body string, enabled bool) (string, string) {
+func buildTOC(body string, enabled bool, text uiText) (string, string) {
 	if !enabled || strings.TrimSpace(body) == "" {
 		return body, ""
 	}
@@ -1004,12 +1127,13 @@ func buildTOC(body string, enabled bool) (string, string) {
 		list.WriteString(fmt.Sprintf(`<li data-level="%d"><a href="#%s">%s</a></li>`, item.level, escapeHTML(item.id), escapeHTML(item.text)))
 	}
 
-	toc := fmt.Sprintf(`<nav class="post-toc" aria-label="Table of contents">
-      <h2>Table of contents</h2>
+	title := escapeHTML(text.get("table_of_contents"))
+	toc := fmt.Sprintf(`<nav class="post-toc" aria-label="%s">
+      <h2>%s</h2>
       <ul>
         %s
       </ul>
-    </nav>`, list.String())
+    </nav>`, title, title, list.String())
 	return updated, toc
 }
 
@@ -1070,7 +1194,7 @@ func sanitizeCommentsScriptTag(raw string) string {
 	return trimmed
 }
 
-func renderRelatedPosts(items []PostRecord, postPathPrefix string) string {
+func renderRelatedPosts(items []PostRecord, postPathPrefix string, text uiText) string {
 	if len(items) == 0 {
 		return ""
 	}
@@ -1082,7 +1206,7 @@ func renderRelatedPosts(items []PostRecord, postPathPrefix string) string {
 		}
 		dateHTML := ""
 		if date != "" {
-			dateHTML = fmt.Sprintf(`<p><time datetime="%s">%s</time></p>`, escapeHTML(date), formatDate(date))
+			dateHTML = fmt.Sprintf(`<p><time datetime="%s">%s</time></p>`, escapeHTML(date), escapeHTML(text.date(date)))
 		}
 		list.WriteString(fmt.Sprintf(`<li>
           <a href="%s%s/">%s</a>
@@ -1090,49 +1214,15 @@ func renderRelatedPosts(items []PostRecord, postPathPrefix string) string {
         </li>`, postPathPrefix, url.PathEscape(post.Slug), escapeHTML(defaultString(post.Title, "Post")), dateHTML))
 	}
 	return fmt.Sprintf(`<section class="post-related">
-      <h2>Related Posts</h2>
+      <h2>%s</h2>
       <ul class="post-related-list">
         %s
       </ul>
-    </section>`, list.String())
+    </section>`, escapeHTML(text.get("related_posts")), list.String())
 }
 
-func renderLanguageLinks(sourceLocale, currentLocale string, sourcePost *PostRecord, translations []PostTranslationRecord) string {
-	type linkItem struct {
-		locale string
-		href   string
-	}
-
-	items := make([]linkItem, 0, len(translations)+1)
-	seen := map[string]struct{}{}
-
-	if sourcePost != nil && strings.TrimSpace(sourcePost.Slug) != "" && sourceLocale != "" {
-		items = append(items, linkItem{
-			locale: sourceLocale,
-			href:   "/posts/" + url.PathEscape(sourcePost.Slug) + "/",
-		})
-		seen[sourceLocale] = struct{}{}
-	}
-
-	for _, t := range translations {
-		locale := normalizeLocale(t.Locale)
-		if locale == "" {
-			continue
-		}
-		if _, ok := seen[locale]; ok {
-			continue
-		}
-		slug := strings.TrimSpace(t.Slug)
-		if slug == "" {
-			continue
-		}
-		items = append(items, linkItem{
-			locale: locale,
-			href:   "/" + url.PathEscape(locale) + "/posts/" + url.PathEscape(slug) + "/",
-		})
-		seen[locale] = struct{}{}
-	}
-
+func renderLanguageLinks(sourceLocale, currentLocale string, sourcePost *PostRecord, translations []PostTranslationRecord, settings SettingsRecord, text uiText) string {
+	items := postLanguageAlternates(sourceLocale, sourcePost, translations, settings)
 	if len(items) <= 1 {
 		return ""
 	}
@@ -1143,49 +1233,42 @@ func renderLanguageLinks(sourceLocale, currentLocale string, sourcePost *PostRec
 			parts = append(parts, fmt.Sprintf(`<strong>%s</strong>`, escapeHTML(item.locale)))
 			continue
 		}
-		parts = append(parts, fmt.Sprintf(`<a class="badge" href="%s">%s</a>`, escapeHTML(item.href), escapeHTML(item.locale)))
+		parts = append(parts, fmt.Sprintf(`<a class="badge" href="%s">%s</a>`, escapeHTML(item.path), escapeHTML(item.locale)))
 	}
 
-	return fmt.Sprintf(`<div class="post-languages">language: %s</div>`, strings.Join(parts, " "))
+	return fmt.Sprintf(`<div class="post-languages">%s %s</div>`, escapeHTML(text.get("language")), strings.Join(parts, " "))
 }
 
 func renderPageFromRecord(page *PageRecord, settings SettingsRecord) (string, bool) {
+	return renderPageInLocale(page, "", settings)
+}
+
+// renderPageInLocale renders page in locale, "" being the source locale. A
+// translated page gets the chrome and menu of its locale.
+func renderPageInLocale(page *PageRecord, locale string, settings SettingsRecord) (string, bool) {
 	if page == nil {
 		return renderNotFound(settings), false
 	}
-	menu := getPagesMenu()
+	menu := getPagesMenuInLocale(locale)
 	body := page.Body
 	if body == "" {
 		body = page.Content
 	}
-	body = rewriteMediaURLs(body)
-
-	return renderHead(defaultString(page.Title, "Page"), settings) +
-		renderNav(menu, settings) +
-		fmt.Sprintf(`<main class="body-tag">
-      <article class="post">
-        <header class="post-header">
-          <h1 class="post-title">%s</h1>
-        </header>
-        <div class="post-body body">%s</div>
-      </article>
-    </main>`, escapeHTML(page.Title), body) +
-		renderFooter(settings), true
+	body = responsiveImages(rewriteMediaURLs(body))
+	if settings.EnableCodeHighlight {
+		body = highlightCodeBlocks(body)
+	}
+
+	return renderThemeTemplate(settings, "page.html", pageView{
+		themeLayout: newLocalizedThemeLayout(defaultString(page.Title, "Page"), defaultString(locale, siteUILocale(settings)), menu, settings, renderPageJSONLD(page, locale, settings)),
+		Title:       page.Title,
+		Body:        template.HTML(body),
+	}), true
 }
 
 func renderNotFound(settings SettingsRecord) string {
 	menu := getPagesMenu()
-	return renderHead("Not Found", settings) +
-		renderNav(menu, settings) +
-		`<main class="body-post">
-      <article class="post">
-        <header class="post-header">
-          <h1 class="post-title">Not Found</h1>
-        </header>
-        <div class="post-body body">Page not found.</div>
-      </article>
-    </main>` +
-		renderFooter(settings)
+	return renderThemeTemplate(settings, "not_found.html", notFoundView{themeLayout: newThemeLayout(uiTextFor(settings, "").get("not_found_title"), menu, settings, "")})
 }
 
 func resolvePostPath(path string) (locale string, slug string, ok bool) {
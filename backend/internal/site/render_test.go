@@ -29,15 +29,36 @@ func loadRenderPostCases(t *testing.T) []renderPostCase {
 	return fixtures
 }
 
-func TestHighlightStylesheets(t *testing.T) {
+func TestHighlightThemes(t *testing.T) {
 	t.Parallel()
 
-	dark, light := highlightStylesheets(SettingsRecord{HighlightTheme: "dracula"})
-	if !strings.Contains(dark, "dracula") {
-		t.Fatalf("dark stylesheet = %q, want dracula theme", dark)
+	dark, light := highlightThemes(SettingsRecord{HighlightTheme: "dracula"})
+	if dark != "dracula" {
+		t.Fatalf("dark theme = %q, want dracula", dark)
 	}
-	if !strings.Contains(light, "github.min.css") {
-		t.Fatalf("light stylesheet = %q, want github fallback", light)
+	if light != "github" {
+		t.Fatalf("light theme = %q, want github fallback", light)
+	}
+	for _, name := range []string{dark, light} {
+		if _, ok := highlightPalettes[name]; !ok {
+			t.Fatalf("missing palette %q", name)
+		}
+	}
+}
+
+func TestRenderHeadInlinesHighlightTheme(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.EnableCodeHighlight = true
+	settings.HighlightTheme = "solarized-dark"
+
+	html := renderHead("Home", settings)
+	if !strings.Contains(html, `<style id="hljs-theme" data-theme-dark="solarized-dark" data-theme-light="solarized-light">`) {
+		t.Fatalf("renderHead should inline the local highlight theme")
+	}
+	if strings.Contains(html, "cdnjs.cloudflare.com") {
+		t.Fatalf("renderHead should not load highlight assets from a CDN")
 	}
 }
 
@@ -61,7 +82,7 @@ func TestRenderHeadRespectsFeatureFlags(t *testing.T) {
 	if !strings.Contains(html, settings.AdsClient) {
 		t.Fatalf("renderHead should include ads script")
 	}
-	if strings.Contains(html, "highlight.min.js") {
+	if strings.Contains(html, "highlight.min.js") || strings.Contains(html, `id="hljs-theme"`) {
 		t.Fatalf("renderHead should omit highlight assets when disabled")
 	}
 	if !strings.Contains(html, `<meta name="robots" content="max-image-preview:large" />`) {
@@ -140,6 +161,26 @@ func TestParseArchiveRoute(t *testing.T) {
 			path:  "/archive/category/",
 			want:  archiveRoute{pageNumber: 1, basePath: "/archive/category", filter: `published = true && tags ~ "category"`, title: "tag: category"},
 		},
+		{
+			label: "author",
+			path:  "/authors/jane-doe/",
+			want:  archiveRoute{pageNumber: 1, basePath: "/authors/jane-doe", title: "author: jane-doe", authorSlug: "jane-doe"},
+		},
+		{
+			label: "author-page",
+			path:  "/authors/jane-doe/2/",
+			want:  archiveRoute{pageNumber: 2, basePath: "/authors/jane-doe", title: "author: jane-doe", authorSlug: "jane-doe"},
+		},
+		{
+			label: "localized-root",
+			path:  "/en/archive/2/",
+			want:  archiveRoute{pageNumber: 2, basePath: "/en/archive", title: "Archive", locale: "en"},
+		},
+		{
+			label: "localized-category",
+			path:  "/en/archive/category/news/",
+			want:  archiveRoute{pageNumber: 1, basePath: "/en/archive/category/news", title: "category: news", locale: "en"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +194,107 @@ func TestParseArchiveRoute(t *testing.T) {
 	}
 }
 
+func TestLocalizedArchiveRouteScope(t *testing.T) {
+	t.Parallel()
+
+	root := parseArchiveRoute("/en/archive/")
+	if !root.isRoot() || root.listingKey() != "/en/archive/" {
+		t.Fatalf("localized root = %#v", root)
+	}
+	route := parseArchiveRoute("/en/archive/go/")
+	if tag, category := route.scope(); tag != "go" || category != "" {
+		t.Fatalf("scope() = %q, %q, want go", tag, category)
+	}
+	if !route.matches(PostRecord{Tags: "go, testing"}) || route.matches(PostRecord{Tags: "rust"}) {
+		t.Fatalf("matches() should filter by the localized tag")
+	}
+	if got := route.localizedTitle(uiTextFor(SettingsRecord{}, "en")); got != "tag: go" {
+		t.Fatalf("localizedTitle() = %q", got)
+	}
+}
+
+func TestRenderLocalizedListingsFromSnapshot(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.ShowArchiveTags = true
+	settings.ShowTags = true
+	translation := PostTranslationRecord{
+		ID:          "tr-1",
+		SourcePost:  "post-1",
+		Locale:      "en",
+		Slug:        "hello-en",
+		Title:       "Hello in English",
+		Body:        "<p>Body</p>",
+		Tags:        "go",
+		Published:   true,
+		PublishedAt: "2026-04-16T10:00:00Z",
+	}
+	ctx := &snapshotBuildContext{
+		settings:             settings,
+		translationsByLocale: map[string][]PostTranslationRecord{"en": {translation}},
+		archiveIndex:         map[string]archiveListing{},
+	}
+	ctx.indexLocalizedArchives("en")
+	if _, ok := ctx.archiveIndex["/en/archive/go/"]; !ok {
+		t.Fatalf("archiveIndex missing localized tag listing: %v", ctx.archiveIndex)
+	}
+
+	archive := renderArchiveFromSnapshot(ctx, "/en/archive/", "", settings)
+	for _, token := range []string{`<html lang="en" dir="ltr">`, `href="/en/posts/hello-en/"`, `href="/en/archive/go/"`, `data-search-index="/en/search-index.json"`} {
+		if !strings.Contains(archive, token) {
+			t.Fatalf("localized archive missing %q: %s", token, archive)
+		}
+	}
+	home := renderLocalizedHomeFromSnapshot(ctx, "en", settings)
+	if !strings.Contains(home, "Hello in English") || strings.Contains(home, settings.WelcomeText) {
+		t.Fatalf("localized home should list translations without the source welcome text: %s", home)
+	}
+}
+
+func TestArchiveRouteWithAuthor(t *testing.T) {
+	t.Parallel()
+
+	route := parseArchiveRoute("/authors/jane-doe/").withAuthor(AuthorRecord{User: "user1", Slug: "jane-doe", DisplayName: "Jane Doe"})
+	if route.title != "Jane Doe" || route.filter != `published = true && author = "user1"` {
+		t.Fatalf("withAuthor() = %#v", route)
+	}
+	if route.isRoot() {
+		t.Fatalf("author archive should not be treated as the root archive")
+	}
+	if tag, category := route.scope(); tag != "" || category != "" {
+		t.Fatalf("scope() = %q, %q, want empty", tag, category)
+	}
+}
+
+func TestRenderPostMetaTagsIncludesAuthor(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.SiteURL = "https://example.com"
+	html := renderPostMetaTags(postMetaInput{
+		Path:        "/posts/hello/",
+		Title:       "Hello",
+		PublishedAt: "2026-03-22T10:11:12Z",
+		Author:      &AuthorRecord{User: "user1", Slug: "jane-doe", DisplayName: "Jane <Doe>"},
+	}, settings)
+
+	for _, token := range []string{
+		`<meta name="author" content="Jane &lt;Doe&gt;" />`,
+		`<script type="application/ld+json">`,
+		`"author":{"@type":"Person","name":"Jane \u003cDoe\u003e","url":"https://example.com/authors/jane-doe"}`,
+		`"datePublished":"2026-03-22T10:11:12Z"`,
+	} {
+		if !strings.Contains(html, token) {
+			t.Fatalf("meta tags missing %q: %s", token, html)
+		}
+	}
+
+	if got := renderAuthorByline(&AuthorRecord{Slug: "jane-doe", DisplayName: "Jane"}); !strings.Contains(got, `href="/authors/jane-doe/" rel="author">Jane</a>`) {
+		t.Fatalf("renderAuthorByline() = %q", got)
+	}
+}
+
 func TestResolvePublishedPost(t *testing.T) {
 	t.Parallel()
 
@@ -213,7 +355,7 @@ func TestLoadPostFamilyFallsBackToProvidedPost(t *testing.T) {
 func TestBuildTOC(t *testing.T) {
 	t.Parallel()
 
-	body, toc := buildTOC("<h2>Intro</h2><p>x</p><h3>Details</h3>", true)
+	body, toc := buildTOC("<h2>Intro</h2><p>x</p><h3>Details</h3>", true, nil)
 	if !strings.Contains(body, `id="intro"`) {
 		t.Fatalf("body = %q, want generated intro heading id", body)
 	}
@@ -228,7 +370,7 @@ func TestBuildTOC(t *testing.T) {
 func TestBuildTOCUnescapesHeadingEntities(t *testing.T) {
 	t.Parallel()
 
-	_, toc := buildTOC("<h2>2014年10月14日 EPSON &gt; マルチフォトカラリオ EP-801A</h2>", true)
+	_, toc := buildTOC("<h2>2014年10月14日 EPSON &gt; マルチフォトカラリオ EP-801A</h2>", true, nil)
 	if !strings.Contains(toc, "2014年10月14日 EPSON &gt; マルチフォトカラリオ EP-801A") {
 		t.Fatalf("toc = %q, want escaped display text for literal > character", toc)
 	}
@@ -240,11 +382,14 @@ func TestBuildTOCUnescapesHeadingEntities(t *testing.T) {
 func TestRenderPostTags(t *testing.T) {
 	t.Parallel()
 
-	html := renderPostTags([]string{"go", "testing"}, true)
-	if !strings.Contains(html, `/archive/go/`) || !strings.Contains(html, `testing`) {
+	html := renderPostTags([]string{"go", "testing"}, "", true)
+	if !strings.Contains(html, `href="/archive/go/"`) || !strings.Contains(html, `testing`) {
 		t.Fatalf("renderPostTags output missing tags: %q", html)
 	}
-	if hidden := renderPostTags([]string{"go"}, false); hidden != "" {
+	if localized := renderPostTags([]string{"go"}, "/en", true); !strings.Contains(localized, `href="/en/archive/go/"`) {
+		t.Fatalf("renderPostTags should link the localized archive, got %q", localized)
+	}
+	if hidden := renderPostTags([]string{"go"}, "", false); hidden != "" {
 		t.Fatalf("renderPostTags should be empty when hidden, got %q", hidden)
 	}
 }
@@ -258,7 +403,7 @@ func TestRenderPostListEscapesExcerpt(t *testing.T) {
 			Slug:    "unsafe-excerpt",
 			Excerpt: `<img src=x onerror=alert(1)>`,
 		},
-	}, false, 160)
+	}, SettingsRecord{ExcerptLength: 160})
 
 	if strings.Contains(got, `<img src=x onerror=alert(1)>`) {
 		t.Fatalf("renderPostList should not render raw excerpt HTML: %q", got)
@@ -288,12 +433,32 @@ func TestRenderCommentsSection(t *testing.T) {
 func TestRenderRelatedPosts(t *testing.T) {
 	t.Parallel()
 
-	got := renderRelatedPosts([]PostRecord{{Title: "One", Slug: "one"}}, "/posts/")
+	got := renderRelatedPosts([]PostRecord{{Title: "One", Slug: "one"}}, "/posts/", nil)
 	if !strings.Contains(got, "/posts/one/") || !strings.Contains(got, "Related Posts") {
 		t.Fatalf("renderRelatedPosts output = %q", got)
 	}
 }
 
+func TestRenderLanguageLinksHidesUnapprovedTranslations(t *testing.T) {
+	t.Parallel()
+
+	source := &PostRecord{Slug: "hello"}
+	translations := []PostTranslationRecord{
+		{Locale: "en", Slug: "hello", TranslationStatus: "approved"},
+		{Locale: "fr", Slug: "hello", TranslationStatus: "machine"},
+	}
+
+	got := renderLanguageLinks("ja", "ja", source, translations, SettingsRecord{}, nil)
+	if !strings.Contains(got, "/fr/posts/hello/") {
+		t.Fatalf("machine translation should be linked by default: %q", got)
+	}
+
+	got = renderLanguageLinks("ja", "ja", source, translations, SettingsRecord{TranslationApprovedOnly: true}, nil)
+	if !strings.Contains(got, "/en/posts/hello/") || strings.Contains(got, "/fr/posts/hello/") {
+		t.Fatalf("approved-only language links = %q", got)
+	}
+}
+
 func TestRenderPageFromRecord(t *testing.T) {
 	t.Parallel()
 
@@ -308,6 +473,48 @@ func TestRenderPageFromRecord(t *testing.T) {
 	}
 }
 
+func TestRenderTranslatedPageFromSnapshot(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.TranslationLocales = "en"
+	about := PageRecord{ID: "page-1", Title: "私たちについて", URL: "/about/", Body: "<p>こんにちは</p>", Published: true, MenuVisible: true, MenuOrder: 1}
+	contact := PageRecord{ID: "page-2", Title: "お問い合わせ", URL: "/contact/", Body: "<p>連絡</p>", Published: true, MenuVisible: true, MenuOrder: 2}
+	translation := PageTranslationRecord{ID: "pt-1", SourcePage: "page-1", Locale: "en", Title: "About us", Body: "<p>Hello</p>", Published: true}
+	ctx := &snapshotBuildContext{
+		settings:             settings,
+		menu:                 buildMenuPages([]PageRecord{about, contact}),
+		publishedPages:       []PageRecord{about, contact},
+		pageByURL:            map[string]PageRecord{about.URL: about, contact.URL: contact},
+		pageTranslationByKey: map[string]PageTranslationRecord{"en|page-1": translation},
+	}
+
+	routes := map[string]bool{}
+	for _, key := range ctx.pageRouteKeys() {
+		routes[key.ID] = true
+	}
+	if !routes["/en/about"] || routes["/en/contact"] {
+		t.Fatalf("pageRouteKeys() = %v", ctx.pageRouteKeys())
+	}
+
+	var html string
+	err := withSnapshotBuildContext(ctx, func() error {
+		if getLocalizedPageByURL("/contact/", "en") != nil {
+			t.Fatalf("untranslated page should have no localized route")
+		}
+		html, _ = renderPageInLocale(getLocalizedPageByURL("/about/", "en"), "en", settings)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withSnapshotBuildContext: %v", err)
+	}
+	for _, token := range []string{`<html lang="en" dir="ltr">`, "<p>Hello</p>", `href="/en/about/">About us</a>`, `href="/contact/">お問い合わせ</a>`, `"inLanguage":"en"`, `"url":"/en/about"`} {
+		if !strings.Contains(html, token) {
+			t.Fatalf("translated page missing %q: %s", token, html)
+		}
+	}
+}
+
 func TestRenderPostFromInputRespectsFlags(t *testing.T) {
 	t.Parallel()
 
@@ -418,3 +625,56 @@ func TestRenderPostFromInputAddsLocalizedOGImageMetaWhenLocalized(t *testing.T)
 		t.Fatalf("localized post output missing localized og:image meta: %q", html)
 	}
 }
+
+func TestExtractSeriesSlug(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		slug string
+		ok   bool
+	}{
+		{path: "/series/go-basics/", slug: "go-basics", ok: true},
+		{path: "/series/go-basics", slug: "go-basics", ok: true},
+		{path: "/series/", ok: false},
+		{path: "/series/go-basics/2", ok: false},
+		{path: "/posts/go-basics/", ok: false},
+	}
+	for _, tt := range tests {
+		slug, ok := extractSeriesSlug(tt.path)
+		if slug != tt.slug || ok != tt.ok {
+			t.Fatalf("extractSeriesSlug(%q) = %q, %v; want %q, %v", tt.path, slug, ok, tt.slug, tt.ok)
+		}
+	}
+}
+
+func TestRenderSeriesBox(t *testing.T) {
+	t.Parallel()
+
+	parts := []seriesPart{
+		{ID: "p1", Title: "Setup", Path: "/posts/setup/"},
+		{ID: "p2", Title: "Routing", Path: "/posts/routing/"},
+		{ID: "p3", Title: "Deploy", Path: "/posts/deploy/"},
+	}
+
+	html := renderSeriesBox("Go basics", "/series/go-basics/", parts, "p2", nil)
+	for _, want := range []string{
+		`<a href="/series/go-basics/">Go basics</a>`,
+		`(part 2 of 3)`,
+		`<li aria-current="page"><strong>Routing</strong></li>`,
+		`<a href="/posts/setup/" rel="prev">`,
+		`<a href="/posts/deploy/" rel="next">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("series box missing %q in %s", want, html)
+		}
+	}
+
+	first := renderSeriesBox("Go basics", "/series/go-basics/", parts, "p1", nil)
+	if strings.Contains(first, `rel="prev"`) || !strings.Contains(first, `<a href="/posts/routing/" rel="next">`) {
+		t.Fatalf("first part navigation = %s", first)
+	}
+	if got := renderSeriesBox("Go basics", "/series/go-basics/", parts, "missing", nil); got != "" {
+		t.Fatalf("series box for unknown part = %q, want empty", got)
+	}
+}
@@ -2,12 +2,12 @@ package site
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 
 	"alleycat-backend/internal/dag"
 )
@@ -19,10 +19,6 @@ type revalidateRequest struct {
 	Original   json.RawMessage `json:"original"`
 }
 
-var snapshotMutation = struct {
-	mu sync.Mutex
-}{}
-
 func handleRevalidate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -61,26 +57,30 @@ func isRevalidateAuthorized(r *http.Request) bool {
 	return r.Header.Get("X-Regen-Token") == token
 }
 
+// applyRevalidation only serializes what has to be: rendering runs under the
+// snapshot build context, and publishing is a compare-and-swap on the live
+// generation. A revalidation that loses the swap to another publish or a
+// rollback redoes its routes on the new live generation.
 func applyRevalidation(req revalidateRequest) error {
-	slog.Info("revalidation lock wait start", "collection", req.Collection, "action", req.Action)
-	snapshotMutation.mu.Lock()
-	defer snapshotMutation.mu.Unlock()
-	slog.Info("revalidation lock acquired", "collection", req.Collection, "action", req.Action)
-
-	root := getPrerenderedSnapshotDir()
-	if root == "" {
+	trigger := "revalidate:" + req.Collection + ":" + req.Action
+	if getPrerenderedSnapshotDir() == "" {
 		slog.Warn("revalidate requested before snapshot was ready; rebuilding whole snapshot", "collection", req.Collection, "action", req.Action)
-		var err error
-		root, err = buildStaticSnapshot()
-		if err != nil {
-			return err
-		}
-		setPrerenderedSnapshotDir(root)
-		return nil
+		_, err := buildAndPublishStaticSnapshot(trigger)
+		return err
 	}
 
 	invalidateDerivedCaches()
-	slog.Info("revalidation context build start", "collection", req.Collection, "action", req.Action, "root", root)
+	switch req.Collection {
+	case "settings", "themes":
+		slog.Info("revalidate mode selected", "mode", "full", "collection", req.Collection, "action", req.Action)
+		return rebuildWholeSnapshot(trigger)
+	case "pages", "posts", "authors", "series", "post_translations", "page_translations":
+	default:
+		slog.Warn("revalidate skipped for unsupported collection", "collection", req.Collection, "action", req.Action)
+		return nil
+	}
+
+	slog.Info("revalidation context build start", "collection", req.Collection, "action", req.Action)
 	ctx, err := newSnapshotBuildContext()
 	if err != nil {
 		slog.Error("revalidation context build failed", "collection", req.Collection, "action", req.Action, "error", err)
@@ -88,34 +88,73 @@ func applyRevalidation(req revalidateRequest) error {
 	}
 	slog.Info("revalidation context build completed", "collection", req.Collection, "action", req.Action)
 
-	return withSnapshotBuildContext(ctx, func() error {
+	for attempt := 1; ; attempt++ {
+		err := revalidateSnapshotGeneration(ctx, req, trigger)
+		if !errors.Is(err, errSnapshotGenerationConflict) || attempt == revalidationPublishAttempts {
+			return err
+		}
+		slog.Warn("revalidation lost the generation swap; retrying", "collection", req.Collection, "action", req.Action, "attempt", attempt)
+	}
+}
+
+const revalidationPublishAttempts = 3
+
+// revalidateSnapshotGeneration applies req to a copy-on-write clone of the
+// live generation, which is only published once every route has been
+// written.
+func revalidateSnapshotGeneration(ctx *snapshotBuildContext, req revalidateRequest, trigger string) error {
+	var live, root string
+	err := withSnapshotBuildContext(ctx, func() error {
+		live = getPrerenderedSnapshotDir()
+		var err error
+		root, err = cloneSnapshotGeneration(live)
+		if err != nil {
+			return err
+		}
+		slog.Info("revalidation render start", "collection", req.Collection, "action", req.Action, "root", root)
+		if err := ctx.refreshDAGSources(sharedSiteDAGContext()); err != nil {
+			return err
+		}
+		ctx.rewindRefreshedDAGSources()
 		switch req.Collection {
-		case "settings":
-			slog.Info("revalidate mode selected", "mode", "full", "collection", req.Collection, "action", req.Action)
-			return rebuildWholeSnapshot()
 		case "pages":
 			slog.Info("revalidate mode selected", "mode", "page", "collection", req.Collection, "action", req.Action)
 			return revalidatePage(root, req)
+		case "page_translations":
+			slog.Info("revalidate mode selected", "mode", "page_translation", "collection", req.Collection, "action", req.Action)
+			return revalidatePageTranslation(root, req)
 		case "posts":
 			slog.Info("revalidate mode selected", "mode", "post", "collection", req.Collection, "action", req.Action)
-			return revalidatePost(root, req)
-		case "post_translations":
-			slog.Info("revalidate mode selected", "mode", "translation", "collection", req.Collection, "action", req.Action)
-			return revalidateTranslation(root, req)
+			if err := revalidatePost(root, req); err != nil {
+				return err
+			}
+			return writeSnapshotSearchIndexes(root, ctx)
+		case "authors":
+			slog.Info("revalidate mode selected", "mode", "author", "collection", req.Collection, "action", req.Action)
+			return revalidateAuthor(root, req)
+		case "series":
+			slog.Info("revalidate mode selected", "mode", "series", "collection", req.Collection, "action", req.Action)
+			return revalidateSeries(root, req)
 		default:
-			slog.Warn("revalidate skipped for unsupported collection", "collection", req.Collection, "action", req.Action)
-			return nil
+			slog.Info("revalidate mode selected", "mode", "translation", "collection", req.Collection, "action", req.Action)
+			if err := revalidateTranslation(root, req); err != nil {
+				return err
+			}
+			return writeSnapshotSearchIndexes(root, ctx)
 		}
 	})
+	if err == nil {
+		err = publishSnapshotGenerationOver(root, live, trigger)
+	}
+	if err != nil && root != "" {
+		discardSnapshotGeneration(root)
+	}
+	return err
 }
 
-func rebuildWholeSnapshot() error {
-	root, err := buildStaticSnapshot()
-	if err != nil {
-		return err
-	}
-	setPrerenderedSnapshotDir(root)
-	return nil
+func rebuildWholeSnapshot(trigger string) error {
+	_, err := buildAndPublishStaticSnapshot(trigger)
+	return err
 }
 
 func revalidatePage(root string, req revalidateRequest) error {
@@ -123,31 +162,89 @@ func revalidatePage(root string, req revalidateRequest) error {
 	original := decodePageRecord(req.Original)
 	slog.Info("revalidate page start", "action", req.Action, "current_url", valueOrEmptyPageURL(current), "original_url", valueOrEmptyPageURL(original))
 
+	locales := parseTranslationLocales(currentRevalidationSettings().TranslationLocales)
+
 	if original != nil && strings.TrimSpace(original.URL) != "" {
-		slog.Info("revalidate page remove original route", "route", original.URL)
-		if err := removeSnapshotRoute(root, original.URL); err != nil {
-			return err
+		routes := []string{original.URL}
+		for _, locale := range locales {
+			routes = append(routes, localePathPrefix(locale)+original.URL)
+		}
+		for _, route := range routes {
+			slog.Info("revalidate page remove original route", "route", route)
+			if err := removeSnapshotRoute(root, route); err != nil {
+				return err
+			}
 		}
 	}
 	if current != nil && current.Published && strings.TrimSpace(current.URL) != "" {
 		slog.Info("revalidate page render current route", "route", current.URL)
-		body, ok, err := renderRouteFromDAG(current.URL)
-		if err != nil {
+		if err := writeDAGPageRoute(root, current.URL); err != nil {
 			return err
 		}
-		if ok {
-			if err := writeSnapshotRoute(root, current.URL, body); err != nil {
+		for _, locale := range locales {
+			if getPageTranslation(current.ID, locale) == nil {
+				continue
+			}
+			route := localePathPrefix(locale) + current.URL
+			slog.Info("revalidate page render translated route", "route", route)
+			if err := writeDAGPageRoute(root, route); err != nil {
 				return err
 			}
 		}
 	}
-	if err := revalidateDAGAffectedRoutes(root, dagChangedKeysForPage(current, original), nil); err != nil {
+	if err := revalidateDAGAffectedRoutes(root, dagChangedKeysForPage(current, original, locales), nil); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// revalidatePageTranslation re-renders the translated page and, through the
+// DAG, every route showing the locale's menu.
+func revalidatePageTranslation(root string, req revalidateRequest) error {
+	current := decodePageTranslationRecord(req.Current)
+	original := decodePageTranslationRecord(req.Original)
+	slog.Info("revalidate page translation start", "action", req.Action, "current_locale", valueOrEmptyPageTranslationLocale(current), "original_locale", valueOrEmptyPageTranslationLocale(original))
+
+	if route, ok := pageTranslationRoute(original); ok {
+		slog.Info("revalidate page translation remove original route", "route", route)
+		if err := removeSnapshotRoute(root, route); err != nil {
+			return err
+		}
+	}
+	if route, ok := pageTranslationRoute(current); ok && getPageTranslation(current.SourcePage, current.Locale) != nil {
+		slog.Info("revalidate page translation render current route", "route", route)
+		if err := writeDAGPageRoute(root, route); err != nil {
+			return err
+		}
+	}
+	return revalidateDAGAffectedRoutes(root, dagChangedKeysForPageTranslation(current, original), nil)
+}
+
+// pageTranslationRoute is /<locale> plus the URL of the translation's
+// published source page.
+func pageTranslationRoute(item *PageTranslationRecord) (string, bool) {
+	if item == nil || normalizeLocale(item.Locale) == "" {
+		return "", false
+	}
+	source := getPageByID(item.SourcePage)
+	if source == nil || strings.TrimSpace(source.URL) == "" {
+		return "", false
+	}
+	return localePathPrefix(item.Locale) + strings.TrimSpace(source.URL), true
+}
+
+func writeDAGPageRoute(root, route string) error {
+	body, ok, err := renderRouteFromDAG(route)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return writeSnapshotRoute(root, route, body)
+}
+
 func revalidatePost(root string, req revalidateRequest) error {
 	current := decodePostRecord(req.Current)
 	original := decodePostRecord(req.Original)
@@ -185,6 +282,7 @@ func revalidatePost(root string, req revalidateRequest) error {
 	if snapshot := currentSnapshotBuildContext(); snapshot != nil {
 		extraRoutes = snapshot.adjacentBaseRouteKeysForPosts(current, original)
 	}
+	extraRoutes = append(extraRoutes, seriesExtraRoutesForPosts(current, original)...)
 	if err := revalidateDAGAffectedPostRoutes(root, dagChangedKeysForPost(current, original), extraRoutes); err != nil {
 		return err
 	}
@@ -197,7 +295,7 @@ func revalidatePost(root string, req revalidateRequest) error {
 		return err
 	}
 	impact := analyzePostImpact(current, original)
-	slog.Info("revalidate post impact analyzed", "home", impact.home, "main_archive", impact.mainArchive, "tag_routes", len(impact.tagArchives), "category_routes", len(impact.categoryDirs))
+	slog.Info("revalidate post impact analyzed", "home", impact.home, "main_archive", impact.mainArchive, "tag_routes", len(impact.tagArchives), "category_routes", len(impact.categoryDirs), "author_routes", len(impact.authorArchives))
 	if impact.home || impact.mainArchive {
 		settings := currentRevalidationSettings()
 		slog.Info("revalidate post home/archive start")
@@ -223,7 +321,7 @@ func revalidateTranslation(root string, req revalidateRequest) error {
 
 	if !dagPostRouteRevalidationEnabled() && current != nil && current.Published && strings.TrimSpace(current.Locale) != "" && strings.TrimSpace(current.Slug) != "" {
 		settings := currentRevalidationSettings()
-		if isEnabledTranslationLocale(settings, current.Locale) {
+		if isEnabledTranslationLocale(settings, current.Locale) && (!settings.TranslationApprovedOnly || isApprovedTranslation(*current)) {
 			route := "/" + normalizeLocale(current.Locale) + "/posts/" + current.Slug + "/"
 			slog.Info("revalidate translation render current route", "route", route)
 			post := translationToPost(*current)
@@ -261,14 +359,140 @@ func revalidateTranslation(root string, req revalidateRequest) error {
 	if err := revalidateAdjacentTranslationContext(root, current, original); err != nil {
 		return err
 	}
-	return nil
+	return revalidateLocalizedListings(root, current, original)
+}
+
+// revalidateAuthor re-renders the author's archive series and the posts that
+// carry their byline. A slug change drops the old archive directory.
+func revalidateAuthor(root string, req revalidateRequest) error {
+	current := decodeAuthorRecord(req.Current)
+	original := decodeAuthorRecord(req.Original)
+	slog.Info("revalidate author start", "action", req.Action, "current_slug", valueOrEmptyAuthorSlug(current), "original_slug", valueOrEmptyAuthorSlug(original))
+
+	if original != nil && strings.TrimSpace(original.Slug) != "" && valueOrEmptyAuthorSlug(current) != strings.TrimSpace(original.Slug) {
+		slog.Info("revalidate author remove original archive", "route", authorArchivePath(original.Slug))
+		if err := clearArchiveRoute(root, authorArchivePath(original.Slug)); err != nil {
+			return err
+		}
+	}
+
+	snapshot := currentSnapshotBuildContext()
+	if !dagPostRouteRevalidationEnabled() {
+		settings := currentRevalidationSettings()
+		if current != nil && strings.TrimSpace(current.Slug) != "" {
+			slog.Info("revalidate author archive rebuild start", "route", authorArchivePath(current.Slug))
+			if err := rebuildArchiveRoute(root, settings, authorArchivePath(current.Slug)); err != nil {
+				return err
+			}
+		}
+		if snapshot != nil {
+			seen := map[string]struct{}{}
+			for _, item := range []*AuthorRecord{current, original} {
+				if item == nil {
+					continue
+				}
+				for _, post := range snapshot.postsByAuthor[strings.TrimSpace(item.User)] {
+					if _, ok := seen[post.ID]; ok {
+						continue
+					}
+					seen[post.ID] = struct{}{}
+					if err := revalidatePostRecordAndTranslations(root, post.ID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	extraRoutes := []dagExtraRoute(nil)
+	if snapshot != nil && current != nil && strings.TrimSpace(current.Slug) != "" {
+		for _, key := range snapshot.listingRouteKeysForBase(authorArchivePath(current.Slug)) {
+			extraRoutes = append(extraRoutes, dagExtraRoute{
+				Key:     key,
+				Reasons: []string{"author archive for " + authorBySlugNodeKey(strings.TrimSpace(current.Slug)).String()},
+			})
+		}
+	}
+	return revalidateDAGAffectedRoutes(root, dagChangedKeysForAuthor(current, original), extraRoutes)
+}
+
+func revalidateSeries(root string, req revalidateRequest) error {
+	current := decodeSeriesRecord(req.Current)
+	original := decodeSeriesRecord(req.Original)
+	slog.Info("revalidate series start", "action", req.Action, "current_slug", valueOrEmptySeriesSlug(current), "original_slug", valueOrEmptySeriesSlug(original))
+
+	if original != nil && strings.TrimSpace(original.Slug) != "" && valueOrEmptySeriesSlug(current) != strings.TrimSpace(original.Slug) {
+		slog.Info("revalidate series remove original index", "route", seriesIndexPath(original.Slug))
+		if err := removeSnapshotRoute(root, seriesIndexPath(original.Slug)); err != nil {
+			return err
+		}
+	}
+
+	postIDs := []string{}
+	seen := map[string]struct{}{}
+	for _, item := range []*SeriesRecord{current, original} {
+		if item == nil {
+			continue
+		}
+		for _, postID := range item.Posts {
+			postID = strings.TrimSpace(postID)
+			if postID == "" {
+				continue
+			}
+			if _, ok := seen[postID]; ok {
+				continue
+			}
+			seen[postID] = struct{}{}
+			postIDs = append(postIDs, postID)
+		}
+	}
+
+	if !dagPostRouteRevalidationEnabled() {
+		for _, postID := range postIDs {
+			if err := revalidatePostRecordAndTranslations(root, postID); err != nil {
+				return err
+			}
+		}
+		if current != nil && strings.TrimSpace(current.Slug) != "" {
+			slog.Info("revalidate series index render", "route", seriesIndexPath(current.Slug))
+			html, ok := renderSeriesIndex(getSeriesBySlug(current.Slug), currentRevalidationSettings())
+			if ok {
+				if err := writeSnapshotRoute(root, seriesIndexPath(current.Slug), []byte(html)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	changed := dagChangedKeysForSeries(current, original)
+	reason := "series part"
+	if len(changed) > 0 {
+		reason = "series part of " + changed[0].String()
+	}
+	extraRoutes := make([]dagExtraRoute, 0, len(postIDs)+1)
+	for _, route := range seriesPartRoutes(postIDs) {
+		extraRoutes = append(extraRoutes, dagExtraRoute{
+			Key:     routeNodeKey(route),
+			Reasons: []string{reason},
+		})
+	}
+	if current != nil && strings.TrimSpace(current.Slug) != "" {
+		extraRoutes = append(extraRoutes, dagExtraRoute{
+			Key:     routeNodeKey(seriesIndexPath(current.Slug)),
+			Reasons: []string{"series index for " + seriesNodeKey(strings.TrimSpace(current.Slug)).String()},
+		})
+	}
+	return revalidateDAGAffectedRoutes(root, changed, extraRoutes)
 }
 
 type postRevalidationImpact struct {
-	home         bool
-	mainArchive  bool
-	tagArchives  []string
-	categoryDirs []string
+	home           bool
+	mainArchive    bool
+	tagArchives    []string
+	categoryDirs   []string
+	authorArchives []string
 }
 
 func revalidateHomeAndArchives(root string, settings SettingsRecord, current, original *PostRecord, impact postRevalidationImpact) error {
@@ -285,14 +509,14 @@ func revalidateDAGListingRoutes(root string, impact postRevalidationImpact) erro
 		return nil
 	}
 
-	routes := make([]dag.NodeKey, 0, 1+1+len(impact.tagArchives)+len(impact.categoryDirs))
+	routes := make([]dag.NodeKey, 0, 1+1+len(impact.tagArchives)+len(impact.categoryDirs)+len(impact.authorArchives))
 	if impact.home {
 		routes = append(routes, snapshot.listingRouteKeysForBase("/")...)
 	}
 	if impact.mainArchive {
 		routes = append(routes, snapshot.listingRouteKeysForBase("/archive/")...)
 	}
-	for _, route := range append(append([]string(nil), impact.tagArchives...), impact.categoryDirs...) {
+	for _, route := range append(append(append([]string(nil), impact.tagArchives...), impact.categoryDirs...), impact.authorArchives...) {
 		routes = append(routes, snapshot.listingRouteKeysForBase(route)...)
 	}
 
@@ -331,7 +555,7 @@ func revalidateLegacyListingRoutes(root string, current, original *PostRecord, i
 			return err
 		}
 	}
-	for _, route := range append(append([]string(nil), impact.tagArchives...), impact.categoryDirs...) {
+	for _, route := range append(append(append([]string(nil), impact.tagArchives...), impact.categoryDirs...), impact.authorArchives...) {
 		slog.Info("revalidate archive route rebuild start", "route", route)
 		if err := rebuildArchiveRoute(root, settings, route); err != nil {
 			return err
@@ -340,6 +564,79 @@ func revalidateLegacyListingRoutes(root string, current, original *PostRecord, i
 	return nil
 }
 
+// revalidateLocalizedListings re-renders the home, archive, tag and category
+// listings of the locales a translation was and is in. Tag and category
+// directories are cleared first so a listing that lost its last post goes
+// away.
+func revalidateLocalizedListings(root string, current, original *PostTranslationRecord) error {
+	snapshot := currentSnapshotBuildContext()
+	if snapshot == nil {
+		return nil
+	}
+	settings := snapshot.settings
+	for _, basePath := range collectLocalizedListingRoutes(settings, current, original) {
+		route := parseArchiveRoute(basePath)
+		if route.locale != "" && !route.isRoot() {
+			if err := clearArchiveRoute(root, basePath); err != nil {
+				return err
+			}
+		}
+		for _, key := range snapshot.listingRouteKeysForBase(basePath) {
+			slog.Info("revalidate localized listing route", "route", key.ID)
+			body, ok, err := renderLocalizedListingRoute(key.ID, settings)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := writeSnapshotRoute(root, key.ID, body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func renderLocalizedListingRoute(path string, settings SettingsRecord) ([]byte, bool, error) {
+	if dagPostRouteRevalidationEnabled() {
+		return renderRouteFromDAG(path)
+	}
+	if locale, rest, _ := extractLocalizedListingRoute(path); rest == "/" {
+		return []byte(renderLocalizedHome(locale, settings)), true, nil
+	}
+	return []byte(renderArchive(path+"/", "", settings)), true, nil
+}
+
+// collectLocalizedListingRoutes lists the listing base paths a translation
+// appears in, for enabled locales only.
+func collectLocalizedListingRoutes(settings SettingsRecord, items ...*PostTranslationRecord) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	add := func(route string) {
+		if _, ok := seen[route]; ok {
+			return
+		}
+		seen[route] = struct{}{}
+		out = append(out, route)
+	}
+	for _, item := range items {
+		if item == nil || !isEnabledTranslationLocale(settings, item.Locale) {
+			continue
+		}
+		prefix := localePathPrefix(item.Locale)
+		add(prefix + "/")
+		add(prefix + "/archive/")
+		for _, tag := range parseTags(item.Tags) {
+			add(prefix + "/archive/" + url.PathEscape(tag) + "/")
+		}
+		if category := strings.TrimSpace(item.Category); category != "" {
+			add(prefix + "/archive/category/" + url.PathEscape(category) + "/")
+		}
+	}
+	return out
+}
+
 func analyzePostImpact(current, original *PostRecord) postRevalidationImpact {
 	impact := postRevalidationImpact{}
 	wasPublished := snapshotPublishedPost(original)
@@ -351,6 +648,7 @@ func analyzePostImpact(current, original *PostRecord) postRevalidationImpact {
 	impact.mainArchive = true
 	impact.tagArchives = collectTagArchiveRoutes(current, original)
 	impact.categoryDirs = collectCategoryArchiveRoutes(current, original)
+	impact.authorArchives = collectAuthorArchiveRoutes(current, original)
 	return impact
 }
 
@@ -419,8 +717,8 @@ func clearArchiveRoute(root string, basePath string) error {
 }
 
 func archiveFilterForBasePath(basePath string) (string, bool) {
-	route := parseArchiveRoute(basePath)
-	if route.basePath != cleanPath(basePath) {
+	route, ok := resolveArchiveRoute(basePath)
+	if !ok || route.basePath != cleanPath(basePath) {
 		return "", false
 	}
 	return route.filter, true
@@ -513,9 +811,13 @@ func renderLegacyPostRoute(fallback func() (string, bool)) ([]byte, bool, error)
 }
 
 func renderRouteFromDAG(path string) ([]byte, bool, error) {
-	engine := newSiteDAGEngine()
-	resolveCtx := engine.NewContext()
-	value, err := engine.Resolve(resolveCtx, routeNodeKey(path))
+	resolveCtx := sharedSiteDAGContext()
+	if snapshot := currentSnapshotBuildContext(); snapshot != nil {
+		if err := snapshot.refreshDAGSources(resolveCtx); err != nil {
+			return nil, false, err
+		}
+	}
+	value, err := resolveCtx.Resolve(routeNodeKey(path))
 	if err != nil {
 		return nil, false, err
 	}
@@ -570,13 +872,62 @@ func dagChangedKeysForTranslation(current, original *PostTranslationRecord) []da
 	return keys
 }
 
-func dagChangedKeysForPage(current, original *PageRecord) []dag.NodeKey {
-	keys := make([]dag.NodeKey, 0, 2)
+// dagChangedKeysForPage covers the page's translations in locales too: they
+// are served under the source page's URL.
+func dagChangedKeysForPage(current, original *PageRecord, locales []string) []dag.NodeKey {
+	keys := make([]dag.NodeKey, 0, 2*(1+len(locales)))
 	for _, item := range []*PageRecord{current, original} {
 		if item == nil || strings.TrimSpace(item.URL) == "" {
 			continue
 		}
-		keys = appendUniqueDAGNodeKey(keys, pageByURLNodeKey(strings.TrimSpace(item.URL)))
+		keys = appendUniqueDAGNodeKey(keys, pageByURLNodeKey("", strings.TrimSpace(item.URL)))
+		for _, locale := range locales {
+			keys = appendUniqueDAGNodeKey(keys, pageByURLNodeKey(locale, strings.TrimSpace(item.URL)))
+		}
+	}
+	return keys
+}
+
+func dagChangedKeysForPageTranslation(current, original *PageTranslationRecord) []dag.NodeKey {
+	keys := make([]dag.NodeKey, 0, 2)
+	for _, item := range []*PageTranslationRecord{current, original} {
+		if item == nil || normalizeLocale(item.Locale) == "" {
+			continue
+		}
+		source := getPageByID(item.SourcePage)
+		if source == nil || strings.TrimSpace(source.URL) == "" {
+			continue
+		}
+		keys = appendUniqueDAGNodeKey(keys, pageByURLNodeKey(normalizeLocale(item.Locale), strings.TrimSpace(source.URL)))
+	}
+	return keys
+}
+
+func dagChangedKeysForAuthor(current, original *AuthorRecord) []dag.NodeKey {
+	keys := make([]dag.NodeKey, 0, 4)
+	for _, item := range []*AuthorRecord{current, original} {
+		if item == nil {
+			continue
+		}
+		if user := strings.TrimSpace(item.User); user != "" {
+			keys = appendUniqueDAGNodeKey(keys, authorProfileNodeKey(user))
+		}
+		if slug := strings.TrimSpace(item.Slug); slug != "" {
+			keys = appendUniqueDAGNodeKey(keys, authorBySlugNodeKey(slug))
+		}
+	}
+	return keys
+}
+
+func dagChangedKeysForSeries(current, original *SeriesRecord) []dag.NodeKey {
+	keys := make([]dag.NodeKey, 0, 2)
+	for _, item := range []*SeriesRecord{current, original} {
+		if item == nil {
+			continue
+		}
+		if slug := strings.TrimSpace(item.Slug); slug != "" {
+			keys = appendUniqueDAGNodeKey(keys, seriesNodeKey(slug))
+		}
 	}
 	return keys
 }
@@ -611,15 +962,33 @@ func revalidateDAGAffectedRoutes(root string, changed []dag.NodeKey, extraRoutes
 		return nil
 	}
 
-	engine := newSiteDAGEngine()
-	resolveCtx := engine.NewContext()
+	resolveCtx := sharedSiteDAGContext()
+	if err := snapshot.refreshDAGSources(resolveCtx); err != nil {
+		return err
+	}
+	refreshed := snapshot.takeRefreshedDAGSources()
+	evicted := resolveCtx.Invalidate(changed)
+	extraKeys := make([]dag.NodeKey, 0, len(extraRoutes))
+	for _, route := range extraRoutes {
+		extraKeys = append(extraKeys, route.Key)
+	}
+	resolveCtx.Invalidate(extraKeys)
+
+	currentRoutes := map[dag.NodeKey]struct{}{}
 	for _, route := range snapshot.routeKeys() {
-		if _, err := engine.Resolve(resolveCtx, route); err != nil {
+		currentRoutes[route] = struct{}{}
+		if _, err := resolveCtx.Resolve(route); err != nil {
 			return err
 		}
 	}
 
-	routes := dagAffectedRouteKeysFromChanged(resolveCtx, changed)
+	sources := append(append([]dag.NodeKey(nil), changed...), refreshed...)
+	routes := dagAffectedRouteKeysFromChanged(resolveCtx, sources)
+	for _, key := range evicted {
+		if key.Kind == nodeRoute {
+			routes = appendUniqueDAGNodeKey(routes, key)
+		}
+	}
 	extraReasons := map[dag.NodeKey][]string{}
 	for _, route := range extraRoutes {
 		routes = appendUniqueDAGNodeKey(routes, route.Key)
@@ -627,8 +996,11 @@ func revalidateDAGAffectedRoutes(root string, changed []dag.NodeKey, extraRoutes
 	}
 
 	for _, route := range routes {
-		slog.Info("revalidate DAG affected route", "route", route.ID, "reasons", dagAffectedRouteReasons(resolveCtx, changed, route, extraReasons[route]))
-		value, err := engine.Resolve(resolveCtx, route)
+		if _, ok := currentRoutes[route]; !ok {
+			continue
+		}
+		slog.Info("revalidate DAG affected route", "route", route.ID, "reasons", dagAffectedRouteReasons(resolveCtx, sources, route, extraReasons[route]))
+		value, err := resolveCtx.Resolve(route)
 		if err != nil {
 			return err
 		}
@@ -687,10 +1059,10 @@ func revalidateTranslationContext(root string, current, original *PostTranslatio
 		}
 		sourcePost := getPostByID(sourceID)
 		impact := analyzePostImpact(sourcePost, sourcePost)
-		if !impact.home && !impact.mainArchive && len(impact.tagArchives) == 0 && len(impact.categoryDirs) == 0 {
+		if !impact.home && !impact.mainArchive && len(impact.tagArchives) == 0 && len(impact.categoryDirs) == 0 && len(impact.authorArchives) == 0 {
 			continue
 		}
-		slog.Info("revalidate translation archive impact analyzed", "source_post_id", sourceID, "home", impact.home, "main_archive", impact.mainArchive, "tag_routes", len(impact.tagArchives), "category_routes", len(impact.categoryDirs))
+		slog.Info("revalidate translation archive impact analyzed", "source_post_id", sourceID, "home", impact.home, "main_archive", impact.mainArchive, "tag_routes", len(impact.tagArchives), "category_routes", len(impact.categoryDirs), "author_routes", len(impact.authorArchives))
 		if err := revalidateHomeAndArchives(root, settings, sourcePost, sourcePost, impact); err != nil {
 			return err
 		}
@@ -794,6 +1166,13 @@ func valueOrEmptyPageURL(item *PageRecord) string {
 	return strings.TrimSpace(item.URL)
 }
 
+func valueOrEmptyPageTranslationLocale(item *PageTranslationRecord) string {
+	if item == nil {
+		return ""
+	}
+	return item.Locale
+}
+
 func valueOrEmptyTranslationLocale(item *PostTranslationRecord) string {
 	if item == nil {
 		return ""
@@ -808,6 +1187,42 @@ func valueOrEmptyTranslationSlug(item *PostTranslationRecord) string {
 	return strings.TrimSpace(item.Slug)
 }
 
+func valueOrEmptyAuthorSlug(item *AuthorRecord) string {
+	if item == nil {
+		return ""
+	}
+	return strings.TrimSpace(item.Slug)
+}
+
+func valueOrEmptySeriesSlug(item *SeriesRecord) string {
+	if item == nil {
+		return ""
+	}
+	return strings.TrimSpace(item.Slug)
+}
+
+func decodeAuthorRecord(data json.RawMessage) *AuthorRecord {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var out AuthorRecord
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return &out
+}
+
+func decodeSeriesRecord(data json.RawMessage) *SeriesRecord {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var out SeriesRecord
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return &out
+}
+
 func decodePostRecord(data json.RawMessage) *PostRecord {
 	if len(data) == 0 || string(data) == "null" {
 		return nil
@@ -830,6 +1245,17 @@ func decodePageRecord(data json.RawMessage) *PageRecord {
 	return &out
 }
 
+func decodePageTranslationRecord(data json.RawMessage) *PageTranslationRecord {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var out PageTranslationRecord
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return &out
+}
+
 func decodeTranslationRecord(data json.RawMessage) *PostTranslationRecord {
 	if len(data) == 0 || string(data) == "null" {
 		return nil
@@ -849,6 +1275,11 @@ func removeSnapshotRoute(root, route string) error {
 	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	for _, encoding := range snapshotEncodings {
+		if err := os.Remove(target + encoding.suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	return nil
 }
 
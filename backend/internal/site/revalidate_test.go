@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -351,6 +352,109 @@ func TestRevalidatePageUsesDAGToUpdateRoutesAffectedByMenuChange(t *testing.T) {
 	}
 }
 
+func TestRevalidatePageTranslationUpdatesTranslatedPageAndLocalizedMenus(t *testing.T) {
+	t.Setenv("SITE_DAG_POST_ROUTES", "true")
+	// Start from an empty graph so sources cached by other tests do not
+	// count as changed here.
+	setSharedSiteDAGContext(newSiteDAGEngine().NewContext())
+
+	root := t.TempDir()
+	settings := defaultSettings()
+	settings.TranslationLocales = "en"
+
+	page := PageRecord{
+		ID:          "page-1",
+		Title:       "私たちについて",
+		URL:         "/about/",
+		Body:        "<p>こんにちは</p>",
+		Published:   true,
+		MenuVisible: true,
+	}
+	translation := PageTranslationRecord{
+		ID:         "pt-1",
+		SourcePage: page.ID,
+		Locale:     "en",
+		Title:      "About us",
+		Body:       "<p>Hello</p>",
+		Published:  true,
+	}
+
+	targets := map[string]string{}
+	for _, route := range []string{"/about/", "/en/about/", "/en/"} {
+		target, err := snapshotFilePath(root, route)
+		if err != nil {
+			t.Fatalf("snapshotFilePath(%q): %v", route, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatalf("MkdirAll(%q): %v", route, err)
+		}
+		if err := os.WriteFile(target, []byte("stale"), 0o644); err != nil {
+			t.Fatalf("WriteFile(%q): %v", route, err)
+		}
+		targets[route] = target
+	}
+
+	ctx := &snapshotBuildContext{
+		settings:             settings,
+		menu:                 []PageRecord{page},
+		publishedPages:       []PageRecord{page},
+		postBySlug:           map[string]PostRecord{},
+		postByID:             map[string]PostRecord{},
+		pageByURL:            map[string]PageRecord{page.URL: page},
+		pageTranslationByKey: map[string]PageTranslationRecord{"en|page-1": translation},
+		translationByKey:     map[string]PostTranslationRecord{},
+		translationsBySource: map[string][]PostTranslationRecord{},
+		translationsByLocale: map[string][]PostTranslationRecord{"en": nil},
+		postsByTag:           map[string][]PostRecord{},
+		postsByCategory:      map[string][]PostRecord{},
+		archiveIndex:         map[string]archiveListing{},
+	}
+
+	req := revalidateRequest{Current: mustMarshalJSONRaw(t, translation)}
+	err := withSnapshotBuildContext(ctx, func() error {
+		return revalidatePageTranslation(root, req)
+	})
+	if err != nil {
+		t.Fatalf("revalidatePageTranslation: %v", err)
+	}
+
+	for route, token := range map[string]string{
+		"/en/about/": "<p>Hello</p>",
+		"/en/":       `href="/en/about/">About us</a>`,
+	} {
+		body, err := os.ReadFile(targets[route])
+		if err != nil {
+			t.Fatalf("ReadFile(%q): %v", route, err)
+		}
+		if !strings.Contains(string(body), token) {
+			t.Fatalf("%q missing %q: %s", route, token, body)
+		}
+	}
+	if body, _ := os.ReadFile(targets["/about/"]); string(body) != "stale" {
+		t.Fatalf("source page should not depend on its translation: %s", body)
+	}
+}
+
+func TestDAGChangedKeysForPageCoversTranslatedRoutes(t *testing.T) {
+	t.Parallel()
+
+	current := &PageRecord{URL: "/about-us/"}
+	original := &PageRecord{URL: "/about/"}
+	keys := dagChangedKeysForPage(current, original, []string{"en", "fr"})
+	for _, want := range []dag.NodeKey{
+		pageByURLNodeKey("", "/about-us/"),
+		pageByURLNodeKey("en", "/about-us/"),
+		pageByURLNodeKey("fr", "/about/"),
+	} {
+		if !slices.Contains(keys, want) {
+			t.Fatalf("dagChangedKeysForPage() = %v, missing %v", keys, want)
+		}
+	}
+	if key := pageByURLNodeKey("en", "/about/"); key.Kind != nodePageTranslationByURL || key.Scope != "en" {
+		t.Fatalf("translated page key = %#v", key)
+	}
+}
+
 func TestRevalidateTranslationUsesDAGToUpdateCurrentRouteWhenEnabled(t *testing.T) {
 	t.Setenv("SITE_DAG_POST_ROUTES", "true")
 
@@ -426,6 +530,70 @@ func TestRevalidateTranslationUsesDAGToUpdateCurrentRouteWhenEnabled(t *testing.
 	}
 }
 
+func TestRevalidateLocalizedListingsFollowTranslationTags(t *testing.T) {
+	t.Setenv("SITE_DAG_POST_ROUTES", "")
+
+	root := t.TempDir()
+	settings := defaultSettings()
+	settings.TranslationLocales = "ru"
+
+	original := PostTranslationRecord{
+		ID:          "tr-1",
+		SourcePost:  "post-1",
+		Locale:      "ru",
+		Slug:        "privet",
+		Title:       "Privet",
+		Body:        "<p>body</p>",
+		Tags:        "old",
+		Published:   true,
+		PublishedAt: "2026-04-16T10:00:00Z",
+	}
+	current := original
+	current.Tags = "new"
+
+	stale, err := snapshotFilePath(root, "/ru/archive/old/")
+	if err != nil {
+		t.Fatalf("snapshotFilePath: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(stale, []byte("stale"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx := &snapshotBuildContext{
+		settings:             settings,
+		translationsByLocale: map[string][]PostTranslationRecord{"ru": {current}},
+		archiveIndex:         map[string]archiveListing{},
+	}
+	ctx.indexLocalizedArchives("ru")
+
+	err = withSnapshotBuildContext(ctx, func() error {
+		return revalidateLocalizedListings(root, &current, &original)
+	})
+	if err != nil {
+		t.Fatalf("revalidateLocalizedListings: %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("old tag listing should be removed, stat err = %v", err)
+	}
+	for _, route := range []string{"/ru/", "/ru/archive/", "/ru/archive/new/"} {
+		target, err := snapshotFilePath(root, route)
+		if err != nil {
+			t.Fatalf("snapshotFilePath %s: %v", route, err)
+		}
+		body, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("ReadFile %s: %v", route, err)
+		}
+		if !strings.Contains(string(body), `href="/ru/posts/privet/"`) {
+			t.Fatalf("%s missing translation: %s", route, body)
+		}
+	}
+}
+
 func TestRevalidatePostRemovesOldRouteAndUpdatesNeighborWhenUnpublishedWithDAG(t *testing.T) {
 	t.Setenv("SITE_DAG_POST_ROUTES", "true")
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 func writeRobotsTXT(w http.ResponseWriter, r *http.Request, settings SettingsRecord) {
@@ -20,7 +21,5 @@ func writeRobotsTXT(w http.ResponseWriter, r *http.Request, settings SettingsRec
 		}
 	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	setNoStoreCacheHeaders(w)
-	_, _ = w.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	writeCacheableBody(w, r, sitemapCacheControl, "text/plain; charset=utf-8", []byte(strings.Join(lines, "\n")+"\n"), time.Time{})
 }
@@ -0,0 +1,216 @@
+// Searches the exported search-index.json in the browser, so archive search
+// also works when the snapshot is served from static hosting. The tokenizer
+// and BM25 scoring mirror search_index.go.
+(function () {
+  "use strict";
+
+  var cjk = /[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}ー]/u;
+  var word = /[\p{L}\p{Nd}]/u;
+  var mark = /\p{Mn}/u;
+  var indexes = {};
+
+  function normalize(value) {
+    var out = "";
+    for (var ch of value) {
+      var folded = ch.normalize("NFKC");
+      if (Array.from(folded).length === 1) ch = folded;
+      out += ch.toLowerCase();
+    }
+    return out;
+  }
+
+  function tokenize(value) {
+    var runes = Array.from(normalize(value));
+    var tokens = [];
+    for (var i = 0; i < runes.length; ) {
+      var j = i;
+      if (cjk.test(runes[i])) {
+        while (j < runes.length && cjk.test(runes[j])) j++;
+        if (j - i === 1) tokens.push(runes[i]);
+        for (var k = i; k + 1 < j; k++) tokens.push(runes[k] + runes[k + 1]);
+        i = j;
+      } else if (word.test(runes[i])) {
+        while (j < runes.length && !cjk.test(runes[j]) && (word.test(runes[j]) || mark.test(runes[j]))) j++;
+        tokens.push(runes.slice(i, j).join(""));
+        i = j;
+      } else {
+        i++;
+      }
+    }
+    return tokens;
+  }
+
+  // The export carries no term positions, so a quoted phrase matches like
+  // its words, and highlighted snippets are left to the site server.
+  function parseQuery(raw) {
+    var query = { terms: [], tags: [], categories: [] };
+    var words = [];
+    var re = /"([^"]*)"?|(\S+)/g;
+    var match;
+    while ((match = re.exec(raw)) !== null) {
+      if (match[1] !== undefined) {
+        if (match[1].trim()) words.push(match[1]);
+        continue;
+      }
+      var item = match[2];
+      var lower = item.toLowerCase();
+      if (lower.indexOf("tag:") === 0 && item.length > 4) {
+        query.tags.push(normalize(item.slice(4).replace(/^"+|"+$/g, "")));
+      } else if (lower.indexOf("category:") === 0 && item.length > 9) {
+        query.categories.push(normalize(item.slice(9).replace(/^"+|"+$/g, "")));
+      } else {
+        words.push(item);
+      }
+    }
+    words.forEach(function (item) {
+      tokenize(item).forEach(function (term) {
+        if (query.terms.indexOf(term) < 0) query.terms.push(term);
+      });
+    });
+    return query;
+  }
+
+  function search(index, query, scope) {
+    var total = index.docs.length;
+    var avg = index.avg_length > 0 ? index.avg_length : 1;
+    var scores = null;
+    query.terms.forEach(function (term) {
+      var postings = index.terms[term] || [];
+      var df = postings.length / 2;
+      var idf = Math.log(1 + (total - df + 0.5) / (df + 0.5));
+      var next = {};
+      for (var i = 0; i < postings.length; i += 2) {
+        var pos = postings[i];
+        if (scores !== null && !(pos in scores)) continue;
+        var freq = postings[i + 1];
+        var norm = index.k1 * (1 - index.b + (index.b * index.docs[pos].length) / avg);
+        next[pos] = (scores === null ? 0 : scores[pos]) + (idf * freq * (index.k1 + 1)) / (freq + norm);
+      }
+      scores = next;
+    });
+    if (scores === null) {
+      scores = {};
+      index.docs.forEach(function (_, pos) {
+        scores[pos] = 0;
+      });
+    }
+
+    var tags = query.tags.concat(scope.tag ? [normalize(scope.tag)] : []);
+    var categories = query.categories.concat(scope.category ? [normalize(scope.category)] : []);
+    var hits = [];
+    Object.keys(scores).forEach(function (key) {
+      var doc = index.docs[key];
+      var docTags = (doc.tags || []).map(normalize);
+      if (!tags.every(function (tag) { return docTags.indexOf(tag) >= 0; })) return;
+      if (!categories.every(function (category) { return normalize(doc.category || "") === category; })) return;
+      if (scope.author && doc.author !== scope.author) return;
+      hits.push({ pos: Number(key), score: scores[key] });
+    });
+    // Docs are exported newest first, which breaks score ties like the server.
+    hits.sort(function (a, b) {
+      return b.score - a.score || a.pos - b.pos;
+    });
+    return hits.map(function (hit) {
+      return index.docs[hit.pos];
+    });
+  }
+
+  function element(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) node.className = className;
+    if (text) node.textContent = text;
+    return node;
+  }
+
+  function render(form, docs) {
+    var container = form.parentNode;
+    var results = container.querySelector(".search-results");
+    if (!results) {
+      results = element("section", "postList search-results");
+      container.appendChild(results);
+    }
+    results.textContent = "";
+    if (docs.length === 0) {
+      results.appendChild(element("p", "search-empty", form.getAttribute("data-search-empty")));
+    }
+    docs.forEach(function (doc) {
+      var article = element("article", "post");
+      var title = element("h2", "post-title");
+      var link = element("a", "", doc.title);
+      link.href = doc.url;
+      title.appendChild(link);
+      var header = element("header", "post-header");
+      header.appendChild(title);
+      if (doc.date) {
+        var details = element("div", "post-details");
+        var date = element("p");
+        date.appendChild(element("time", "", doc.date));
+        details.appendChild(date);
+        header.appendChild(details);
+      }
+      article.appendChild(header);
+      if (doc.excerpt) article.appendChild(element("div", "post-excerpt body", doc.excerpt));
+      var more = element("a", "post-link", form.getAttribute("data-search-more"));
+      more.href = doc.url;
+      article.appendChild(more);
+      results.appendChild(article);
+    });
+    document.querySelectorAll(".postList:not(.search-results), .pagination").forEach(function (node) {
+      node.hidden = true;
+    });
+  }
+
+  function loadIndex(path) {
+    if (!indexes[path]) {
+      indexes[path] = fetch(path, { credentials: "same-origin" }).then(function (response) {
+        if (!response.ok) throw new Error("search index " + response.status);
+        return response.json();
+      });
+      indexes[path].catch(function () {
+        delete indexes[path];
+      });
+    }
+    return indexes[path];
+  }
+
+  function run(form, raw) {
+    var scope = {
+      tag: form.getAttribute("data-search-tag"),
+      category: form.getAttribute("data-search-category"),
+      author: form.getAttribute("data-search-author"),
+    };
+    return loadIndex(form.getAttribute("data-search-index")).then(function (index) {
+      render(form, search(index, parseQuery(raw), scope));
+    });
+  }
+
+  function bind(form) {
+    if (form.hasAttribute("data-search-bound")) return;
+    form.setAttribute("data-search-bound", "");
+    var input = form.querySelector("input[name=q]");
+    form.addEventListener("submit", function (event) {
+      var raw = input.value.trim();
+      if (!raw) return;
+      event.preventDefault();
+      run(form, raw).then(
+        function () {
+          var url = new URL(form.action, window.location.href);
+          url.searchParams.set("q", raw);
+          window.history.replaceState(null, "", url);
+        },
+        function () {
+          form.submit();
+        }
+      );
+    });
+    // A static host serves the archive page for ?q= without results, which
+    // the empty input gives away; the site server fills the input in.
+    var raw = (new URLSearchParams(window.location.search).get("q") || "").trim();
+    if (raw && !input.value) {
+      input.value = raw;
+      run(form, raw).catch(function () {});
+    }
+  }
+
+  document.querySelectorAll("form[data-search-index]").forEach(bind);
+})();
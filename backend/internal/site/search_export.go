@@ -0,0 +1,213 @@
+package site
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	searchIndexExportVersion = 1
+	searchIndexFileName      = "search-index.json"
+)
+
+// searchClientScript is inlined after each archive search form; see
+// renderSearchForm.
+//
+//go:embed search_client.js
+var searchClientScript string
+
+var localizedSearchIndexPattern = regexp.MustCompile(`^/([a-z]{2,3}(?:-[a-z0-9]{2,8})*)/search-index\.json$`)
+
+// searchIndexExport is the client-side copy of searchIndex. Postings are
+// flattened to [doc, weight, doc, weight, ...] so the file stays small; docs
+// are addressed by their position in Docs.
+type searchIndexExport struct {
+	Version   int                    `json:"version"`
+	Locale    string                 `json:"locale"`
+	Tokenizer string                 `json:"tokenizer"`
+	K1        float64                `json:"k1"`
+	B         float64                `json:"b"`
+	AvgLength float64                `json:"avg_length"`
+	Docs      []searchIndexExportDoc `json:"docs"`
+	Terms     map[string][]float64   `json:"terms"`
+}
+
+type searchIndexExportDoc struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Excerpt  string   `json:"excerpt,omitempty"`
+	Date     string   `json:"date,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Length   float64  `json:"length"`
+}
+
+func searchIndexRoutePath(locale string) string {
+	if normalizeLocale(locale) == "" {
+		return "/" + searchIndexFileName
+	}
+	return "/" + normalizeLocale(locale) + "/" + searchIndexFileName
+}
+
+func extractSearchIndexLocale(path string) (string, bool) {
+	if path == "/"+searchIndexFileName {
+		return "", true
+	}
+	matches := localizedSearchIndexPattern.FindStringSubmatch(path)
+	if len(matches) != 2 {
+		return "", false
+	}
+	return parseLocaleSegment(matches[1])
+}
+
+func (idx *searchIndex) export(locale string, excerptLength int) searchIndexExport {
+	if excerptLength <= 0 {
+		excerptLength = 160
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ids := make([]string, 0, len(idx.docs))
+	for id := range idx.docs {
+		ids = append(ids, id)
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		a := postPublishedTime(idx.docs[ids[i]].post)
+		b := postPublishedTime(idx.docs[ids[j]].post)
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return idx.docs[ids[i]].post.Slug < idx.docs[ids[j]].post.Slug
+	})
+
+	out := searchIndexExport{
+		Version:   searchIndexExportVersion,
+		Locale:    normalizeLocale(locale),
+		Tokenizer: "cjk-bigram",
+		K1:        searchBM25K1,
+		B:         searchBM25B,
+		Docs:      make([]searchIndexExportDoc, 0, len(ids)),
+		Terms:     make(map[string][]float64, len(idx.postings)),
+	}
+	if len(ids) > 0 {
+		out.AvgLength = idx.totalLength / float64(len(ids))
+	}
+
+	positions := make(map[string]int, len(ids))
+	for i, id := range ids {
+		positions[id] = i
+		doc := idx.docs[id]
+		post := doc.post
+		body := post.Body
+		if body == "" {
+			body = post.Content
+		}
+		excerpt := strings.TrimSpace(post.Excerpt)
+		if excerpt == "" {
+			excerpt = buildExcerpt(body, excerptLength)
+		}
+		date := post.PublishedAt
+		if date == "" {
+			date = post.Date
+		}
+		out.Docs = append(out.Docs, searchIndexExportDoc{
+			URL:      postRoutePath(locale, url.PathEscape(strings.TrimSpace(post.Slug))),
+			Title:    post.Title,
+			Excerpt:  excerpt,
+			Date:     formatDate(date),
+			Tags:     parseTags(post.Tags),
+			Category: strings.TrimSpace(post.Category),
+			Author:   strings.TrimSpace(post.Author),
+			Length:   doc.length,
+		})
+	}
+
+	for term, postings := range idx.postings {
+		docs := make([]string, 0, len(postings))
+		for id := range postings {
+			docs = append(docs, id)
+		}
+		sort.Slice(docs, func(i, j int) bool {
+			return positions[docs[i]] < positions[docs[j]]
+		})
+		flat := make([]float64, 0, len(docs)*2)
+		for _, id := range docs {
+			flat = append(flat, float64(positions[id]), postings[id])
+		}
+		out.Terms[term] = flat
+	}
+	return out
+}
+
+func renderSearchIndexJSON(index *searchIndex, locale string, settings SettingsRecord) ([]byte, error) {
+	return json.Marshal(index.export(locale, settings.ExcerptLength))
+}
+
+func (ctx *snapshotBuildContext) searchIndexForLocale(locale string) *searchIndex {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		if ctx.searchIndex == nil {
+			ctx.searchIndex = sharedSearchIndex("")
+			ctx.searchIndex.sync(ctx.publishedPosts)
+		}
+		return ctx.searchIndex
+	}
+
+	translations := ctx.translationsByLocale[locale]
+	posts := make([]PostRecord, 0, len(translations))
+	for _, item := range translations {
+		posts = append(posts, translationToPost(item))
+	}
+	index := sharedSearchIndex(locale)
+	added, updated, removed := index.sync(posts)
+	slog.Debug("search index synced", "locale", locale, "added", added, "updated", updated, "removed", removed)
+	return index
+}
+
+func writeSnapshotSearchIndexes(root string, ctx *snapshotBuildContext) error {
+	if ctx == nil {
+		return nil
+	}
+
+	locales := append([]string{""}, parseTranslationLocales(ctx.settings.TranslationLocales)...)
+	for _, locale := range locales {
+		body, err := renderSearchIndexJSON(ctx.searchIndexForLocale(locale), locale, ctx.settings)
+		if err != nil {
+			return err
+		}
+		if err := writeSnapshotFile(root, searchIndexRoutePath(locale), body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSearchIndexJSON(w http.ResponseWriter, settings SettingsRecord, locale string) {
+	var posts []PostRecord
+	if normalizeLocale(locale) == "" {
+		posts = listPublishedPosts()
+	} else {
+		for _, item := range listPublishedTranslationsByLocale(locale) {
+			posts = append(posts, translationToPost(item))
+		}
+	}
+	index := sharedSearchIndex(locale)
+	index.sync(posts)
+
+	body, err := renderSearchIndexJSON(index, locale, settings)
+	if err != nil {
+		http.Error(w, "failed to generate search index", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setNoStoreCacheHeaders(w)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,583 @@
+package site
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+const (
+	searchBM25K1          = 1.2
+	searchBM25B           = 0.75
+	searchSnippetRadius   = 60
+	searchTitleWeight     = 3.0
+	searchTagWeight       = 2.0
+	searchCategoryWeight  = 2.0
+	searchExcerptWeight   = 1.0
+	searchBodyWeight      = 1.0
+	searchSlugWeight      = 1.0
+	searchSnippetMaxMarks = 8
+)
+
+var siteSearchIndexes = struct {
+	mu    sync.RWMutex
+	items map[string]*searchIndex
+}{
+	items: map[string]*searchIndex{},
+}
+
+type searchIndex struct {
+	mu          sync.RWMutex
+	synced      bool
+	docs        map[string]*searchDocument
+	postings    map[string]map[string]float64
+	totalLength float64
+}
+
+type searchDocument struct {
+	post      PostRecord
+	signature string
+	length    float64
+	terms     map[string]float64
+	tags      []string
+	category  string
+	text      []rune
+	lowerText []rune
+}
+
+type searchQuery struct {
+	terms      []string
+	phrases    []string
+	tags       []string
+	categories []string
+}
+
+type searchHit struct {
+	Post    PostRecord
+	Score   float64
+	Snippet string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     map[string]*searchDocument{},
+		postings: map[string]map[string]float64{},
+	}
+}
+
+func sharedSearchIndex(locale string) *searchIndex {
+	key := normalizeLocale(locale)
+	siteSearchIndexes.mu.RLock()
+	index := siteSearchIndexes.items[key]
+	siteSearchIndexes.mu.RUnlock()
+	if index != nil {
+		return index
+	}
+
+	siteSearchIndexes.mu.Lock()
+	defer siteSearchIndexes.mu.Unlock()
+	if index = siteSearchIndexes.items[key]; index == nil {
+		index = newSearchIndex()
+		siteSearchIndexes.items[key] = index
+	}
+	return index
+}
+
+func currentSearchIndex(locale string) *searchIndex {
+	if ctx := currentSnapshotBuildContext(); ctx != nil && normalizeLocale(locale) == "" && ctx.searchIndex != nil {
+		return ctx.searchIndex
+	}
+	index := sharedSearchIndex(locale)
+	index.mu.RLock()
+	synced := index.synced
+	index.mu.RUnlock()
+	if !synced {
+		return nil
+	}
+	return index
+}
+
+// sync brings the index in line with posts, re-tokenizing only the documents
+// whose indexed fields changed since the previous call.
+func (idx *searchIndex) sync(posts []PostRecord) (added, updated, removed int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(posts))
+	for _, post := range posts {
+		id := searchDocumentID(post)
+		if id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		signature := searchDocumentSignature(post)
+		if existing, ok := idx.docs[id]; ok {
+			if existing.signature == signature {
+				existing.post = post
+				continue
+			}
+			idx.removeLocked(id)
+			updated++
+		} else {
+			added++
+		}
+		idx.addLocked(id, newSearchDocument(post, signature))
+	}
+	for id := range idx.docs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		idx.removeLocked(id)
+		removed++
+	}
+	idx.synced = true
+	return added, updated, removed
+}
+
+func (idx *searchIndex) addLocked(id string, doc *searchDocument) {
+	idx.docs[id] = doc
+	idx.totalLength += doc.length
+	for term, freq := range doc.terms {
+		postings := idx.postings[term]
+		if postings == nil {
+			postings = map[string]float64{}
+			idx.postings[term] = postings
+		}
+		postings[id] = freq
+	}
+}
+
+func (idx *searchIndex) removeLocked(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, id)
+}
+
+func (idx *searchIndex) search(raw string) []searchHit {
+	query := parseSearchQuery(raw)
+	if query.empty() {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	candidates := idx.candidatesLocked(query.terms)
+	hits := make([]searchHit, 0, len(candidates))
+	for _, id := range candidates {
+		doc := idx.docs[id]
+		if doc == nil || !doc.matchesFilters(query) {
+			continue
+		}
+		hits = append(hits, searchHit{
+			Post:    doc.post,
+			Score:   idx.scoreLocked(id, doc, query.terms),
+			Snippet: doc.snippet(query),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		a := postPublishedTime(hits[i].Post)
+		b := postPublishedTime(hits[j].Post)
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return hits[i].Post.Slug < hits[j].Post.Slug
+	})
+	return hits
+}
+
+func (idx *searchIndex) candidatesLocked(terms []string) []string {
+	if len(terms) == 0 {
+		out := make([]string, 0, len(idx.docs))
+		for id := range idx.docs {
+			out = append(out, id)
+		}
+		return out
+	}
+
+	ordered := append([]string(nil), terms...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(idx.postings[ordered[i]]) < len(idx.postings[ordered[j]])
+	})
+	out := make([]string, 0, len(idx.postings[ordered[0]]))
+	for id := range idx.postings[ordered[0]] {
+		matched := true
+		for _, term := range ordered[1:] {
+			if _, ok := idx.postings[term][id]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (idx *searchIndex) scoreLocked(id string, doc *searchDocument, terms []string) float64 {
+	if len(terms) == 0 || len(idx.docs) == 0 {
+		return 0
+	}
+	total := float64(len(idx.docs))
+	avgLength := idx.totalLength / total
+	if avgLength <= 0 {
+		avgLength = 1
+	}
+	score := 0.0
+	for _, term := range terms {
+		postings := idx.postings[term]
+		freq := postings[id]
+		if freq == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+		norm := searchBM25K1 * (1 - searchBM25B + searchBM25B*doc.length/avgLength)
+		score += idf * freq * (searchBM25K1 + 1) / (freq + norm)
+	}
+	return score
+}
+
+func searchDocumentID(post PostRecord) string {
+	if id := strings.TrimSpace(post.ID); id != "" {
+		return id
+	}
+	return strings.TrimSpace(post.Slug)
+}
+
+func searchDocumentSignature(post PostRecord) string {
+	body := post.Body
+	if body == "" {
+		body = post.Content
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{post.Title, post.Slug, post.Tags, post.Category, post.Excerpt, body}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSearchDocument(post PostRecord, signature string) *searchDocument {
+	body := post.Body
+	if body == "" {
+		body = post.Content
+	}
+	plainBody := html.UnescapeString(stripHTML(body))
+	excerpt := html.UnescapeString(strings.TrimSpace(post.Excerpt))
+	doc := &searchDocument{
+		post:      post,
+		signature: signature,
+		terms:     map[string]float64{},
+		category:  normalizeSearchText(strings.TrimSpace(post.Category)),
+	}
+	for _, tag := range parseTags(post.Tags) {
+		doc.tags = append(doc.tags, normalizeSearchText(tag))
+	}
+
+	weighted := []struct {
+		text   string
+		weight float64
+	}{
+		{post.Title, searchTitleWeight},
+		{post.Slug, searchSlugWeight},
+		{strings.Join(parseTags(post.Tags), " "), searchTagWeight},
+		{post.Category, searchCategoryWeight},
+		{excerpt, searchExcerptWeight},
+		{plainBody, searchBodyWeight},
+	}
+	for _, field := range weighted {
+		for _, term := range tokenizeSearchDocumentText(field.text) {
+			doc.terms[term] += field.weight
+			doc.length += field.weight
+		}
+	}
+
+	text := strings.TrimSpace(post.Title)
+	if plainBody != "" {
+		text += "\n" + plainBody
+	} else if excerpt != "" {
+		text += "\n" + excerpt
+	}
+	doc.text = []rune(text)
+	doc.lowerText = []rune(normalizeSearchText(text))
+	return doc
+}
+
+func (doc *searchDocument) matchesFilters(query searchQuery) bool {
+	for _, tag := range query.tags {
+		found := false
+		for _, candidate := range doc.tags {
+			if candidate == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, category := range query.categories {
+		if doc.category != category {
+			return false
+		}
+	}
+	if len(query.phrases) > 0 {
+		lower := string(doc.lowerText)
+		for _, phrase := range query.phrases {
+			if !strings.Contains(lower, phrase) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// snippet returns escaped HTML around the first match with every query term
+// and phrase wrapped in <mark>. Positions are computed on runes because
+// normalizeSearchText keeps a one-to-one rune mapping with the original text.
+func (doc *searchDocument) snippet(query searchQuery) string {
+	needles := make([][]rune, 0, len(query.phrases)+len(query.terms))
+	for _, phrase := range query.phrases {
+		needles = append(needles, []rune(phrase))
+	}
+	for _, term := range query.terms {
+		needles = append(needles, []rune(term))
+	}
+	if len(needles) == 0 || len(doc.text) == 0 {
+		return ""
+	}
+
+	first := -1
+	for _, needle := range needles {
+		if at := indexRunes(doc.lowerText, needle, 0); at >= 0 && (first < 0 || at < first) {
+			first = at
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := first - searchSnippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := first + searchSnippetRadius*2
+	if end > len(doc.text) {
+		end = len(doc.text)
+	}
+
+	marks := make([]bool, end-start)
+	count := 0
+	for _, needle := range needles {
+		for at := indexRunes(doc.lowerText[:end], needle, start); at >= 0 && count < searchSnippetMaxMarks; at = indexRunes(doc.lowerText[:end], needle, at+len(needle)) {
+			for i := at; i < at+len(needle) && i < end; i++ {
+				marks[i-start] = true
+			}
+			count++
+		}
+	}
+
+	out := strings.Builder{}
+	if start > 0 {
+		out.WriteString("…")
+	}
+	open := false
+	for i := start; i < end; i++ {
+		marked := marks[i-start]
+		if marked && !open {
+			out.WriteString("<mark>")
+			open = true
+		} else if !marked && open {
+			out.WriteString("</mark>")
+			open = false
+		}
+		r := doc.text[i]
+		if r == '\n' {
+			r = ' '
+		}
+		out.WriteString(escapeHTML(string(r)))
+	}
+	if open {
+		out.WriteString("</mark>")
+	}
+	if end < len(doc.text) {
+		out.WriteString("…")
+	}
+	return out.String()
+}
+
+func indexRunes(haystack, needle []rune, from int) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := from; i+len(needle) <= len(haystack); i++ {
+		matched := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseSearchQuery(raw string) searchQuery {
+	query := searchQuery{}
+	input := strings.TrimSpace(raw)
+	words := make([]string, 0)
+	for len(input) > 0 {
+		input = strings.TrimLeftFunc(input, unicode.IsSpace)
+		if input == "" {
+			break
+		}
+		if strings.HasPrefix(input, `"`) {
+			rest := input[1:]
+			end := strings.Index(rest, `"`)
+			if end < 0 {
+				end = len(rest)
+			}
+			if phrase := normalizeSearchText(strings.TrimSpace(rest[:end])); phrase != "" {
+				query.phrases = append(query.phrases, phrase)
+				words = append(words, phrase)
+			}
+			if end < len(rest) {
+				end++
+			}
+			input = rest[end:]
+			continue
+		}
+
+		end := strings.IndexFunc(input, unicode.IsSpace)
+		if end < 0 {
+			end = len(input)
+		}
+		word := input[:end]
+		input = input[end:]
+		if value, ok := searchOperatorValue(word, "tag:"); ok {
+			query.tags = append(query.tags, value)
+			continue
+		}
+		if value, ok := searchOperatorValue(word, "category:"); ok {
+			query.categories = append(query.categories, value)
+			continue
+		}
+		words = append(words, word)
+	}
+
+	seen := map[string]struct{}{}
+	for _, word := range words {
+		for _, term := range tokenizeSearchText(word) {
+			if _, ok := seen[term]; ok {
+				continue
+			}
+			seen[term] = struct{}{}
+			query.terms = append(query.terms, term)
+		}
+	}
+	return query
+}
+
+func searchOperatorValue(word, prefix string) (string, bool) {
+	if len(word) <= len(prefix) || !strings.EqualFold(word[:len(prefix)], prefix) {
+		return "", false
+	}
+	value := strings.Trim(word[len(prefix):], `"`)
+	if value == "" {
+		return "", false
+	}
+	return normalizeSearchText(value), true
+}
+
+func (query searchQuery) empty() bool {
+	return len(query.terms) == 0 && len(query.phrases) == 0 && len(query.tags) == 0 && len(query.categories) == 0
+}
+
+// normalizeSearchText folds width variants and case rune by rune so that the
+// result can be indexed with the same offsets as the source text.
+func normalizeSearchText(value string) string {
+	runes := []rune(value)
+	for i, r := range runes {
+		folded := []rune(width.Fold.String(string(r)))
+		if len(folded) == 1 {
+			r = folded[0]
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+func isSearchCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) ||
+		r == 'ー'
+}
+
+// tokenizeSearchText splits Latin text into words and CJK runs into
+// overlapping bigrams; a single CJK character is kept as a unigram.
+func tokenizeSearchText(value string) []string {
+	return tokenizeSearchRunes(value, false)
+}
+
+// tokenizeSearchDocumentText additionally emits every CJK character as a
+// unigram so that one-character queries still find documents.
+func tokenizeSearchDocumentText(value string) []string {
+	return tokenizeSearchRunes(value, true)
+}
+
+func tokenizeSearchRunes(value string, cjkUnigrams bool) []string {
+	runes := []rune(normalizeSearchText(value))
+	tokens := make([]string, 0, len(runes)/2)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isSearchCJK(r):
+			j := i
+			for j < len(runes) && isSearchCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 || cjkUnigrams {
+				for k := i; k < j; k++ {
+					tokens = append(tokens, string(runes[k]))
+				}
+			}
+			for k := i; k+1 < j; k++ {
+				tokens = append(tokens, string(runes[k:k+2]))
+			}
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && !isSearchCJK(runes[j]) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || unicode.Is(unicode.Mn, runes[j])) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,111 @@
+package site
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeSearchTextUsesCJKBigrams(t *testing.T) {
+	t.Parallel()
+
+	got := tokenizeSearchText("Go言語入門")
+	want := []string{"go", "言語", "語入", "入門"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("tokenizeSearchText() = %v, want %v", got, want)
+	}
+}
+
+func TestSearchIndexRanksAndFilters(t *testing.T) {
+	t.Parallel()
+
+	idx := newSearchIndex()
+	idx.sync([]PostRecord{
+		{ID: "a", Slug: "a", Title: "Caching notes", Body: "A short note about caching.", Tags: "go", Category: "dev", Published: true, PublishedAt: "2024-01-01 00:00:00.000Z"},
+		{ID: "b", Slug: "b", Title: "Weekend", Body: "Mentions caching once in passing among many other words about walks and food.", Tags: "life", Category: "diary", Published: true, PublishedAt: "2024-02-01 00:00:00.000Z"},
+		{ID: "c", Slug: "c", Title: "東京の天気", Body: "今日の東京は晴れでした。", Tags: "life", Published: true, PublishedAt: "2024-03-01 00:00:00.000Z"},
+	})
+
+	hits := idx.search("caching")
+	if len(hits) != 2 || hits[0].Post.ID != "a" {
+		t.Fatalf("search(caching) = %+v, want a ranked first of 2", hits)
+	}
+	if !strings.Contains(hits[0].Snippet, "<mark>caching</mark>") {
+		t.Fatalf("snippet = %q, want highlighted term", hits[0].Snippet)
+	}
+
+	if hits := idx.search("caching tag:life"); len(hits) != 1 || hits[0].Post.ID != "b" {
+		t.Fatalf("search(caching tag:life) = %+v, want only b", hits)
+	}
+	if hits := idx.search("category:dev"); len(hits) != 1 || hits[0].Post.ID != "a" {
+		t.Fatalf("search(category:dev) = %+v, want only a", hits)
+	}
+	if hits := idx.search(`"short note"`); len(hits) != 1 || hits[0].Post.ID != "a" {
+		t.Fatalf("phrase search = %+v, want only a", hits)
+	}
+	if hits := idx.search("東京"); len(hits) != 1 || hits[0].Post.ID != "c" {
+		t.Fatalf("search(東京) = %+v, want only c", hits)
+	}
+}
+
+func TestSearchIndexSyncIsIncremental(t *testing.T) {
+	t.Parallel()
+
+	idx := newSearchIndex()
+	posts := []PostRecord{
+		{ID: "a", Slug: "a", Title: "First", Body: "alpha"},
+		{ID: "b", Slug: "b", Title: "Second", Body: "beta"},
+	}
+	if added, updated, removed := idx.sync(posts); added != 2 || updated != 0 || removed != 0 {
+		t.Fatalf("initial sync = %d/%d/%d, want 2/0/0", added, updated, removed)
+	}
+
+	posts = []PostRecord{{ID: "a", Slug: "a", Title: "First", Body: "gamma"}}
+	if added, updated, removed := idx.sync(posts); added != 0 || updated != 1 || removed != 1 {
+		t.Fatalf("second sync = %d/%d/%d, want 0/1/1", added, updated, removed)
+	}
+	if hits := idx.search("alpha"); len(hits) != 0 {
+		t.Fatalf("search(alpha) = %+v, want stale terms removed", hits)
+	}
+	if hits := idx.search("gamma"); len(hits) != 1 {
+		t.Fatalf("search(gamma) = %+v, want updated post", hits)
+	}
+}
+
+func TestSearchIndexExportFlattensPostings(t *testing.T) {
+	t.Parallel()
+
+	idx := newSearchIndex()
+	idx.sync([]PostRecord{
+		{ID: "a", Slug: "older", Title: "Alpha", Body: "shared", PublishedAt: "2024-01-01 00:00:00.000Z"},
+		{ID: "b", Slug: "newer", Title: "Beta", Body: "shared", Author: "user1", PublishedAt: "2024-02-01 00:00:00.000Z"},
+	})
+
+	out := idx.export("ja", 0)
+	if len(out.Docs) != 2 || out.Docs[0].URL != "/ja/posts/newer/" || out.Docs[0].Author != "user1" {
+		t.Fatalf("docs = %+v, want newest first with localized URL", out.Docs)
+	}
+	if got := out.Terms["shared"]; !slices.Equal(got, []float64{0, 1, 1, 1}) {
+		t.Fatalf("terms[shared] = %v, want [0 1 1 1]", got)
+	}
+}
+
+func TestExtractSearchIndexLocale(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		locale string
+		ok     bool
+	}{
+		"/search-index.json":       {"", true},
+		"/ja/search-index.json":    {"ja", true},
+		"/JA/search-index.json":    {"", false},
+		"/posts/search-index.json": {"", false},
+	}
+	for path, want := range cases {
+		locale, ok := extractSearchIndexLocale(path)
+		if locale != want.locale || ok != want.ok {
+			t.Fatalf("extractSearchIndexLocale(%q) = %q, %v; want %q, %v", path, locale, ok, want.locale, want.ok)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type seriesPart struct {
+	ID    string
+	Title string
+	Path  string
+}
+
+func seriesIndexPath(slug string) string {
+	return "/series/" + url.PathEscape(strings.TrimSpace(slug)) + "/"
+}
+
+// extractSeriesSlug matches /series/<slug> with or without a trailing slash.
+func extractSeriesSlug(path string) (string, bool) {
+	rest, found := strings.CutPrefix(path, "/series/")
+	if !found {
+		return "", false
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if rest == "" || strings.Contains(rest, "/") {
+		return "", false
+	}
+	return decodePathSegment(rest), true
+}
+
+func seriesTitle(series *SeriesRecord, locale string) string {
+	if series == nil {
+		return ""
+	}
+	if title := strings.TrimSpace(series.TitleTranslations[normalizeLocale(locale)]); title != "" {
+		return title
+	}
+	return defaultString(strings.TrimSpace(series.Title), series.Slug)
+}
+
+// seriesParts resolves the published parts of a series in reading order. In a
+// locale, each part links to its translation when one is enabled and falls back
+// to the source post otherwise.
+func seriesParts(series *SeriesRecord, locale string, settings SettingsRecord) []seriesPart {
+	if series == nil || len(series.Posts) == 0 {
+		return nil
+	}
+	locale = normalizeLocale(locale)
+	resolved := make([]*seriesPart, len(series.Posts))
+	var wg sync.WaitGroup
+	for i, postID := range series.Posts {
+		wg.Add(1)
+		go func(i int, postID string) {
+			defer wg.Done()
+			post := getPostByID(strings.TrimSpace(postID))
+			if post == nil || !post.Published || strings.TrimSpace(post.Slug) == "" {
+				return
+			}
+			part := &seriesPart{
+				ID:    post.ID,
+				Title: defaultString(post.Title, "Post"),
+				Path:  postRoutePath("", url.PathEscape(strings.TrimSpace(post.Slug))),
+			}
+			if locale != "" {
+				for _, translation := range getEnabledTranslationsBySource(post.ID, settings) {
+					if normalizeLocale(translation.Locale) != locale || strings.TrimSpace(translation.Slug) == "" {
+						continue
+					}
+					part.Title = defaultString(translation.Title, part.Title)
+					part.Path = postRoutePath(locale, url.PathEscape(strings.TrimSpace(translation.Slug)))
+					break
+				}
+			}
+			resolved[i] = part
+		}(i, postID)
+	}
+	wg.Wait()
+
+	parts := make([]seriesPart, 0, len(resolved))
+	seen := map[string]struct{}{}
+	for _, part := range resolved {
+		if part == nil {
+			continue
+		}
+		if _, ok := seen[part.ID]; ok {
+			continue
+		}
+		seen[part.ID] = struct{}{}
+		parts = append(parts, *part)
+	}
+	return parts
+}
+
+// renderSeriesBox lists every part with the current one marked and links to
+// the previous and next parts. currentID is the source post id, so
+// translations share the box of their source post.
+func renderSeriesBox(title, indexPath string, parts []seriesPart, currentID string, text uiText) string {
+	current := -1
+	for i, part := range parts {
+		if part.ID == currentID {
+			current = i
+			break
+		}
+	}
+	if current < 0 {
+		return ""
+	}
+
+	list := strings.Builder{}
+	for i, part := range parts {
+		if i == current {
+			list.WriteString(fmt.Sprintf(`<li aria-current="page"><strong>%s</strong></li>`, escapeHTML(part.Title)))
+			continue
+		}
+		list.WriteString(fmt.Sprintf(`<li><a href="%s">%s</a></li>`, escapeHTML(part.Path), escapeHTML(part.Title)))
+	}
+
+	navItems := make([]string, 0, 2)
+	if current > 0 {
+		prev := parts[current-1]
+		navItems = append(navItems, fmt.Sprintf(`<li class="pagination-prev"><a href="%s" rel="prev"><span>%s</span><strong>%s</strong></a></li>`, escapeHTML(prev.Path), escapeHTML(text.get("previous_part")), escapeHTML(prev.Title)))
+	}
+	if current < len(parts)-1 {
+		next := parts[current+1]
+		navItems = append(navItems, fmt.Sprintf(`<li class="pagination-next"><a href="%s" rel="next"><span>%s</span><strong>%s</strong></a></li>`, escapeHTML(next.Path), escapeHTML(text.get("next_part")), escapeHTML(next.Title)))
+	}
+	navHTML := ""
+	if len(navItems) > 0 {
+		navHTML = `<nav class="pagination post-series-pagination"><ul>` + strings.Join(navItems, "") + `</ul></nav>`
+	}
+
+	return fmt.Sprintf(`<aside class="post-series">
+        <p class="post-series-title"><a href="%s">%s</a> <span>(%s)</span></p>
+        <ol class="post-series-list">%s</ol>
+        %s
+      </aside>`, escapeHTML(indexPath), escapeHTML(title), escapeHTML(text.format("series_part", current+1, len(parts))), list.String(), navHTML)
+}
+
+func renderPostSeriesBox(sourcePostID, locale string, settings SettingsRecord) string {
+	series := getSeriesByPostID(sourcePostID)
+	if series == nil {
+		return ""
+	}
+	return renderSeriesBox(seriesTitle(series, locale), seriesIndexPath(series.Slug), seriesParts(series, locale, settings), strings.TrimSpace(sourcePostID), uiTextFor(settings, locale))
+}
+
+func renderSeriesIndex(series *SeriesRecord, settings SettingsRecord) (string, bool) {
+	if series == nil {
+		return renderNotFound(settings), false
+	}
+	var menu []PageRecord
+	var parts []seriesPart
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		menu = getPagesMenu()
+	}()
+	go func() {
+		defer wg.Done()
+		parts = seriesParts(series, "", settings)
+	}()
+	wg.Wait()
+
+	title := seriesTitle(series, "")
+	return renderThemeTemplate(settings, "series.html", seriesView{
+		themeLayout: newThemeLayout(title, menu, settings, ""),
+		Title:       title,
+		Description: strings.TrimSpace(series.Description),
+		Parts:       parts,
+	}), true
+}
+
+func seriesPartRoutes(postIDs []string) []string {
+	snapshot := currentSnapshotBuildContext()
+	if snapshot == nil {
+		return nil
+	}
+	routes := []string{}
+	for _, postID := range postIDs {
+		if post, ok := snapshot.postByID[postID]; ok && strings.TrimSpace(post.Slug) != "" {
+			routes = append(routes, postRoutePath("", strings.TrimSpace(post.Slug)))
+		}
+		for _, translation := range snapshot.translationsBySource[postID] {
+			if locale := normalizeLocale(translation.Locale); locale != "" && strings.TrimSpace(translation.Slug) != "" {
+				routes = append(routes, postRoutePath(locale, strings.TrimSpace(translation.Slug)))
+			}
+		}
+	}
+	return routes
+}
+
+// seriesExtraRoutesForPosts returns the sibling part routes and index of any
+// series listing the posts, so part titles stay current across the series.
+func seriesExtraRoutesForPosts(items ...*PostRecord) []dagExtraRoute {
+	snapshot := currentSnapshotBuildContext()
+	if snapshot == nil {
+		return nil
+	}
+	out := []dagExtraRoute{}
+	seen := map[string]struct{}{}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		series, ok := snapshot.seriesByPost[strings.TrimSpace(item.ID)]
+		if !ok || strings.TrimSpace(series.Slug) == "" {
+			continue
+		}
+		if _, ok := seen[series.Slug]; ok {
+			continue
+		}
+		seen[series.Slug] = struct{}{}
+		reason := "series part of " + seriesNodeKey(strings.TrimSpace(series.Slug)).String()
+		for _, route := range append(seriesPartRoutes(series.Posts), seriesIndexPath(series.Slug)) {
+			out = append(out, dagExtraRoute{Key: routeNodeKey(route), Reasons: []string{reason}})
+		}
+	}
+	return out
+}
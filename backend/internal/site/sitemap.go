@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -24,14 +25,23 @@ var sitemapCache = struct {
 }
 
 type sitemapURLSet struct {
-	XMLName xml.Name     `xml:"urlset"`
-	Xmlns   string       `xml:"xmlns,attr"`
-	URLs    []sitemapURL `xml:"url"`
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsXHTML string       `xml:"xmlns:xhtml,attr,omitempty"`
+	URLs       []sitemapURL `xml:"url"`
 }
 
 type sitemapURL struct {
-	Loc     string `xml:"loc"`
-	LastMod string `xml:"lastmod,omitempty"`
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+// sitemapAlternate is an hreflang alternate of a sitemap URL.
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
 }
 
 func writeSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecord) {
@@ -41,12 +51,13 @@ func writeSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecor
 		return
 	}
 
-	key := fmt.Sprintf("main|%s|%t|%t", baseURL, settings.EnableFeedXML, settings.EnableFeedJSON)
+	key := fmt.Sprintf("main|%s|%t|%t|%s", baseURL, settings.EnableFeedXML, settings.EnableFeedJSON, sitemapTranslationKey(settings))
 	body, err := cachedSitemapBody(key, func() ([]byte, error) {
 		var pages []PageRecord
 		var posts []PostRecord
+		var translations []PostTranslationRecord
 		var fetchWG sync.WaitGroup
-		fetchWG.Add(2)
+		fetchWG.Add(3)
 		go func() {
 			defer fetchWG.Done()
 			pages = listPublishedPages()
@@ -55,7 +66,12 @@ func writeSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecor
 			defer fetchWG.Done()
 			posts = listPublishedPosts()
 		}()
+		go func() {
+			defer fetchWG.Done()
+			translations = listSitemapTranslations(settings)
+		}()
 		fetchWG.Wait()
+		alternates := sitemapPostAlternates(baseURL, settings, posts, translations)
 
 		urls := make([]sitemapURL, 0, 256)
 		urls = append(urls,
@@ -97,9 +113,11 @@ func writeSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecor
 			if date == "" {
 				date = strings.TrimSpace(post.Date)
 			}
+			loc := baseURL + "/posts/" + url.PathEscape(slug) + "/"
 			urls = append(urls, sitemapURL{
-				Loc:     fmt.Sprintf("%s/posts/%s/", baseURL, slug),
-				LastMod: sitemapDate(date),
+				Loc:        loc,
+				LastMod:    sitemapDate(date),
+				Alternates: alternates[loc],
 			})
 		}
 		return buildSitemapXML(urls)
@@ -108,7 +126,7 @@ func writeSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecor
 		http.Error(w, "failed to generate sitemap", http.StatusInternalServerError)
 		return
 	}
-	writeSitemapBody(w, body)
+	writeSitemapBody(w, r, body)
 }
 
 func writeLocalizedSitemap(w http.ResponseWriter, r *http.Request, settings SettingsRecord, locale string) {
@@ -123,19 +141,65 @@ func writeLocalizedSitemap(w http.ResponseWriter, r *http.Request, settings Sett
 		return
 	}
 
-	key := fmt.Sprintf("locale|%s|%s", baseURL, locale)
+	key := fmt.Sprintf("locale|%s|%s|%s", baseURL, locale, sitemapTranslationKey(settings))
 	body, err := cachedSitemapBody(key, func() ([]byte, error) {
-		translations := listPublishedTranslationsByLocale(locale)
-		urls := make([]sitemapURL, 0, len(translations))
+		var posts []PostRecord
+		var translations []PostTranslationRecord
+		var pages []PageRecord
+		var pageTranslations []PageTranslationRecord
+		var fetchWG sync.WaitGroup
+		fetchWG.Add(4)
+		go func() {
+			defer fetchWG.Done()
+			posts = listPublishedPosts()
+		}()
+		go func() {
+			defer fetchWG.Done()
+			translations = listSitemapTranslations(settings)
+		}()
+		go func() {
+			defer fetchWG.Done()
+			pages = listPublishedPages()
+		}()
+		go func() {
+			defer fetchWG.Done()
+			pageTranslations = listPublishedPageTranslationsByLocale(locale)
+		}()
+		fetchWG.Wait()
+		alternates := sitemapPostAlternates(baseURL, settings, posts, translations)
+
+		prefix := baseURL + "/" + url.PathEscape(locale)
+		urls := make([]sitemapURL, 0, len(translations)+len(pageTranslations)+2)
+		urls = append(urls,
+			sitemapURL{Loc: prefix + "/"},
+			sitemapURL{Loc: prefix + "/archive/"},
+		)
+		pageByID := make(map[string]PageRecord, len(pages))
+		for _, page := range pages {
+			pageByID[page.ID] = page
+		}
+		for _, item := range pageTranslations {
+			source, ok := pageByID[strings.TrimSpace(item.SourcePage)]
+			if !ok || !strings.HasPrefix(strings.TrimSpace(source.URL), "/") {
+				continue
+			}
+			page := localizePage(source, item)
+			urls = append(urls, sitemapURL{
+				Loc:     baseURL + strings.TrimSpace(page.URL),
+				LastMod: sitemapDate(defaultString(page.PublishedAt, page.Date)),
+			})
+		}
 		for _, item := range translations {
 			slug := strings.TrimSpace(item.Slug)
-			if slug == "" {
+			if slug == "" || normalizeLocale(item.Locale) != locale {
 				continue
 			}
 			date := strings.TrimSpace(item.PublishedAt)
+			loc := prefix + "/posts/" + url.PathEscape(slug) + "/"
 			urls = append(urls, sitemapURL{
-				Loc:     fmt.Sprintf("%s/%s/posts/%s/", baseURL, locale, slug),
-				LastMod: sitemapDate(date),
+				Loc:        loc,
+				LastMod:    sitemapDate(date),
+				Alternates: alternates[loc],
 			})
 		}
 		return buildSitemapXML(urls)
@@ -144,7 +208,12 @@ func writeLocalizedSitemap(w http.ResponseWriter, r *http.Request, settings Sett
 		http.Error(w, "failed to generate sitemap", http.StatusInternalServerError)
 		return
 	}
-	writeSitemapBody(w, body)
+	writeSitemapBody(w, r, body)
+}
+
+// sitemapTranslationKey covers the settings that change hreflang clusters.
+func sitemapTranslationKey(settings SettingsRecord) string {
+	return fmt.Sprintf("%s|%s|%t", siteSourceLocale(settings), strings.Join(parseTranslationLocales(settings.TranslationLocales), ","), settings.TranslationApprovedOnly)
 }
 
 func sitemapBaseURL(r *http.Request, settings SettingsRecord) string {
@@ -208,6 +277,12 @@ func buildSitemapXML(urls []sitemapURL) ([]byte, error) {
 		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
 		URLs:  urls,
 	}
+	for _, item := range urls {
+		if len(item.Alternates) > 0 {
+			payload.XmlnsXHTML = "http://www.w3.org/1999/xhtml"
+			break
+		}
+	}
 	body, err := xml.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return nil, err
@@ -215,10 +290,8 @@ func buildSitemapXML(urls []sitemapURL) ([]byte, error) {
 	return append([]byte(xml.Header), body...), nil
 }
 
-func writeSitemapBody(w http.ResponseWriter, body []byte) {
-	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
-	setNoStoreCacheHeaders(w)
-	_, _ = w.Write(body)
+func writeSitemapBody(w http.ResponseWriter, r *http.Request, body []byte) {
+	writeCacheableBody(w, r, sitemapCacheControl, "application/xml; charset=utf-8", body, time.Time{})
 }
 
 func cachedSitemapBody(key string, build func() ([]byte, error)) ([]byte, error) {
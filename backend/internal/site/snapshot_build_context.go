@@ -1,6 +1,7 @@
 package site
 
 import (
+	"log/slog"
 	"net/url"
 	"sort"
 	"strconv"
@@ -54,6 +55,14 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 		return nil, err
 	}
 	tags, categories := collectTaxonomiesStrict(posts)
+	authors, err := listAuthorsStrict()
+	if err != nil {
+		return nil, err
+	}
+	series, err := listSeriesStrict()
+	if err != nil {
+		return nil, err
+	}
 
 	ctx := &snapshotBuildContext{
 		settings:             settings,
@@ -65,11 +74,19 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 		postBySlug:           map[string]PostRecord{},
 		postByID:             map[string]PostRecord{},
 		pageByURL:            map[string]PageRecord{},
+		pageTranslationByKey: map[string]PageTranslationRecord{},
 		translationByKey:     map[string]PostTranslationRecord{},
 		translationsBySource: map[string][]PostTranslationRecord{},
 		translationsByLocale: map[string][]PostTranslationRecord{},
 		postsByTag:           map[string][]PostRecord{},
 		postsByCategory:      map[string][]PostRecord{},
+		authors:              append([]AuthorRecord(nil), authors...),
+		authorByUser:         map[string]AuthorRecord{},
+		authorBySlug:         map[string]AuthorRecord{},
+		postsByAuthor:        map[string][]PostRecord{},
+		series:               append([]SeriesRecord(nil), series...),
+		seriesBySlug:         map[string]SeriesRecord{},
+		seriesByPost:         map[string]SeriesRecord{},
 		archiveIndex:         map[string]archiveListing{},
 	}
 
@@ -86,11 +103,36 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 		if category := strings.TrimSpace(post.Category); category != "" {
 			ctx.postsByCategory[category] = append(ctx.postsByCategory[category], post)
 		}
+		if author := strings.TrimSpace(post.Author); author != "" {
+			ctx.postsByAuthor[author] = append(ctx.postsByAuthor[author], post)
+		}
 	}
 
+	for _, author := range ctx.authors {
+		if user := strings.TrimSpace(author.User); user != "" {
+			ctx.authorByUser[user] = author
+		}
+		if slug := strings.TrimSpace(author.Slug); slug != "" {
+			ctx.authorBySlug[slug] = author
+		}
+	}
+
+	for _, item := range ctx.series {
+		if slug := strings.TrimSpace(item.Slug); slug != "" {
+			ctx.seriesBySlug[slug] = item
+		}
+		for _, postID := range item.Posts {
+			if _, ok := ctx.seriesByPost[postID]; !ok {
+				ctx.seriesByPost[postID] = item
+			}
+		}
+	}
+
+	pageIDs := map[string]struct{}{}
 	for _, page := range ctx.publishedPages {
 		if pageURL := strings.TrimSpace(page.URL); pageURL != "" {
 			ctx.pageByURL[pageURL] = page
+			pageIDs[page.ID] = struct{}{}
 		}
 	}
 
@@ -117,6 +159,20 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 		}
 	}
 
+	for _, locale := range parseTranslationLocales(settings.TranslationLocales) {
+		items, err := listPublishedPageTranslationsByLocaleStrict(locale)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			sourceID := strings.TrimSpace(item.SourcePage)
+			if _, ok := pageIDs[sourceID]; !ok {
+				continue
+			}
+			ctx.pageTranslationByKey[locale+"|"+sourceID] = item
+		}
+	}
+
 	for sourceID, items := range ctx.translationsBySource {
 		sort.SliceStable(items, func(i, j int) bool {
 			return normalizeLocale(items[i].Locale) < normalizeLocale(items[j].Locale)
@@ -124,6 +180,10 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 		ctx.translationsBySource[sourceID] = items
 	}
 
+	ctx.searchIndex = sharedSearchIndex("")
+	added, updated, removed := ctx.searchIndex.sync(ctx.publishedPosts)
+	slog.Debug("search index synced", "added", added, "updated", updated, "removed", removed)
+
 	ctx.archiveIndex["/archive/"] = ctx.buildArchiveListing(ctx.publishedPosts)
 	for tag, items := range ctx.postsByTag {
 		ctx.archiveIndex["/archive/"+url.PathEscape(tag)+"/"] = ctx.buildArchiveListing(items)
@@ -131,10 +191,44 @@ func newSnapshotBuildContext() (*snapshotBuildContext, error) {
 	for category, items := range ctx.postsByCategory {
 		ctx.archiveIndex["/archive/category/"+url.PathEscape(category)+"/"] = ctx.buildArchiveListing(items)
 	}
+	for _, author := range ctx.authors {
+		if strings.TrimSpace(author.Slug) == "" {
+			continue
+		}
+		ctx.archiveIndex[authorArchivePath(author.Slug)] = ctx.buildArchiveListing(ctx.postsByAuthor[strings.TrimSpace(author.User)])
+	}
+	for locale := range ctx.translationsByLocale {
+		ctx.indexLocalizedArchives(locale)
+	}
 
 	return ctx, nil
 }
 
+// indexLocalizedArchives adds the /<locale>/archive/ listings of a
+// translation locale, mirroring the source archives.
+func (ctx *snapshotBuildContext) indexLocalizedArchives(locale string) {
+	prefix := localePathPrefix(locale)
+	posts := ctx.postsForLocale(locale)
+	byTag := map[string][]PostRecord{}
+	byCategory := map[string][]PostRecord{}
+	for _, post := range posts {
+		for _, tag := range parseTags(post.Tags) {
+			byTag[tag] = append(byTag[tag], post)
+		}
+		if category := strings.TrimSpace(post.Category); category != "" {
+			byCategory[category] = append(byCategory[category], post)
+		}
+	}
+
+	ctx.archiveIndex[prefix+"/archive/"] = ctx.buildArchiveListing(posts)
+	for tag, items := range byTag {
+		ctx.archiveIndex[prefix+"/archive/"+url.PathEscape(tag)+"/"] = ctx.buildArchiveListing(items)
+	}
+	for category, items := range byCategory {
+		ctx.archiveIndex[prefix+"/archive/category/"+url.PathEscape(category)+"/"] = ctx.buildArchiveListing(items)
+	}
+}
+
 func buildMenuPages(pages []PageRecord) []PageRecord {
 	menu := make([]PageRecord, 0, len(pages))
 	for _, page := range pages {
@@ -195,13 +289,15 @@ func (ctx *snapshotBuildContext) pageRouteKeys() []dag.NodeKey {
 		return nil
 	}
 
-	routes := make([]dag.NodeKey, 0, len(ctx.publishedPages))
+	routes := make([]dag.NodeKey, 0, len(ctx.publishedPages)+len(ctx.pageTranslationByKey))
 	seen := map[dag.NodeKey]struct{}{}
+	pageURLs := make(map[string]string, len(ctx.publishedPages))
 	for _, page := range ctx.publishedPages {
 		pageURL := strings.TrimSpace(page.URL)
 		if pageURL == "" {
 			continue
 		}
+		pageURLs[page.ID] = pageURL
 		key := routeNodeKey(pageURL)
 		if _, ok := seen[key]; ok {
 			continue
@@ -209,6 +305,34 @@ func (ctx *snapshotBuildContext) pageRouteKeys() []dag.NodeKey {
 		seen[key] = struct{}{}
 		routes = append(routes, key)
 	}
+
+	for _, item := range ctx.pageTranslationByKey {
+		pageURL, ok := pageURLs[strings.TrimSpace(item.SourcePage)]
+		if !ok || normalizeLocale(item.Locale) == "" {
+			continue
+		}
+		key := routeNodeKey(localePathPrefix(item.Locale) + pageURL)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		routes = append(routes, key)
+	}
+	return routes
+}
+
+func (ctx *snapshotBuildContext) seriesRouteKeys() []dag.NodeKey {
+	if ctx == nil {
+		return nil
+	}
+
+	routes := make([]dag.NodeKey, 0, len(ctx.series))
+	for _, item := range ctx.series {
+		if strings.TrimSpace(item.Slug) == "" {
+			continue
+		}
+		routes = append(routes, routeNodeKey(seriesIndexPath(item.Slug)))
+	}
 	return routes
 }
 
@@ -221,6 +345,11 @@ func (ctx *snapshotBuildContext) listingRouteKeys() []dag.NodeKey {
 	seen := map[dag.NodeKey]struct{}{
 		routeNodeKey("/"): {},
 	}
+	for locale := range ctx.translationsByLocale {
+		key := routeNodeKey(localePathPrefix(locale) + "/")
+		seen[key] = struct{}{}
+		routes = append(routes, key)
+	}
 
 	appendListing := func(basePath string, listing archiveListing) {
 		pageCount := listing.pageCount
@@ -255,6 +384,12 @@ func (ctx *snapshotBuildContext) listingRouteKeysForBase(basePath string) []dag.
 	if cleanPath(basePath) == "/" {
 		return []dag.NodeKey{routeNodeKey("/")}
 	}
+	if locale, rest, ok := extractLocalizedListingRoute(basePath); ok && rest == "/" {
+		if _, enabled := ctx.translationsByLocale[locale]; !enabled {
+			return nil
+		}
+		return []dag.NodeKey{routeNodeKey(basePath)}
+	}
 
 	listing, ok := ctx.archiveListing(basePath)
 	if !ok {
@@ -281,9 +416,9 @@ func (ctx *snapshotBuildContext) routeKeys() []dag.NodeKey {
 		return nil
 	}
 
-	routes := make([]dag.NodeKey, 0, len(ctx.postRouteKeys())+len(ctx.pageRouteKeys())+len(ctx.listingRouteKeys()))
+	routes := make([]dag.NodeKey, 0, len(ctx.postRouteKeys())+len(ctx.pageRouteKeys())+len(ctx.listingRouteKeys())+len(ctx.series))
 	seen := map[dag.NodeKey]struct{}{}
-	for _, key := range append(append(ctx.postRouteKeys(), ctx.pageRouteKeys()...), ctx.listingRouteKeys()...) {
+	for _, key := range append(append(append(ctx.postRouteKeys(), ctx.pageRouteKeys()...), ctx.listingRouteKeys()...), ctx.seriesRouteKeys()...) {
 		if _, ok := seen[key]; ok {
 			continue
 		}
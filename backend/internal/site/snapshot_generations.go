@@ -0,0 +1,452 @@
+package site
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	snapshotGenerationPrefix = "snapshot-"
+	snapshotManifestSuffix   = ".manifest.json"
+)
+
+// snapshotRetainGenerations is how many published generations stay on disk.
+// Older ones are removed once newer ones exist, which also gives in-flight
+// requests on a replaced generation time to finish.
+var snapshotRetainGenerations = max(1, int(getEnvInt64("SNAPSHOT_RETAIN_GENERATIONS", 5)))
+
+var errSnapshotGenerationNotFound = errors.New("snapshot generation not found")
+
+// errSnapshotGenerationConflict reports that the live generation changed
+// while a clone of it was being revalidated.
+var errSnapshotGenerationConflict = errors.New("snapshot generation changed during revalidation")
+
+// snapshotSwap serializes changes of the live generation: publishes and
+// rollbacks.
+var snapshotSwap = struct {
+	mu sync.Mutex
+}{}
+
+// snapshotManifest describes one published generation. It is stored next to
+// the generation directory (not inside it) so it is never served.
+type snapshotManifest struct {
+	ID      string                          `json:"id"`
+	Parent  string                          `json:"parent,omitempty"`
+	BuiltAt time.Time                       `json:"built_at"`
+	Trigger string                          `json:"trigger"`
+	Files   map[string]snapshotManifestFile `json:"files"`
+}
+
+type snapshotManifestFile struct {
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Absolutized  bool      `json:"absolutized,omitempty"`
+	Encodings    []string  `json:"encodings,omitempty"`
+}
+
+type snapshotGenerationSummary struct {
+	ID      string    `json:"id"`
+	Parent  string    `json:"parent,omitempty"`
+	BuiltAt time.Time `json:"built_at"`
+	Trigger string    `json:"trigger"`
+	Files   int       `json:"files"`
+	Active  bool      `json:"active"`
+}
+
+func newSnapshotGeneration() (string, error) {
+	if err := os.MkdirAll(staticExportDir, 0o755); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(staticExportDir, snapshotGenerationPrefix+time.Now().UTC().Format("20060102-150405")+"-")
+}
+
+// cloneSnapshotGeneration starts a copy-on-write generation from parent.
+// Files are hard-linked; writeSnapshotFile replaces files by rename, so the
+// parent's inodes are never modified.
+func cloneSnapshotGeneration(parent string) (string, error) {
+	root, err := newSnapshotGeneration()
+	if err != nil {
+		return "", err
+	}
+	err = filepath.WalkDir(parent, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(parent, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(root, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if err := os.Link(path, target); err != nil {
+			if err := copySnapshotFile(path, target); err != nil {
+				return err
+			}
+		}
+		if meta, ok := lookupTrackedSnapshotFileMeta(path); ok {
+			storeSnapshotFileMeta(target, meta)
+		}
+		return nil
+	})
+	if err != nil {
+		discardSnapshotGeneration(root)
+		return "", err
+	}
+	return root, nil
+}
+
+func copySnapshotFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func discardSnapshotGeneration(root string) {
+	_ = os.RemoveAll(root)
+	forgetSnapshotFileMetas(root)
+}
+
+// buildAndPublishStaticSnapshot renders a full generation and publishes it
+// with the live one as its parent.
+func buildAndPublishStaticSnapshot(trigger string) (string, error) {
+	root, err := buildStaticSnapshot()
+	if err != nil {
+		return "", err
+	}
+	snapshotSwap.mu.Lock()
+	err = publishSnapshotGenerationLocked(root, getPrerenderedSnapshotDir(), trigger)
+	snapshotSwap.mu.Unlock()
+	if err != nil {
+		discardSnapshotGeneration(root)
+		return "", err
+	}
+	return root, nil
+}
+
+// publishSnapshotGeneration writes the manifest for root, makes it the live
+// generation, and prunes generations beyond the retention limit.
+func publishSnapshotGeneration(root, parent, trigger string) error {
+	snapshotSwap.mu.Lock()
+	defer snapshotSwap.mu.Unlock()
+	return publishSnapshotGenerationLocked(root, parent, trigger)
+}
+
+// publishSnapshotGenerationOver publishes root only while parent is still
+// the live generation, so a clone never overwrites a newer publish or a
+// rollback.
+func publishSnapshotGenerationOver(root, parent, trigger string) error {
+	snapshotSwap.mu.Lock()
+	defer snapshotSwap.mu.Unlock()
+	if getPrerenderedSnapshotDir() != parent {
+		return errSnapshotGenerationConflict
+	}
+	return publishSnapshotGenerationLocked(root, parent, trigger)
+}
+
+func publishSnapshotGenerationLocked(root, parent, trigger string) error {
+	manifest, err := buildSnapshotManifest(root, parent, trigger)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(root+snapshotManifestSuffix, data); err != nil {
+		return err
+	}
+	setPrerenderedSnapshotDir(root)
+	slog.Info("snapshot generation published", "id", manifest.ID, "parent", parent, "trigger", trigger, "files", len(manifest.Files))
+	pruneSnapshotGenerations()
+	return nil
+}
+
+func buildSnapshotManifest(root, parent, trigger string) (snapshotManifest, error) {
+	manifest := snapshotManifest{
+		ID:      filepath.Base(root),
+		BuiltAt: time.Now().UTC(),
+		Trigger: trigger,
+		Files:   map[string]snapshotManifestFile{},
+	}
+	if parent != "" {
+		manifest.Parent = filepath.Base(parent)
+	}
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil || entry.IsDir() {
+			return walkErr
+		}
+		if isSnapshotSibling(path) {
+			return nil
+		}
+		meta, ok := lookupSnapshotFileMeta(path)
+		if !ok {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		manifest.Files[filepath.ToSlash(rel)] = snapshotManifestFile{
+			SHA256:       meta.sha256,
+			Size:         info.Size(),
+			LastModified: meta.modTime,
+			Absolutized:  meta.absolutized,
+			Encodings:    meta.encodings,
+		}
+		return nil
+	})
+	return manifest, err
+}
+
+// isSnapshotSibling reports whether path is a precompressed copy of a
+// snapshot file rather than a file of its own.
+func isSnapshotSibling(path string) bool {
+	for _, encoding := range snapshotEncodings {
+		if base, ok := strings.CutSuffix(path, encoding.suffix); ok {
+			if info, err := os.Stat(base); err == nil && !info.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readSnapshotManifest(id string) (snapshotManifest, error) {
+	manifest := snapshotManifest{}
+	if id == "" || id != filepath.Base(id) || !strings.HasPrefix(id, snapshotGenerationPrefix) {
+		return manifest, errSnapshotGenerationNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(staticExportDir, id+snapshotManifestSuffix))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return manifest, errSnapshotGenerationNotFound
+		}
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, err
+	}
+	if info, err := os.Stat(filepath.Join(staticExportDir, id)); err != nil || !info.IsDir() {
+		return manifest, errSnapshotGenerationNotFound
+	}
+	return manifest, nil
+}
+
+// listSnapshotGenerations returns published generations, newest first.
+func listSnapshotGenerations() ([]snapshotManifest, error) {
+	matches, err := filepath.Glob(filepath.Join(staticExportDir, snapshotGenerationPrefix+"*"+snapshotManifestSuffix))
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]snapshotManifest, 0, len(matches))
+	for _, match := range matches {
+		manifest, err := readSnapshotManifest(strings.TrimSuffix(filepath.Base(match), snapshotManifestSuffix))
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		if !manifests[i].BuiltAt.Equal(manifests[j].BuiltAt) {
+			return manifests[i].BuiltAt.After(manifests[j].BuiltAt)
+		}
+		return manifests[i].ID > manifests[j].ID
+	})
+	return manifests, nil
+}
+
+func snapshotGenerationSummaries() ([]snapshotGenerationSummary, error) {
+	manifests, err := listSnapshotGenerations()
+	if err != nil {
+		return nil, err
+	}
+	active := filepath.Base(getPrerenderedSnapshotDir())
+	summaries := make([]snapshotGenerationSummary, 0, len(manifests))
+	for _, manifest := range manifests {
+		summaries = append(summaries, snapshotGenerationSummary{
+			ID:      manifest.ID,
+			Parent:  manifest.Parent,
+			BuiltAt: manifest.BuiltAt,
+			Trigger: manifest.Trigger,
+			Files:   len(manifest.Files),
+			Active:  manifest.ID == active,
+		})
+	}
+	return summaries, nil
+}
+
+// rollbackSnapshotGeneration makes a retained generation live again. Its
+// validators come from the manifest, so ETags match what was served before.
+func rollbackSnapshotGeneration(id string) error {
+	manifest, err := readSnapshotManifest(id)
+	if err != nil {
+		return err
+	}
+	root := filepath.Join(staticExportDir, manifest.ID)
+	for rel, file := range manifest.Files {
+		sum, err := hex.DecodeString(file.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("snapshot manifest %s: bad hash for %s", manifest.ID, rel)
+		}
+		storeSnapshotFileMeta(filepath.Join(root, filepath.FromSlash(rel)), snapshotFileMeta{
+			sha256:      file.SHA256,
+			etag:        `"` + file.SHA256[:32] + `"`,
+			modTime:     file.LastModified,
+			absolutized: file.Absolutized,
+			encodings:   file.Encodings,
+		})
+	}
+	invalidateDerivedCaches()
+	setPrerenderedSnapshotDir(root)
+	// The shared DAG and search indexes describe the replaced generation, so
+	// the next revalidation rebuilds them instead of diffing against it.
+	setSharedSiteDAGContext(newSiteDAGEngine().NewContext())
+	invalidateSearchIndexes()
+	slog.Info("snapshot generation rolled back", "id", manifest.ID)
+	return nil
+}
+
+// pruneSnapshotGenerations removes published generations beyond the
+// retention limit. The live generation is always kept, even after a rollback
+// to an older one.
+func pruneSnapshotGenerations() {
+	manifests, err := listSnapshotGenerations()
+	if err != nil {
+		slog.Warn("snapshot generation listing failed", "error", err)
+		return
+	}
+	active := filepath.Base(getPrerenderedSnapshotDir())
+	kept := 0
+	for _, manifest := range manifests {
+		if manifest.ID == active || kept < snapshotRetainGenerations-1 {
+			if manifest.ID != active {
+				kept++
+			}
+			continue
+		}
+		root := filepath.Join(staticExportDir, manifest.ID)
+		_ = os.Remove(root + snapshotManifestSuffix)
+		discardSnapshotGeneration(root)
+		slog.Info("snapshot generation pruned", "id", manifest.ID)
+	}
+}
+
+// writeFileAtomic replaces path through a rename so readers (and hard links
+// shared with older generations) never see a partial or modified file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// isSnapshotAdminAuthorized guards the generation endpoints. Unlike
+// revalidation they are refused outright when no STATIC_REGEN_TOKEN is set.
+func isSnapshotAdminAuthorized(r *http.Request) bool {
+	token := strings.TrimSpace(os.Getenv("STATIC_REGEN_TOKEN"))
+	return token != "" && r.Header.Get("X-Regen-Token") == token
+}
+
+func handleSnapshotGenerations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isSnapshotAdminAuthorized(r) {
+		slog.Warn("snapshot generations request forbidden", "remote_addr", r.RemoteAddr)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	summaries, err := snapshotGenerationSummaries()
+	if err != nil {
+		slog.Error("snapshot generations listing failed", "error", err)
+		http.Error(w, "listing failed", http.StatusInternalServerError)
+		return
+	}
+	setNoStoreCacheHeaders(w)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"generations": summaries})
+}
+
+func handleSnapshotRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isSnapshotAdminAuthorized(r) {
+		slog.Warn("snapshot rollback request forbidden", "remote_addr", r.RemoteAddr)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	snapshotSwap.mu.Lock()
+	err := rollbackSnapshotGeneration(strings.TrimSpace(req.ID))
+	snapshotSwap.mu.Unlock()
+	if errors.Is(err, errSnapshotGenerationNotFound) {
+		http.Error(w, "generation not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("snapshot rollback failed", "id", req.ID, "error", err)
+		http.Error(w, "rollback failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"ok":true}`))
+}
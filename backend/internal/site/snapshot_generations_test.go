@@ -0,0 +1,231 @@
+package site
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotGenerationsCloneRollbackAndPrune(t *testing.T) {
+	prevExportDir, prevRetain, prevSnapshot := staticExportDir, snapshotRetainGenerations, getPrerenderedSnapshotDir()
+	staticExportDir = t.TempDir()
+	snapshotRetainGenerations = 2
+	t.Cleanup(func() {
+		staticExportDir, snapshotRetainGenerations = prevExportDir, prevRetain
+		setPrerenderedSnapshotDir(prevSnapshot)
+	})
+
+	first, err := newSnapshotGeneration()
+	if err != nil {
+		t.Fatalf("newSnapshotGeneration: %v", err)
+	}
+	if err := writeSnapshotFile(first, "/about/", []byte("<p>first</p>")); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
+	}
+	if err := writeSnapshotFile(first, "/old/", []byte("<p>old</p>")); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
+	}
+	if err := publishSnapshotGeneration(first, "", "boot"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+	serveAbout := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/about/", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		if !servePrerenderedSnapshot(rec, req, "/about/") {
+			t.Fatal("servePrerenderedSnapshot returned false")
+		}
+		return rec
+	}
+	firstETag := serveAbout("").Header().Get("ETag")
+
+	second, err := cloneSnapshotGeneration(first)
+	if err != nil {
+		t.Fatalf("cloneSnapshotGeneration: %v", err)
+	}
+	if err := writeSnapshotFile(second, "/about/", []byte("<p>second</p>")); err != nil {
+		t.Fatalf("writeSnapshotFile: %v", err)
+	}
+	if err := removeSnapshotRoute(second, "/old/"); err != nil {
+		t.Fatalf("removeSnapshotRoute: %v", err)
+	}
+	if got := getPrerenderedSnapshotDir(); got != first {
+		t.Fatalf("unpublished clone went live: %q", got)
+	}
+	if err := publishSnapshotGeneration(second, first, "revalidate:pages:update"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+
+	// The clone must not have written through hard links into its parent.
+	if body, err := os.ReadFile(filepath.Join(first, "about", "index.html")); err != nil || string(body) != "<p>first</p>" {
+		t.Fatalf("parent generation modified: %q, %v", body, err)
+	}
+	if _, err := os.Stat(filepath.Join(first, "old", "index.html")); err != nil {
+		t.Fatalf("parent generation lost a file: %v", err)
+	}
+
+	manifest, err := readSnapshotManifest(filepath.Base(second))
+	if err != nil {
+		t.Fatalf("readSnapshotManifest: %v", err)
+	}
+	if manifest.Parent != filepath.Base(first) || manifest.Trigger != "revalidate:pages:update" {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	if _, ok := manifest.Files["old/index.html"]; ok || len(manifest.Files) != 1 {
+		t.Fatalf("manifest files = %+v", manifest.Files)
+	}
+	if got, want := manifest.Files["about/index.html"].SHA256, contentSHA256([]byte("<p>second</p>")); got != want {
+		t.Fatalf("manifest hash = %q, want %q", got, want)
+	}
+
+	summaries, err := snapshotGenerationSummaries()
+	if err != nil || len(summaries) != 2 || summaries[0].ID != manifest.ID || !summaries[0].Active {
+		t.Fatalf("summaries = %+v, %v", summaries, err)
+	}
+
+	forgetSnapshotFileMetas(first)
+	if err := rollbackSnapshotGeneration(filepath.Base(first)); err != nil {
+		t.Fatalf("rollbackSnapshotGeneration: %v", err)
+	}
+	if got := getPrerenderedSnapshotDir(); got != first {
+		t.Fatalf("rollback did not activate %q: %q", first, got)
+	}
+	if rec := serveAbout(firstETag); rec.Code != http.StatusNotModified {
+		t.Fatalf("rolled back generation should keep its ETag, status = %d", rec.Code)
+	}
+	if err := rollbackSnapshotGeneration("../" + filepath.Base(first)); err != errSnapshotGenerationNotFound {
+		t.Fatalf("rollback outside the export dir = %v", err)
+	}
+
+	third, err := cloneSnapshotGeneration(first)
+	if err != nil {
+		t.Fatalf("cloneSnapshotGeneration: %v", err)
+	}
+	if err := publishSnapshotGeneration(third, first, "revalidate:posts:create"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+	generations, err := listSnapshotGenerations()
+	if err != nil {
+		t.Fatalf("listSnapshotGenerations: %v", err)
+	}
+	if len(generations) != 2 || generations[0].ID != filepath.Base(third) {
+		t.Fatalf("generations after prune = %+v", generations)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("oldest generation should be pruned: %v", err)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Fatalf("retained generation removed: %v", err)
+	}
+}
+
+func TestSnapshotGenerationEndpointsRequireToken(t *testing.T) {
+	t.Setenv("STATIC_REGEN_TOKEN", "")
+
+	rec := httptest.NewRecorder()
+	routeHandler(rec, httptest.NewRequest(http.MethodGet, "/__internal/snapshots", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status without a configured token = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	routeHandler(rec, httptest.NewRequest(http.MethodPost, "/__internal/snapshots/rollback", strings.NewReader(`{"id":"snapshot-missing"}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("rollback status without a configured token = %d, want 403", rec.Code)
+	}
+
+	t.Setenv("STATIC_REGEN_TOKEN", "secret")
+	rec = httptest.NewRecorder()
+	routeHandler(rec, httptest.NewRequest(http.MethodGet, "/__internal/snapshots", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/__internal/snapshots/rollback", strings.NewReader(`{"id":"snapshot-missing"}`))
+	req.Header.Set("X-Regen-Token", "secret")
+	routeHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestRollbackResetsSharedDAGAndSearchIndexes(t *testing.T) {
+	prevExportDir, prevSnapshot := staticExportDir, getPrerenderedSnapshotDir()
+	staticExportDir = t.TempDir()
+	t.Cleanup(func() {
+		staticExportDir = prevExportDir
+		setPrerenderedSnapshotDir(prevSnapshot)
+	})
+
+	first, err := newSnapshotGeneration()
+	if err != nil {
+		t.Fatalf("newSnapshotGeneration: %v", err)
+	}
+	if err := publishSnapshotGeneration(first, "", "boot"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+	second, err := cloneSnapshotGeneration(first)
+	if err != nil {
+		t.Fatalf("cloneSnapshotGeneration: %v", err)
+	}
+	if err := publishSnapshotGeneration(second, first, "revalidate:posts:update"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+
+	graph := sharedSiteDAGContext()
+	sharedSearchIndex("").sync([]PostRecord{{ID: "post-1", Slug: "newer", Title: "Newer", Published: true}})
+	if currentSearchIndex("") == nil {
+		t.Fatal("search index should be synced before the rollback")
+	}
+
+	if err := rollbackSnapshotGeneration(filepath.Base(first)); err != nil {
+		t.Fatalf("rollbackSnapshotGeneration: %v", err)
+	}
+	if sharedSiteDAGContext() == graph {
+		t.Fatal("rollback kept the shared DAG context of the replaced generation")
+	}
+	if currentSearchIndex("") != nil {
+		t.Fatal("rollback kept the search index of the replaced generation")
+	}
+}
+
+func TestPublishSnapshotGenerationOverRejectsReplacedParent(t *testing.T) {
+	prevExportDir, prevSnapshot := staticExportDir, getPrerenderedSnapshotDir()
+	staticExportDir = t.TempDir()
+	t.Cleanup(func() {
+		staticExportDir = prevExportDir
+		setPrerenderedSnapshotDir(prevSnapshot)
+	})
+
+	base, err := newSnapshotGeneration()
+	if err != nil {
+		t.Fatalf("newSnapshotGeneration: %v", err)
+	}
+	if err := publishSnapshotGeneration(base, "", "boot"); err != nil {
+		t.Fatalf("publishSnapshotGeneration: %v", err)
+	}
+	first, err := cloneSnapshotGeneration(base)
+	if err != nil {
+		t.Fatalf("cloneSnapshotGeneration: %v", err)
+	}
+	second, err := cloneSnapshotGeneration(base)
+	if err != nil {
+		t.Fatalf("cloneSnapshotGeneration: %v", err)
+	}
+
+	if err := publishSnapshotGenerationOver(first, base, "revalidate:posts:update"); err != nil {
+		t.Fatalf("publishSnapshotGenerationOver(first): %v", err)
+	}
+	if err := publishSnapshotGenerationOver(second, base, "revalidate:pages:update"); !errors.Is(err, errSnapshotGenerationConflict) {
+		t.Fatalf("publishSnapshotGenerationOver(second) = %v, want conflict", err)
+	}
+	if got := getPrerenderedSnapshotDir(); got != first {
+		t.Fatalf("live generation = %q, want %q", got, first)
+	}
+}
@@ -0,0 +1,166 @@
+package site
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotFileMeta holds the validators of a snapshot file, computed when the
+// file is written so a conditional request never has to read it.
+type snapshotFileMeta struct {
+	sha256  string
+	etag    string
+	modTime time.Time
+	// absolutized is set when HTML links were rewritten against the
+	// configured site URL at write time.
+	absolutized bool
+	// encodings lists the precompressed siblings written next to the file.
+	encodings []string
+}
+
+var snapshotFileMetas = struct {
+	mu    sync.RWMutex
+	items map[string]snapshotFileMeta
+}{
+	items: map[string]snapshotFileMeta{},
+}
+
+// recordSnapshotFileMeta keeps Last-Modified at the route's published_at
+// while the content is unchanged. A rewrite with different content (an edit,
+// a settings or theme change) moves it forward to the write time, so clients
+// that only send If-Modified-Since still see the update.
+func recordSnapshotFileMeta(root, target, route string, body []byte, absolutized bool, encodings []string) {
+	meta := snapshotFileMeta{
+		sha256:      contentSHA256(body),
+		etag:        contentETag(body),
+		modTime:     snapshotRouteModTime(route),
+		absolutized: absolutized,
+		encodings:   encodings,
+	}
+
+	snapshotFileMetas.mu.Lock()
+	defer snapshotFileMetas.mu.Unlock()
+	prev, ok := snapshotFileMetas.items[target]
+	if !ok {
+		if served := getPrerenderedSnapshotDir(); served != "" && served != root {
+			if rel, err := filepath.Rel(root, target); err == nil {
+				prev, ok = snapshotFileMetas.items[filepath.Join(served, rel)]
+			}
+		}
+	}
+	if ok {
+		if prev.etag == meta.etag {
+			meta.modTime = prev.modTime
+		} else if now := time.Now().UTC().Truncate(time.Second); !meta.modTime.After(prev.modTime) {
+			meta.modTime = now
+			if !now.After(prev.modTime) {
+				meta.modTime = prev.modTime.Add(time.Second)
+			}
+		}
+	}
+	snapshotFileMetas.items[target] = meta
+}
+
+// lookupSnapshotFileMeta falls back to hashing the file for snapshots written
+// before this process started tracking them.
+func lookupSnapshotFileMeta(target string) (snapshotFileMeta, bool) {
+	snapshotFileMetas.mu.RLock()
+	meta, ok := snapshotFileMetas.items[target]
+	snapshotFileMetas.mu.RUnlock()
+	if ok {
+		return meta, true
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.IsDir() {
+		return snapshotFileMeta{}, false
+	}
+	body, err := os.ReadFile(target)
+	if err != nil {
+		return snapshotFileMeta{}, false
+	}
+	meta = snapshotFileMeta{sha256: contentSHA256(body), etag: contentETag(body), modTime: info.ModTime().UTC()}
+	snapshotFileMetas.mu.Lock()
+	snapshotFileMetas.items[target] = meta
+	snapshotFileMetas.mu.Unlock()
+	return meta, true
+}
+
+// lookupTrackedSnapshotFileMeta only returns validators recorded by a write,
+// a clone or a rollback.
+func lookupTrackedSnapshotFileMeta(target string) (snapshotFileMeta, bool) {
+	snapshotFileMetas.mu.RLock()
+	meta, ok := snapshotFileMetas.items[target]
+	snapshotFileMetas.mu.RUnlock()
+	return meta, ok
+}
+
+func storeSnapshotFileMeta(target string, meta snapshotFileMeta) {
+	snapshotFileMetas.mu.Lock()
+	snapshotFileMetas.items[target] = meta
+	snapshotFileMetas.mu.Unlock()
+}
+
+func forgetSnapshotFileMetas(root string) {
+	if root == "" {
+		return
+	}
+	prefix := filepath.Clean(root) + string(os.PathSeparator)
+	snapshotFileMetas.mu.Lock()
+	for target := range snapshotFileMetas.items {
+		if strings.HasPrefix(target, prefix) {
+			delete(snapshotFileMetas.items, target)
+		}
+	}
+	snapshotFileMetas.mu.Unlock()
+}
+
+// snapshotWriteSettings returns the settings a snapshot file is rendered
+// with: the build's while one runs, the cached ones otherwise.
+func snapshotWriteSettings() SettingsRecord {
+	if ctx := currentSnapshotBuildContext(); ctx != nil {
+		return ctx.settings
+	}
+	return getSettings()
+}
+
+// snapshotRouteModTime is the Last-Modified of a snapshot route: the
+// published_at of the post, translation or page it shows, or of the newest
+// post for listings. Outside a snapshot build it is the write time.
+func snapshotRouteModTime(route string) time.Time {
+	ctx := currentSnapshotBuildContext()
+	if ctx == nil {
+		return time.Now().UTC().Truncate(time.Second)
+	}
+
+	clean := cleanPath(route)
+	parts := strings.Split(strings.Trim(clean, "/"), "/")
+	if len(parts) == 2 && parts[0] == "posts" {
+		slug, _ := url.PathUnescape(parts[1])
+		if post, ok := ctx.postBySlug[slug]; ok {
+			return postPublishedTime(post)
+		}
+	}
+	if locale, slug, ok := extractLocalizedPostRoute(clean); ok {
+		slug, _ = url.PathUnescape(slug)
+		if item, ok := ctx.translationByKey[locale+"|"+slug]; ok {
+			return postPublishedTime(translationToPost(item))
+		}
+	}
+	for _, candidate := range []string{route, clean, clean + "/"} {
+		if page, ok := ctx.pageByURL[candidate]; ok {
+			return postPublishedTime(PostRecord{PublishedAt: page.PublishedAt, Date: page.Date})
+		}
+	}
+
+	latest := time.Time{}
+	for _, post := range ctx.publishedPosts {
+		if published := postPublishedTime(post); published.After(latest) {
+			latest = published
+		}
+	}
+	return latest
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"alleycat-backend/internal/dag"
 )
 
 var prerenderedSnapshot = struct {
@@ -19,8 +21,7 @@ var prerenderedSnapshot = struct {
 
 func warmStaticSnapshotAtBoot() {
 	slog.Info("static snapshot bootstrap start", "static_export_dir", staticExportDir)
-	if dir, err := buildStaticSnapshot(); err == nil {
-		setPrerenderedSnapshotDir(dir)
+	if dir, err := buildAndPublishStaticSnapshot("boot"); err == nil {
 		slog.Info("static snapshot bootstrap completed", "dir", dir)
 		return
 	} else {
@@ -31,9 +32,8 @@ func warmStaticSnapshotAtBoot() {
 		backoff := 2 * time.Second
 		for {
 			slog.Info("static snapshot retry start", "backoff", backoff.String())
-			dir, err := buildStaticSnapshot()
+			dir, err := buildAndPublishStaticSnapshot("boot")
 			if err == nil {
-				setPrerenderedSnapshotDir(dir)
 				slog.Info("static snapshot retry completed", "dir", dir)
 				return
 			}
@@ -46,14 +46,12 @@ func warmStaticSnapshotAtBoot() {
 	}()
 }
 
+// setPrerenderedSnapshotDir only swaps the served directory. Replaced
+// generations stay on disk until pruneSnapshotGenerations drops them.
 func setPrerenderedSnapshotDir(next string) {
 	prerenderedSnapshot.mu.Lock()
-	prev := prerenderedSnapshot.dir
 	prerenderedSnapshot.dir = next
 	prerenderedSnapshot.mu.Unlock()
-	if prev != "" && prev != next {
-		_ = os.RemoveAll(prev)
-	}
 }
 
 func getPrerenderedSnapshotDir() string {
@@ -64,17 +62,13 @@ func getPrerenderedSnapshotDir() string {
 }
 
 func buildStaticSnapshot() (string, error) {
-	if err := os.MkdirAll(staticExportDir, 0o755); err != nil {
-		return "", err
-	}
-
-	root, err := os.MkdirTemp(staticExportDir, "snapshot-")
+	root, err := newSnapshotGeneration()
 	if err != nil {
 		return "", err
 	}
 	defer func() {
 		if err != nil {
-			_ = os.RemoveAll(root)
+			discardSnapshotGeneration(root)
 		}
 	}()
 
@@ -92,8 +86,12 @@ func buildStaticSnapshot() (string, error) {
 		"locales", len(parseTranslationLocales(settings.TranslationLocales)),
 	)
 
+	resolveCtx := newSiteDAGEngine().NewContext()
 	err = withSnapshotBuildContext(ctx, func() error {
-		if err := renderSnapshotRoutesInParallel(root, snapshotBuildWorkers(), buildSnapshotRenderTasks(ctx, settings)); err != nil {
+		if err := renderSnapshotRoutesInParallel(root, snapshotBuildWorkers(), buildRouteSnapshotRenderTasks(ctx, resolveCtx)); err != nil {
+			return err
+		}
+		if err := writeSnapshotSearchIndexes(root, ctx); err != nil {
 			return err
 		}
 
@@ -102,6 +100,7 @@ func buildStaticSnapshot() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	setSharedSiteDAGContext(resolveCtx)
 
 	slog.Info("static snapshot build completed", "root", root)
 	return root, nil
@@ -167,25 +166,18 @@ type snapshotRenderTask struct {
 	render func() ([]byte, bool)
 }
 
-func buildSnapshotRenderTasks(ctx *snapshotBuildContext, settings SettingsRecord) []snapshotRenderTask {
-	_ = settings
-	return buildRouteSnapshotRenderTasks(ctx)
-}
-
-func buildRouteSnapshotRenderTasks(ctx *snapshotBuildContext) []snapshotRenderTask {
+func buildRouteSnapshotRenderTasks(ctx *snapshotBuildContext, resolveCtx *dag.ResolveContext) []snapshotRenderTask {
 	if ctx == nil {
 		return nil
 	}
 
-	engine := newSiteDAGEngine()
 	tasks := make([]snapshotRenderTask, 0, len(ctx.routeKeys()))
 	for _, key := range ctx.routeKeys() {
 		route := key.ID
 		tasks = append(tasks, snapshotRenderTask{
 			route: route,
 			render: func() ([]byte, bool) {
-				resolveCtx := engine.NewContext()
-				value, err := engine.Resolve(resolveCtx, routeNodeKey(route))
+				value, err := resolveCtx.Resolve(routeNodeKey(route))
 				if err != nil {
 					slog.Error("static snapshot post route render failed", "route", route, "error", err)
 					return nil, false
@@ -305,7 +297,22 @@ func writeSnapshotFile(root, route string, body []byte) error {
 	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
 		return err
 	}
-	return os.WriteFile(target, body, 0o644)
+	// With a configured site URL, HTML is absolutized once here so it can be
+	// served (and precompressed) as-is.
+	contentType := snapshotContentType(route)
+	absolutized := false
+	if strings.HasPrefix(contentType, "text/html") {
+		if settings := snapshotWriteSettings(); normalizeSiteBaseURL(settings.SiteURL) != "" {
+			body = absolutizePrerenderedSnapshotHTML(body, settings)
+			absolutized = true
+		}
+	}
+	if err := writeFileAtomic(target, body); err != nil {
+		return err
+	}
+	encodings := writeSnapshotSiblings(target, contentType, body)
+	recordSnapshotFileMeta(root, target, route, body, absolutized, encodings)
+	return nil
 }
 
 func snapshotFilePath(root, route string) (string, error) {
@@ -53,14 +53,15 @@ func TestBuildSnapshotRenderTasksUsesDAGForRoutes(t *testing.T) {
 		postsByTag:           map[string][]PostRecord{},
 		postsByCategory:      map[string][]PostRecord{},
 		archiveIndex: map[string]archiveListing{
-			"/archive/": {posts: []PostRecord{source}, pageCount: 1},
+			"/archive/":    {posts: []PostRecord{source}, pageCount: 1},
+			"/ru/archive/": {posts: []PostRecord{translationToPost(translation)}, pageCount: 1},
 		},
 	}
 
 	err := withSnapshotBuildContext(ctx, func() error {
-		tasks := buildSnapshotRenderTasks(ctx, settings)
-		if len(tasks) != 5 {
-			t.Fatalf("buildSnapshotRenderTasks length = %d, want 5", len(tasks))
+		tasks := buildRouteSnapshotRenderTasks(ctx, newSiteDAGEngine().NewContext())
+		if len(tasks) != 7 {
+			t.Fatalf("buildRouteSnapshotRenderTasks length = %d, want 7", len(tasks))
 		}
 
 		seen := map[string]bool{}
@@ -72,11 +73,11 @@ func TestBuildSnapshotRenderTasksUsesDAGForRoutes(t *testing.T) {
 			seen[task.route] = true
 			switch task.route {
 			case "/":
-				if !strings.Contains(string(body), "More posts can be found") {
+				if !strings.Contains(string(body), "過去の記事は") {
 					t.Fatalf("home body missing expected content: %s", string(body))
 				}
 			case "/archive":
-				if !strings.Contains(string(body), "Archive") {
+				if !strings.Contains(string(body), "アーカイブ") {
 					t.Fatalf("archive body missing title: %s", string(body))
 				}
 			case "/posts/hello":
@@ -84,16 +85,24 @@ func TestBuildSnapshotRenderTasksUsesDAGForRoutes(t *testing.T) {
 					t.Fatalf("base post body missing title: %s", string(body))
 				}
 			case "/ru/posts/privet":
-				if !strings.Contains(string(body), "Privet") {
+				if !strings.Contains(string(body), "Privet") || !strings.Contains(string(body), `<html lang="ru" dir="ltr">`) {
 					t.Fatalf("localized post body missing title: %s", string(body))
 				}
+			case "/ru":
+				if !strings.Contains(string(body), `href="/ru/posts/privet/"`) || !strings.Contains(string(body), `<a href="/ru/archive/">Архив</a>`) {
+					t.Fatalf("localized home body missing translation listing: %s", string(body))
+				}
+			case "/ru/archive":
+				if !strings.Contains(string(body), `<html lang="ru" dir="ltr">`) || !strings.Contains(string(body), "Privet") {
+					t.Fatalf("localized archive body missing translation listing: %s", string(body))
+				}
 			case "/about":
 				if !strings.Contains(string(body), "About") {
 					t.Fatalf("page body missing title: %s", string(body))
 				}
 			}
 		}
-		for _, route := range []string{"/", "/archive", "/posts/hello", "/ru/posts/privet", "/about"} {
+		for _, route := range []string{"/", "/archive", "/posts/hello", "/ru/posts/privet", "/ru", "/ru/archive", "/about"} {
 			if !seen[route] {
 				t.Fatalf("missing route %q in tasks", route)
 			}
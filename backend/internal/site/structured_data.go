@@ -0,0 +1,155 @@
+package site
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// renderJSONLD wraps schema.org data in a script tag. json.Marshal escapes
+// <, > and &, so the payload can't close the script element.
+func renderJSONLD(data map[string]any) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	return `<script type="application/ld+json">` + string(encoded) + `</script>`
+}
+
+func absoluteOrRelativeSiteURL(settings SettingsRecord, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return defaultString(buildAbsoluteSiteURL(settings, path), path)
+}
+
+// renderPostJSONLD describes a post or translation as a BlogPosting. A
+// translation points at its source post through translationOfWork.
+func renderPostJSONLD(input postMetaInput, canonicalURL, imageURL string, settings SettingsRecord) string {
+	data := map[string]any{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         strings.TrimSpace(input.Title),
+		"url":              canonicalURL,
+		"mainEntityOfPage": canonicalURL,
+		"publisher": map[string]any{
+			"@type": "Organization",
+			"name":  settings.SiteName,
+			"url":   absoluteOrRelativeSiteURL(settings, "/"),
+		},
+	}
+	if description := strings.TrimSpace(input.Description); description != "" {
+		data["description"] = description
+	}
+	if publishedAt := strings.TrimSpace(input.PublishedAt); publishedAt != "" {
+		data["datePublished"] = publishedAt
+	}
+	if locale := normalizeLocale(input.Locale); locale != "" {
+		data["inLanguage"] = locale
+	}
+	if imageURL == "" && strings.TrimSpace(input.Image) != "" {
+		imageURL = absoluteOrRelativeSiteURL(settings, input.Image)
+	}
+	if imageURL != "" {
+		data["image"] = imageURL
+	}
+	if person := authorJSONLDPerson(input.Author, settings); person != nil {
+		data["author"] = person
+	}
+	if sourcePath := strings.TrimSpace(input.SourcePath); sourcePath != "" && sourcePath != input.Path {
+		work := map[string]any{
+			"@type": "BlogPosting",
+			"@id":   absoluteOrRelativeSiteURL(settings, sourcePath),
+			"url":   absoluteOrRelativeSiteURL(settings, sourcePath),
+		}
+		if locale := normalizeLocale(input.SourceLocale); locale != "" {
+			work["inLanguage"] = locale
+		}
+		data["translationOfWork"] = work
+	}
+	return renderJSONLD(data)
+}
+
+func renderPageJSONLD(page *PageRecord, locale string, settings SettingsRecord) string {
+	if page == nil || strings.TrimSpace(page.URL) == "" {
+		return ""
+	}
+	pageURL := absoluteOrRelativeSiteURL(settings, page.URL)
+	data := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "WebPage",
+		"name":     defaultString(strings.TrimSpace(page.Title), "Page"),
+		"url":      pageURL,
+		"isPartOf": map[string]any{
+			"@type": "WebSite",
+			"name":  settings.SiteName,
+			"url":   absoluteOrRelativeSiteURL(settings, "/"),
+		},
+	}
+	if publishedAt := strings.TrimSpace(defaultString(page.PublishedAt, page.Date)); publishedAt != "" {
+		data["datePublished"] = publishedAt
+	}
+	if language := defaultString(normalizeLocale(locale), siteSourceLocale(settings)); language != "" {
+		data["inLanguage"] = language
+	}
+	return renderJSONLD(data)
+}
+
+// renderWebSiteJSONLD describes the site with a SearchAction on the archive
+// search, so search engines can offer a sitelinks search box.
+func renderWebSiteJSONLD(settings SettingsRecord) string {
+	data := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "WebSite",
+		"name":     settings.SiteName,
+		"url":      absoluteOrRelativeSiteURL(settings, "/"),
+		"potentialAction": map[string]any{
+			"@type": "SearchAction",
+			"target": map[string]any{
+				"@type":       "EntryPoint",
+				"urlTemplate": absoluteOrRelativeSiteURL(settings, "/archive") + "?q={search_term_string}",
+			},
+			"query-input": "required name=search_term_string",
+		},
+	}
+	if description := strings.TrimSpace(settings.Description); description != "" {
+		data["description"] = description
+	}
+	if locale := siteSourceLocale(settings); locale != "" {
+		data["inLanguage"] = locale
+	}
+	return renderJSONLD(data)
+}
+
+// renderArchiveBreadcrumbJSONLD lists Home > Archive > term for tag and
+// category archives. Other archives have no breadcrumb trail.
+func renderArchiveBreadcrumbJSONLD(route archiveRoute, settings SettingsRecord) string {
+	tag, category := route.scope()
+	name := defaultString(tag, category)
+	if strings.TrimSpace(name) == "" {
+		return ""
+	}
+	prefix := localePathPrefix(route.locale)
+	items := []map[string]any{
+		{"@type": "ListItem", "position": 1, "name": defaultString(settings.SiteName, "Home"), "item": absoluteOrRelativeSiteURL(settings, prefix+"/")},
+		{"@type": "ListItem", "position": 2, "name": "Archive", "item": absoluteOrRelativeSiteURL(settings, prefix+"/archive/")},
+		{"@type": "ListItem", "position": 3, "name": name, "item": absoluteOrRelativeSiteURL(settings, route.basePath+"/")},
+	}
+	return renderJSONLD(map[string]any{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	})
+}
+
+// postFeaturedImagePath prefers the translation's own featured image and
+// falls back to the source post's.
+func postFeaturedImagePath(translation *PostTranslationRecord, source *PostRecord) string {
+	if translation != nil && strings.TrimSpace(translation.FeaturedImage) != "" {
+		return "/api/files/post_translations/" + url.PathEscape(translation.ID) + "/" + url.PathEscape(translation.FeaturedImage)
+	}
+	if source != nil && strings.TrimSpace(source.FeaturedImage) != "" {
+		return "/api/files/posts/" + url.PathEscape(source.ID) + "/" + url.PathEscape(source.FeaturedImage)
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package site
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPostMetaTagsTranslationJSONLD(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.SiteURL = "https://example.com"
+	settings.SiteName = "Alleycat"
+	settings.EnableOGPImageGeneration = false
+	translation := &PostTranslationRecord{ID: "t1", SourcePost: "p1", Locale: "en", Slug: "hello", FeaturedImage: "cover.png"}
+	html := renderPostMetaTags(postMetaInput{
+		Path:         "/en/posts/hello/",
+		Locale:       "en",
+		Title:        "Hello",
+		PublishedAt:  "2026-03-22T10:11:12Z",
+		Image:        postFeaturedImagePath(translation, &PostRecord{ID: "p1", FeaturedImage: "source.png"}),
+		SourcePath:   "/posts/konnichiwa/",
+		SourceLocale: "ja",
+	}, settings)
+
+	for _, token := range []string{
+		`"@type":"BlogPosting"`,
+		`"headline":"Hello"`,
+		`"inLanguage":"en"`,
+		`"image":"https://example.com/api/files/post_translations/t1/cover.png"`,
+		`"translationOfWork":{"@id":"https://example.com/posts/konnichiwa","@type":"BlogPosting","inLanguage":"ja","url":"https://example.com/posts/konnichiwa"}`,
+	} {
+		if !strings.Contains(html, token) {
+			t.Fatalf("meta tags missing %q: %s", token, html)
+		}
+	}
+
+	settings.EnableOGPImageGeneration = true
+	html = renderPostMetaTags(postMetaInput{Path: "/posts/hello/", Title: "Hello", Image: "/api/files/posts/p1/source.png"}, settings)
+	if !strings.Contains(html, `"image":"https://example.com/og/`) || strings.Contains(html, "translationOfWork") {
+		t.Fatalf("source post JSON-LD should use the OG image: %s", html)
+	}
+}
+
+func TestArchiveAndSiteJSONLD(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultSettings()
+	settings.SiteURL = "https://example.com"
+	settings.SiteName = "Alleycat"
+
+	tag := renderArchiveBreadcrumbJSONLD(archiveRoute{basePath: "/archive/go%20lang", pageNumber: 1}, settings)
+	if !strings.Contains(tag, `"@type":"BreadcrumbList"`) || !strings.Contains(tag, `"name":"go lang","position":3`) {
+		t.Fatalf("tag breadcrumb = %s", tag)
+	}
+	category := renderArchiveBreadcrumbJSONLD(archiveRoute{basePath: "/archive/category/notes", pageNumber: 1}, settings)
+	if !strings.Contains(category, `"item":"https://example.com/archive/category/notes","name":"notes","position":3`) {
+		t.Fatalf("category breadcrumb = %s", category)
+	}
+	if root := renderArchiveBreadcrumbJSONLD(archiveRoute{basePath: "/archive", pageNumber: 1}, settings); root != "" {
+		t.Fatalf("root archive should have no breadcrumb: %s", root)
+	}
+
+	site := renderWebSiteJSONLD(settings)
+	if !strings.Contains(site, `"urlTemplate":"https://example.com/archive?q={search_term_string}"`) || !strings.Contains(site, `"@type":"SearchAction"`) {
+		t.Fatalf("website JSON-LD = %s", site)
+	}
+}
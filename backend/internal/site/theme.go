@@ -0,0 +1,542 @@
+package site
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	builtinThemeName     = "default"
+	themeManifestFile    = "theme.json"
+	themeCacheTTL        = time.Minute
+	maxThemePackageBytes = 10 << 20
+	maxThemeFileBytes    = 4 << 20
+)
+
+//go:embed themes/default
+var builtinThemeFS embed.FS
+
+var themeNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// themeManifest is the theme.json at the root of a theme package. Templates
+// and stylesheet are relative to the package directory.
+type themeManifest struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Stylesheet string `json:"stylesheet"`
+	Templates  string `json:"templates"`
+}
+
+type themePackage struct {
+	name       string
+	manifest   themeManifest
+	templates  *template.Template
+	stylesheet string
+}
+
+type themeCacheEntry struct {
+	pkg       themePackage
+	expiresAt time.Time
+}
+
+// The views below are the data theme templates receive. Fields typed
+// template.HTML are fragments the site has already rendered and escaped.
+type themeLayout struct {
+	Head   headView
+	Nav    navView
+	Footer footerView
+	Text   uiText
+}
+
+type headView struct {
+	Lang           string
+	Dir            string
+	Title          string
+	SiteName       string
+	Description    string
+	ThemeStyles    template.HTML
+	FontStyles     template.HTML
+	CriticalStyles template.HTML
+	FeedAlternates template.HTML
+	Analytics      template.HTML
+	Ads            template.HTML
+	CodeHighlight  template.HTML
+	Extra          template.HTML
+}
+
+type navLink struct {
+	URL   string
+	Label string
+}
+
+type navView struct {
+	SiteName   string
+	HomeURL    string
+	ArchiveURL string
+	Links      []navLink
+	Text       uiText
+}
+
+type footerView struct {
+	FooterHTML template.HTML
+}
+
+type postListItem struct {
+	URL           string
+	Title         string
+	Date          string
+	DateLabel     string
+	ReadTime      int
+	ReadTimeLabel string
+	Tags          template.HTML
+	Excerpt       string
+	Snippet       template.HTML
+}
+
+type postListView struct {
+	Items []postListItem
+	Text  uiText
+}
+
+type homeView struct {
+	themeLayout
+	TopImage    string
+	TopImageAlt string
+	WelcomeText string
+	Posts       template.HTML
+}
+
+type archiveView struct {
+	themeLayout
+	Title         string
+	FeedLinks     template.HTML
+	Search        template.HTML
+	Posts         template.HTML
+	Pagination    template.HTML
+	TagsNav       template.HTML
+	CategoriesNav template.HTML
+}
+
+type postView struct {
+	themeLayout
+	Title         string
+	Date          string
+	DateLabel     string
+	Byline        template.HTML
+	ReadTime      int
+	ReadTimeLabel string
+	Category      string
+	Tags          template.HTML
+	Languages     template.HTML
+	Series        template.HTML
+	TOC           template.HTML
+	Body          template.HTML
+	Comments      template.HTML
+	Related       template.HTML
+	Navigation    template.HTML
+}
+
+type pageView struct {
+	themeLayout
+	Title string
+	Body  template.HTML
+}
+
+type notFoundView struct {
+	themeLayout
+}
+
+type seriesView struct {
+	themeLayout
+	Title       string
+	Description string
+	Parts       []seriesPart
+}
+
+var themeCache = struct {
+	mu    sync.RWMutex
+	items map[string]themeCacheEntry
+	// uploaded holds the names of the theme records, refreshed with the same
+	// TTL as items, so unknown names never reach PocketBase one by one.
+	uploaded          map[string]struct{}
+	uploadedExpiresAt time.Time
+}{items: map[string]themeCacheEntry{}}
+
+var builtinTheme = sync.OnceValue(func() themePackage {
+	templates, err := parseThemeTemplates(nil, "")
+	if err != nil {
+		panic(fmt.Sprintf("parse built-in theme: %v", err))
+	}
+	return themePackage{name: builtinThemeName, manifest: themeManifest{Name: builtinThemeName}, templates: templates}
+})
+
+func invalidateThemeCache() {
+	themeCache.mu.Lock()
+	themeCache.items = map[string]themeCacheEntry{}
+	themeCache.uploaded = nil
+	themeCache.uploadedExpiresAt = time.Time{}
+	themeCache.mu.Unlock()
+}
+
+func normalizeThemeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = strings.ToLower(strings.TrimSpace(defaultTheme))
+	}
+	return name
+}
+
+// themeFor returns the template set for settings.Theme. A theme without a
+// package keeps the built-in templates and only swaps its stylesheet, which
+// is how the bundled CSS themes work.
+func themeFor(settings SettingsRecord) themePackage {
+	name := normalizeThemeName(settings.Theme)
+	now := time.Now()
+	themeCache.mu.RLock()
+	cached, ok := themeCache.items[name]
+	themeCache.mu.RUnlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.pkg
+	}
+	if !isKnownTheme(name) {
+		// Unknown names are not cached, so they cannot grow the cache.
+		return themePackage{name: name, manifest: themeManifest{Name: name}, templates: builtinTheme().templates, stylesheet: themeStylesheet(name)}
+	}
+
+	pkg := loadThemePackage(name)
+	themeCache.mu.Lock()
+	themeCache.items[name] = themeCacheEntry{pkg: pkg, expiresAt: now.Add(themeCacheTTL)}
+	themeCache.mu.Unlock()
+	return pkg
+}
+
+// isKnownTheme reports whether name is the built-in or configured default
+// theme, a theme directory under the public dir or an uploaded theme record.
+func isKnownTheme(name string) bool {
+	name = normalizeThemeName(name)
+	if name == builtinThemeName || name == normalizeThemeName(defaultTheme) {
+		return true
+	}
+	if !themeNamePattern.MatchString(name) {
+		return false
+	}
+	if info, err := os.Stat(filepath.Join(activePublicDir, "themes", name)); err == nil && info.IsDir() {
+		return true
+	}
+	_, ok := uploadedThemeNames()[name]
+	return ok
+}
+
+func uploadedThemeNames() map[string]struct{} {
+	now := time.Now()
+	themeCache.mu.RLock()
+	names, expiresAt := themeCache.uploaded, themeCache.uploadedExpiresAt
+	themeCache.mu.RUnlock()
+	if names != nil && now.Before(expiresAt) {
+		return names
+	}
+
+	list, err := fetchList[ThemeRecord](fmt.Sprintf("%s/api/collections/themes/records", pbURL), map[string]string{
+		"page":    "1",
+		"perPage": "500",
+		"fields":  "name",
+	})
+	if err != nil {
+		slog.Warn("theme list fetch failed", "error", err)
+		if names == nil {
+			names = map[string]struct{}{}
+		}
+	} else {
+		names = make(map[string]struct{}, len(list.Items))
+		for _, item := range list.Items {
+			names[normalizeThemeName(item.Name)] = struct{}{}
+		}
+	}
+	themeCache.mu.Lock()
+	themeCache.uploaded = names
+	themeCache.uploadedExpiresAt = now.Add(themeCacheTTL)
+	themeCache.mu.Unlock()
+	return names
+}
+
+func loadThemePackage(name string) themePackage {
+	pkg := themePackage{name: name, manifest: themeManifest{Name: name}, stylesheet: themeStylesheet(name)}
+	fallback := builtinTheme()
+	pkg.templates = fallback.templates
+	if !themeNamePattern.MatchString(name) {
+		return pkg
+	}
+
+	dir, ok := localThemeDir(name)
+	if !ok {
+		dir, ok = syncUploadedTheme(name)
+	}
+	if !ok {
+		return pkg
+	}
+	manifest, err := readThemeManifest(dir)
+	if err != nil {
+		slog.Warn("theme manifest invalid", "theme", name, "error", err)
+		return pkg
+	}
+	templates, err := parseThemeTemplates(os.DirFS(dir), defaultString(manifest.Templates, "templates"))
+	if err != nil {
+		slog.Warn("theme templates invalid", "theme", name, "error", err)
+		return pkg
+	}
+	pkg.manifest = manifest
+	pkg.templates = templates
+	if stylesheet := strings.TrimSpace(manifest.Stylesheet); stylesheet != "" {
+		pkg.stylesheet = "/themes/" + url.PathEscape(name) + "/" + strings.TrimPrefix(path.Clean("/"+stylesheet), "/")
+	}
+	return pkg
+}
+
+// parseThemeTemplates parses the built-in templates and then the theme's own
+// on top, so a package only has to ship the partials it changes.
+func parseThemeTemplates(fsys fs.FS, dir string) (*template.Template, error) {
+	templates, err := template.New(builtinThemeName).ParseFS(builtinThemeFS, "themes/default/templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	if fsys == nil {
+		return templates, nil
+	}
+	dir = path.Clean(strings.TrimPrefix(dir, "/"))
+	matches, err := fs.Glob(fsys, path.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return templates, nil
+	}
+	return templates.ParseFS(fsys, matches...)
+}
+
+func readThemeManifest(dir string) (themeManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, themeManifestFile))
+	if err != nil {
+		return themeManifest{}, err
+	}
+	var manifest themeManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return themeManifest{}, err
+	}
+	if strings.Contains(manifest.Templates, "..") || strings.Contains(manifest.Stylesheet, "..") {
+		return themeManifest{}, errors.New("theme paths must stay inside the package")
+	}
+	return manifest, nil
+}
+
+func localThemeDir(name string) (string, bool) {
+	dir := filepath.Join(activePublicDir, "themes", name)
+	if info, err := os.Stat(filepath.Join(dir, themeManifestFile)); err != nil || info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// syncUploadedTheme extracts the package uploaded through the CMS into
+// themeCacheDir, downloading again only when the record changes.
+func syncUploadedTheme(name string) (string, bool) {
+	list, err := fetchList[ThemeRecord](fmt.Sprintf("%s/api/collections/themes/records", pbURL), map[string]string{
+		"page":    "1",
+		"perPage": "1",
+		"filter":  fmt.Sprintf(`name = "%s"`, escapeFilter(name)),
+	})
+	if err != nil || len(list.Items) == 0 {
+		return "", false
+	}
+	record := list.Items[0]
+	if strings.TrimSpace(record.Package) == "" {
+		return "", false
+	}
+	dir := filepath.Join(themeCacheDir, name)
+	marker := record.Package + "\n" + record.Updated
+	if current, err := os.ReadFile(filepath.Join(dir, ".package")); err == nil && string(current) == marker {
+		return dir, true
+	}
+
+	data, err := downloadThemePackage(record)
+	if err != nil {
+		slog.Warn("theme package download failed", "theme", name, "error", err)
+		return "", false
+	}
+	if err := extractThemePackage(data, dir); err != nil {
+		slog.Warn("theme package extract failed", "theme", name, "error", err)
+		return "", false
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".package"), []byte(marker), 0o644); err != nil {
+		slog.Warn("theme package marker write failed", "theme", name, "error", err)
+	}
+	return dir, true
+}
+
+func downloadThemePackage(record ThemeRecord) ([]byte, error) {
+	target := fmt.Sprintf("%s/api/files/themes/%s/%s", pbURL, record.ID, url.PathEscape(record.Package))
+	resp, err := httpClient.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThemePackageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThemePackageBytes {
+		return nil, errors.New("theme package too large")
+	}
+	return data, nil
+}
+
+// extractThemePackage unpacks a zip into dest. The package may be zipped with
+// or without its top-level directory; theme.json marks the root either way.
+func extractThemePackage(data []byte, dest string) error {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	root, ok := themePackageRoot(reader.File)
+	if !ok {
+		return errors.New("theme package has no theme.json")
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dest), ".theme-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name, ok := strings.CutPrefix(file.Name, root)
+		if !ok {
+			continue
+		}
+		clean := path.Clean(name)
+		if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return fmt.Errorf("theme package path %q escapes the package", file.Name)
+		}
+		if file.UncompressedSize64 > maxThemeFileBytes {
+			return fmt.Errorf("theme package file %q is too large", file.Name)
+		}
+		if err := extractThemeFile(file, filepath.Join(tmp, filepath.FromSlash(clean))); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func extractThemeFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, io.LimitReader(src, maxThemeFileBytes)); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func themePackageRoot(files []*zip.File) (string, bool) {
+	root := ""
+	found := false
+	for _, file := range files {
+		if path.Base(file.Name) != themeManifestFile {
+			continue
+		}
+		dir := path.Dir(file.Name)
+		prefix := ""
+		if dir != "." {
+			prefix = dir + "/"
+		}
+		if !found || len(prefix) < len(root) {
+			root = prefix
+			found = true
+		}
+	}
+	return root, found
+}
+
+// resolveThemeAssetPath maps /themes/<name>/... to a file of an extracted
+// uploaded theme.
+func resolveThemeAssetPath(clean string) (string, bool) {
+	rest, ok := strings.CutPrefix(clean, "/themes/")
+	if !ok {
+		return "", false
+	}
+	name, file, ok := strings.Cut(rest, "/")
+	if !ok || !themeNamePattern.MatchString(name) || file == "" || strings.HasPrefix(path.Base(file), ".") {
+		return "", false
+	}
+	target := filepath.Join(themeCacheDir, name, filepath.FromSlash(file))
+	rel, err := filepath.Rel(filepath.Join(themeCacheDir, name), target)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	if info, err := os.Stat(target); err != nil || info.IsDir() {
+		return "", false
+	}
+	return target, true
+}
+
+// renderThemeTemplate executes a template of the active theme, falling back
+// to the built-in set when a package template fails.
+func renderThemeTemplate(settings SettingsRecord, name string, data any) string {
+	pkg := themeFor(settings)
+	out := bytes.Buffer{}
+	err := pkg.templates.ExecuteTemplate(&out, name, data)
+	if err == nil {
+		return out.String()
+	}
+	slog.Warn("theme template render failed", "theme", pkg.name, "template", name, "error", err)
+	out.Reset()
+	if err := builtinTheme().templates.ExecuteTemplate(&out, name, data); err != nil {
+		slog.Error("built-in template render failed", "template", name, "error", err)
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,151 @@
+package site
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func buildThemeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBuiltinThemeRendersCurrentMarkup(t *testing.T) {
+	t.Parallel()
+
+	settings := SettingsRecord{SiteName: "Alley & Cat", FooterHTML: "<p>footer</p>"}
+	nav := renderNav([]PageRecord{{URL: "/about/", Title: "About"}}, settings)
+	if !strings.Contains(nav, `<strong>Alley &amp; Cat</strong>`) || !strings.Contains(nav, `<li><a href="/about/">About</a></li>`) {
+		t.Fatalf("nav = %q", nav)
+	}
+	footer := renderFooter(settings)
+	if !strings.Contains(footer, `<footer class="footer"><p>footer</p></footer>`) || !strings.HasSuffix(footer, "</html>") {
+		t.Fatalf("footer = %q", footer)
+	}
+	if got := renderFooter(SettingsRecord{}); strings.Contains(got, "<footer") {
+		t.Fatalf("footer should be omitted when empty, got %q", got)
+	}
+}
+
+func TestExtractThemePackageOverridesPartials(t *testing.T) {
+	t.Parallel()
+
+	data := buildThemeZip(t, map[string]string{
+		"paper/theme.json":            `{"name":"paper","version":"1.0.0","stylesheet":"paper.css","templates":"templates"}`,
+		"paper/paper.css":             `body{color:black}`,
+		"paper/templates/footer.html": `<footer class="paper">{{.FooterHTML}}</footer></body></html>`,
+	})
+	dir := filepath.Join(t.TempDir(), "paper")
+	if err := extractThemePackage(data, dir); err != nil {
+		t.Fatalf("extractThemePackage: %v", err)
+	}
+	manifest, err := readThemeManifest(dir)
+	if err != nil || manifest.Stylesheet != "paper.css" {
+		t.Fatalf("manifest = %+v, err = %v", manifest, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "paper.css")); err != nil {
+		t.Fatalf("stylesheet was not extracted: %v", err)
+	}
+
+	templates, err := parseThemeTemplates(os.DirFS(dir), manifest.Templates)
+	if err != nil {
+		t.Fatalf("parseThemeTemplates: %v", err)
+	}
+	out := strings.Builder{}
+	if err := templates.ExecuteTemplate(&out, "footer.html", footerView{FooterHTML: "hi"}); err != nil {
+		t.Fatalf("execute footer: %v", err)
+	}
+	if out.String() != `<footer class="paper">hi</footer></body></html>` {
+		t.Fatalf("footer = %q", out.String())
+	}
+	if templates.Lookup("post.html") == nil {
+		t.Fatalf("partials the package does not ship should fall back to the built-in theme")
+	}
+}
+
+func TestExtractThemePackageRejectsUnsafePaths(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]map[string]string{
+		"traversal": {
+			"theme.json":   `{"name":"evil"}`,
+			"../evil.html": "x",
+		},
+		"no manifest": {
+			"templates/footer.html": "x",
+		},
+	}
+	for name, files := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(t.TempDir(), "evil")
+			if err := extractThemePackage(buildThemeZip(t, files), dir); err == nil {
+				t.Fatalf("extractThemePackage should fail")
+			}
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Fatalf("failed extraction should not leave %s behind", dir)
+			}
+		})
+	}
+}
+
+func TestPreviewThemeSwitchesTemplateSet(t *testing.T) {
+	t.Parallel()
+
+	templates, err := parseThemeTemplates(fstest.MapFS{
+		"templates/nav.html": {Data: []byte(`<nav class="preview-nav">{{.SiteName}}</nav>`)},
+	}, "templates")
+	if err != nil {
+		t.Fatalf("parseThemeTemplates: %v", err)
+	}
+	themeCache.mu.Lock()
+	themeCache.items["preview-test"] = themeCacheEntry{
+		pkg:       themePackage{name: "preview-test", templates: templates, stylesheet: "/themes/preview-test/styles.css"},
+		expiresAt: time.Now().Add(time.Hour),
+	}
+	themeCache.uploaded = map[string]struct{}{"preview-test": {}}
+	themeCache.uploadedExpiresAt = time.Now().Add(time.Hour)
+	themeCache.mu.Unlock()
+
+	base := SettingsRecord{SiteName: "Blog", Theme: "minimal"}
+	preview := withPreviewTheme(base, httptest.NewRequest("GET", "/?theme=preview-test", nil))
+	if got := renderNav(nil, preview); got != `<nav class="preview-nav">Blog</nav>` {
+		t.Fatalf("preview nav = %q", got)
+	}
+	if got := renderNav(nil, base); !strings.Contains(got, `class="navbar"`) {
+		t.Fatalf("configured theme nav = %q", got)
+	}
+
+	unknown := withPreviewTheme(base, httptest.NewRequest("GET", "/?theme=no-such-theme", nil))
+	if unknown.Theme != "minimal" {
+		t.Fatalf("unknown preview theme = %q, want the configured theme", unknown.Theme)
+	}
+	themeFor(SettingsRecord{Theme: "no-such-theme"})
+	themeCache.mu.RLock()
+	_, cached := themeCache.items["no-such-theme"]
+	themeCache.mu.RUnlock()
+	if cached {
+		t.Fatal("unknown theme should not be cached")
+	}
+}
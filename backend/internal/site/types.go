@@ -1,6 +1,9 @@
 package site
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type PBList[T any] struct {
 	Items      []T `json:"items"`
@@ -24,24 +27,42 @@ type PostRecord struct {
 	Excerpt     string `json:"excerpt"`
 	Tags        string `json:"tags"`
 	Category    string `json:"category"`
+	Author      string `json:"author"`
 	Published   bool   `json:"published"`
 	PublishedAt string `json:"published_at"`
 	Date        string `json:"date"`
+	// FeaturedImage is the file name in the record's featured_image field.
+	FeaturedImage string `json:"featured_image"`
 }
 
 type PostTranslationRecord struct {
-	ID              string `json:"id"`
-	SourcePost      string `json:"source_post"`
-	Locale          string `json:"locale"`
-	Title           string `json:"title"`
-	Slug            string `json:"slug"`
-	Body            string `json:"body"`
-	Excerpt         string `json:"excerpt"`
-	Tags            string `json:"tags"`
-	Category        string `json:"category"`
-	Published       bool   `json:"published"`
-	PublishedAt     string `json:"published_at"`
-	TranslationDone bool   `json:"translation_done"`
+	ID                string `json:"id"`
+	SourcePost        string `json:"source_post"`
+	Locale            string `json:"locale"`
+	Title             string `json:"title"`
+	Slug              string `json:"slug"`
+	Body              string `json:"body"`
+	Excerpt           string `json:"excerpt"`
+	Tags              string `json:"tags"`
+	Category          string `json:"category"`
+	Author            string `json:"author"`
+	Published         bool   `json:"published"`
+	PublishedAt       string `json:"published_at"`
+	TranslationDone   bool   `json:"translation_done"`
+	TranslationStatus string `json:"translation_status"`
+	FeaturedImage     string `json:"featured_image"`
+}
+
+type PageTranslationRecord struct {
+	ID                string `json:"id"`
+	SourcePage        string `json:"source_page"`
+	Locale            string `json:"locale"`
+	Title             string `json:"title"`
+	Body              string `json:"body"`
+	MenuTitle         string `json:"menuTitle"`
+	Published         bool   `json:"published"`
+	PublishedAt       string `json:"published_at"`
+	TranslationStatus string `json:"translation_status"`
 }
 
 type PageRecord struct {
@@ -97,14 +118,48 @@ type SettingsRecord struct {
 	TranslationLocales       string `json:"translation_locales"`
 	TranslationModel         string `json:"translation_model"`
 	TranslationRequestsPM    int    `json:"translation_requests_per_minute"`
+	TranslationApprovedOnly  bool   `json:"translation_approved_only"`
 	GeminiAPIKey             string `json:"gemini_api_key"`
+
+	// UIStrings overrides the shipped UI text per locale; see uiTextFor.
+	UIStrings json.RawMessage `json:"ui_strings"`
 }
 
-type MediaRecord struct {
+type AuthorRecord struct {
+	ID          string `json:"id"`
+	User        string `json:"user"`
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Avatar      string `json:"avatar"`
+	URL         string `json:"url"`
+}
+
+type SeriesRecord struct {
+	ID                string            `json:"id"`
+	Title             string            `json:"title"`
+	Slug              string            `json:"slug"`
+	Description       string            `json:"description"`
+	Posts             []string          `json:"posts"`
+	TitleTranslations map[string]string `json:"title_translations"`
+}
+
+type ThemeRecord struct {
 	ID      string `json:"id"`
-	File    string `json:"file"`
-	Caption string `json:"caption"`
-	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Updated string `json:"updated"`
+}
+
+type MediaRecord struct {
+	ID       string `json:"id"`
+	File     string `json:"file"`
+	Caption  string `json:"caption"`
+	Path     string `json:"path"`
+	Alt      string `json:"alt"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Checksum string `json:"checksum"`
 }
 
 type archiveListing struct {
@@ -122,12 +177,22 @@ type snapshotBuildContext struct {
 	postBySlug           map[string]PostRecord
 	postByID             map[string]PostRecord
 	pageByURL            map[string]PageRecord
+	pageTranslationByKey map[string]PageTranslationRecord
 	translationByKey     map[string]PostTranslationRecord
 	translationsBySource map[string][]PostTranslationRecord
 	translationsByLocale map[string][]PostTranslationRecord
 	postsByTag           map[string][]PostRecord
 	postsByCategory      map[string][]PostRecord
+	authors              []AuthorRecord
+	authorByUser         map[string]AuthorRecord
+	authorBySlug         map[string]AuthorRecord
+	postsByAuthor        map[string][]PostRecord
+	series               []SeriesRecord
+	seriesBySlug         map[string]SeriesRecord
+	seriesByPost         map[string]SeriesRecord
 	archiveIndex         map[string]archiveListing
+	searchIndex          *searchIndex
+	dagRefresh           dagSourceRefresh
 }
 
 type localizedPostResult struct {
@@ -148,4 +213,9 @@ type archiveRoute struct {
 	basePath   string
 	filter     string
 	title      string
+	authorSlug string
+	author     *AuthorRecord
+	// locale is set on a translation locale's archives, whose basePath
+	// carries the /<locale> prefix.
+	locale string
 }
@@ -43,13 +43,20 @@ func stripHTML(value string) string {
 }
 
 func formatDate(value string) string {
+	return formatDateLayout(value, "2006-01-02")
+}
+
+// formatDateLayout formats a record date with a Go time layout. Values that
+// don't parse are returned as they are.
+func formatDateLayout(value, layout string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
 		return ""
 	}
 
+	key := layout + "|" + trimmed
 	formatDateCache.mu.RLock()
-	cached, ok := formatDateCache.items[trimmed]
+	cached, ok := formatDateCache.items[key]
 	formatDateCache.mu.RUnlock()
 	if ok {
 		return cached
@@ -61,11 +68,11 @@ func formatDate(value string) string {
 	}
 	result := trimmed
 	if err != nil {
-		setFormatDateCache(trimmed, result)
+		setFormatDateCache(key, result)
 		return result
 	}
-	result = parsed.Format("2006-01-02")
-	setFormatDateCache(trimmed, result)
+	result = parsed.Format(layout)
+	setFormatDateCache(key, result)
 	return result
 }
 
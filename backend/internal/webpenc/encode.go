@@ -0,0 +1,271 @@
+// Package webpenc writes lossless WebP (VP8L) images without cgo or external
+// tools. It trades compression ratio for simplicity: subtract-green and a
+// per-tile predictor transform, LZ77 backward references and one set of
+// prefix codes for the whole image.
+package webpenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+const (
+	maxDimension        = 1 << 14
+	predictorSizeBits   = 5
+	transformPredictor  = 0
+	transformSubGreen   = 2
+	vp8lSignature       = 0x2f
+	vp8xFlagAlpha       = 0x10
+	vp8xFlagExif        = 0x08
+	predictorModeL      = 1
+	predictorModeT      = 2
+	predictorModeSelect = 11
+	predictorModeClamp  = 12
+)
+
+var errImageTooLarge = errors.New("webpenc: image dimensions exceed 16384 pixels")
+
+var predictorModes = []uint32{predictorModeL, predictorModeT, predictorModeSelect, predictorModeClamp}
+
+// Options controls the container around the VP8L bitstream.
+type Options struct {
+	// Exif is a raw TIFF-structured EXIF payload stored in an EXIF chunk.
+	Exif []byte
+}
+
+// Encode writes img to w as a lossless WebP file.
+func Encode(w io.Writer, img image.Image, o *Options) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return errors.New("webpenc: empty image")
+	}
+	if width > maxDimension || height > maxDimension {
+		return errImageTooLarge
+	}
+
+	argb, hasAlpha := imageARGB(img)
+	bitstream := encodeVP8L(argb, width, height, hasAlpha)
+
+	var exif []byte
+	if o != nil {
+		exif = o.Exif
+	}
+	var out bytes.Buffer
+	if len(exif) == 0 {
+		writeRIFF(&out, chunk{"VP8L", bitstream})
+	} else {
+		writeRIFF(&out, vp8xChunk(width, height, hasAlpha), chunk{"VP8L", bitstream}, chunk{"EXIF", exif})
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+type chunk struct {
+	fourCC string
+	data   []byte
+}
+
+func vp8xChunk(width, height int, hasAlpha bool) chunk {
+	data := make([]byte, 10)
+	data[0] = vp8xFlagExif
+	if hasAlpha {
+		data[0] |= vp8xFlagAlpha
+	}
+	putUint24(data[4:], uint32(width-1))
+	putUint24(data[7:], uint32(height-1))
+	return chunk{"VP8X", data}
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+func writeRIFF(out *bytes.Buffer, chunks ...chunk) {
+	size := 4
+	for _, c := range chunks {
+		size += 8 + len(c.data) + len(c.data)&1
+	}
+	out.WriteString("RIFF")
+	_ = binary.Write(out, binary.LittleEndian, uint32(size))
+	out.WriteString("WEBP")
+	for _, c := range chunks {
+		out.WriteString(c.fourCC)
+		_ = binary.Write(out, binary.LittleEndian, uint32(len(c.data)))
+		out.Write(c.data)
+		if len(c.data)&1 == 1 {
+			out.WriteByte(0)
+		}
+	}
+}
+
+func imageARGB(img image.Image) ([]uint32, bool) {
+	bounds := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	}
+	width, height := bounds.Dx(), bounds.Dy()
+	argb := make([]uint32, width*height)
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+width*4]
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]
+			if a != 0xff {
+				hasAlpha = true
+			}
+			argb[y*width+x] = uint32(a)<<24 | uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+		}
+	}
+	return argb, hasAlpha
+}
+
+func encodeVP8L(argb []uint32, width, height int, hasAlpha bool) []byte {
+	bw := &bitWriter{}
+	bw.writeBits(vp8lSignature, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3)
+
+	subtractGreen(argb)
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubGreen, 2)
+
+	residuals, modes, tilesX, tilesY := applyPredictor(argb, width, height)
+	bw.writeBits(1, 1)
+	bw.writeBits(transformPredictor, 2)
+	bw.writeBits(predictorSizeBits-2, 3)
+	writeImageData(bw, modes, tilesX, tilesY, false)
+
+	bw.writeBits(0, 1)
+	writeImageData(bw, residuals, width, height, true)
+	return bw.bytes()
+}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// applyPredictor picks the cheapest predictor for each tile and returns the
+// residual image together with the tile mode image.
+func applyPredictor(argb []uint32, width, height int) ([]uint32, []uint32, int, int) {
+	tileSize := 1 << predictorSizeBits
+	tilesX := (width + tileSize - 1) / tileSize
+	tilesY := (height + tileSize - 1) / tileSize
+	modes := make([]uint32, tilesX*tilesY)
+	residuals := make([]uint32, len(argb))
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, y0 := tx*tileSize, ty*tileSize
+			x1, y1 := min(x0+tileSize, width), min(y0+tileSize, height)
+			best, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						cost += residualCost(subPixels(argb[y*width+x], predict(argb, width, x, y, mode)))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesX+tx] = 0xff000000 | best<<8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					residuals[y*width+x] = subPixels(argb[y*width+x], predict(argb, width, x, y, best))
+				}
+			}
+		}
+	}
+	return residuals, modes, tilesX, tilesY
+}
+
+func predict(argb []uint32, width, x, y int, mode uint32) uint32 {
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return argb[y*width+x-1]
+	case x == 0:
+		return argb[(y-1)*width+x]
+	}
+	left := argb[y*width+x-1]
+	top := argb[(y-1)*width+x]
+	topLeft := argb[(y-1)*width+x-1]
+	switch mode {
+	case predictorModeL:
+		return left
+	case predictorModeT:
+		return top
+	case predictorModeSelect:
+		return selectPredictor(left, top, topLeft)
+	default:
+		return clampAddSubtractFull(left, top, topLeft)
+	}
+}
+
+func channel(p uint32, shift uint) int {
+	return int((p >> shift) & 0xff)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func selectPredictor(left, top, topLeft uint32) uint32 {
+	costL, costT := 0, 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		costL += absInt(channel(topLeft, shift) - channel(top, shift))
+		costT += absInt(channel(topLeft, shift) - channel(left, shift))
+	}
+	if costL < costT {
+		return left
+	}
+	return top
+}
+
+func clampAddSubtractFull(left, top, topLeft uint32) uint32 {
+	var out uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		v := channel(left, shift) + channel(top, shift) - channel(topLeft, shift)
+		out |= uint32(min(max(v, 0), 255)) << shift
+	}
+	return out
+}
+
+func subPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		out |= uint32((channel(a, shift)-channel(b, shift))&0xff) << shift
+	}
+	return out
+}
+
+func residualCost(p uint32) int {
+	cost := 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		cost += absInt(int(int8(channel(p, shift))))
+	}
+	return cost
+}
@@ -0,0 +1,103 @@
+package webpenc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := image.NewNRGBA(image.Rect(0, 0, 67, 45))
+	rng.Read(noise.Pix)
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 300, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 300; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y * 2), B: uint8(x + y), A: 255})
+		}
+	}
+
+	flat := image.NewRGBA(image.Rect(10, 10, 50, 30))
+	for i := range flat.Pix {
+		flat.Pix[i] = 0xff
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{name: "noise with alpha", img: noise},
+		{name: "gradient", img: gradient},
+		{name: "flat with offset bounds", img: flat},
+		{name: "single pixel", img: image.NewGray(image.Rect(0, 0, 1, 1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.img, nil); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			assertSamePixels(t, tt.img, decoded)
+		})
+	}
+}
+
+func TestEncodeWithExif(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	exif := []byte("II*\x00\x08\x00\x00\x00\x00\x00")
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, &Options{Exif: exif}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	data := buf.Bytes()
+	if string(data[12:16]) != "VP8X" || !bytes.Contains(data, append([]byte("EXIF"), 10, 0, 0, 0)) {
+		t.Fatalf("expected VP8X container with EXIF chunk")
+	}
+	config, err := webp.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode config: %v", err)
+	}
+	if config.Width != 8 || config.Height != 4 {
+		t.Fatalf("config = %dx%d, want 8x4", config.Width, config.Height)
+	}
+	if _, err := webp.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+}
+
+func TestEncodeRejectsOversizedImages(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, maxDimension+1, 1))
+	if err := Encode(&bytes.Buffer{}, img, nil); err == nil {
+		t.Fatalf("expected error for oversized image")
+	}
+}
+
+func assertSamePixels(t *testing.T, want, got image.Image) {
+	t.Helper()
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Dx() != gb.Dx() || wb.Dy() != gb.Dy() {
+		t.Fatalf("bounds = %v, want size of %v", gb, wb)
+	}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			if w.A == 0 && g.A == 0 {
+				continue
+			}
+			if w != g {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, g, w)
+			}
+		}
+	}
+}
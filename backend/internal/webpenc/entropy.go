@@ -0,0 +1,433 @@
+package webpenc
+
+import (
+	"math/bits"
+	"slices"
+)
+
+const (
+	numLiteralCodes   = 256
+	numLengthCodes    = 24
+	numDistanceCodes  = 40
+	numCodeLengthSyms = 19
+	maxCodeLength     = 15
+	maxCodeLengthCode = 7
+	minMatchLength    = 4
+	maxMatchLength    = 4096
+	maxMatchDistance  = 1<<20 - 120
+	hashBits          = 16
+	maxChainSteps     = 16
+	// Plain distances are offset past the 120 two-dimensional neighbourhood
+	// codes; codes 1 and 2 are the pixel above and the pixel to the left.
+	distanceCodeOffset = 120
+)
+
+var codeLengthCodeOrder = [numCodeLengthSyms]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// token is either a literal ARGB pixel or a backward reference.
+type token struct {
+	literal  uint32
+	length   int
+	distance int
+}
+
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c prefixCode) write(w *bitWriter, symbol int) {
+	if n := c.lengths[symbol]; n > 0 {
+		w.writeBits(c.codes[symbol], uint(n))
+	}
+}
+
+func writeImageData(w *bitWriter, argb []uint32, width, height int, topLevel bool) {
+	// No color cache.
+	w.writeBits(0, 1)
+	if topLevel {
+		// A single prefix code group for the whole image.
+		w.writeBits(0, 1)
+	}
+
+	tokens := backwardReferences(argb, width)
+	green := make([]uint32, numLiteralCodes+numLengthCodes)
+	red := make([]uint32, numLiteralCodes)
+	blue := make([]uint32, numLiteralCodes)
+	alpha := make([]uint32, numLiteralCodes)
+	dist := make([]uint32, numDistanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[(t.literal>>8)&0xff]++
+			red[(t.literal>>16)&0xff]++
+			blue[t.literal&0xff]++
+			alpha[t.literal>>24]++
+			continue
+		}
+		lengthCode, _, _ := prefixEncode(t.length)
+		green[numLiteralCodes+lengthCode]++
+		distCode, _, _ := prefixEncode(distanceCode(t.distance, width))
+		dist[distCode]++
+	}
+
+	greenCode := writePrefixCode(w, green)
+	redCode := writePrefixCode(w, red)
+	blueCode := writePrefixCode(w, blue)
+	alphaCode := writePrefixCode(w, alpha)
+	distCode := writePrefixCode(w, dist)
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			greenCode.write(w, int((t.literal>>8)&0xff))
+			redCode.write(w, int((t.literal>>16)&0xff))
+			blueCode.write(w, int(t.literal&0xff))
+			alphaCode.write(w, int(t.literal>>24))
+			continue
+		}
+		code, extraBits, extra := prefixEncode(t.length)
+		greenCode.write(w, numLiteralCodes+code)
+		w.writeBits(extra, extraBits)
+		code, extraBits, extra = prefixEncode(distanceCode(t.distance, width))
+		distCode.write(w, code)
+		w.writeBits(extra, extraBits)
+	}
+}
+
+func distanceCode(distance, width int) int {
+	switch distance {
+	case width:
+		return 1
+	case 1:
+		return 2
+	}
+	return distance + distanceCodeOffset
+}
+
+// prefixEncode splits a length or distance value into its prefix symbol and
+// extra bits, mirroring the VP8L prefix coding.
+func prefixEncode(value int) (int, uint, uint32) {
+	v := value - 1
+	if v < 4 {
+		return v, 0, 0
+	}
+	high := bits.Len(uint(v)) - 1
+	second := (v >> (high - 1)) & 1
+	extraBits := uint(high - 1)
+	return 2*high + second, extraBits, uint32(v & (1<<extraBits - 1))
+}
+
+// backwardReferences greedily replaces repeated pixel runs with LZ77
+// references found through a hash chain over pixel pairs.
+func backwardReferences(argb []uint32, width int) []token {
+	tokens := make([]token, 0, len(argb)/2)
+	head := make([]int32, 1<<hashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, len(argb))
+	insert := func(i int) {
+		if i+1 >= len(argb) {
+			return
+		}
+		h := pairHash(argb[i], argb[i+1])
+		prev[i] = head[h]
+		head[h] = int32(i)
+	}
+
+	for i := 0; i < len(argb); {
+		bestLength, bestDistance := 0, 0
+		if i+minMatchLength <= len(argb) {
+			limit := min(maxMatchLength, len(argb)-i)
+			candidate := int(head[pairHash(argb[i], argb[i+1])])
+			if i >= width && width > 0 {
+				// The pixel above is the cheapest reference to encode.
+				if n := matchLength(argb, i-width, i, limit); n >= minMatchLength {
+					bestLength, bestDistance = n, width
+				}
+			}
+			for steps := 0; candidate >= 0 && steps < maxChainSteps; steps++ {
+				distance := i - candidate
+				if distance > maxMatchDistance {
+					break
+				}
+				if n := matchLength(argb, candidate, i, limit); n > bestLength {
+					bestLength, bestDistance = n, distance
+					if n == limit {
+						break
+					}
+				}
+				candidate = int(prev[candidate])
+			}
+		}
+
+		if bestLength >= minMatchLength {
+			tokens = append(tokens, token{length: bestLength, distance: bestDistance})
+			for j := i; j < i+bestLength; j++ {
+				insert(j)
+			}
+			i += bestLength
+			continue
+		}
+		tokens = append(tokens, token{literal: argb[i]})
+		insert(i)
+		i++
+	}
+	return tokens
+}
+
+func pairHash(a, b uint32) uint32 {
+	return (a*0x9e3779b1 ^ b*0x85ebca6b) >> (32 - hashBits)
+}
+
+func matchLength(argb []uint32, from, to, limit int) int {
+	n := 0
+	for n < limit && argb[from+n] == argb[to+n] {
+		n++
+	}
+	return n
+}
+
+// writePrefixCode writes the code for a histogram and returns it for
+// encoding symbols. One or two small symbols use the compact simple code.
+func writePrefixCode(w *bitWriter, counts []uint32) prefixCode {
+	used := make([]int, 0, 2)
+	for symbol, count := range counts {
+		if count > 0 {
+			used = append(used, symbol)
+			if len(used) > 2 {
+				break
+			}
+		}
+	}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < numLiteralCodes) {
+		if len(used) == 0 {
+			used = append(used, 0)
+		}
+		code := prefixCode{lengths: make([]uint8, len(counts)), codes: make([]uint32, len(counts))}
+		w.writeBits(1, 1)
+		w.writeBits(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			w.writeBits(0, 1)
+			w.writeBits(uint32(used[0]), 1)
+		} else {
+			w.writeBits(1, 1)
+			w.writeBits(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			w.writeBits(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+	w.writeBits(0, 1)
+	writeCodeLengths(w, lengths)
+	return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+type codeLengthToken struct {
+	symbol    int
+	extra     uint32
+	extraBits uint
+}
+
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+	tokens := codeLengthTokens(lengths)
+	counts := make([]uint32, numCodeLengthSyms)
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+	clLengths := huffmanLengths(counts, maxCodeLengthCode)
+	clCodes := canonicalCodes(clLengths)
+
+	numCodes := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if clLengths[symbol] > 0 {
+			numCodes = max(numCodes, i+1)
+		}
+	}
+	w.writeBits(uint32(numCodes-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:numCodes] {
+		w.writeBits(uint32(clLengths[symbol]), 3)
+	}
+	// Code lengths cover the whole alphabet.
+	w.writeBits(0, 1)
+	for _, t := range tokens {
+		w.writeBits(clCodes[t.symbol], uint(clLengths[t.symbol]))
+		if t.extraBits > 0 {
+			w.writeBits(t.extra, t.extraBits)
+		}
+	}
+}
+
+func codeLengthTokens(lengths []uint8) []codeLengthToken {
+	tokens := []codeLengthToken{}
+	for i := 0; i < len(lengths); {
+		value := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == value {
+			run++
+		}
+		i += run
+		if value == 0 {
+			for run > 0 {
+				switch {
+				case run >= 11:
+					n := min(run, 138)
+					tokens = append(tokens, codeLengthToken{symbol: 18, extra: uint32(n - 11), extraBits: 7})
+					run -= n
+				case run >= 3:
+					tokens = append(tokens, codeLengthToken{symbol: 17, extra: uint32(run - 3), extraBits: 3})
+					run = 0
+				default:
+					tokens = append(tokens, codeLengthToken{symbol: 0})
+					run--
+				}
+			}
+			continue
+		}
+		tokens = append(tokens, codeLengthToken{symbol: int(value)})
+		run--
+		for run > 0 {
+			if run < 3 {
+				tokens = append(tokens, codeLengthToken{symbol: int(value)})
+				run--
+				continue
+			}
+			n := min(run, 6)
+			tokens = append(tokens, codeLengthToken{symbol: 16, extra: uint32(n - 3), extraBits: 2})
+			run -= n
+		}
+	}
+	return tokens
+}
+
+// huffmanLengths builds code lengths no longer than maxLength. Rare symbols
+// are flattened until the tree fits. At least two symbols always get a code
+// so every normal code is complete.
+func huffmanLengths(counts []uint32, maxLength int) []uint8 {
+	type node struct {
+		weight uint64
+		symbol int
+		left   int
+		right  int
+	}
+
+	symbols := []int{}
+	for symbol, count := range counts {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	for filler := 0; len(symbols) < 2; filler++ {
+		if !slices.Contains(symbols, filler) {
+			symbols = append(symbols, filler)
+		}
+	}
+
+	lengths := make([]uint8, len(counts))
+	for floor := uint64(1); ; floor *= 2 {
+		leaves := make([]node, len(symbols))
+		for i, symbol := range symbols {
+			leaves[i] = node{weight: max(uint64(counts[symbol]), floor), symbol: symbol, left: -1, right: -1}
+		}
+		slices.SortStableFunc(leaves, func(a, b node) int {
+			switch {
+			case a.weight < b.weight:
+				return -1
+			case a.weight > b.weight:
+				return 1
+			}
+			return a.symbol - b.symbol
+		})
+
+		nodes := leaves
+		nextLeaf, nextInternal := 0, len(leaves)
+		pick := func() int {
+			if nextLeaf < len(leaves) && (nextInternal >= len(nodes) || nodes[nextLeaf].weight <= nodes[nextInternal].weight) {
+				nextLeaf++
+				return nextLeaf - 1
+			}
+			nextInternal++
+			return nextInternal - 1
+		}
+		for len(nodes) < 2*len(leaves)-1 {
+			a := pick()
+			b := pick()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, symbol: -1, left: a, right: b})
+		}
+
+		depth := make([]int, len(nodes))
+		tooDeep := false
+		for i := len(nodes) - 1; i >= 0; i-- {
+			n := nodes[i]
+			if n.left < 0 {
+				if depth[i] > maxLength {
+					tooDeep = true
+				}
+				continue
+			}
+			depth[n.left] = depth[i] + 1
+			depth[n.right] = depth[i] + 1
+		}
+		if tooDeep {
+			continue
+		}
+		for i := range leaves {
+			lengths[nodes[i].symbol] = uint8(depth[i])
+		}
+		return lengths
+	}
+}
+
+// canonicalCodes assigns canonical prefix codes and bit-reverses them, since
+// the bit writer emits least significant bits first.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var histogram [maxCodeLength + 1]uint32
+	for _, n := range lengths {
+		histogram[n]++
+	}
+	histogram[0] = 0
+	var next [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for n := 1; n <= maxCodeLength; n++ {
+		code = (code + histogram[n-1]) << 1
+		next[n] = code
+	}
+	codes := make([]uint32, len(lengths))
+	for symbol, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		codes[symbol] = bits.Reverse32(next[n]) >> (32 - uint(n))
+		next[n]++
+	}
+	return codes
+}
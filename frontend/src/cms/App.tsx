@@ -1,12 +1,16 @@
 import { Route, RouterProvider, createBrowserRouter, createRoutesFromElements } from "react-router-dom";
 import AdminLogin from "@cms/features/auth/AdminLogin";
+import AdminAuthors from "@cms/features/authors/AdminAuthors";
 import RequireAdmin from "@cms/features/auth/RequireAdmin";
 import AdminLayout from "@cms/features/layout/AdminLayout";
 import AdminPageEditor from "@cms/features/pages/AdminPageEditor";
 import AdminPages from "@cms/features/pages/AdminPages";
 import AdminPostEditor from "@cms/features/posts/AdminPostEditor";
 import AdminPosts from "@cms/features/posts/AdminPosts";
+import AdminRevalidation from "@cms/features/revalidation/AdminRevalidation";
+import AdminSeries from "@cms/features/series/AdminSeries";
 import AdminSettings from "@cms/features/settings/AdminSettings";
+import AdminThemes from "@cms/features/themes/AdminThemes";
 
 export default function CmsApp() {
   const base = import.meta.env.VITE_BASE || "/";
@@ -26,7 +30,11 @@ export default function CmsApp() {
           <Route path="/posts/:id" element={<AdminPostEditor />} />
           <Route path="/pages" element={<AdminPages />} />
           <Route path="/pages/:id" element={<AdminPageEditor />} />
+          <Route path="/authors" element={<AdminAuthors />} />
+          <Route path="/series" element={<AdminSeries />} />
+          <Route path="/themes" element={<AdminThemes />} />
           <Route path="/settings" element={<AdminSettings />} />
+          <Route path="/revalidation" element={<AdminRevalidation />} />
         </Route>
       </>
     ),
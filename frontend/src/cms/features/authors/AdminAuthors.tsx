@@ -0,0 +1,233 @@
+import { useEffect, useState } from "react";
+import { AuthorRecord, pb } from "@cms/lib/pb";
+import { AdminButton, AdminSelectField, AdminTable, AdminTextAreaField, AdminTextField } from "@cms/ui/AriaControls";
+import FormStatusMessage from "@cms/ui/FormStatusMessage";
+import useAdminPageTitle from "@cms/useAdminPageTitle";
+
+type UserOption = { id: string; name?: string; email?: string };
+type MediaOption = { id: string; path?: string; caption?: string; file?: string };
+
+const emptyAuthor: Omit<AuthorRecord, "id"> = {
+  user: "",
+  slug: "",
+  display_name: "",
+  bio: "",
+  avatar: "",
+  url: "",
+};
+
+const slugify = (value: string) =>
+  value
+    .toLowerCase()
+    .trim()
+    .replace(/[^a-z0-9]+/g, "-")
+    .replace(/^-+|-+$/g, "");
+
+export default function AdminAuthors() {
+  const [authors, setAuthors] = useState<AuthorRecord[]>([]);
+  const [users, setUsers] = useState<UserOption[]>([]);
+  const [media, setMedia] = useState<MediaOption[]>([]);
+  const [editingId, setEditingId] = useState<string | null>(null);
+  const [form, setForm] = useState(emptyAuthor);
+  const [loading, setLoading] = useState(false);
+  const [saving, setSaving] = useState(false);
+  const [reloadToken, setReloadToken] = useState(0);
+  const [error, setError] = useState("");
+  const [success, setSuccess] = useState("");
+
+  useAdminPageTitle("Authors");
+
+  useEffect(() => {
+    let alive = true;
+    const load = async () => {
+      setLoading(true);
+      setError("");
+      try {
+        const [authorItems, userItems, mediaItems] = await Promise.all([
+          pb.collection("authors").getFullList<AuthorRecord>({ sort: "display_name" }),
+          pb.collection("cms_users").getFullList<UserOption>({ sort: "name" }),
+          pb.collection("media").getFullList<MediaOption>({ sort: "-created" }),
+        ]);
+        if (!alive) return;
+        setAuthors(authorItems);
+        setUsers(userItems);
+        setMedia(mediaItems);
+      } catch {
+        if (!alive) return;
+        setAuthors([]);
+        setError("Authors could not be loaded. Refresh and try again.");
+      } finally {
+        if (alive) setLoading(false);
+      }
+    };
+    load();
+    return () => {
+      alive = false;
+    };
+  }, [reloadToken]);
+
+  const edit = (item: AuthorRecord | null) => {
+    setSuccess("");
+    setError("");
+    setEditingId(item ? item.id : "new");
+    setForm(item ? { ...emptyAuthor, ...item } : emptyAuthor);
+  };
+
+  const save = async () => {
+    setSaving(true);
+    setError("");
+    setSuccess("");
+    const payload = {
+      ...form,
+      slug: slugify(form.slug || form.display_name),
+      display_name: form.display_name.trim(),
+      bio: (form.bio || "").trim(),
+      url: (form.url || "").trim(),
+    };
+    try {
+      if (editingId && editingId !== "new") {
+        await pb.collection("authors").update(editingId, payload);
+      } else {
+        await pb.collection("authors").create(payload);
+      }
+      setEditingId(null);
+      setSuccess("Author saved. Their archive and bylines will be regenerated.");
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("The author could not be saved. Check that the slug and user are not already taken.");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const remove = async (id: string) => {
+    setError("");
+    try {
+      await pb.collection("authors").delete(id);
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("This author could not be deleted. Only admins can delete authors.");
+    }
+  };
+
+  const userLabel = (id: string) => {
+    const user = users.find((item) => item.id === id);
+    return user ? user.name || user.email || user.id : id;
+  };
+
+  return (
+    <section>
+      <header className="admin-header">
+        <div>
+          <p className="admin-eyebrow">Site</p>
+          <h1>Authors</h1>
+        </div>
+        <AdminButton className="admin-primary" onPress={() => edit(null)}>
+          New author
+        </AdminButton>
+      </header>
+      <FormStatusMessage error={error} success={success} />
+      {loading ? <p className="admin-note">Loading authors…</p> : null}
+      {editingId ? (
+        <div className="admin-settings-subsection">
+          <AdminSelectField
+            label="CMS user"
+            value={form.user}
+            onChange={(value) => setForm((prev) => ({ ...prev, user: value }))}
+            placeholder="Select a user"
+            options={users.map((user) => ({ value: user.id, label: user.name || user.email || user.id }))}
+          />
+          <AdminTextField
+            label="Display name"
+            value={form.display_name}
+            onChange={(value) => setForm((prev) => ({ ...prev, display_name: value }))}
+            required
+          />
+          <AdminTextField
+            label="Slug"
+            value={form.slug}
+            onChange={(value) => setForm((prev) => ({ ...prev, slug: value }))}
+            placeholder={slugify(form.display_name)}
+          />
+          <AdminTextAreaField
+            label="Bio"
+            value={form.bio || ""}
+            onChange={(value) => setForm((prev) => ({ ...prev, bio: value }))}
+            rows={4}
+          />
+          <AdminSelectField
+            label="Avatar"
+            value={form.avatar || ""}
+            onChange={(value) => setForm((prev) => ({ ...prev, avatar: value }))}
+            options={[
+              { value: "", label: "No avatar" },
+              ...media.map((item) => ({ value: item.id, label: item.path || item.caption || item.file || item.id })),
+            ]}
+          />
+          <AdminTextField
+            label="Website"
+            type="url"
+            value={form.url || ""}
+            onChange={(value) => setForm((prev) => ({ ...prev, url: value }))}
+          />
+          <div className="admin-actions">
+            <AdminButton className="admin-primary" disabled={saving || !form.user || !form.display_name.trim()} onPress={() => void save()}>
+              {saving ? "Saving…" : "Save author"}
+            </AdminButton>
+            <AdminButton className="admin-secondary" onPress={() => setEditingId(null)}>
+              Cancel
+            </AdminButton>
+          </div>
+        </div>
+      ) : null}
+      <div className="admin-list-shell">
+        <AdminTable
+          ariaLabel="Authors"
+          items={authors}
+          columns={[
+            {
+              id: "name",
+              name: "Name",
+              mobileLabel: "Name",
+              isRowHeader: true,
+              render: (item) => item.display_name,
+            },
+            {
+              id: "slug",
+              name: "Archive",
+              mobileLabel: "Archive",
+              render: (item) => `/authors/${item.slug}/`,
+            },
+            {
+              id: "user",
+              name: "CMS user",
+              mobileLabel: "CMS user",
+              render: (item) => userLabel(item.user),
+            },
+            {
+              id: "actions",
+              name: "Action",
+              mobileLabel: "Action",
+              width: "160px",
+              render: (item) => (
+                <div className="admin-actions">
+                  <AdminButton className="admin-secondary" onPress={() => edit(item)}>
+                    Edit
+                  </AdminButton>
+                  <AdminButton ariaLabel={`Delete ${item.display_name}`} className="admin-danger-button" onPress={() => void remove(item.id)}>
+                    🗑
+                  </AdminButton>
+                </div>
+              ),
+            },
+          ]}
+        />
+      </div>
+      {!loading && !error && authors.length === 0 ? (
+        <div className="admin-empty-state">
+          <p>No author profiles yet. Posts render without a byline until their author has one.</p>
+        </div>
+      ) : null}
+    </section>
+  );
+}
@@ -2,15 +2,19 @@ import { AdminCheckboxField, AdminTextField } from "@cms/ui/AriaControls";
 
 type PublishFieldsProps = {
   publishedAt: string;
+  unpublishAt: string;
   published: boolean;
   onPublishedAtChange: (value: string) => void;
+  onUnpublishAtChange: (value: string) => void;
   onPublishedChange: (checked: boolean) => void;
 };
 
 export default function PublishFields({
   publishedAt,
+  unpublishAt,
   published,
   onPublishedAtChange,
+  onUnpublishAtChange,
   onPublishedChange,
 }: PublishFieldsProps) {
   return (
@@ -21,6 +25,12 @@ export default function PublishFields({
         value={publishedAt}
         onChange={onPublishedAtChange}
       />
+      <AdminTextField
+        label="Unpublish at"
+        type="datetime-local"
+        value={unpublishAt}
+        onChange={onUnpublishAtChange}
+      />
       <AdminCheckboxField label="Published" checked={published} onChange={onPublishedChange} />
     </>
   );
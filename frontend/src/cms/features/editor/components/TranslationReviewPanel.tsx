@@ -0,0 +1,73 @@
+import { useEffect, useState } from "react";
+import { AdminSelectField } from "@cms/ui/AriaControls";
+import {
+  fetchTranslationDiff,
+  translationStatusOptions,
+  type TranslationDiff,
+  type TranslationStatus,
+} from "@cms/features/editor/translationReview";
+
+type TranslationReviewPanelProps = {
+  translationId: string;
+  status: TranslationStatus;
+  refreshKey?: string | number | null;
+  onStatusChange: (status: TranslationStatus) => void;
+};
+
+const stripTags = (value: string) => value.replace(/<[^>]*>/g, " ").replace(/\s+/g, " ").trim();
+
+export default function TranslationReviewPanel({ translationId, status, refreshKey, onStatusChange }: TranslationReviewPanelProps) {
+  const [diff, setDiff] = useState<TranslationDiff | null>(null);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (!translationId) {
+      setDiff(null);
+      return;
+    }
+    let active = true;
+    setError("");
+    fetchTranslationDiff(translationId)
+      .then((data) => {
+        if (active) setDiff(data);
+      })
+      .catch((err: Error) => {
+        if (active) setError(err.message);
+      });
+    return () => {
+      active = false;
+    };
+  }, [translationId, refreshKey]);
+
+  const pending = (diff?.segments || []).filter((segment) => segment.status !== "current");
+  if (diff && diff.title.status !== "current") {
+    pending.unshift(diff.title);
+  }
+
+  return (
+    <>
+      <AdminSelectField
+        label="Review status"
+        value={status}
+        onChange={(value) => onStatusChange(value as TranslationStatus)}
+        options={translationStatusOptions}
+      />
+      {error && <p className="admin-note">{error}</p>}
+      {diff && pending.length === 0 && <p className="admin-note">Every segment matches the current source.</p>}
+      {pending.length > 0 && (
+        <ul className="admin-translation-diff">
+          {pending.map((segment, idx) => (
+            <li key={`${segment.hash || "removed"}-${idx}`} className={`admin-translation-diff-${segment.status}`}>
+              <p className="admin-section-label">
+                {segment.status}
+                {segment.human ? " · edited" : ""}
+              </p>
+              {segment.source && <p className="admin-note">Source: {stripTags(segment.source)}</p>}
+              {segment.translation && <p className="admin-note">Translation: {stripTags(segment.translation)}</p>}
+            </li>
+          ))}
+        </ul>
+      )}
+    </>
+  );
+}
@@ -1,11 +1,13 @@
 type UsePublishStateParams = {
   setPublishedAt: (value: string) => void;
+  setUnpublishAt: (value: string) => void;
   setPublished: (value: boolean) => void;
   markDirty: () => void;
 };
 
 export default function usePublishState({
   setPublishedAt,
+  setUnpublishAt,
   setPublished,
   markDirty,
 }: UsePublishStateParams) {
@@ -14,6 +16,11 @@ export default function usePublishState({
     markDirty();
   };
 
+  const onUnpublishAtChange = (value: string) => {
+    setUnpublishAt(value);
+    markDirty();
+  };
+
   const onPublishedChange = (checked: boolean) => {
     setPublished(checked);
     markDirty();
@@ -21,6 +28,7 @@ export default function usePublishState({
 
   return {
     onPublishedAtChange,
+    onUnpublishAtChange,
     onPublishedChange,
   };
 }
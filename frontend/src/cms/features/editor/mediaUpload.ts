@@ -39,6 +39,8 @@ type MediaRecord = {
   path?: string;
   caption?: string;
   checksum?: string;
+  source_checksum?: string;
+  duplicate?: boolean;
 };
 
 const toRelativeMediaURL = (value: string) => {
@@ -73,10 +75,11 @@ const resolveMediaURL = (record: MediaRecord) => {
 };
 
 const findMediaByChecksum = async (checksum: string): Promise<MediaRecord | null> => {
+  const value = escapeFilterString(checksum);
   try {
     return await pb
       .collection("media")
-      .getFirstListItem<MediaRecord>(`checksum = "${escapeFilterString(checksum)}"`);
+      .getFirstListItem<MediaRecord>(`checksum = "${value}" || source_checksum = "${value}"`);
   } catch (error) {
     if (error instanceof ClientResponseError && error.status === 404) {
       return null;
@@ -85,6 +88,9 @@ const findMediaByChecksum = async (checksum: string): Promise<MediaRecord | null
   }
 };
 
+// The server also dedupes by checksum (after optimization) and answers a
+// duplicate upload with the existing record, so the lookup here only saves
+// the upload round trip.
 export const uploadImageAndGetURL = async (file: File): Promise<string> => {
   const checksum = await hashFileSHA256(file);
   const existing = await findMediaByChecksum(checksum);
@@ -94,38 +100,31 @@ export const uploadImageAndGetURL = async (file: File): Promise<string> => {
   form.set("file", file);
   form.set("public", "true");
   form.set("alt", file.name);
-  form.set("checksum", checksum);
 
-  let record: MediaRecord;
-  try {
-    record = await pb.collection("media").create<MediaRecord>(form);
-  } catch (error) {
-    if (
-      error instanceof ClientResponseError &&
-      error.status === 400 &&
-      error.response?.data &&
-      typeof error.response.data === "object" &&
-      "checksum" in error.response.data
-    ) {
-      const alreadyExists = await findMediaByChecksum(checksum);
-      if (alreadyExists) return resolveMediaURL(alreadyExists);
-    }
-    throw error;
-  }
+  const record = await pb.collection("media").create<MediaRecord>(form);
+  return resolveMediaURL(record);
+};
 
-  const filename = String(record.file || "");
-  const mediaPath = typeof record.path === "string" ? record.path.trim() : "";
-  if (!mediaPath && filename) {
-    const nextPath = buildUploadPath(filename, checksum);
-    try {
-      await pb.collection("media").update(record.id, { path: nextPath });
-      record.path = nextPath;
-    } catch {
-      // Ignore path update failures, fallback URL still works.
-    }
-  }
+export type MediaUsage = {
+  collection: string;
+  id: string;
+  field: string;
+  title: string;
+  slug?: string;
+};
 
-  return resolveMediaURL(record);
+export const fetchMediaUsage = async (mediaId: string) => {
+  const headers: Record<string, string> = {};
+  const token = pb.authStore.token;
+  if (token) {
+    headers.Authorization = `Bearer ${token}`;
+  }
+  const response = await fetch(`${pb.baseUrl}/api/media/${encodeURIComponent(mediaId)}/usage`, { headers });
+  if (!response.ok) {
+    throw new Error("Failed to load media usage.");
+  }
+  const data = (await response.json()) as { usage?: MediaUsage[] };
+  return data.usage ?? [];
 };
 
 export const __test__ = {
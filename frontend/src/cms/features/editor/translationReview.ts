@@ -0,0 +1,40 @@
+import { pb } from "@cms/lib/pb";
+
+export type TranslationStatus = "machine" | "in_review" | "approved" | "stale";
+
+export const translationStatusOptions: Array<{ value: TranslationStatus; label: string }> = [
+  { value: "machine", label: "Machine-translated" },
+  { value: "in_review", label: "In review" },
+  { value: "approved", label: "Approved" },
+  { value: "stale", label: "Stale (source changed)" },
+];
+
+export type TranslationSegmentDiff = {
+  index: number;
+  hash?: string;
+  status: "current" | "stale" | "changed" | "removed";
+  source: string;
+  translation: string;
+  human: boolean;
+};
+
+export type TranslationDiff = {
+  id: string;
+  locale: string;
+  status: TranslationStatus;
+  title: TranslationSegmentDiff;
+  segments: TranslationSegmentDiff[];
+};
+
+export const fetchTranslationDiff = async (translationId: string) => {
+  const headers: Record<string, string> = {};
+  const token = pb.authStore.token;
+  if (token) {
+    headers.Authorization = `Bearer ${token}`;
+  }
+  const response = await fetch(`${pb.baseUrl}/api/translations/${encodeURIComponent(translationId)}/diff`, { headers });
+  if (!response.ok) {
+    throw new Error("Failed to load translation diff.");
+  }
+  return (await response.json()) as TranslationDiff;
+};
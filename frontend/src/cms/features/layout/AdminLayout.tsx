@@ -31,9 +31,21 @@ export default function AdminLayout() {
         <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/pages" onClick={closeSidebar}>
           Pages
         </NavLink>
+        <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/authors" onClick={closeSidebar}>
+          Authors
+        </NavLink>
+        <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/series" onClick={closeSidebar}>
+          Series
+        </NavLink>
+        <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/themes" onClick={closeSidebar}>
+          Themes
+        </NavLink>
         <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/settings" onClick={closeSidebar}>
           Settings
         </NavLink>
+        <NavLink className={({ isActive }) => (isActive ? "is-current" : undefined)} to="/revalidation" onClick={closeSidebar}>
+          Revalidation
+        </NavLink>
         <AdminButton className="admin-ghost" onPress={() => { closeSidebar(); logout(); }}>
           Sign Out
         </AdminButton>
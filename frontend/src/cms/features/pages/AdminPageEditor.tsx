@@ -40,6 +40,7 @@ export default function AdminPageEditor() {
   const [editorMode, setEditorMode] = useState<EditorMode>("rich");
   const [markdownViewMode, setMarkdownViewMode] = useState<MarkdownViewMode>("write");
   const [publishedAt, setPublishedAt] = useState("");
+  const [unpublishAt, setUnpublishAt] = useState("");
   const [published, setPublished] = useState(true);
   const [error, setError] = useState("");
   const [saving, setSaving] = useState(false);
@@ -73,8 +74,9 @@ export default function AdminPageEditor() {
     setFieldError,
   });
 
-  const { onPublishedAtChange, onPublishedChange } = usePublishState({
+  const { onPublishedAtChange, onUnpublishAtChange, onPublishedChange } = usePublishState({
     setPublishedAt,
+    setUnpublishAt,
     setPublished,
     markDirty,
   });
@@ -121,6 +123,7 @@ export default function AdminPageEditor() {
         setEditorMode(markdownMode ? "markdown" : "rich");
         setMarkdownViewMode("write");
         setPublishedAt(formatDateTimeLocalInput(record.published_at));
+        setUnpublishAt(formatDateTimeLocalInput(record.unpublish_at));
         setPublished(Boolean(record.published));
         setSlugEditedManually(true);
         setFieldErrors({});
@@ -193,6 +196,7 @@ export default function AdminPageEditor() {
       menuTitle,
       body: normalizedBody,
       published_at: publishedAt ? localInputToISOString(publishedAt) : new Date().toISOString(),
+      unpublish_at: localInputToISOString(unpublishAt),
       published,
     };
 
@@ -356,8 +360,10 @@ export default function AdminPageEditor() {
             <p className="admin-section-label">Publishing</p>
             <PublishFields
               publishedAt={publishedAt}
+              unpublishAt={unpublishAt}
               published={published}
               onPublishedAtChange={onPublishedAtChange}
+              onUnpublishAtChange={onUnpublishAtChange}
               onPublishedChange={onPublishedChange}
             />
           </div>
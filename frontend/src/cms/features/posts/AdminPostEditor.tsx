@@ -21,6 +21,8 @@ import FormStatusMessage from "@cms/ui/FormStatusMessage";
 import PublishFields from "@cms/features/editor/components/PublishFields";
 import TitleSlugFields from "@cms/features/editor/components/TitleSlugFields";
 import TranslationStatusModal from "@cms/features/editor/components/TranslationStatusModal";
+import TranslationReviewPanel from "@cms/features/editor/components/TranslationReviewPanel";
+import type { TranslationStatus } from "@cms/features/editor/translationReview";
 import useAdminPageTitle from "@cms/useAdminPageTitle";
 import useUnsavedChangesGuard from "@cms/features/editor/hooks/useUnsavedChangesGuard";
 import useEditorFormState from "@cms/features/editor/hooks/useEditorFormState";
@@ -40,6 +42,7 @@ type EditorPostRecord = {
   category?: string;
   author?: string;
   published_at?: string;
+  unpublish_at?: string;
   published?: boolean;
 };
 
@@ -48,6 +51,7 @@ type EditorPostTranslationRecord = EditorPostRecord & {
   locale?: string;
   source_post?: string;
   translation_done?: boolean;
+  translation_status?: TranslationStatus;
 };
 
 type FieldErrors = {
@@ -103,7 +107,9 @@ export default function AdminPostEditor() {
   const [category, setCategory] = useState("");
   const [author, setAuthor] = useState("");
   const [publishedAt, setPublishedAt] = useState("");
+  const [unpublishAt, setUnpublishAt] = useState("");
   const [published, setPublished] = useState(true);
+  const [translationStatus, setTranslationStatus] = useState<TranslationStatus>("machine");
   const [featuredImage, setFeaturedImage] = useState<File | null>(null);
   const [attachments, setAttachments] = useState<File[]>([]);
   const [authors, setAuthors] = useState<Array<{ id: string; name?: string; email?: string }>>([]);
@@ -187,8 +193,9 @@ export default function AdminPostEditor() {
     setFieldError,
   });
 
-  const { onPublishedAtChange, onPublishedChange } = usePublishState({
+  const { onPublishedAtChange, onUnpublishAtChange, onPublishedChange } = usePublishState({
     setPublishedAt,
+    setUnpublishAt,
     setPublished,
     markDirty,
   });
@@ -223,7 +230,9 @@ export default function AdminPostEditor() {
     setCategory(record.category || "");
     setAuthor(record.author || "");
     setPublishedAt(formatDateTimeLocalInput(record.published_at));
+    setUnpublishAt(formatDateTimeLocalInput(record.unpublish_at));
     setPublished(Boolean(record.published));
+    setTranslationStatus((record as EditorPostTranslationRecord).translation_status || "machine");
     setFeaturedImage(null);
     setAttachments([]);
     setFieldErrors({});
@@ -244,6 +253,7 @@ export default function AdminPostEditor() {
       category: source.category,
       author: source.author,
       published_at: source.published_at,
+      unpublish_at: source.unpublish_at,
       published: source.published,
     });
   };
@@ -285,6 +295,7 @@ export default function AdminPostEditor() {
         setCategory("");
         setAuthor("");
         setPublishedAt("");
+        setUnpublishAt("");
         setPublished(true);
         setFeaturedImage(null);
         setAttachments([]);
@@ -660,6 +671,7 @@ export default function AdminPostEditor() {
     if (category.trim() !== "") form.set("category", category.trim());
     if (author.trim() !== "") form.set("author", author.trim());
     form.set("published_at", publishedAt ? localInputToISOString(publishedAt) : new Date().toISOString());
+    form.set("unpublish_at", localInputToISOString(unpublishAt));
     form.set("published", String(published));
     if (featuredImage) {
       form.set("featured_image", featuredImage);
@@ -691,6 +703,7 @@ export default function AdminPostEditor() {
         form.set("source_post", sourcePostId);
         form.set("locale", selectedLocale);
         form.set("translation_done", "true");
+        form.set("translation_status", translationStatus);
         const currentTranslation = localeRecords[selectedLocale];
         const saved = currentTranslation
           ? ((await pb.collection("post_translations").update(currentTranslation.id, form)) as unknown as EditorPostTranslationRecord)
@@ -873,11 +886,28 @@ export default function AdminPostEditor() {
             <p className="admin-note">Release timing and visibility.</p>
             <PublishFields
               publishedAt={publishedAt}
+              unpublishAt={unpublishAt}
               published={published}
               onPublishedAtChange={onPublishedAtChange}
+              onUnpublishAtChange={onUnpublishAtChange}
               onPublishedChange={onPublishedChange}
             />
           </div>
+          {selectedLocale !== sourceLocale && (
+            <div className="admin-form admin-form-section admin-rail-section admin-rail-panel">
+              <p className="admin-section-label">Review</p>
+              <p className="admin-note">Segments that differ from the current source.</p>
+              <TranslationReviewPanel
+                translationId={localeRecords[selectedLocale]?.id || ""}
+                status={translationStatus}
+                refreshKey={lastSavedAt ? String(lastSavedAt) : null}
+                onStatusChange={(value) => {
+                  setTranslationStatus(value);
+                  markDirty();
+                }}
+              />
+            </div>
+          )}
           <div className="admin-form admin-form-section admin-rail-section admin-rail-panel">
             <p className="admin-section-label">Metadata</p>
             <p className="admin-note">Category, author, and tags.</p>
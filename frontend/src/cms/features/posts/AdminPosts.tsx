@@ -9,6 +9,7 @@ import {
   AdminTable,
   AdminTextField,
 } from "@cms/ui/AriaControls";
+import { fetchMediaUsage } from "@cms/features/editor/mediaUpload";
 import FormStatusMessage from "@cms/ui/FormStatusMessage";
 import useAdminPageTitle from "@cms/useAdminPageTitle";
 
@@ -36,6 +37,7 @@ export default function AdminPosts() {
   const [deleteTargetId, setDeleteTargetId] = useState<string | null>(null);
   const [deleteLoading, setDeleteLoading] = useState(false);
   const [error, setError] = useState("");
+  const [cleanupError, setCleanupError] = useState("");
   const [searchParams, setSearchParams] = useSearchParams();
 
   useAdminPageTitle("Posts");
@@ -173,6 +175,7 @@ export default function AdminPosts() {
   const remove = async (id: string) => {
     setDeleteLoading(true);
     setError("");
+    setCleanupError("");
     let mediaIds: string[] = [];
     let translationIds: string[] = [];
     try {
@@ -204,25 +207,16 @@ export default function AdminPosts() {
 
       if (mediaIds.length > 0) {
         try {
-          const [postsAll, pagesAll, translationsAll] = await Promise.all([
-            pb.collection("posts").getFullList({ fields: "body,content" }),
-            pb.collection("pages").getFullList({ fields: "body,content" }),
-            pb.collection("post_translations").getFullList({ fields: "body,content" }),
-          ]);
-          const blobs = [
-            ...postsAll.map((item: any) => `${item.body ?? ""} ${item.content ?? ""}`),
-            ...pagesAll.map((item: any) => `${item.body ?? ""} ${item.content ?? ""}`),
-            ...translationsAll.map((item: any) => `${item.body ?? ""} ${item.content ?? ""}`),
-          ];
-          for (const mediaId of mediaIds) {
-            const marker = `/api/files/media/${mediaId}/`;
-            const inUse = blobs.some((text) => text.includes(marker));
-            if (!inUse) {
+          // The server drops this post's media references on delete, so
+          // anything left without usage is safe to remove.
+          for (const mediaId of new Set(mediaIds)) {
+            const usage = await fetchMediaUsage(mediaId);
+            if (usage.length === 0) {
               await pb.collection("media").delete(mediaId);
             }
           }
         } catch {
-          // ignore media cleanup errors
+          setCleanupError("The post was deleted, but its unused media could not be cleaned up. Check the media library.");
         }
       }
 
@@ -245,7 +239,7 @@ export default function AdminPosts() {
           New Post
         </Link>
       </header>
-      <FormStatusMessage error={error} />
+      <FormStatusMessage error={error || cleanupError} />
       <AdminConfirmDialog
         open={publishConfirmOpen && pendingPublishValue !== null}
         title={pendingPublishValue ? "Publish selected posts" : "Unpublish selected posts"}
@@ -0,0 +1,156 @@
+import { useEffect, useState } from "react";
+import { pb, RevalidationOutboxRecord } from "@cms/lib/pb";
+import { AdminButton, AdminTable } from "@cms/ui/AriaControls";
+import FormStatusMessage from "@cms/ui/FormStatusMessage";
+import useAdminPageTitle from "@cms/useAdminPageTitle";
+import { parseServerDateTime } from "@cms/utils/datetime";
+
+export default function AdminRevalidation() {
+  const [entries, setEntries] = useState<RevalidationOutboxRecord[]>([]);
+  const [loading, setLoading] = useState(false);
+  const [reloadToken, setReloadToken] = useState(0);
+  const [error, setError] = useState("");
+
+  useAdminPageTitle("Revalidation");
+
+  useEffect(() => {
+    let alive = true;
+    const loadEntries = async () => {
+      setLoading(true);
+      setError("");
+      try {
+        const items = await pb.collection("revalidation_outbox").getFullList<RevalidationOutboxRecord>({
+          sort: "-created",
+        });
+        if (!alive) return;
+        setEntries(items);
+      } catch {
+        if (!alive) return;
+        setEntries([]);
+        setError("The revalidation queue could not be loaded. Refresh and try again.");
+      } finally {
+        if (alive) setLoading(false);
+      }
+    };
+    loadEntries();
+    return () => {
+      alive = false;
+    };
+  }, [reloadToken]);
+
+  const retry = async (id: string) => {
+    setError("");
+    try {
+      await pb.collection("revalidation_outbox").update(id, { status: "pending" });
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("This entry could not be requeued. Try again.");
+    }
+  };
+
+  const discard = async (id: string) => {
+    setError("");
+    try {
+      await pb.collection("revalidation_outbox").delete(id);
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("This entry could not be discarded. Try again.");
+    }
+  };
+
+  const deadCount = entries.filter((item) => item.status === "dead").length;
+
+  return (
+    <section>
+      <header className="admin-header">
+        <div>
+          <p className="admin-eyebrow">Site</p>
+          <h1>Revalidation</h1>
+        </div>
+        <AdminButton className="admin-secondary" disabled={loading} onPress={() => setReloadToken((n) => n + 1)}>
+          Refresh
+        </AdminButton>
+      </header>
+      <FormStatusMessage error={error} />
+      {loading ? <p className="admin-note">Loading revalidation queue…</p> : null}
+      <div className="admin-list-shell">
+        <div className="admin-table-utility admin-list-strip is-passive">
+          <div className="admin-table-utility-copy">
+            <p className="admin-section-label">Queue</p>
+            <p className="admin-table-selection">{deadCount} dead-lettered</p>
+            <p className="admin-note">{entries.length - deadCount} waiting for delivery to the site server.</p>
+          </div>
+        </div>
+        <AdminTable
+          ariaLabel="Revalidation queue"
+          items={entries}
+          columns={[
+            {
+              id: "target",
+              name: "Change",
+              mobileLabel: "Change",
+              isRowHeader: true,
+              render: (item) => `${item.collection} ${item.action} ${item.record_id || ""}`.trim(),
+            },
+            {
+              id: "status",
+              name: "Status",
+              mobileLabel: "Status",
+              className: "admin-table-status-column",
+              width: "126px",
+              render: (item) => (
+                <span className={item.status === "dead" ? "admin-status-badge is-draft" : "admin-status-badge is-published"}>
+                  {item.status === "dead" ? "Dead" : item.status === "delivering" ? "Delivering" : "Pending"}
+                </span>
+              ),
+            },
+            {
+              id: "attempts",
+              name: "Attempts",
+              mobileLabel: "Attempts",
+              width: "96px",
+              render: (item) => String(item.attempts ?? 0),
+            },
+            {
+              id: "next",
+              name: "Next attempt",
+              mobileLabel: "Next attempt",
+              width: "180px",
+              render: (item) =>
+                item.status === "dead" ? "—" : parseServerDateTime(item.next_attempt_at)?.toLocaleString("ja-JP") ?? "—",
+            },
+            {
+              id: "error",
+              name: "Last error",
+              mobileLabel: "Last error",
+              render: (item) => item.last_error || "—",
+            },
+            {
+              id: "actions",
+              name: "Action",
+              mobileLabel: "Action",
+              width: "160px",
+              render: (item) => (
+                <div className="admin-actions">
+                  {item.status === "dead" ? (
+                    <AdminButton className="admin-secondary" onPress={() => void retry(item.id)}>
+                      Retry
+                    </AdminButton>
+                  ) : null}
+                  <AdminButton ariaLabel={`Discard ${item.collection} ${item.action}`} className="admin-danger-button" onPress={() => void discard(item.id)}>
+                    🗑
+                  </AdminButton>
+                </div>
+              ),
+            },
+          ]}
+        />
+      </div>
+      {!loading && !error && entries.length === 0 ? (
+        <div className="admin-empty-state">
+          <p>Every change has been delivered to the site server.</p>
+        </div>
+      ) : null}
+    </section>
+  );
+}
@@ -0,0 +1,291 @@
+import { useEffect, useState } from "react";
+import { SeriesRecord, pb } from "@cms/lib/pb";
+import { AdminButton, AdminSelectField, AdminTable, AdminTextAreaField, AdminTextField } from "@cms/ui/AriaControls";
+import FormStatusMessage from "@cms/ui/FormStatusMessage";
+import useAdminPageTitle from "@cms/useAdminPageTitle";
+
+type PostOption = { id: string; title?: string; slug?: string };
+
+const emptySeries: Omit<SeriesRecord, "id"> = {
+  title: "",
+  slug: "",
+  description: "",
+  posts: [],
+  title_translations: {},
+};
+
+const slugify = (value: string) =>
+  value
+    .toLowerCase()
+    .trim()
+    .replace(/[^a-z0-9]+/g, "-")
+    .replace(/^-+|-+$/g, "");
+
+const parseTitleTranslations = (value: string) => {
+  const out: Record<string, string> = {};
+  value.split("\n").forEach((line) => {
+    const [locale, ...rest] = line.split("=");
+    const title = rest.join("=").trim();
+    if (locale.trim() && title) out[locale.trim().toLowerCase()] = title;
+  });
+  return out;
+};
+
+const formatTitleTranslations = (value?: Record<string, string> | null) =>
+  Object.entries(value || {})
+    .map(([locale, title]) => `${locale}=${title}`)
+    .join("\n");
+
+export default function AdminSeries() {
+  const [series, setSeries] = useState<SeriesRecord[]>([]);
+  const [posts, setPosts] = useState<PostOption[]>([]);
+  const [editingId, setEditingId] = useState<string | null>(null);
+  const [form, setForm] = useState(emptySeries);
+  const [titleTranslations, setTitleTranslations] = useState("");
+  const [pendingPost, setPendingPost] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [saving, setSaving] = useState(false);
+  const [reloadToken, setReloadToken] = useState(0);
+  const [error, setError] = useState("");
+  const [success, setSuccess] = useState("");
+
+  useAdminPageTitle("Series");
+
+  useEffect(() => {
+    let alive = true;
+    const load = async () => {
+      setLoading(true);
+      setError("");
+      try {
+        const [seriesItems, postItems] = await Promise.all([
+          pb.collection("series").getFullList<SeriesRecord>({ sort: "title" }),
+          pb.collection("posts").getFullList<PostOption>({ sort: "-published_at", fields: "id,title,slug" }),
+        ]);
+        if (!alive) return;
+        setSeries(seriesItems);
+        setPosts(postItems);
+      } catch {
+        if (!alive) return;
+        setSeries([]);
+        setError("Series could not be loaded. Refresh and try again.");
+      } finally {
+        if (alive) setLoading(false);
+      }
+    };
+    load();
+    return () => {
+      alive = false;
+    };
+  }, [reloadToken]);
+
+  const edit = (item: SeriesRecord | null) => {
+    setSuccess("");
+    setError("");
+    setPendingPost("");
+    setEditingId(item ? item.id : "new");
+    setForm(item ? { ...emptySeries, ...item, posts: item.posts || [] } : emptySeries);
+    setTitleTranslations(formatTitleTranslations(item?.title_translations));
+  };
+
+  const postLabel = (id: string) => {
+    const post = posts.find((item) => item.id === id);
+    return post ? post.title || post.slug || post.id : id;
+  };
+
+  const addPart = () => {
+    if (!pendingPost || form.posts.includes(pendingPost)) return;
+    setForm((prev) => ({ ...prev, posts: [...prev.posts, pendingPost] }));
+    setPendingPost("");
+  };
+
+  const movePart = (index: number, offset: number) => {
+    setForm((prev) => {
+      const next = [...prev.posts];
+      const target = index + offset;
+      if (target < 0 || target >= next.length) return prev;
+      [next[index], next[target]] = [next[target], next[index]];
+      return { ...prev, posts: next };
+    });
+  };
+
+  const removePart = (index: number) => {
+    setForm((prev) => ({ ...prev, posts: prev.posts.filter((_, i) => i !== index) }));
+  };
+
+  const save = async () => {
+    setSaving(true);
+    setError("");
+    setSuccess("");
+    const payload = {
+      ...form,
+      title: form.title.trim(),
+      slug: slugify(form.slug || form.title),
+      description: (form.description || "").trim(),
+      title_translations: parseTitleTranslations(titleTranslations),
+    };
+    try {
+      if (editingId && editingId !== "new") {
+        await pb.collection("series").update(editingId, payload);
+      } else {
+        await pb.collection("series").create(payload);
+      }
+      setEditingId(null);
+      setSuccess("Series saved. Every part and the series page will be regenerated.");
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("The series could not be saved. Check that the slug is not already taken.");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const remove = async (id: string) => {
+    setError("");
+    try {
+      await pb.collection("series").delete(id);
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("This series could not be deleted. Refresh and try again.");
+    }
+  };
+
+  return (
+    <section>
+      <header className="admin-header">
+        <div>
+          <p className="admin-eyebrow">Content</p>
+          <h1>Series</h1>
+        </div>
+        <AdminButton className="admin-primary" onPress={() => edit(null)}>
+          New series
+        </AdminButton>
+      </header>
+      <FormStatusMessage error={error} success={success} />
+      {loading ? <p className="admin-note">Loading series…</p> : null}
+      {editingId ? (
+        <div className="admin-settings-subsection">
+          <AdminTextField
+            label="Title"
+            value={form.title}
+            onChange={(value) => setForm((prev) => ({ ...prev, title: value }))}
+            required
+          />
+          <AdminTextField
+            label="Slug"
+            value={form.slug}
+            onChange={(value) => setForm((prev) => ({ ...prev, slug: value }))}
+            placeholder={slugify(form.title)}
+          />
+          <AdminTextAreaField
+            label="Description"
+            value={form.description || ""}
+            onChange={(value) => setForm((prev) => ({ ...prev, description: value }))}
+            rows={3}
+          />
+          <AdminTextAreaField
+            label="Translated titles"
+            value={titleTranslations}
+            onChange={setTitleTranslations}
+            placeholder={"en=Go basics\nfr=Les bases de Go"}
+            rows={3}
+          />
+          <p className="admin-note">Parts, in reading order. Translations of each part are linked automatically.</p>
+          <ol className="admin-series-parts">
+            {form.posts.map((id, index) => (
+              <li key={id}>
+                <span>{postLabel(id)}</span>
+                <div className="admin-actions">
+                  <AdminButton ariaLabel={`Move ${postLabel(id)} up`} className="admin-secondary" disabled={index === 0} onPress={() => movePart(index, -1)}>
+                    ↑
+                  </AdminButton>
+                  <AdminButton
+                    ariaLabel={`Move ${postLabel(id)} down`}
+                    className="admin-secondary"
+                    disabled={index === form.posts.length - 1}
+                    onPress={() => movePart(index, 1)}
+                  >
+                    ↓
+                  </AdminButton>
+                  <AdminButton ariaLabel={`Remove ${postLabel(id)}`} className="admin-danger-button" onPress={() => removePart(index)}>
+                    🗑
+                  </AdminButton>
+                </div>
+              </li>
+            ))}
+          </ol>
+          <div className="admin-actions">
+            <AdminSelectField
+              label="Add part"
+              value={pendingPost}
+              onChange={setPendingPost}
+              placeholder="Select a post"
+              options={posts
+                .filter((post) => !form.posts.includes(post.id))
+                .map((post) => ({ value: post.id, label: post.title || post.slug || post.id }))}
+            />
+            <AdminButton className="admin-secondary" disabled={!pendingPost} onPress={addPart}>
+              Add
+            </AdminButton>
+          </div>
+          <div className="admin-actions">
+            <AdminButton className="admin-primary" disabled={saving || !form.title.trim()} onPress={() => void save()}>
+              {saving ? "Saving…" : "Save series"}
+            </AdminButton>
+            <AdminButton className="admin-secondary" onPress={() => setEditingId(null)}>
+              Cancel
+            </AdminButton>
+          </div>
+        </div>
+      ) : null}
+      <div className="admin-list-shell">
+        <AdminTable
+          ariaLabel="Series"
+          items={series}
+          columns={[
+            {
+              id: "title",
+              name: "Title",
+              mobileLabel: "Title",
+              isRowHeader: true,
+              render: (item) => item.title,
+            },
+            {
+              id: "slug",
+              name: "Page",
+              mobileLabel: "Page",
+              render: (item) => `/series/${item.slug}/`,
+            },
+            {
+              id: "parts",
+              name: "Parts",
+              mobileLabel: "Parts",
+              width: "100px",
+              render: (item) => String((item.posts || []).length),
+            },
+            {
+              id: "actions",
+              name: "Action",
+              mobileLabel: "Action",
+              width: "160px",
+              render: (item) => (
+                <div className="admin-actions">
+                  <AdminButton className="admin-secondary" onPress={() => edit(item)}>
+                    Edit
+                  </AdminButton>
+                  <AdminButton ariaLabel={`Delete ${item.title}`} className="admin-danger-button" onPress={() => void remove(item.id)}>
+                    🗑
+                  </AdminButton>
+                </div>
+              ),
+            },
+          ]}
+        />
+      </div>
+      {!loading && !error && series.length === 0 ? (
+        <div className="admin-empty-state">
+          <p>No series yet. Group multi-part posts into a series to give readers part navigation.</p>
+        </div>
+      ) : null}
+    </section>
+  );
+}
@@ -28,6 +28,26 @@ const translationLanguageOptions = [
   "ru",
 ];
 
+const translationProviderOptions = [
+  { value: "gemini", label: "Gemini" },
+  { value: "openai", label: "OpenAI-compatible (OpenAI, llama.cpp, Ollama)" },
+  { value: "deepl", label: "DeepL" },
+];
+
+const secretKeyFields = [
+  { field: "gemini_api_key", label: "Gemini API Key" },
+  { field: "openai_api_key", label: "OpenAI-compatible API Key" },
+  { field: "deepl_api_key", label: "DeepL API Key" },
+] as const;
+
+type SecretKeyField = (typeof secretKeyFields)[number]["field"];
+
+const emptySecretDrafts: Record<SecretKeyField, string> = {
+  gemini_api_key: "",
+  openai_api_key: "",
+  deepl_api_key: "",
+};
+
 const defaults = {
   site_name: "Example Blog",
   description: "A calm place to write.",
@@ -51,6 +71,8 @@ const defaults = {
   comments_script_tag: "",
   enable_code_highlight: true,
   highlight_theme: "github-dark",
+  media_encoder: "auto",
+  media_keep_metadata: false,
   archive_page_size: 10,
   excerpt_length: 0,
   home_page_size: 3,
@@ -63,11 +85,21 @@ const defaults = {
   enable_post_translation: false,
   translation_source_locale: "ja",
   translation_locales: "en",
+  translation_provider: "gemini",
   translation_model: "gemini-1.5-flash",
+  translation_endpoint: "",
   translation_requests_per_minute: 60,
+  translation_approved_only: false,
 };
 
-type SettingsRecord = typeof defaults & { id?: string };
+type SettingsRecord = typeof defaults & { id?: string; translation_locale_providers?: unknown; ui_strings?: unknown };
+
+const formatJSONSetting = (value: unknown) => {
+  if (!value || (typeof value === "object" && Object.keys(value as object).length === 0)) {
+    return "";
+  }
+  return JSON.stringify(value, null, 2);
+};
 
 function SettingsSection({
   id,
@@ -140,8 +172,11 @@ export default function AdminSettings() {
   const [saving, setSaving] = useState(false);
   const [themeLocked, setThemeLocked] = useState(false);
   const [themeCheckDone, setThemeCheckDone] = useState(false);
-  const [geminiApiKey, setGeminiApiKey] = useState("");
-  const [hasGeminiApiKey, setHasGeminiApiKey] = useState(false);
+  const [uploadedThemes, setUploadedThemes] = useState<string[]>([]);
+  const [secretDrafts, setSecretDrafts] = useState<Record<SecretKeyField, string>>(emptySecretDrafts);
+  const [savedSecrets, setSavedSecrets] = useState<Partial<Record<SecretKeyField, boolean>>>({});
+  const [localeProvidersText, setLocaleProvidersText] = useState("");
+  const [uiStringsText, setUIStringsText] = useState("");
   const [error, setError] = useState("");
   const [dirty, setDirty] = useState(false);
   const [lastSavedAt, setLastSavedAt] = useState("");
@@ -156,6 +191,8 @@ export default function AdminSettings() {
         if (res.items.length > 0) {
           const merged = { ...defaults, ...res.items[0] };
           setSettings(merged);
+          setLocaleProvidersText(formatJSONSetting(merged.translation_locale_providers));
+          setUIStringsText(formatJSONSetting(merged.ui_strings));
         } else {
           setSettings(defaults);
         }
@@ -165,11 +202,15 @@ export default function AdminSettings() {
       } finally {
         if (canManageSecrets) {
           try {
-            const secretRes = await pb.collection("app_secrets").getList(1, 1, { fields: "id,gemini_api_key" });
-            const storedKey = String(secretRes.items[0]?.gemini_api_key || "").trim();
-            setHasGeminiApiKey(storedKey !== "");
+            const secretRes = await pb.collection("app_secrets").getList(1, 1, {
+              fields: ["id", ...secretKeyFields.map((item) => item.field)].join(","),
+            });
+            const stored = secretRes.items[0];
+            setSavedSecrets(
+              Object.fromEntries(secretKeyFields.map((item) => [item.field, String(stored?.[item.field] || "").trim() !== ""])),
+            );
           } catch {
-            setHasGeminiApiKey(false);
+            setSavedSecrets({});
           }
         }
         setLoading(false);
@@ -196,6 +237,13 @@ export default function AdminSettings() {
     check();
   }, []);
 
+  useEffect(() => {
+    pb.collection("themes")
+      .getFullList<{ name: string }>({ sort: "name", fields: "name" })
+      .then((items) => setUploadedThemes(items.map((item) => item.name)))
+      .catch(() => setUploadedThemes([]));
+  }, []);
+
   const update = (key: keyof SettingsRecord, value: string | number | boolean) => {
     setSettings((prev) => ({ ...prev, [key]: value }));
     setDirty(true);
@@ -220,33 +268,57 @@ export default function AdminSettings() {
         setError("Settings record is not initialized yet. Please restart PocketBase.");
         return;
       }
+      let localeProviders: unknown = null;
+      if (localeProvidersText.trim() !== "") {
+        try {
+          localeProviders = JSON.parse(localeProvidersText);
+        } catch {
+          setError("Per-locale providers must be valid JSON.");
+          return;
+        }
+      }
+      let uiStrings: unknown = null;
+      if (uiStringsText.trim() !== "") {
+        try {
+          uiStrings = JSON.parse(uiStringsText);
+        } catch {
+          setError("UI strings must be valid JSON.");
+          return;
+        }
+      }
       const payload = {
         ...settings,
         translation_source_locale: settings.translation_source_locale.trim().toLowerCase(),
         translation_locales: settings.translation_locales.trim().toLowerCase(),
+        translation_provider: settings.translation_provider.trim(),
         translation_model: settings.translation_model.trim(),
+        translation_endpoint: settings.translation_endpoint.trim(),
+        translation_locale_providers: localeProviders,
+        ui_strings: uiStrings,
         translation_requests_per_minute: Number(settings.translation_requests_per_minute) || 60,
       };
       delete payload.id;
       const updated = await pb.collection("settings").update(settingsId, payload);
       setSettings({ ...defaults, ...updated });
+      setLocaleProvidersText(formatJSONSetting(updated.translation_locale_providers));
+      setUIStringsText(formatJSONSetting(updated.ui_strings));
       setDirty(false);
       setLastSavedAt(new Date().toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" }));
 
-      const trimmedGeminiKey = geminiApiKey.trim();
-      if (canManageSecrets && trimmedGeminiKey !== "") {
+      const secretPayload = Object.fromEntries(
+        secretKeyFields
+          .map((item) => [item.field, secretDrafts[item.field].trim()] as const)
+          .filter(([, value]) => value !== ""),
+      );
+      if (canManageSecrets && Object.keys(secretPayload).length > 0) {
         const secretRes = await pb.collection("app_secrets").getList(1, 1, { fields: "id" });
         if (secretRes.items.length > 0) {
-          await pb.collection("app_secrets").update(secretRes.items[0].id, {
-            gemini_api_key: trimmedGeminiKey,
-          });
+          await pb.collection("app_secrets").update(secretRes.items[0].id, secretPayload);
         } else {
-          await pb.collection("app_secrets").create({
-            gemini_api_key: trimmedGeminiKey,
-          });
+          await pb.collection("app_secrets").create(secretPayload);
         }
-        setGeminiApiKey("");
-        setHasGeminiApiKey(true);
+        setSecretDrafts(emptySecretDrafts);
+        setSavedSecrets((prev) => ({ ...prev, ...Object.fromEntries(Object.keys(secretPayload).map((field) => [field, true])) }));
         setDirty(false);
         setLastSavedAt(new Date().toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" }));
       }
@@ -349,6 +421,7 @@ export default function AdminSettings() {
               { value: "wiki", label: "Wiki" },
               { value: "docs", label: "Docs" },
               { value: "minimal", label: "Minimal" },
+              ...uploadedThemes.map((name) => ({ value: name, label: `${name} (uploaded)` })),
             ]}
           />
           {themeCheckDone && themeLocked && (
@@ -362,7 +435,7 @@ export default function AdminSettings() {
           id="settings-translation"
           eyebrow="Automation"
           title="Translation Pipeline"
-          note="Control source locale, target locales, generation model, and request pacing."
+          note="Control source locale, target locales, translation provider, and request pacing."
         >
           <SettingRow
             label="Enable post translation"
@@ -387,7 +460,29 @@ export default function AdminSettings() {
             }))}
           />
           <AdminTextField label="Translation locales (comma separated)" value={settings.translation_locales} onChange={(value) => update("translation_locales", value)} placeholder="en, zh-cn" />
-          <AdminTextField label="Gemini model" value={settings.translation_model} onChange={(value) => update("translation_model", value)} placeholder="gemini-1.5-flash" />
+          <AdminSelectField
+            label="Translation provider"
+            value={settings.translation_provider || "gemini"}
+            onChange={(value) => update("translation_provider", value)}
+            options={translationProviderOptions}
+          />
+          <AdminTextField label="Translation model" value={settings.translation_model} onChange={(value) => update("translation_model", value)} placeholder="gemini-1.5-flash / gpt-4o-mini" />
+          <AdminTextField
+            label="Translation endpoint"
+            value={settings.translation_endpoint}
+            onChange={(value) => update("translation_endpoint", value)}
+            placeholder="https://api.openai.com/v1 or http://localhost:11434/v1"
+          />
+          <AdminTextAreaField
+            label="Per-locale providers (JSON)"
+            value={localeProvidersText}
+            onChange={(value) => {
+              setLocaleProvidersText(value);
+              setDirty(true);
+            }}
+            rows={4}
+            placeholder={`{"zh-cn": "deepl", "ko": {"provider": "openai", "model": "qwen2.5", "endpoint": "http://localhost:11434/v1"}}`}
+          />
           <AdminTextField
             label="Translation requests/minute"
             type="number"
@@ -396,18 +491,25 @@ export default function AdminSettings() {
             min={1}
             max={1000}
           />
-          {canManageSecrets && (
-            <AdminTextField
-              label={`Gemini API Key ${hasGeminiApiKey ? "(saved)" : "(not set)"}`}
-              type="password"
-              value={geminiApiKey}
-              onChange={(value) => {
-                setGeminiApiKey(value);
-                setDirty(true);
-              }}
-              placeholder={hasGeminiApiKey ? "Saved key is hidden. Leave blank to keep it." : "Paste a new key to save it."}
-            />
-          )}
+          <SettingRow
+            label="Serve approved translations only"
+            description="Hide machine-translated, in-review, and stale translations from the public site."
+            control={<AdminCheckboxField ariaLabel="Serve approved translations only" className="admin-check admin-setting-toggle" label="" checked={settings.translation_approved_only} onChange={(checked) => update("translation_approved_only", checked)} />}
+          />
+          {canManageSecrets &&
+            secretKeyFields.map((item) => (
+              <AdminTextField
+                key={item.field}
+                label={`${item.label} ${savedSecrets[item.field] ? "(saved)" : "(not set)"}`}
+                type="password"
+                value={secretDrafts[item.field]}
+                onChange={(value) => {
+                  setSecretDrafts((prev) => ({ ...prev, [item.field]: value }));
+                  setDirty(true);
+                }}
+                placeholder={savedSecrets[item.field] ? "Saved key is hidden. Leave blank to keep it." : "Paste a new key to save it."}
+              />
+            ))}
         </SettingsSection>
 
         <SettingsSection
@@ -468,6 +570,18 @@ export default function AdminSettings() {
                 control={<AdminCheckboxField ariaLabel="Show categories" className="admin-check admin-setting-toggle" label="" checked={settings.show_categories} onChange={(checked) => update("show_categories", checked)} />}
               />
             </SettingsSubsection>
+            <SettingsSubsection title="Interface text" note="Override the built-in labels, date format, and reading time per locale.">
+              <AdminTextAreaField
+                label="UI strings (JSON)"
+                value={uiStringsText}
+                onChange={(value) => {
+                  setUIStringsText(value);
+                  setDirty(true);
+                }}
+                rows={5}
+                placeholder={`{"ja": {"older_post": "← 前の投稿", "date_format": "2006/01/02"}, "en": {"read_time": "%d min read"}}`}
+              />
+            </SettingsSubsection>
             <SettingsSubsection title="Code display" note="Syntax highlighting and formatting for technical writing.">
               <SettingRow
                 label="Enable code highlight"
@@ -500,6 +614,31 @@ export default function AdminSettings() {
                 }
               />
             </SettingsSubsection>
+            <SettingsSubsection title="Media uploads" note="How uploaded images are re-encoded before they are stored.">
+              <SettingRow
+                label="Image encoder"
+                description="Auto tries the built-in lossless WebP encoder, then cwebp, then keeps the original format."
+                control={
+                  <AdminSelectField
+                    ariaLabel="Image encoder"
+                    label=""
+                    value={settings.media_encoder}
+                    onChange={(value) => update("media_encoder", value)}
+                    options={[
+                      { value: "auto", label: "Auto" },
+                      { value: "go", label: "Built-in (lossless WebP)" },
+                      { value: "cwebp", label: "cwebp" },
+                      { value: "none", label: "Keep original format" },
+                    ]}
+                  />
+                }
+              />
+              <SettingRow
+                label="Keep image metadata"
+                description="Keep EXIF data such as camera details and GPS location. When off, metadata is removed and photos are rotated upright."
+                control={<AdminCheckboxField ariaLabel="Keep image metadata" className="admin-check admin-setting-toggle" label="" checked={settings.media_keep_metadata} onChange={(checked) => update("media_keep_metadata", checked)} />}
+              />
+            </SettingsSubsection>
           </div>
         </SettingsSection>
 
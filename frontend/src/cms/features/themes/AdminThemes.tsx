@@ -0,0 +1,174 @@
+import { useEffect, useState } from "react";
+import { ThemeRecord, hasRole, pb } from "@cms/lib/pb";
+import { AdminButton, AdminFileTriggerField, AdminTable, AdminTextField } from "@cms/ui/AriaControls";
+import FormStatusMessage from "@cms/ui/FormStatusMessage";
+import useAdminPageTitle from "@cms/useAdminPageTitle";
+
+const themeNamePattern = /^[a-z0-9][a-z0-9_-]*$/;
+
+export default function AdminThemes() {
+  const [themes, setThemes] = useState<ThemeRecord[]>([]);
+  const [name, setName] = useState("");
+  const [description, setDescription] = useState("");
+  const [pkg, setPkg] = useState<File | null>(null);
+  const [loading, setLoading] = useState(false);
+  const [saving, setSaving] = useState(false);
+  const [reloadToken, setReloadToken] = useState(0);
+  const [error, setError] = useState("");
+  const [success, setSuccess] = useState("");
+  const canManage = hasRole(["admin"]);
+
+  useAdminPageTitle("Themes");
+
+  useEffect(() => {
+    let alive = true;
+    const load = async () => {
+      setLoading(true);
+      setError("");
+      try {
+        const items = await pb.collection("themes").getFullList<ThemeRecord>({ sort: "name" });
+        if (alive) setThemes(items);
+      } catch {
+        if (!alive) return;
+        setThemes([]);
+        setError("Themes could not be loaded. Refresh and try again.");
+      } finally {
+        if (alive) setLoading(false);
+      }
+    };
+    load();
+    return () => {
+      alive = false;
+    };
+  }, [reloadToken]);
+
+  const upload = async () => {
+    const trimmed = name.trim().toLowerCase();
+    setError("");
+    setSuccess("");
+    if (!themeNamePattern.test(trimmed)) {
+      setError("Theme names use lowercase letters, numbers, - and _.");
+      return;
+    }
+    if (!pkg) {
+      setError("Choose a theme package (.zip) to upload.");
+      return;
+    }
+    const form = new FormData();
+    form.set("name", trimmed);
+    form.set("description", description.trim());
+    form.set("package", pkg);
+    setSaving(true);
+    try {
+      const existing = themes.find((item) => item.name === trimmed);
+      if (existing) {
+        await pb.collection("themes").update(existing.id, form);
+      } else {
+        await pb.collection("themes").create(form);
+      }
+      setName("");
+      setDescription("");
+      setPkg(null);
+      setSuccess(`Theme "${trimmed}" uploaded. Preview it with ?theme=${trimmed} or select it in Settings.`);
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("The theme could not be uploaded. Check that the package is a zip with a theme.json.");
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const remove = async (id: string) => {
+    setError("");
+    try {
+      await pb.collection("themes").delete(id);
+      setReloadToken((n) => n + 1);
+    } catch {
+      setError("This theme could not be deleted. Refresh and try again.");
+    }
+  };
+
+  return (
+    <section>
+      <header className="admin-header">
+        <div>
+          <p className="admin-eyebrow">Presentation</p>
+          <h1>Themes</h1>
+        </div>
+      </header>
+      <FormStatusMessage error={error} success={success} />
+      <p className="admin-note">
+        A theme package is a zip with a theme.json, a templates directory of html/template files, and its assets. Templates the
+        package leaves out fall back to the built-in theme.
+      </p>
+      {canManage ? (
+        <div className="admin-settings-subsection">
+          <AdminTextField label="Name" value={name} onChange={setName} placeholder="paper" required />
+          <AdminTextField label="Description" value={description} onChange={setDescription} />
+          <AdminFileTriggerField
+            label="Package"
+            buttonLabel="Choose zip"
+            acceptedFileTypes={["application/zip", ".zip"]}
+            description={pkg ? pkg.name : "No package selected."}
+            onSelect={(files) => setPkg(files?.[0] ?? null)}
+          />
+          <div className="admin-actions">
+            <AdminButton className="admin-primary" disabled={saving || !name.trim() || !pkg} onPress={() => void upload()}>
+              {saving ? "Uploading…" : "Upload theme"}
+            </AdminButton>
+          </div>
+        </div>
+      ) : null}
+      {loading ? <p className="admin-note">Loading themes…</p> : null}
+      <div className="admin-list-shell">
+        <AdminTable
+          ariaLabel="Themes"
+          items={themes}
+          columns={[
+            {
+              id: "name",
+              name: "Name",
+              mobileLabel: "Name",
+              isRowHeader: true,
+              render: (item) => item.name,
+            },
+            {
+              id: "description",
+              name: "Description",
+              mobileLabel: "Description",
+              render: (item) => item.description || "",
+            },
+            {
+              id: "preview",
+              name: "Preview",
+              mobileLabel: "Preview",
+              width: "120px",
+              render: (item) => (
+                <a href={`/?theme=${encodeURIComponent(item.name)}`} target="_blank" rel="noreferrer">
+                  Preview
+                </a>
+              ),
+            },
+            {
+              id: "actions",
+              name: "Action",
+              mobileLabel: "Action",
+              width: "100px",
+              render: (item) =>
+                canManage ? (
+                  <AdminButton ariaLabel={`Delete ${item.name}`} className="admin-danger-button" onPress={() => void remove(item.id)}>
+                    🗑
+                  </AdminButton>
+                ) : null,
+            },
+          ]}
+        />
+      </div>
+      {!loading && !error && themes.length === 0 ? (
+        <div className="admin-empty-state">
+          <p>No uploaded themes. The bundled themes and any packages under PUBLIC_DIR/themes are always available.</p>
+        </div>
+      ) : null}
+    </section>
+  );
+}
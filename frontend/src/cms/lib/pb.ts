@@ -38,6 +38,7 @@ export type PostRecord = {
   featured_image?: string;
   attachments?: string[];
   published_at?: string;
+  unpublish_at?: string;
   published?: boolean;
 };
 
@@ -55,8 +56,36 @@ export type PostTranslationRecord = {
   featured_image?: string;
   attachments?: string[];
   published_at?: string;
+  unpublish_at?: string;
   published?: boolean;
   translation_done?: boolean;
+  translation_status?: "machine" | "in_review" | "approved" | "stale";
+};
+
+export type AuthorRecord = {
+  id: string;
+  user: string;
+  slug: string;
+  display_name: string;
+  bio?: string;
+  avatar?: string;
+  url?: string;
+};
+
+export type SeriesRecord = {
+  id: string;
+  title: string;
+  slug: string;
+  description?: string;
+  posts: string[];
+  title_translations?: Record<string, string> | null;
+};
+
+export type ThemeRecord = {
+  id: string;
+  name: string;
+  description?: string;
+  package?: string;
 };
 
 export type TranslationJobRecord = {
@@ -71,6 +100,19 @@ export type TranslationJobRecord = {
   finished_at?: string;
 };
 
+export type RevalidationOutboxRecord = {
+  id: string;
+  collection: string;
+  action: "create" | "update" | "delete";
+  record_id?: string;
+  status?: "pending" | "delivering" | "dead";
+  attempts?: number;
+  last_status?: number;
+  last_error?: string;
+  next_attempt_at?: string;
+  created?: string;
+};
+
 export type PageRecord = {
   id: string;
   title: string;
@@ -81,6 +123,7 @@ export type PageRecord = {
   menuOrder?: number;
   menuTitle?: string;
   published_at?: string;
+  unpublish_at?: string;
   published?: boolean;
 };
 